@@ -3,9 +3,9 @@ package array
 import (
 	"fmt"
 	"reflect"
-	"strings"
 
 	"github.com/khezen/struct/collection"
+	"github.com/khezen/struct/internal/format"
 )
 
 // Provides a common array baseline for both threadsafe and non-ts arrays.
@@ -83,6 +83,97 @@ func (a *array) IndexOf(item interface{}) (int, error) {
 	return -1, ErrNotFound
 }
 
+// Find returns the first item satisfying pred.
+func (a *array) Find(pred func(item interface{}) bool) (interface{}, error) {
+	i, err := a.FindIndex(pred)
+	if err != nil {
+		return nil, err
+	}
+	return a.s[i], nil
+}
+
+// FindIndex returns the index of the first item satisfying pred.
+func (a *array) FindIndex(pred func(item interface{}) bool) (int, error) {
+	for i, item := range a.s {
+		if pred(item) {
+			return i, nil
+		}
+	}
+	return -1, ErrNotFound
+}
+
+// FindLast returns the last item satisfying pred.
+func (a *array) FindLast(pred func(item interface{}) bool) (interface{}, error) {
+	for i := len(a.s) - 1; i >= 0; i-- {
+		if pred(a.s[i]) {
+			return a.s[i], nil
+		}
+	}
+	return nil, ErrNotFound
+}
+
+// LastIndexOf returns the index of the last occurrence of item.
+func (a *array) LastIndexOf(item interface{}) (int, error) {
+	for i := len(a.s) - 1; i >= 0; i-- {
+		if a.s[i] == item {
+			return i, nil
+		}
+	}
+	return -1, ErrNotFound
+}
+
+// IndexOfFrom returns the index of the first occurrence of item from start,
+// which may be the length of the array.
+func (a *array) IndexOfFrom(item interface{}, start int) (int, error) {
+	a.checkRange(start, start)
+	for i := start; i < len(a.s); i++ {
+		if a.s[i] == item {
+			return i, nil
+		}
+	}
+	return -1, ErrNotFound
+}
+
+// Count returns the number of items satisfying pred.
+func (a *array) Count(pred func(item interface{}) bool) int {
+	count := 0
+	for _, item := range a.s {
+		if pred(item) {
+			count++
+		}
+	}
+	return count
+}
+
+// RemoveIf removes the items satisfying pred and returns how many were removed.
+func (a *array) RemoveIf(pred func(item interface{}) bool) int {
+	kept := a.s[:0]
+	for _, item := range a.s {
+		if !pred(item) {
+			kept = append(kept, item)
+		}
+	}
+	for i := len(kept); i < len(a.s); i++ {
+		a.s[i] = nil
+	}
+	removed := len(a.s) - len(kept)
+	a.s = kept
+	return removed
+}
+
+// ReplaceIf replaces each item satisfying pred by substitute(item) and
+// returns how many were replaced.
+func (a *array) ReplaceIf(pred func(item interface{}) bool, substitute func(item interface{}) interface{}) int {
+	count := 0
+	for i, item := range a.s {
+		if pred(item) {
+			a.s[i] = substitute(item)
+			count++
+		}
+	}
+	return count
+}
+
 func (a *array) Swap(i, j int) {
 	itemi := a.Get(i)
 	itemj := a.Get(j)
@@ -125,7 +216,7 @@ func (a *array) IsEmpty() bool {
 	return a.Len() == 0
 }
 
-func (a *array) IsEqual(t collection.Interface) bool {
+func (a *array) IsEqual(t collection.ReadOnly) bool {
 	// Force locking only if given set is threadsafe.
 	if conv, ok := t.(*arraySync); ok {
 		conv.l.RLock()
@@ -139,8 +230,8 @@ func (a *array) IsEqual(t collection.Interface) bool {
 	if length != t.Len() {
 		return false
 	}
-	items := t.Slice()
-	for i, item := range a.Slice() {
+	items := UnsafeSlice(t)
+	for i, item := range a.s {
 		compared := items[i]
 		if reflect.TypeOf(item) != reflect.TypeOf(compared) {
 			return false
@@ -206,35 +297,147 @@ func (a *array) Retain(t collection.Interface) {
 
 // String returns a string representation of s
 func (a *array) String() string {
-	if a.IsEmpty() {
-		return "[]"
-	}
-	t := make([]string, 0, a.Len())
-	for _, item := range a.s {
-		t = append(t, fmt.Sprintf("%v", item))
-	}
-	return fmt.Sprintf("[%s]", strings.Join(t, " "))
+	return format.String(a)
 }
 
-// Slice returns a slice of all items. There is also StringSlice() and
-// IntSlice() methods for returning slices of type string or int.
+// Format implements fmt.Formatter: %v prints the items, %+v adds the type and
+// the length and %#v the Go syntax. A precision truncates the items: %.3v
+// prints the 3 first followed by "...".
+func (a *array) Format(f fmt.State, verb rune) {
+	format.Seq(f, verb, "array", "array.New", a.Slice())
+}
+
+// Slice returns a copy of the items. Use UnsafeSlice to access them without copy.
 func (a *array) Slice() []interface{} {
-	return a.s
+	s := make([]interface{}, len(a.s))
+	copy(s, a.s)
+	return s
+}
+
+// UnsafeSlice returns the slice backing a without copying it when a is one of
+// the arrays of this package, and a copy otherwise. Modifying the returned slice
+// modifies a, and threadsafe arrays are not locked while it is used.
+func UnsafeSlice(a collection.ReadOnly) []interface{} {
+	switch conv := a.(type) {
+	case *array:
+		return conv.s
+	case *arraySync:
+		return conv.s
+	case *arraySort:
+		return conv.s
+	case *arraySortSync:
+		return conv.s
+	}
+	return a.Slice()
 }
 
+// SubArray returns a copy of the items from i to j, both included.
 func (a *array) SubArray(i, j int) Interface {
 	if i > j {
 		panic(ErrBadSubsetBoudaries)
 	}
 	a.checkIndex(i)
 	a.checkIndex(j)
-	slice := a.Slice()
-	result := New(slice...)
-	result.Remove(slice[:i]...)
-	result.Remove(slice[j+1:]...)
+	return New(a.s[i : j+1]...)
+}
+
+// SliceRange returns a copy of the items from i included to j excluded.
+func (a *array) SliceRange(i, j int) Interface {
+	a.checkRange(i, j)
+	return New(a.s[i:j]...)
+}
+
+// Splice removes deleteCount items from i, inserts items at i and returns the
+// removed items. i may be the length of the array.
+func (a *array) Splice(i, deleteCount int, items ...interface{}) []interface{} {
+	a.checkRange(i, i+deleteCount)
+	removed := make([]interface{}, deleteCount)
+	copy(removed, a.s[i:])
+	s := make([]interface{}, 0, len(a.s)-deleteCount+len(items))
+	s = append(s, a.s[:i]...)
+	s = append(s, items...)
+	a.s = append(s, a.s[i+deleteCount:]...)
+	return removed
+}
+
+// RemoveRange removes the items from i included to j excluded.
+func (a *array) RemoveRange(i, j int) {
+	a.Splice(i, j-i)
+}
+
+// InsertAll inserts the items of t at i, which may be the length of the array.
+func (a *array) InsertAll(i int, t collection.ReadOnly) {
+	a.Splice(i, 0, t.Slice()...)
+}
+
+func (a *array) Reverse() {
+	reverse(a.s)
+}
+
+// Rotate moves each item k positions towards the end of the array, the last
+// items wrapping around to the beginning. A negative k rotates towards the beginning.
+func (a *array) Rotate(k int) {
+	length := len(a.s)
+	if length == 0 {
+		return
+	}
+	k %= length
+	if k < 0 {
+		k += length
+	}
+	reverse(a.s)
+	reverse(a.s[:k])
+	reverse(a.s[k:])
+}
+
+// Fill replaces the items from i included to j excluded by item.
+func (a *array) Fill(i, j int, item interface{}) {
+	a.checkRange(i, j)
+	for ; i < j; i++ {
+		a.s[i] = item
+	}
+}
+
+// Repeat returns a new array holding the items n times, or none if n <= 0.
+func (a *array) Repeat(n int) Interface {
+	if n <= 0 {
+		return New()
+	}
+	s := make([]interface{}, 0, n*len(a.s))
+	for ; n > 0; n-- {
+		s = append(s, a.s...)
+	}
+	return &array{s}
+}
+
+// Concat returns a new array holding the items followed by those of each t.
+func (a *array) Concat(t ...collection.ReadOnly) Interface {
+	return a.concat(slices(t))
+}
+
+func (a *array) concat(slices [][]interface{}) Interface {
+	result := New(a.s...)
+	for _, s := range slices {
+		result.Add(s...)
+	}
 	return result
 }
 
+// slices returns the items of each collection
+func slices(t []collection.ReadOnly) [][]interface{} {
+	s := make([][]interface{}, 0, len(t))
+	for _, c := range t {
+		s = append(s, c.Slice())
+	}
+	return s
+}
+
+func reverse(s []interface{}) {
+	for i, j := 0, len(s)-1; i < j; i, j = i+1, j-1 {
+		s[i], s[j] = s[j], s[i]
+	}
+}
+
 // Copy returns a new Set with a copy of s.
 func (a *array) CopyArr() Interface {
 	return New(a.s...)
@@ -249,3 +452,13 @@ func (a *array) checkIndex(i int) {
 		panic(ErrIndexOutOfBounds)
 	}
 }
+
+// checkRange panics unless 0 <= i <= j <= length
+func (a *array) checkRange(i, j int) {
+	if i < 0 || i > a.Len() {
+		panic(ErrIndexOutOfBounds)
+	}
+	if j < i || j > a.Len() {
+		panic(ErrBadSubsetBoudaries)
+	}
+}
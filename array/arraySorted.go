@@ -1,26 +1,402 @@
 package array
 
 import (
+	"fmt"
 	"sort"
+
+	"github.com/khezen/struct/collection"
+	"github.com/khezen/struct/internal/format"
 )
 
 type arraySort struct {
 	array
-	less func(slice []interface{}, i, j int) bool
+	order
 }
 
 // NewSorted creates an array that expose Sort method
 func NewSorted(less func(slice []interface{}, i, j int) bool, items ...interface{}) Sorted {
-	return &arraySort{
-		*(New(items...).(*array)),
-		less,
+	a := &arraySort{
+		array: *(New(items...).(*array)),
 	}
+	a.order = newOrder(less, &a.array)
+	return a
 }
 
 func (a *arraySort) Less(i, j int) bool {
-	return a.less(a.Slice(), i, j)
+	return a.less(a.s, i, j)
+}
+
+func (a *arraySort) String() string {
+	return format.String(a)
+}
+
+// Format prints the items. With %#v, it prints the syntax of an equal array
+// since less cannot be printed.
+func (a *arraySort) Format(f fmt.State, verb rune) {
+	format.Seq(f, verb, "array.Sorted", "array.New", a.Slice())
 }
 
 func (a *arraySort) Sort() {
-	sort.Sort(a)
+	a.sort(&a.array)
+}
+
+func (a *arraySort) SortStable() {
+	a.sortStable(&a.array)
+}
+
+func (a *arraySort) SetLess(less func(slice []interface{}, i, j int) bool) {
+	a.setLess(&a.array, less)
+}
+
+func (a *arraySort) IsSorted() bool {
+	return a.isSorted(&a.array)
+}
+
+func (a *arraySort) SearchSorted(item interface{}) (int, bool) {
+	return a.search(&a.array, item)
+}
+
+func (a *arraySort) InsertSorted(items ...interface{}) {
+	a.insertSorted(&a.array, items)
+}
+
+func (a *arraySort) BinaryIndexOf(item interface{}) (int, error) {
+	return a.binaryIndexOf(&a.array, item)
+}
+
+func (a *arraySort) LowerBound(item interface{}) int {
+	return a.lowerBound(&a.array, item)
+}
+
+func (a *arraySort) UpperBound(item interface{}) int {
+	return a.upperBound(&a.array, item)
+}
+
+func (a *arraySort) SetAutoSort(auto bool) {
+	a.setAutoSort(&a.array, auto)
+}
+
+func (a *arraySort) Has(items ...interface{}) bool {
+	return a.has(&a.array, items)
+}
+
+func (a *arraySort) IndexOf(item interface{}) (int, error) {
+	return a.indexOf(&a.array, item)
+}
+
+func (a *arraySort) Remove(items ...interface{}) {
+	a.remove(&a.array, items)
+}
+
+func (a *arraySort) Add(items ...interface{}) {
+	a.add(&a.array, items)
+}
+
+func (a *arraySort) Insert(i int, items ...interface{}) {
+	a.insert(&a.array, i, items)
+}
+
+func (a *arraySort) Replace(item, substitute interface{}) {
+	a.replace(&a.array, item, substitute)
+}
+
+func (a *arraySort) ReplaceAt(i int, substitute interface{}) interface{} {
+	return a.replaceAt(&a.array, i, substitute)
+}
+
+func (a *arraySort) Swap(i, j int) {
+	a.swap(&a.array, i, j)
+}
+
+func (a *arraySort) Merge(t collection.Interface) {
+	a.rewrite(&a.array, func() {
+		a.array.Merge(t)
+	})
+}
+
+func (a *arraySort) Splice(i, deleteCount int, items ...interface{}) []interface{} {
+	return a.splice(&a.array, i, deleteCount, items)
+}
+
+func (a *arraySort) InsertAll(i int, t collection.ReadOnly) {
+	a.splice(&a.array, i, 0, t.Slice())
+}
+
+func (a *arraySort) Reverse() {
+	a.rewrite(&a.array, a.array.Reverse)
+}
+
+func (a *arraySort) Rotate(k int) {
+	a.rewrite(&a.array, func() {
+		a.array.Rotate(k)
+	})
+}
+
+func (a *arraySort) Fill(i, j int, item interface{}) {
+	a.array.Fill(i, j, item)
+	a.modified(&a.array, i, j)
+}
+
+func (a *arraySort) ReplaceIf(pred func(item interface{}) bool, substitute func(item interface{}) interface{}) int {
+	var count int
+	a.rewrite(&a.array, func() {
+		count = a.array.ReplaceIf(pred, substitute)
+	})
+	return count
+}
+
+// order holds the comparison of a sorted array and what is known of its
+// ordering. Its methods are shared by arraySort and arraySortSync, which
+// lock around them.
+type order struct {
+	less   func(slice []interface{}, i, j int) bool
+	sorted bool // the items are known to be sorted
+	auto   bool // modifications keep the items sorted, which implies sorted
+}
+
+func newOrder(less func(slice []interface{}, i, j int) bool, a *array) order {
+	o := order{less: less}
+	o.isSorted(a)
+	return o
+}
+
+// sorter sorts a slice with the less function of an order
+type sorter struct {
+	s    []interface{}
+	less func(slice []interface{}, i, j int) bool
+}
+
+func (s sorter) Len() int {
+	return len(s.s)
+}
+
+func (s sorter) Less(i, j int) bool {
+	return s.less(s.s, i, j)
+}
+
+func (s sorter) Swap(i, j int) {
+	s.s[i], s.s[j] = s.s[j], s.s[i]
+}
+
+// lessItem reports whether x sorts before y
+func (o *order) lessItem(x, y interface{}) bool {
+	return o.less([]interface{}{x, y}, 0, 1)
+}
+
+func (o *order) sort(a *array) {
+	sort.Sort(sorter{a.s, o.less})
+	o.sorted = true
+}
+
+func (o *order) sortStable(a *array) {
+	a.stable(o.less)
+	o.sorted = true
+}
+
+// setLess changes the comparison, which the items may not follow anymore
+func (o *order) setLess(a *array, less func(slice []interface{}, i, j int) bool) {
+	o.less = less
+	o.sorted = false
+	if o.isSorted(a); o.auto && !o.sorted {
+		o.sort(a)
+	}
+}
+
+// isSorted checks the order of the items unless they are known to be sorted.
+// Without less function, nothing is known of the order.
+func (o *order) isSorted(a *array) bool {
+	if !o.sorted && o.less != nil {
+		o.sorted = o.inOrder(a.s)
+	}
+	return o.sorted
+}
+
+// inOrder reports whether s is sorted, and false when less cannot compare its
+// items, such as items of another type
+func (o *order) inOrder(s []interface{}) (sorted bool) {
+	defer func() {
+		if recover() != nil {
+			sorted = false
+		}
+	}()
+	return sort.IsSorted(sorter{s, o.less})
+}
+
+// lowerBound returns the index of the first item not sorting before item
+func (o *order) lowerBound(a *array, item interface{}) int {
+	return sort.Search(len(a.s), func(i int) bool {
+		return !o.lessItem(a.s[i], item)
+	})
+}
+
+// upperBound returns the index of the first item sorting after item
+func (o *order) upperBound(a *array, item interface{}) int {
+	return sort.Search(len(a.s), func(i int) bool {
+		return o.lessItem(item, a.s[i])
+	})
+}
+
+// search returns the index of item, or the one where it would be inserted
+func (o *order) search(a *array, item interface{}) (int, bool) {
+	i := o.lowerBound(a, item)
+	for j := i; j < len(a.s) && !o.lessItem(item, a.s[j]); j++ {
+		if a.s[j] == item {
+			return j, true
+		}
+	}
+	return i, false
+}
+
+// find searches item with less, reporting ok false when less cannot compare
+// item to the items, such as an item of another type
+func (o *order) find(a *array, item interface{}) (i int, found, ok bool) {
+	defer func() {
+		if recover() != nil {
+			i, found, ok = 0, false, false
+		}
+	}()
+	i, found = o.search(a, item)
+	return i, found, true
+}
+
+// binaryIndexOf falls back to a linear search when less cannot compare item
+func (o *order) binaryIndexOf(a *array, item interface{}) (int, error) {
+	i, found, ok := o.find(a, item)
+	switch {
+	case !ok:
+		return a.IndexOf(item)
+	case found:
+		return i, nil
+	}
+	return -1, ErrNotFound
+}
+
+// indexOf searches item in O(log n) once a is known to be sorted
+func (o *order) indexOf(a *array, item interface{}) (int, error) {
+	if !o.sorted {
+		return a.IndexOf(item)
+	}
+	return o.binaryIndexOf(a, item)
+}
+
+func (o *order) has(a *array, items []interface{}) bool {
+	if !o.sorted {
+		return a.Has(items...)
+	}
+	for _, item := range items {
+		if _, err := o.binaryIndexOf(a, item); err != nil {
+			return false
+		}
+	}
+	return true
+}
+
+// remove searches the items as indexOf does, and keeps the order
+func (o *order) remove(a *array, items []interface{}) {
+	for _, item := range items {
+		if i, err := o.indexOf(a, item); err == nil {
+			a.RemoveAt(i)
+		}
+	}
+}
+
+// insertSorted inserts each item after the equal ones, sorting a first
+// unless it is known to be sorted
+func (o *order) insertSorted(a *array, items []interface{}) {
+	if !o.sorted {
+		o.sort(a)
+	}
+	for _, item := range items {
+		i := o.upperBound(a, item)
+		a.s = append(a.s, nil)
+		copy(a.s[i+1:], a.s[i:])
+		a.s[i] = item
+	}
+}
+
+func (o *order) setAutoSort(a *array, auto bool) {
+	if auto && !o.sorted {
+		o.sort(a)
+	}
+	o.auto = auto
+}
+
+// modified updates what is known of the ordering once the items from i to j
+// were modified, and sorts a again in auto-sort mode
+func (o *order) modified(a *array, i, j int) {
+	if !o.sorted {
+		return
+	}
+	if i < 1 {
+		i = 1
+	}
+	if j > len(a.s)-1 {
+		j = len(a.s) - 1
+	}
+	o.sorted = o.inOrder(a.s[i-1 : j+1])
+	if o.auto && !o.sorted {
+		o.sort(a)
+	}
+}
+
+// rewrite applies f, which may modify any item
+func (o *order) rewrite(a *array, f func()) {
+	f()
+	o.modified(a, 0, len(a.s))
+}
+
+func (o *order) add(a *array, items []interface{}) {
+	if o.auto {
+		o.insertSorted(a, items)
+		return
+	}
+	length := len(a.s)
+	a.Add(items...)
+	o.modified(a, length, len(a.s))
+}
+
+// insert ignores i in auto-sort mode
+func (o *order) insert(a *array, i int, items []interface{}) {
+	if o.auto {
+		o.insertSorted(a, items)
+		return
+	}
+	a.Insert(i, items...)
+	o.modified(a, i, i+len(items))
+}
+
+// replaceAt moves the substitute to its place in auto-sort mode
+func (o *order) replaceAt(a *array, i int, substitute interface{}) interface{} {
+	if o.auto {
+		item := a.RemoveAt(i)
+		o.insertSorted(a, []interface{}{substitute})
+		return item
+	}
+	item := a.ReplaceAt(i, substitute)
+	o.modified(a, i, i+1)
+	return item
+}
+
+func (o *order) replace(a *array, item, substitute interface{}) {
+	if i, err := o.indexOf(a, item); err == nil {
+		o.replaceAt(a, i, substitute)
+	}
+}
+
+func (o *order) swap(a *array, i, j int) {
+	a.Swap(i, j)
+	o.modified(a, i, i+1)
+	o.modified(a, j, j+1)
+}
+
+// splice inserts the items at their place in auto-sort mode
+func (o *order) splice(a *array, i, deleteCount int, items []interface{}) []interface{} {
+	if o.auto {
+		removed := a.Splice(i, deleteCount)
+		o.insertSorted(a, items)
+		return removed
+	}
+	removed := a.Splice(i, deleteCount, items...)
+	o.modified(a, i, i+len(items))
+	return removed
 }
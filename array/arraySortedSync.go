@@ -1,21 +1,25 @@
 package array
 
 import (
-	"sort"
+	"fmt"
+
+	"github.com/khezen/struct/collection"
+	"github.com/khezen/struct/internal/format"
 )
 
 // Sorted is the interface for sortable arrays
 type arraySortSync struct {
 	*arraySync
-	less func(slice []interface{}, i, j int) bool
+	order
 }
 
 // NewSortedSync creates a thread safe array that expose Sort method
 func NewSortedSync(less func(slice []interface{}, i, j int) bool, items ...interface{}) Sorted {
-	return &arraySortSync{
-		(NewSync(items...).(*arraySync)),
-		less,
+	a := &arraySortSync{
+		arraySync: NewSync(items...).(*arraySync),
 	}
+	a.order = newOrder(less, &a.array)
+	return a
 }
 
 func (a *arraySortSync) Less(i, j int) bool {
@@ -24,6 +28,185 @@ func (a *arraySortSync) Less(i, j int) bool {
 	return a.less(a.arraySync.s, i, j)
 }
 
+func (a *arraySortSync) String() string {
+	return format.String(a)
+}
+
+// Format prints the items. With %#v, it prints the syntax of an equal array
+// since less cannot be printed.
+func (a *arraySortSync) Format(f fmt.State, verb rune) {
+	format.Seq(f, verb, "array.SortedSync", "array.NewSync", a.Slice())
+}
+
 func (a *arraySortSync) Sort() {
-	sort.Sort(a)
+	a.l.Lock()
+	defer a.l.Unlock()
+	a.sort(&a.array)
+}
+
+func (a *arraySortSync) SortStable() {
+	a.l.Lock()
+	defer a.l.Unlock()
+	a.sortStable(&a.array)
+}
+
+func (a *arraySortSync) SetLess(less func(slice []interface{}, i, j int) bool) {
+	a.l.Lock()
+	defer a.l.Unlock()
+	a.setLess(&a.array, less)
+}
+
+// IsSorted takes the write lock since it records the result of the check.
+func (a *arraySortSync) IsSorted() bool {
+	a.l.Lock()
+	defer a.l.Unlock()
+	return a.isSorted(&a.array)
+}
+
+func (a *arraySortSync) SearchSorted(item interface{}) (int, bool) {
+	a.l.RLock()
+	defer a.l.RUnlock()
+	return a.search(&a.array, item)
+}
+
+func (a *arraySortSync) InsertSorted(items ...interface{}) {
+	a.l.Lock()
+	defer a.l.Unlock()
+	a.insertSorted(&a.array, items)
+}
+
+func (a *arraySortSync) BinaryIndexOf(item interface{}) (int, error) {
+	a.l.RLock()
+	defer a.l.RUnlock()
+	return a.binaryIndexOf(&a.array, item)
+}
+
+func (a *arraySortSync) LowerBound(item interface{}) int {
+	a.l.RLock()
+	defer a.l.RUnlock()
+	return a.lowerBound(&a.array, item)
+}
+
+func (a *arraySortSync) UpperBound(item interface{}) int {
+	a.l.RLock()
+	defer a.l.RUnlock()
+	return a.upperBound(&a.array, item)
+}
+
+func (a *arraySortSync) SetAutoSort(auto bool) {
+	a.l.Lock()
+	defer a.l.Unlock()
+	a.setAutoSort(&a.array, auto)
+}
+
+func (a *arraySortSync) Has(items ...interface{}) bool {
+	a.l.RLock()
+	defer a.l.RUnlock()
+	return a.has(&a.array, items)
+}
+
+func (a *arraySortSync) IndexOf(item interface{}) (int, error) {
+	a.l.RLock()
+	defer a.l.RUnlock()
+	return a.indexOf(&a.array, item)
+}
+
+func (a *arraySortSync) Remove(items ...interface{}) {
+	a.l.Lock()
+	defer a.l.Unlock()
+	a.remove(&a.array, items)
+}
+
+func (a *arraySortSync) Add(items ...interface{}) {
+	a.l.Lock()
+	defer a.l.Unlock()
+	a.add(&a.array, items)
+}
+
+func (a *arraySortSync) Insert(i int, items ...interface{}) {
+	a.l.Lock()
+	defer a.l.Unlock()
+	a.insert(&a.array, i, items)
+}
+
+func (a *arraySortSync) Replace(item, substitute interface{}) {
+	a.l.Lock()
+	defer a.l.Unlock()
+	a.replace(&a.array, item, substitute)
+}
+
+func (a *arraySortSync) ReplaceAt(i int, substitute interface{}) interface{} {
+	a.l.Lock()
+	defer a.l.Unlock()
+	return a.replaceAt(&a.array, i, substitute)
+}
+
+func (a *arraySortSync) Swap(i, j int) {
+	a.l.Lock()
+	defer a.l.Unlock()
+	a.swap(&a.array, i, j)
+}
+
+func (a *arraySortSync) Merge(t collection.Interface) {
+	a.l.Lock()
+	defer a.l.Unlock()
+	a.rewrite(&a.array, func() {
+		a.array.Merge(t)
+	})
+}
+
+func (a *arraySortSync) Splice(i, deleteCount int, items ...interface{}) []interface{} {
+	a.l.Lock()
+	defer a.l.Unlock()
+	return a.splice(&a.array, i, deleteCount, items)
+}
+
+// InsertAll reads t before locking a, so that t may be a itself.
+func (a *arraySortSync) InsertAll(i int, t collection.ReadOnly) {
+	items := t.Slice()
+	a.l.Lock()
+	defer a.l.Unlock()
+	a.splice(&a.array, i, 0, items)
+}
+
+func (a *arraySortSync) Reverse() {
+	a.l.Lock()
+	defer a.l.Unlock()
+	a.rewrite(&a.array, a.array.Reverse)
+}
+
+func (a *arraySortSync) Rotate(k int) {
+	a.l.Lock()
+	defer a.l.Unlock()
+	a.rewrite(&a.array, func() {
+		a.array.Rotate(k)
+	})
+}
+
+func (a *arraySortSync) Fill(i, j int, item interface{}) {
+	a.l.Lock()
+	defer a.l.Unlock()
+	a.array.Fill(i, j, item)
+	a.modified(&a.array, i, j)
+}
+
+// ReplaceIf holds the lock while calling pred and substitute, which must not use a.
+func (a *arraySortSync) ReplaceIf(pred func(item interface{}) bool, substitute func(item interface{}) interface{}) int {
+	a.l.Lock()
+	defer a.l.Unlock()
+	var count int
+	a.rewrite(&a.array, func() {
+		count = a.array.ReplaceIf(pred, substitute)
+	})
+	return count
+}
+
+// Snapshot returns a non-threadsafe sorted copy of a consistent at the time of the call.
+func (a *arraySortSync) Snapshot() Interface {
+	a.arraySync.l.RLock()
+	defer a.arraySync.l.RUnlock()
+	return &arraySort{
+		array: *New(a.arraySync.s...).(*array),
+		order: a.order,
+	}
 }
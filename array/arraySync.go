@@ -1,9 +1,11 @@
 package array
 
 import (
+	"fmt"
 	"sync"
 
 	"github.com/khezen/struct/collection"
+	"github.com/khezen/struct/internal/format"
 )
 
 // arraySync defines a thread safe array data structure.
@@ -125,7 +127,7 @@ func (a *arraySync) IsEmpty() bool {
 	return a.array.IsEmpty()
 }
 
-func (a *arraySync) IsEqual(t collection.Interface) bool {
+func (a *arraySync) IsEqual(t collection.ReadOnly) bool {
 	a.l.RLock()
 	defer a.l.RUnlock()
 	return a.array.IsEqual(t)
@@ -156,13 +158,15 @@ func (a *arraySync) Retain(t collection.Interface) {
 }
 
 func (a *arraySync) String() string {
-	a.l.RLock()
-	defer a.l.RUnlock()
-	return a.array.String()
+	return format.String(a)
 }
 
-// Slice returns a slice of all items. There is also StringSlice() and
-// IntSlice() methods for returning slices of type string or int.
+func (a *arraySync) Format(f fmt.State, verb rune) {
+	format.Seq(f, verb, "array.Sync", "array.NewSync", a.Slice())
+}
+
+// Slice returns a slice of all items. The StringSlice and IntSlice functions
+// return slices of type string or int.
 func (a *arraySync) Slice() []interface{} {
 	a.l.RLock()
 	defer a.l.RUnlock()
@@ -179,7 +183,119 @@ func (a *arraySync) CopyArr() Interface {
 func (a *arraySync) SubArray(i, j int) Interface {
 	a.l.RLock()
 	defer a.l.RUnlock()
-	arr := a.array.SubArray(i, j)
+	return a.wrap(a.array.SubArray(i, j))
+}
+
+func (a *arraySync) SliceRange(i, j int) Interface {
+	a.l.RLock()
+	defer a.l.RUnlock()
+	return a.wrap(a.array.SliceRange(i, j))
+}
+
+func (a *arraySync) Splice(i, deleteCount int, items ...interface{}) []interface{} {
+	a.l.Lock()
+	defer a.l.Unlock()
+	return a.array.Splice(i, deleteCount, items...)
+}
+
+func (a *arraySync) RemoveRange(i, j int) {
+	a.l.Lock()
+	defer a.l.Unlock()
+	a.array.RemoveRange(i, j)
+}
+
+// InsertAll reads t before locking a, so that t may be a itself.
+func (a *arraySync) InsertAll(i int, t collection.ReadOnly) {
+	items := t.Slice()
+	a.l.Lock()
+	defer a.l.Unlock()
+	a.array.Splice(i, 0, items...)
+}
+
+func (a *arraySync) Reverse() {
+	a.l.Lock()
+	defer a.l.Unlock()
+	a.array.Reverse()
+}
+
+func (a *arraySync) Rotate(k int) {
+	a.l.Lock()
+	defer a.l.Unlock()
+	a.array.Rotate(k)
+}
+
+func (a *arraySync) Fill(i, j int, item interface{}) {
+	a.l.Lock()
+	defer a.l.Unlock()
+	a.array.Fill(i, j, item)
+}
+
+func (a *arraySync) Repeat(n int) Interface {
+	a.l.RLock()
+	defer a.l.RUnlock()
+	return a.wrap(a.array.Repeat(n))
+}
+
+// Concat reads each t before locking a, so that t may be a itself.
+func (a *arraySync) Concat(t ...collection.ReadOnly) Interface {
+	slices := slices(t)
+	a.l.RLock()
+	defer a.l.RUnlock()
+	return a.wrap(a.array.concat(slices))
+}
+
+func (a *arraySync) Find(pred func(item interface{}) bool) (interface{}, error) {
+	a.l.RLock()
+	defer a.l.RUnlock()
+	return a.array.Find(pred)
+}
+
+func (a *arraySync) FindIndex(pred func(item interface{}) bool) (int, error) {
+	a.l.RLock()
+	defer a.l.RUnlock()
+	return a.array.FindIndex(pred)
+}
+
+func (a *arraySync) FindLast(pred func(item interface{}) bool) (interface{}, error) {
+	a.l.RLock()
+	defer a.l.RUnlock()
+	return a.array.FindLast(pred)
+}
+
+func (a *arraySync) LastIndexOf(item interface{}) (int, error) {
+	a.l.RLock()
+	defer a.l.RUnlock()
+	return a.array.LastIndexOf(item)
+}
+
+func (a *arraySync) IndexOfFrom(item interface{}, start int) (int, error) {
+	a.l.RLock()
+	defer a.l.RUnlock()
+	return a.array.IndexOfFrom(item, start)
+}
+
+func (a *arraySync) Count(pred func(item interface{}) bool) int {
+	a.l.RLock()
+	defer a.l.RUnlock()
+	return a.array.Count(pred)
+}
+
+// RemoveIf holds the lock while calling pred, which must not use a.
+func (a *arraySync) RemoveIf(pred func(item interface{}) bool) int {
+	a.l.Lock()
+	defer a.l.Unlock()
+	return a.array.RemoveIf(pred)
+}
+
+// ReplaceIf holds the lock while calling pred and substitute, which must not use a.
+func (a *arraySync) ReplaceIf(pred func(item interface{}) bool, substitute func(item interface{}) interface{}) int {
+	a.l.Lock()
+	defer a.l.Unlock()
+	return a.array.ReplaceIf(pred, substitute)
+}
+
+// wrap returns a thread safe array holding the items of arr
+func (a *arraySync) wrap(arr Interface) Interface {
 	return &arraySync{
 		*arr.(*array),
 		sync.RWMutex{},
@@ -189,3 +305,10 @@ func (a *arraySync) SubArray(i, j int) Interface {
 func (a *arraySync) CopyCollection() collection.Interface {
 	return a.CopyArr()
 }
+
+// Snapshot returns a non-threadsafe copy of a consistent at the time of the call.
+func (a *arraySync) Snapshot() Interface {
+	a.l.RLock()
+	defer a.l.RUnlock()
+	return New(a.s...)
+}
@@ -1,9 +1,14 @@
 package array
 
 import (
+	"fmt"
+	"math/rand"
+	"reflect"
 	"testing"
 
 	"github.com/khezen/struct/collection"
+	"github.com/khezen/struct/compare"
+	"github.com/khezen/struct/observer"
 )
 
 func testErr(err error, expectErr bool, t *testing.T) {
@@ -168,6 +173,79 @@ func TestIndexOf(t *testing.T) {
 	}
 }
 
+func TestFind(t *testing.T) {
+	even := func(item interface{}) bool {
+		return item.(int)%2 == 0
+	}
+	none := func(item interface{}) bool {
+		return false
+	}
+	for _, a := range []Interface{New(1, 2, 3, 4, 2, 5), NewSync(1, 2, 3, 4, 2, 5)} {
+		cases := []struct {
+			f         func() (interface{}, error)
+			expected  interface{}
+			expectErr bool
+		}{
+			{func() (interface{}, error) { return a.Find(even) }, 2, false},
+			{func() (interface{}, error) { return a.Find(none) }, nil, true},
+			{func() (interface{}, error) { return a.FindIndex(even) }, 1, false},
+			{func() (interface{}, error) { return a.FindIndex(none) }, -1, true},
+			{func() (interface{}, error) { return a.FindLast(func(item interface{}) bool { return item.(int) > 3 }) }, 5, false},
+			{func() (interface{}, error) { return a.FindLast(none) }, nil, true},
+			{func() (interface{}, error) { return a.LastIndexOf(2) }, 4, false},
+			{func() (interface{}, error) { return a.LastIndexOf(9) }, -1, true},
+			{func() (interface{}, error) { return a.IndexOfFrom(2, 0) }, 1, false},
+			{func() (interface{}, error) { return a.IndexOfFrom(2, 2) }, 4, false},
+			{func() (interface{}, error) { return a.IndexOfFrom(2, 5) }, -1, true},
+			{func() (interface{}, error) { return a.IndexOfFrom(2, 6) }, -1, true},
+			{func() (interface{}, error) { return a.Count(even), nil }, 3, false},
+		}
+		for _, c := range cases {
+			v, err := c.f()
+			testErr(err, c.expectErr, t)
+			if v != c.expected {
+				t.Errorf("Expected %v. Got %v.", c.expected, v)
+			}
+		}
+		func() {
+			defer func() {
+				if r := recover(); r != ErrIndexOutOfBounds {
+					t.Errorf("Expected %v. Got %v.", ErrIndexOutOfBounds, r)
+				}
+			}()
+			a.IndexOfFrom(2, 7)
+		}()
+	}
+}
+
+func TestRemoveReplaceIf(t *testing.T) {
+	even := func(item interface{}) bool {
+		return item.(int)%2 == 0
+	}
+	double := func(item interface{}) interface{} {
+		return item.(int) * 2
+	}
+	cases := []struct {
+		array, expected Interface
+		f               func(a Interface) int
+		count           int
+	}{
+		{New(1, 2, 3, 4, 2), New(1, 3), func(a Interface) int { return a.RemoveIf(even) }, 3},
+		{New(1, 3), New(1, 3), func(a Interface) int { return a.RemoveIf(even) }, 0},
+		{NewSync(2, 4), NewSync(), func(a Interface) int { return a.RemoveIf(even) }, 2},
+		{New(1, 2, 3, 4), New(1, 4, 3, 8), func(a Interface) int { return a.ReplaceIf(even, double) }, 2},
+		{NewSync(1, 2), NewSync(1, 4), func(a Interface) int { return a.ReplaceIf(even, double) }, 1},
+	}
+	for _, c := range cases {
+		if count := c.f(c.array); count != c.count {
+			t.Errorf("Expected %v. Got %v.", c.count, count)
+		}
+		if !c.array.IsEqual(c.expected) {
+			t.Errorf("Expected %v. Got %v.", c.expected, c.array)
+		}
+	}
+}
+
 func TestSubArray(t *testing.T) {
 	cases := []struct {
 		array, expected Interface
@@ -175,6 +253,7 @@ func TestSubArray(t *testing.T) {
 	}{
 		{New(1, 42, -8, 12), New(42, -8), 1, 2},
 		{NewSync(1, 42, -8, 12), NewSync(42, -8), 1, 2},
+		{New(1, 2, 1, 2), New(2, 1), 1, 2},
 	}
 	for _, c := range cases {
 		arr := c.array.SubArray(c.i, c.j)
@@ -187,6 +266,165 @@ func TestSubArray(t *testing.T) {
 	}
 }
 
+func TestSliceRange(t *testing.T) {
+	cases := []struct {
+		array, expected Interface
+		i, j            int
+	}{
+		{New(1, 42, -8, 12), New(42, -8), 1, 3},
+		{New(1, 42, -8, 12), New(), 4, 4},
+		{New(1, 42, -8, 12), New(1, 42, -8, 12), 0, 4},
+		{NewSync(1, 42, -8, 12), NewSync(42, -8), 1, 3},
+		{New(1, 2, 1, 2), New(2, 1), 1, 3},
+	}
+	for _, c := range cases {
+		arr := c.array.SliceRange(c.i, c.j)
+		if !arr.IsEqual(c.expected) {
+			t.Errorf("Expected %v. Got %v.", c.expected, arr)
+		}
+		arr.Add(100)
+		if c.array.Has(100) {
+			t.Errorf("c.array should not be modified")
+		}
+	}
+}
+
+func TestSplice(t *testing.T) {
+	cases := []struct {
+		array, expected Interface
+		i, deleteCount  int
+		items, removed  []interface{}
+	}{
+		{New(1, 2, 3, 4), New(1, 5, 6, 7, 4), 1, 2, []interface{}{5, 6, 7}, []interface{}{2, 3}},
+		{New(1, 2, 3, 4), New(1, 2, 3, 4, 5), 4, 0, []interface{}{5}, []interface{}{}},
+		{New(1, 2, 3, 4), New(3, 4), 0, 2, nil, []interface{}{1, 2}},
+		{NewSync(1, 2, 3, 4), NewSync(1, 5, 4), 1, 2, []interface{}{5}, []interface{}{2, 3}},
+	}
+	for _, c := range cases {
+		removed := c.array.Splice(c.i, c.deleteCount, c.items...)
+		if !c.array.IsEqual(c.expected) {
+			t.Errorf("Expected %v. Got %v.", c.expected, c.array)
+		}
+		if !New(removed...).IsEqual(New(c.removed...)) {
+			t.Errorf("Expected %v. Got %v.", c.removed, removed)
+		}
+	}
+}
+
+func TestRangePanic(t *testing.T) {
+	cases := []struct {
+		f        func(a Interface)
+		expected error
+	}{
+		{func(a Interface) { a.SliceRange(2, 1) }, ErrBadSubsetBoudaries},
+		{func(a Interface) { a.SliceRange(0, 4) }, ErrBadSubsetBoudaries},
+		{func(a Interface) { a.SliceRange(-1, 1) }, ErrIndexOutOfBounds},
+		{func(a Interface) { a.Splice(1, 3) }, ErrBadSubsetBoudaries},
+		{func(a Interface) { a.Splice(1, -1) }, ErrBadSubsetBoudaries},
+		{func(a Interface) { a.Splice(4, 0) }, ErrIndexOutOfBounds},
+		{func(a Interface) { a.RemoveRange(2, 1) }, ErrBadSubsetBoudaries},
+		{func(a Interface) { a.InsertAll(4, New(1)) }, ErrIndexOutOfBounds},
+		{func(a Interface) { a.Fill(0, 5, 1) }, ErrBadSubsetBoudaries},
+	}
+	for _, a := range []Interface{New(1, 2, 3), NewSync(1, 2, 3)} {
+		for _, c := range cases {
+			func() {
+				defer func() {
+					if r := recover(); r != c.expected {
+						t.Errorf("Expected %v. Got %v.", c.expected, r)
+					}
+				}()
+				c.f(a)
+			}()
+		}
+	}
+}
+
+func TestRemoveRange(t *testing.T) {
+	cases := []struct {
+		array, expected Interface
+		i, j            int
+	}{
+		{New(1, 2, 3, 4), New(1, 4), 1, 3},
+		{New(1, 2, 3, 4), New(1, 2, 3, 4), 2, 2},
+		{NewSync(1, 2, 3, 4), NewSync(), 0, 4},
+	}
+	for _, c := range cases {
+		c.array.RemoveRange(c.i, c.j)
+		if !c.array.IsEqual(c.expected) {
+			t.Errorf("Expected %v. Got %v.", c.expected, c.array)
+		}
+	}
+}
+
+func TestInsertAll(t *testing.T) {
+	a := NewSync(1, 2)
+	cases := []struct {
+		array    Interface
+		i        int
+		t        collection.ReadOnly
+		expected Interface
+	}{
+		{New(1, 2), 1, New(3, 4), New(1, 3, 4, 2)},
+		{New(1, 2), 2, New(3), New(1, 2, 3)},
+		{NewSync(1, 2), 0, NewSync(3), NewSync(3, 1, 2)},
+		{a, 1, a, NewSync(1, 1, 2, 2)},
+	}
+	for _, c := range cases {
+		c.array.InsertAll(c.i, c.t)
+		if !c.array.IsEqual(c.expected) {
+			t.Errorf("Expected %v. Got %v.", c.expected, c.array)
+		}
+	}
+}
+
+func TestReverseRotateFill(t *testing.T) {
+	cases := []struct {
+		array, expected Interface
+		f               func(a Interface)
+	}{
+		{New(1, 2, 3, 4), New(4, 3, 2, 1), func(a Interface) { a.Reverse() }},
+		{New(), New(), func(a Interface) { a.Reverse() }},
+		{NewSync(1, 2, 3), NewSync(3, 2, 1), func(a Interface) { a.Reverse() }},
+		{New(1, 2, 3, 4, 5), New(4, 5, 1, 2, 3), func(a Interface) { a.Rotate(2) }},
+		{New(1, 2, 3, 4, 5), New(3, 4, 5, 1, 2), func(a Interface) { a.Rotate(-2) }},
+		{New(1, 2, 3, 4, 5), New(5, 1, 2, 3, 4), func(a Interface) { a.Rotate(11) }},
+		{New(1, 2, 3), New(1, 2, 3), func(a Interface) { a.Rotate(3) }},
+		{New(), New(), func(a Interface) { a.Rotate(3) }},
+		{NewSync(1, 2, 3), NewSync(3, 1, 2), func(a Interface) { a.Rotate(1) }},
+		{New(1, 2, 3, 4), New(1, 0, 0, 4), func(a Interface) { a.Fill(1, 3, 0) }},
+		{NewSync(1, 2, 3), NewSync(0, 0, 0), func(a Interface) { a.Fill(0, 3, 0) }},
+	}
+	for _, c := range cases {
+		c.f(c.array)
+		if !c.array.IsEqual(c.expected) {
+			t.Errorf("Expected %v. Got %v.", c.expected, c.array)
+		}
+	}
+}
+
+func TestRepeatConcat(t *testing.T) {
+	a := NewSync(1, 2)
+	cases := []struct {
+		result, expected Interface
+	}{
+		{New(1, 2).Repeat(3), New(1, 2, 1, 2, 1, 2)},
+		{New(1, 2).Repeat(0), New()},
+		{NewSync(1, 2).Repeat(-1), NewSync()},
+		{New(1, 2).Concat(), New(1, 2)},
+		{New(1, 2).Concat(New(2, 3), New(4)), New(1, 2, 2, 3, 4)},
+		{a.Concat(a), NewSync(1, 2, 1, 2)},
+	}
+	for _, c := range cases {
+		if !c.result.IsEqual(c.expected) {
+			t.Errorf("Expected %v. Got %v.", c.expected, c.result)
+		}
+	}
+	if _, ok := a.Repeat(2).(*arraySync); !ok {
+		t.Errorf("Expected %v. Got %v.", true, ok)
+	}
+}
+
 func TestSwap(t *testing.T) {
 	cases := []struct {
 		array, expected Interface
@@ -433,6 +671,27 @@ func TestString(t *testing.T) {
 
 }
 
+func TestFormat(t *testing.T) {
+	cases := []struct {
+		format   string
+		array    Interface
+		expected string
+	}{
+		{"%v", New(3, 1, 2), "[3 1 2]"},
+		{"%+v", NewSync(3, 1, 2), "array.Sync(len=3) [3 1 2]"},
+		{"%#v", New("a", 1), `array.New("a", 1)`},
+		{"%.2v", NewSorted(nil, 3, 1, 2), "[3 1 ...]"},
+		{"%+v", NewSortedSync(nil, 3), "array.SortedSync(len=1) [3]"},
+		{"%v", NewReadOnly(New(1)), "[1]"},
+		{"%v", NewObservable(New(1)), "[1]"},
+	}
+	for _, c := range cases {
+		if got := fmt.Sprintf(c.format, c.array); got != c.expected {
+			t.Errorf("Expected %v. Got %v.", c.expected, got)
+		}
+	}
+}
+
 func TestSlice(t *testing.T) {
 	cases := []struct {
 		slice []interface{}
@@ -591,3 +850,584 @@ func TestSort(t *testing.T) {
 		}
 	}
 }
+
+func TestSearchSorted(t *testing.T) {
+	less := func(slice []interface{}, i, j int) bool {
+		return slice[i].(int) < slice[j].(int)
+	}
+	cases := []Sorted{NewSorted(less, 3, 1, 5, 3), NewSortedSync(less, 3, 1, 5, 3)}
+	for _, a := range cases {
+		if a.IsSorted() {
+			t.Errorf("Expected %v. Got %v.", false, true)
+		}
+		a.InsertSorted(4, 0)
+		if !a.IsEqual(New(0, 1, 3, 3, 4, 5)) {
+			t.Errorf("Expected %v. Got %v.", New(0, 1, 3, 3, 4, 5), a)
+		}
+		if !a.IsSorted() {
+			t.Errorf("Expected %v. Got %v.", true, false)
+		}
+		searches := []struct {
+			item         int
+			i, low, high int
+			found        bool
+		}{
+			{3, 2, 2, 4, true},
+			{2, 2, 2, 2, false},
+			{-1, 0, 0, 0, false},
+			{5, 5, 5, 6, true},
+			{6, 6, 6, 6, false},
+		}
+		for _, c := range searches {
+			i, found := a.SearchSorted(c.item)
+			if i != c.i || found != c.found {
+				t.Errorf("Expected %v, %v. Got %v, %v.", c.i, c.found, i, found)
+			}
+			if low := a.LowerBound(c.item); low != c.low {
+				t.Errorf("Expected %v. Got %v.", c.low, low)
+			}
+			if high := a.UpperBound(c.item); high != c.high {
+				t.Errorf("Expected %v. Got %v.", c.high, high)
+			}
+			i, err := a.BinaryIndexOf(c.item)
+			if c.found && (err != nil || i != c.i) {
+				t.Errorf("Expected %v. Got %v, %v.", c.i, i, err)
+			}
+			if !c.found && err != ErrNotFound {
+				t.Errorf("Expected %v. Got %v.", ErrNotFound, err)
+			}
+			if a.Has(c.item) != c.found {
+				t.Errorf("Expected %v. Got %v.", c.found, !c.found)
+			}
+		}
+		if a.Has("x") || a.Has(3, "x") {
+			t.Errorf("Expected %v. Got %v.", false, true)
+		}
+		if _, err := a.IndexOf("x"); err != ErrNotFound {
+			t.Errorf("Expected %v. Got %v.", ErrNotFound, err)
+		}
+		a.Remove("x", 3)
+		if !a.IsEqual(New(0, 1, 3, 4, 5)) || !a.IsSorted() {
+			t.Errorf("Expected %v. Got %v.", New(0, 1, 3, 4, 5), a)
+		}
+		a.InsertSorted(3)
+		a.Swap(0, 5)
+		if a.IsSorted() {
+			t.Errorf("Expected %v. Got %v.", false, true)
+		}
+		i, err := a.IndexOf(0)
+		if err != nil || i != 5 {
+			t.Errorf("Expected %v. Got %v, %v.", 5, i, err)
+		}
+	}
+}
+
+func TestSortedForeignItems(t *testing.T) {
+	less := func(slice []interface{}, i, j int) bool {
+		return slice[i].(int) < slice[j].(int)
+	}
+	cases := []Sorted{NewSorted(less, 1, 2), NewSortedSync(less, 1, 2)}
+	for _, a := range cases {
+		a.Add("x")
+		a.Insert(0, "y")
+		a.Swap(0, 1)
+		if !a.IsEqual(New(1, "y", 2, "x")) || a.IsSorted() {
+			t.Errorf("Expected %v. Got %v.", New(1, "y", 2, "x"), a)
+		}
+		if !a.Has(2, "x") {
+			t.Errorf("Expected %v. Got %v.", true, false)
+		}
+	}
+	if a := NewSorted(less, 1, "x"); a.IsSorted() || a.Len() != 2 {
+		t.Errorf("Expected %v. Got %v.", New(1, "x"), a)
+	}
+}
+
+func TestAutoSort(t *testing.T) {
+	less := func(slice []interface{}, i, j int) bool {
+		return slice[i].(int) < slice[j].(int)
+	}
+	cases := []Sorted{NewSorted(less, 3, 1), NewSortedSync(less, 3, 1)}
+	for _, a := range cases {
+		a.SetAutoSort(true)
+		ops := []struct {
+			op       func()
+			expected Interface
+		}{
+			{func() {}, New(1, 3)},
+			{func() { a.Add(2, 0) }, New(0, 1, 2, 3)},
+			{func() { a.Insert(0, 5) }, New(0, 1, 2, 3, 5)},
+			{func() { a.ReplaceAt(0, 4) }, New(1, 2, 3, 4, 5)},
+			{func() { a.Replace(5, -1) }, New(-1, 1, 2, 3, 4)},
+			{func() { a.Swap(0, 4) }, New(-1, 1, 2, 3, 4)},
+			{func() { a.Splice(1, 2, 7, 0) }, New(-1, 0, 3, 4, 7)},
+			{func() { a.InsertAll(0, New(6)) }, New(-1, 0, 3, 4, 6, 7)},
+			{func() { a.Reverse() }, New(-1, 0, 3, 4, 6, 7)},
+			{func() { a.Rotate(2) }, New(-1, 0, 3, 4, 6, 7)},
+			{func() { a.Fill(0, 2, 5) }, New(3, 4, 5, 5, 6, 7)},
+			{func() { a.Merge(New(1)) }, New(1, 3, 4, 5, 5, 6, 7)},
+			{func() {
+				a.ReplaceIf(func(item interface{}) bool {
+					return item.(int) > 5
+				}, func(item interface{}) interface{} {
+					return -item.(int)
+				})
+			}, New(-7, -6, 1, 3, 4, 5, 5)},
+		}
+		for _, c := range ops {
+			c.op()
+			if !a.IsEqual(c.expected) {
+				t.Errorf("Expected %v. Got %v.", c.expected, a)
+			}
+		}
+		a.SetAutoSort(false)
+		a.Add(0)
+		if a.IsSorted() {
+			t.Errorf("Expected %v. Got %v.", false, true)
+		}
+		if !a.Has(0) {
+			t.Errorf("Expected %v. Got %v.", true, false)
+		}
+	}
+	snapshot := cases[1].(*arraySortSync).Snapshot().(Sorted)
+	snapshot.Add(2)
+	if !snapshot.IsEqual(New(-7, -6, 1, 3, 4, 5, 5, 0, 2)) {
+		t.Errorf("Expected %v. Got %v.", New(-7, -6, 1, 3, 4, 5, 5, 0, 2), snapshot)
+	}
+}
+
+func TestSortStable(t *testing.T) {
+	type pair struct {
+		key, value int
+	}
+	items := []interface{}{pair{2, 0}, pair{1, 1}, pair{2, 2}, pair{1, 3}}
+	byKey := func(item interface{}) interface{} {
+		return item.(pair).key
+	}
+	expected := New(pair{1, 1}, pair{1, 3}, pair{2, 0}, pair{2, 2})
+	cases := []Interface{
+		New(items...),
+		NewSync(items...),
+		NewSorted(nil, items...),
+		NewSortedSync(nil, items...),
+		NewObservable(New(items...)),
+	}
+	for _, a := range cases {
+		SortBy(a, byKey)
+		if !a.IsEqual(expected) {
+			t.Errorf("Expected %v. Got %v.", expected, a)
+		}
+		SortFunc(a, compare.Reversed(compare.By(byKey, compare.Natural)))
+		if !a.IsEqual(New(pair{2, 0}, pair{2, 2}, pair{1, 1}, pair{1, 3})) {
+			t.Errorf("Expected %v. Got %v.", New(pair{2, 0}, pair{2, 2}, pair{1, 1}, pair{1, 3}), a)
+		}
+	}
+	less := compare.Less(compare.By(byKey, compare.Natural))
+	sorted := []Sorted{NewSorted(less, items...), NewSortedSync(less, items...)}
+	for _, a := range sorted {
+		a.SortStable()
+		if !a.IsEqual(expected) || !a.IsSorted() {
+			t.Errorf("Expected %v. Got %v.", expected, a)
+		}
+		a.SetLess(compare.Less(compare.Reversed(compare.By(byKey, compare.Natural))))
+		if a.IsSorted() {
+			t.Errorf("Expected %v. Got %v.", false, true)
+		}
+		a.SetAutoSort(true)
+		if !a.IsEqual(New(pair{2, 0}, pair{2, 2}, pair{1, 1}, pair{1, 3})) {
+			t.Errorf("Expected %v. Got %v.", New(pair{2, 0}, pair{2, 2}, pair{1, 1}, pair{1, 3}), a)
+		}
+		a.SetLess(less)
+		if !a.IsEqual(expected) {
+			t.Errorf("Expected %v. Got %v.", expected, a)
+		}
+		SortFunc(a, compare.Reversed(compare.By(byKey, compare.Natural)))
+		if !a.IsEqual(expected) {
+			t.Errorf("Expected %v. Got %v.", expected, a)
+		}
+	}
+}
+
+func TestSliceCopy(t *testing.T) {
+	cases := []Interface{New(1, 2, 3), NewSync(1, 2, 3), NewSorted(nil, 1, 2, 3), NewSortedSync(nil, 1, 2, 3)}
+	for _, a := range cases {
+		a.Slice()[0] = 42
+		if a.Get(0) != 1 {
+			t.Errorf("Expected %v. Got %v.", 1, a.Get(0))
+		}
+		UnsafeSlice(a)[0] = 42
+		if a.Get(0) != 42 {
+			t.Errorf("Expected %v. Got %v.", 42, a.Get(0))
+		}
+		UnsafeSlice(NewReadOnly(a))[0] = 1
+		if a.Get(0) != 42 {
+			t.Errorf("Expected %v. Got %v.", 42, a.Get(0))
+		}
+	}
+}
+
+func TestReadOnly(t *testing.T) {
+	cases := []Interface{New(1, 2, 3), NewSync(1, 2, 3)}
+	for _, a := range cases {
+		r := NewReadOnly(a)
+		if NewReadOnly(r) != r {
+			t.Errorf("Expected %v. Got %v.", r, NewReadOnly(r))
+		}
+		mutators := []func(){
+			func() { r.Add(4) },
+			func() { r.Insert(0, 4) },
+			func() { r.Remove(1) },
+			func() { r.RemoveAt(0) },
+			func() { r.Replace(1, 4) },
+			func() { r.ReplaceAt(0, 4) },
+			func() { r.Swap(0, 1) },
+			func() { r.Clear() },
+			func() { r.Merge(New(4)) },
+			func() { r.Separate(New(1)) },
+			func() { r.Retain(New(1)) },
+			func() { r.Splice(0, 1) },
+			func() { r.RemoveRange(0, 1) },
+			func() { r.InsertAll(0, New(4)) },
+			func() { r.Reverse() },
+			func() { r.Rotate(1) },
+			func() { r.Fill(0, 1, 4) },
+			func() { r.RemoveIf(func(item interface{}) bool { return true }) },
+			func() {
+				r.ReplaceIf(func(item interface{}) bool { return true }, func(item interface{}) interface{} { return 0 })
+			},
+		}
+		for _, mutate := range mutators {
+			func() {
+				defer func() {
+					if r := recover(); r != collection.ErrReadOnly {
+						t.Errorf("Expected %v. Got %v.", collection.ErrReadOnly, r)
+					}
+				}()
+				mutate()
+			}()
+		}
+		if !r.IsEqual(New(1, 2, 3)) || r.Len() != 3 || r.IsEmpty() || !r.Has(1, 2) || r.Get(1) != 2 {
+			t.Errorf("Expected %v. Got %v.", a, r)
+		}
+		if i, err := r.IndexOf(3); err != nil || i != 2 || r.String() != "[1 2 3]" {
+			t.Errorf("Expected %v. Got %v.", 2, i)
+		}
+		a.Add(4)
+		if !r.Has(4) || len(r.Slice()) != 4 || !r.SubArray(0, 1).IsEqual(New(1, 2)) || !r.SliceRange(0, 1).IsEqual(New(1)) {
+			t.Errorf("Expected %v. Got %v.", a, r)
+		}
+		if !r.Repeat(2).IsEqual(New(1, 2, 3, 4, 1, 2, 3, 4)) || !r.Concat(New(5)).IsEqual(New(1, 2, 3, 4, 5)) {
+			t.Errorf("Expected %v. Got %v.", a, r)
+		}
+		three := func(item interface{}) bool { return item == 3 }
+		if v, _ := r.Find(three); v != 3 || r.Count(three) != 1 {
+			t.Errorf("Expected %v. Got %v.", 3, v)
+		}
+		if i, _ := r.FindIndex(three); i != 2 {
+			t.Errorf("Expected %v. Got %v.", 2, i)
+		}
+		if v, _ := r.FindLast(three); v != 3 {
+			t.Errorf("Expected %v. Got %v.", 3, v)
+		}
+		if i, _ := r.LastIndexOf(3); i != 2 {
+			t.Errorf("Expected %v. Got %v.", 2, i)
+		}
+		if i, _ := r.IndexOfFrom(3, 1); i != 2 {
+			t.Errorf("Expected %v. Got %v.", 2, i)
+		}
+		count := 0
+		r.Each(func(item interface{}) bool {
+			count++
+			return true
+		})
+		r.CopyArr().Add(5)
+		r.CopyCollection().Add(5)
+		if count != 4 || r.Has(5) {
+			t.Errorf("Expected %v. Got %v.", 4, count)
+		}
+	}
+}
+
+func TestSnapshot(t *testing.T) {
+	less := func(slice []interface{}, i, j int) bool {
+		return slice[i].(int) < slice[j].(int)
+	}
+	cases := []struct {
+		a      Interface
+		sorted bool
+	}{
+		{NewSync(3, 1, 2), false},
+		{NewSortedSync(less, 3, 1, 2), true},
+	}
+	for _, c := range cases {
+		snapshot := c.a.(Snapshotter).Snapshot()
+		c.a.Add(4)
+		if !snapshot.IsEqual(New(3, 1, 2)) {
+			t.Errorf("Expected %v. Got %v.", New(3, 1, 2), snapshot)
+		}
+		if sorted, ok := snapshot.(Sorted); ok != c.sorted {
+			t.Errorf("Expected %v. Got %v.", c.sorted, ok)
+		} else if ok {
+			sorted.Sort()
+			if !snapshot.IsEqual(New(1, 2, 3)) {
+				t.Errorf("Expected %v. Got %v.", New(1, 2, 3), snapshot)
+			}
+		}
+	}
+}
+
+// replay applies events on a to reproduce the modifications of an observable array
+func replay(a Interface, events ...observer.Event) {
+	for _, e := range events {
+		switch e.Op {
+		case observer.Add:
+			if e.Index == a.Len() {
+				a.Add(e.New)
+			} else {
+				a.Insert(e.Index, e.New)
+			}
+		case observer.Remove:
+			a.RemoveAt(e.Index)
+		case observer.Replace:
+			a.ReplaceAt(e.Index, e.New)
+		case observer.Clear:
+			a.Clear()
+		case observer.Batch:
+			replay(a, e.Batch...)
+		}
+	}
+}
+
+func TestObservable(t *testing.T) {
+	a := NewObservable(New(1, 2, 3))
+	mirror := New(1, 2, 3)
+	var ops []observer.Op
+	unsubscribe := a.Subscribe(func(e observer.Event) {
+		ops = append(ops, e.Op)
+		replay(mirror, e)
+	})
+	a.Add(4, 4)
+	a.Insert(0, 0)
+	a.Remove(4, 9)
+	a.RemoveAt(1)
+	a.Replace(2, 20)
+	a.Replace(9, 90)
+	a.ReplaceAt(0, 10)
+	a.Swap(0, 1)
+	a.Merge(New(5, 6, 20))
+	a.Separate(New(5))
+	a.Retain(New(20, 10, 4, 6, 7))
+	a.Merge(a)
+	a.Splice(1, 1, 30, 31)
+	a.InsertAll(0, New(40, 41))
+	a.RemoveRange(0, 1)
+	a.Reverse()
+	a.Rotate(2)
+	a.Fill(1, 3, 50)
+	a.Rotate(0)
+	a.RemoveIf(func(item interface{}) bool { return item == 31 })
+	a.ReplaceIf(func(item interface{}) bool { return item == 50 }, func(item interface{}) interface{} { return 51 })
+	a.Add(31)
+	if !mirror.IsEqual(a) || !a.IsEqual(New(20, 51, 51, 4, 30, 31)) {
+		t.Errorf("Expected %v. Got %v.", a, mirror)
+	}
+	expected := []observer.Op{
+		observer.Add, observer.Add, observer.Add, observer.Remove, observer.Remove,
+		observer.Replace, observer.Replace, observer.Batch, observer.Batch, observer.Batch, observer.Batch,
+		observer.Batch, observer.Batch, observer.Batch, observer.Batch, observer.Batch, observer.Batch,
+		observer.Batch, observer.Batch, observer.Add,
+	}
+	if len(ops) != len(expected) {
+		t.Fatalf("Expected %v. Got %v.", expected, ops)
+	}
+	for i := range expected {
+		if ops[i] != expected[i] {
+			t.Errorf("Expected %v. Got %v.", expected, ops)
+		}
+	}
+	a.Clear()
+	if !mirror.IsEmpty() {
+		t.Errorf("Expected %v. Got %v.", "[]", mirror)
+	}
+	unsubscribe()
+	a.Add(1)
+	if !mirror.IsEmpty() || a.Len() != 1 || a.Get(0) != 1 || !a.Has(1) || a.IsEmpty() {
+		t.Errorf("Expected %v. Got %v.", "[]", mirror)
+	}
+	if i, err := a.IndexOf(1); err != nil || i != 0 {
+		t.Errorf("Expected %v. Got %v.", 0, i)
+	}
+	a.Add(2, 3)
+	if a.String() != "[1 2 3]" || len(a.Slice()) != 3 || a.SubArray(0, 1).Len() != 2 || a.CopyCollection().Len() != 3 {
+		t.Errorf("Expected %v. Got %v.", "[1 2 3]", a)
+	}
+	if a.SliceRange(0, 1).Len() != 1 || a.Repeat(2).Len() != 6 || a.Concat(a, New(4)).Len() != 7 {
+		t.Errorf("Expected %v. Got %v.", "[1 2 3]", a)
+	}
+	odd := func(item interface{}) bool { return item.(int)%2 == 1 }
+	if v, _ := a.Find(odd); v != 1 || a.Count(odd) != 2 {
+		t.Errorf("Expected %v. Got %v.", 1, v)
+	}
+	if v, _ := a.FindLast(odd); v != 3 {
+		t.Errorf("Expected %v. Got %v.", 3, v)
+	}
+	if i, _ := a.FindIndex(odd); i != 0 {
+		t.Errorf("Expected %v. Got %v.", 0, i)
+	}
+	if i, _ := a.LastIndexOf(3); i != 2 {
+		t.Errorf("Expected %v. Got %v.", 2, i)
+	}
+	if i, _ := a.IndexOfFrom(3, 1); i != 2 {
+		t.Errorf("Expected %v. Got %v.", 2, i)
+	}
+	visited := 0
+	a.Each(func(item interface{}) bool {
+		visited++
+		return true
+	})
+	if visited != 3 {
+		t.Errorf("Expected %v. Got %v.", 3, visited)
+	}
+}
+
+func TestObservableChan(t *testing.T) {
+	a := NewObservable(NewSync())
+	events, unsubscribe := a.SubscribeChan()
+	a.Add(1)
+	a.Add(2)
+	for i := 0; i < 2; i++ {
+		e := <-events
+		if e.Op != observer.Add || e.Index != i || e.New != i+1 {
+			t.Errorf("Expected %v. Got %v.", i+1, e.New)
+		}
+	}
+	unsubscribe()
+	if _, open := <-events; open {
+		t.Errorf("Expected %v. Got %v.", false, open)
+	}
+}
+
+func TestObservableReentrant(t *testing.T) {
+	a := NewObservable(New())
+	a.Subscribe(func(e observer.Event) {
+		if e.Op == observer.Add && e.New.(int) < 3 {
+			a.Add(e.New.(int) + 1)
+		}
+	})
+	a.Add(0)
+	if !a.IsEqual(New(0, 1, 2, 3)) {
+		t.Errorf("Expected %v. Got %v.", New(0, 1, 2, 3), a)
+	}
+}
+
+func TestDiff(t *testing.T) {
+	cases := []struct {
+		a, b     Interface
+		expected Patch
+	}{
+		{New(1, 2, 3), New(1, 2, 3), nil},
+		{New(1, 2, 3), New(1, 3), Patch{{Op: OpDelete, Index: 1, Item: 2}}},
+		{New(1, 3), New(1, 2, 3), Patch{{Op: OpInsert, Index: 1, Item: 2}}},
+		{New(1, 2, 3), New(3, 1, 2), Patch{{Op: OpMove, Index: 0, From: 2, Item: 3}}},
+		{New(1, 2, 3), New(2, 3, 1), Patch{{Op: OpMove, Index: 2, From: 0, Item: 1}}},
+		{New(), NewSync(1), Patch{{Op: OpInsert, Index: 0, Item: 1}}},
+		{NewSync(1, 2), New(), Patch{{Op: OpDelete, Index: 1, Item: 2}, {Op: OpDelete, Index: 0, Item: 1}}},
+	}
+	for _, c := range cases {
+		patch := Diff(c.a, c.b)
+		if len(patch) != len(c.expected) {
+			t.Errorf("Expected %v. Got %v.", c.expected, patch)
+			continue
+		}
+		for i := range patch {
+			if patch[i] != c.expected[i] {
+				t.Errorf("Expected %v. Got %v.", c.expected, patch)
+			}
+		}
+		Apply(c.a, patch)
+		if !c.a.IsEqual(c.b) {
+			t.Errorf("Expected %v. Got %v.", c.b, c.a)
+		}
+	}
+}
+
+func TestDiffRandom(t *testing.T) {
+	r := rand.New(rand.NewSource(42))
+	randomArray := func() Interface {
+		a := New()
+		for i := r.Intn(30); i > 0; i-- {
+			a.Add(r.Intn(8))
+		}
+		return a
+	}
+	for i := 0; i < 1000; i++ {
+		a, b := randomArray(), randomArray()
+		patch := Diff(a, b)
+		removed := 0
+		for _, e := range patch {
+			if e.Op != OpInsert {
+				removed++
+			}
+		}
+		if kept := a.Len() - removed; kept != lcsLen(a.Slice(), b.Slice()) {
+			t.Errorf("Expected %v. Got %v.", lcsLen(a.Slice(), b.Slice()), kept)
+		}
+		Apply(a, patch)
+		if !a.IsEqual(b) {
+			t.Errorf("Expected %v. Got %v.", b, a)
+		}
+	}
+}
+
+// lcsLen computes the length of the longest common subsequence by dynamic programming
+func lcsLen(a, b []interface{}) int {
+	l := make([][]int, len(a)+1)
+	for i := range l {
+		l[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			switch {
+			case a[i] == b[j]:
+				l[i][j] = l[i+1][j+1] + 1
+			case l[i+1][j] > l[i][j+1]:
+				l[i][j] = l[i+1][j]
+			default:
+				l[i][j] = l[i][j+1]
+			}
+		}
+	}
+	return l[0][0]
+}
+
+func TestEditOpString(t *testing.T) {
+	cases := []struct {
+		op       EditOp
+		expected string
+	}{
+		{OpInsert, "Insert"},
+		{OpDelete, "Delete"},
+		{OpMove, "Move"},
+		{EditOp(0), "Unknown"},
+	}
+	for _, c := range cases {
+		if c.op.String() != c.expected {
+			t.Errorf("Expected %v. Got %v.", c.expected, c.op)
+		}
+	}
+}
+
+func TestFromSlice(t *testing.T) {
+	for _, from := range []func(interface{}) (Interface, error){FromSlice, FromSliceSync} {
+		c, err := from([]string{"b", "a", "b"})
+		testErr(err, false, t)
+		if !c.IsEqual(New("b", "a", "b")) {
+			t.Errorf("Expected %v. Got %v.", New("b", "a", "b"), c)
+		}
+		var strs []string
+		testErr(ToSlice(c, &strs), false, t)
+		if !reflect.DeepEqual(strs, []string{"b", "a", "b"}) {
+			t.Errorf("Expected %v. Got %v.", []string{"b", "a", "b"}, strs)
+		}
+	}
+}
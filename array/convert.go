@@ -0,0 +1,39 @@
+package array
+
+import "github.com/khezen/struct/collection"
+
+// FromSlice creates an array with the elements of any slice or array, or of a
+// pointer to one, such as a []string. It fails with collection.ErrNotSlice for
+// other values.
+func FromSlice(slice interface{}) (Interface, error) {
+	items, err := collection.Items(slice)
+	if err != nil {
+		return nil, err
+	}
+	return New(items...), nil
+}
+
+// FromSliceSync creates a thread safe array with the elements of any slice or array.
+func FromSliceSync(slice interface{}) (Interface, error) {
+	items, err := collection.Items(slice)
+	if err != nil {
+		return nil, err
+	}
+	return NewSync(items...), nil
+}
+
+// ToSlice replaces the slice ptr points to, such as a *[]string, with the
+// items. It fails with collection.ErrType when an item does not fit.
+func ToSlice(c ReadOnly, ptr interface{}) error {
+	return collection.ToSlice(c, ptr)
+}
+
+// StringSlice returns the items, which must all be strings.
+func StringSlice(c ReadOnly) ([]string, error) {
+	return collection.StringSlice(c)
+}
+
+// IntSlice returns the items, which must all be ints.
+func IntSlice(c ReadOnly) ([]int, error) {
+	return collection.IntSlice(c)
+}
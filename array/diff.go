@@ -0,0 +1,215 @@
+package array
+
+// EditOp is the kind of an Edit
+type EditOp int
+
+const (
+	// OpInsert - Item is inserted at Index
+	OpInsert EditOp = iota + 1
+	// OpDelete - Item is removed from Index
+	OpDelete
+	// OpMove - Item is removed from From then inserted at Index
+	OpMove
+)
+
+var editOpNames = [...]string{"", "Insert", "Delete", "Move"}
+
+func (op EditOp) String() string {
+	if op <= 0 || int(op) >= len(editOpNames) {
+		return "Unknown"
+	}
+	return editOpNames[op]
+}
+
+// Edit is one step of a Patch. Indexes refer to the array as modified by the
+// previous edits of the patch.
+type Edit struct {
+	Op    EditOp
+	Index int
+	From  int
+	Item  interface{}
+}
+
+// Patch is a sequence of edits turning an array into another
+type Patch []Edit
+
+// Diff returns a patch turning a into b. Items kept in place form a longest
+// common subsequence of a and b, computed with the Myers algorithm. Items
+// removed from a and inserted back in b are reported as moves.
+// It works on ordered sets as well.
+func Diff(a, b ReadOnly) Patch {
+	x, y := a.Slice(), b.Slice()
+	keptX, keptY := lcs(x, y)
+
+	// pair items deleted from x with equal items inserted in y
+	sources := make(map[interface{}][]int)
+	for i, item := range x {
+		if !keptX[i] {
+			sources[item] = append(sources[item], i)
+		}
+	}
+	moved := make([]bool, len(x))
+	// target holds the identity of each item of y: the index of the item of x
+	// it comes from, or len(x)+j for inserted items
+	target := make([]int, len(y))
+	next := 0
+	for j, item := range y {
+		switch s := sources[item]; {
+		case keptY[j]:
+			for !keptX[next] {
+				next++
+			}
+			target[j] = next
+			next++
+		case len(s) > 0:
+			target[j] = s[0]
+			sources[item] = s[1:]
+			moved[s[0]] = true
+		default:
+			target[j] = len(x) + j
+		}
+	}
+
+	// cur tracks the identities of the items as the patch is applied
+	cur := make([]int, len(x))
+	for i := range cur {
+		cur[i] = i
+	}
+	var patch Patch
+	for i := len(x) - 1; i >= 0; i-- {
+		if !keptX[i] && !moved[i] {
+			patch = append(patch, Edit{Op: OpDelete, Index: i, Item: x[i]})
+			cur = append(cur[:i], cur[i+1:]...)
+		}
+	}
+	// each item of y is placed right after the item preceding it in y, so that
+	// only items still waiting to be moved may lie between kept items
+	at := 0
+	for j, id := range target {
+		switch {
+		case keptY[j]:
+			for cur[at] != id {
+				at++
+			}
+		case id >= len(x):
+			patch = append(patch, Edit{Op: OpInsert, Index: at, Item: y[j]})
+			cur = append(cur[:at], append([]int{id}, cur[at:]...)...)
+		default:
+			from := 0
+			for cur[from] != id {
+				from++
+			}
+			if from != at {
+				if from < at {
+					at--
+				}
+				patch = append(patch, Edit{Op: OpMove, Index: at, From: from, Item: y[j]})
+				cur = append(cur[:from], cur[from+1:]...)
+				cur = append(cur[:at], append([]int{id}, cur[at:]...)...)
+			}
+		}
+		at++
+	}
+	return patch
+}
+
+// Apply replays the edits of patch on a.
+func Apply(a Interface, patch Patch) {
+	for _, e := range patch {
+		switch e.Op {
+		case OpInsert:
+			insertAt(a, e.Index, e.Item)
+		case OpDelete:
+			a.RemoveAt(e.Index)
+		case OpMove:
+			insertAt(a, e.Index, a.RemoveAt(e.From))
+		}
+	}
+}
+
+func insertAt(a Interface, i int, item interface{}) {
+	if i == a.Len() {
+		a.Add(item)
+	} else {
+		a.Insert(i, item)
+	}
+}
+
+// lcs flags the items of a and b belonging to a longest common subsequence,
+// using the O((N+M)D) algorithm of Myers.
+func lcs(a, b []interface{}) (keptA, keptB []bool) {
+	keptA, keptB = make([]bool, len(a)), make([]bool, len(b))
+	// common prefix and suffix are kept without running the algorithm
+	start := 0
+	for start < len(a) && start < len(b) && a[start] == b[start] {
+		keptA[start], keptB[start] = true, true
+		start++
+	}
+	endA, endB := len(a), len(b)
+	for endA > start && endB > start && a[endA-1] == b[endB-1] {
+		endA--
+		endB--
+		keptA[endA], keptB[endB] = true, true
+	}
+	x, y := a[start:endA], b[start:endB]
+	n, m := len(x), len(y)
+	if n == 0 || m == 0 {
+		return
+	}
+
+	// v[k] is the furthest x reached on diagonal k = x - y. trace[d] keeps
+	// v after step d for diagonals -d-1 to d+1, at index k+d+1.
+	max := n + m
+	off := max + 1
+	v := make([]int, 2*off+1)
+	var trace [][]int
+	for d := 0; d <= max; d++ {
+		done := false
+		for k := -d; k <= d; k += 2 {
+			var i int
+			if k == -d || (k != d && v[off+k-1] < v[off+k+1]) {
+				i = v[off+k+1]
+			} else {
+				i = v[off+k-1] + 1
+			}
+			j := i - k
+			for i < n && j < m && x[i] == y[j] {
+				i++
+				j++
+			}
+			v[off+k] = i
+			if i >= n && j >= m {
+				done = true
+				break
+			}
+		}
+		trace = append(trace, append([]int(nil), v[off-d-1:off+d+2]...))
+		if done {
+			break
+		}
+	}
+
+	i, j := n, m
+	for d := len(trace) - 1; d > 0; d-- {
+		prev := trace[d-1]
+		k := i - j
+		prevK := k - 1
+		if k == -d || (k != d && prev[k-1+d] < prev[k+1+d]) {
+			prevK = k + 1
+		}
+		prevI := prev[prevK+d]
+		prevJ := prevI - prevK
+		for i > prevI && j > prevJ {
+			i--
+			j--
+			keptA[start+i], keptB[start+j] = true, true
+		}
+		i, j = prevI, prevJ
+	}
+	for i > 0 && j > 0 {
+		i--
+		j--
+		keptA[start+i], keptB[start+j] = true, true
+	}
+	return
+}
@@ -11,19 +11,48 @@ import (
 	"github.com/khezen/struct/collection"
 )
 
+// ReadOnly describes methods of an array which do not modify it
+type ReadOnly interface {
+	collection.ReadOnly
+	Get(i int) interface{}
+	IndexOf(interface{}) (int, error)
+}
+
 // Interface is describing a Set. Sets are an unordered, unique list of values.
 type Interface interface {
 	collection.Interface
-	Get(i int) interface{}
+	ReadOnly
 	Insert(i int, item ...interface{})
 	RemoveAt(i int) interface{}
 	ReplaceAt(i int, substitute interface{}) interface{}
-	IndexOf(interface{}) (int, error)
 	Swap(i, j int)
 	SubArray(i, j int) Interface
+	SliceRange(i, j int) Interface
+	Splice(i, deleteCount int, items ...interface{}) []interface{}
+	RemoveRange(i, j int)
+	InsertAll(i int, t collection.ReadOnly)
+	Reverse()
+	Rotate(k int)
+	Fill(i, j int, item interface{})
+	Repeat(n int) Interface
+	Concat(t ...collection.ReadOnly) Interface
+	Find(pred func(item interface{}) bool) (interface{}, error)
+	FindIndex(pred func(item interface{}) bool) (int, error)
+	FindLast(pred func(item interface{}) bool) (interface{}, error)
+	LastIndexOf(item interface{}) (int, error)
+	IndexOfFrom(item interface{}, start int) (int, error)
+	Count(pred func(item interface{}) bool) int
+	RemoveIf(pred func(item interface{}) bool) int
+	ReplaceIf(pred func(item interface{}) bool, substitute func(item interface{}) interface{}) int
 	CopyArr() Interface
 }
 
+// Snapshotter is implemented by thread safe arrays, which can return a
+// non-threadsafe copy of themselves consistent at the time of the call.
+type Snapshotter interface {
+	Snapshot() Interface
+}
+
 var (
 	// ErrIndexOutOfBounds - index is out of bounds
 	ErrIndexOutOfBounds = errors.New("ErrIndexOutOfBounds")
@@ -0,0 +1,438 @@
+package array
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/khezen/struct/collection"
+	"github.com/khezen/struct/internal/format"
+	"github.com/khezen/struct/observer"
+)
+
+// Observable is a thread safe array publishing an event on each modification
+type Observable interface {
+	Interface
+	// Subscribe calls f with every event until unsubscribe is called
+	Subscribe(f func(observer.Event)) (unsubscribe func())
+	// SubscribeChan sends every event on a channel closed by unsubscribe
+	SubscribeChan() (events <-chan observer.Event, unsubscribe func())
+}
+
+// arrayObservable forwards calls to the wrapped array and publishes its changes
+type arrayObservable struct {
+	a Interface
+	l sync.RWMutex
+	observer.Subject
+}
+
+// NewObservable wraps a in a thread safe array publishing Add, Remove, Replace
+// and Clear events carrying the index of the item, and a single Batch event for
+// Merge, Separate, Retain, Swap and each bulk operation. Applying the events in order on a copy of a
+// reproduces it. a must not be modified but through the wrapper.
+func NewObservable(a Interface) Observable {
+	return &arrayObservable{a: a}
+}
+
+// write applies f under lock and delivers the events it returns once unlocked
+func (o *arrayObservable) write(f func() []observer.Event) {
+	func() {
+		o.l.Lock()
+		defer o.l.Unlock()
+		o.Queue(f()...)
+	}()
+	o.Flush()
+}
+
+// operand returns the wrapped array when t is o itself, which is already locked
+func (o *arrayObservable) operand(t collection.ReadOnly) collection.ReadOnly {
+	if t == collection.ReadOnly(o) {
+		return o.a
+	}
+	return t
+}
+
+func (o *arrayObservable) Add(items ...interface{}) {
+	o.write(func() []observer.Event {
+		events := make([]observer.Event, 0, len(items))
+		for _, item := range items {
+			events = append(events, added(o.a.Len(), item))
+			o.a.Add(item)
+		}
+		return events
+	})
+}
+
+func (o *arrayObservable) Insert(i int, items ...interface{}) {
+	o.write(func() []observer.Event {
+		o.a.Insert(i, items...)
+		events := make([]observer.Event, 0, len(items))
+		for j, item := range items {
+			events = append(events, added(i+j, item))
+		}
+		return events
+	})
+}
+
+func (o *arrayObservable) Remove(items ...interface{}) {
+	o.write(func() []observer.Event {
+		return o.remove(items)
+	})
+}
+
+// remove deletes the first occurrence of each item
+func (o *arrayObservable) remove(items []interface{}) []observer.Event {
+	events := make([]observer.Event, 0, len(items))
+	for _, item := range items {
+		if i, err := o.a.IndexOf(item); err == nil {
+			events = append(events, removed(i, o.a.RemoveAt(i)))
+		}
+	}
+	return events
+}
+
+func (o *arrayObservable) RemoveAt(i int) (item interface{}) {
+	o.write(func() []observer.Event {
+		item = o.a.RemoveAt(i)
+		return []observer.Event{removed(i, item)}
+	})
+	return item
+}
+
+func (o *arrayObservable) Replace(item, substitute interface{}) {
+	o.write(func() []observer.Event {
+		i, err := o.a.IndexOf(item)
+		if err != nil {
+			return nil
+		}
+		return []observer.Event{replaced(i, o.a.ReplaceAt(i, substitute), substitute)}
+	})
+}
+
+func (o *arrayObservable) ReplaceAt(i int, substitute interface{}) (item interface{}) {
+	o.write(func() []observer.Event {
+		item = o.a.ReplaceAt(i, substitute)
+		return []observer.Event{replaced(i, item, substitute)}
+	})
+	return item
+}
+
+func (o *arrayObservable) Swap(i, j int) {
+	o.write(func() []observer.Event {
+		itemi, itemj := o.a.Get(i), o.a.Get(j)
+		o.a.Swap(i, j)
+		return batch([]observer.Event{replaced(i, itemi, itemj), replaced(j, itemj, itemi)})
+	})
+}
+
+func (o *arrayObservable) Clear() {
+	o.write(func() []observer.Event {
+		if o.a.IsEmpty() {
+			return nil
+		}
+		o.a.Clear()
+		return []observer.Event{{Op: observer.Clear, Index: -1}}
+	})
+}
+
+func (o *arrayObservable) Merge(t collection.Interface) {
+	o.write(func() []observer.Event {
+		var events []observer.Event
+		for _, item := range o.operand(t).Slice() {
+			if !o.a.Has(item) {
+				events = append(events, added(o.a.Len(), item))
+				o.a.Add(item)
+			}
+		}
+		return batch(events)
+	})
+}
+
+func (o *arrayObservable) Separate(t collection.Interface) {
+	o.write(func() []observer.Event {
+		return batch(o.remove(o.operand(t).Slice()))
+	})
+}
+
+// Retain removes items from the last to the first so that indexes of
+// later events remain valid once earlier events are applied.
+func (o *arrayObservable) Retain(t collection.Interface) {
+	o.write(func() []observer.Event {
+		t := o.operand(t)
+		var events []observer.Event
+		for i := o.a.Len() - 1; i >= 0; i-- {
+			if !t.Has(o.a.Get(i)) {
+				events = append(events, removed(i, o.a.RemoveAt(i)))
+			}
+		}
+		return batch(events)
+	})
+}
+
+func (o *arrayObservable) Splice(i, deleteCount int, items ...interface{}) (removed []interface{}) {
+	o.write(func() []observer.Event {
+		return o.splice(i, func() []interface{} {
+			removed = o.a.Splice(i, deleteCount, items...)
+			return removed
+		})
+	})
+	return removed
+}
+
+func (o *arrayObservable) RemoveRange(i, j int) {
+	o.Splice(i, j-i)
+}
+
+func (o *arrayObservable) InsertAll(i int, t collection.ReadOnly) {
+	o.write(func() []observer.Event {
+		return o.splice(i, func() []interface{} {
+			return o.a.Splice(i, 0, o.operand(t).Slice()...)
+		})
+	})
+}
+
+func (o *arrayObservable) Reverse() {
+	o.write(func() []observer.Event {
+		return o.rewrite(o.a.Reverse)
+	})
+}
+
+func (o *arrayObservable) Rotate(k int) {
+	o.write(func() []observer.Event {
+		return o.rewrite(func() {
+			o.a.Rotate(k)
+		})
+	})
+}
+
+func (o *arrayObservable) Fill(i, j int, item interface{}) {
+	o.write(func() []observer.Event {
+		return o.rewrite(func() {
+			o.a.Fill(i, j, item)
+		})
+	})
+}
+
+func (o *arrayObservable) RemoveIf(pred func(item interface{}) bool) (count int) {
+	o.write(func() []observer.Event {
+		return o.patch(func() {
+			count = o.a.RemoveIf(pred)
+		})
+	})
+	return count
+}
+
+func (o *arrayObservable) ReplaceIf(pred func(item interface{}) bool, substitute func(item interface{}) interface{}) (count int) {
+	o.write(func() []observer.Event {
+		return o.rewrite(func() {
+			count = o.a.ReplaceIf(pred, substitute)
+		})
+	})
+	return count
+}
+
+// patch applies f, then returns the events turning the previous items into the new ones
+func (o *arrayObservable) patch(f func()) []observer.Event {
+	before := New(o.a.Slice()...)
+	f()
+	var events []observer.Event
+	for _, e := range Diff(before, o.a) {
+		switch e.Op {
+		case OpInsert:
+			events = append(events, added(e.Index, e.Item))
+		case OpDelete:
+			events = append(events, removed(e.Index, e.Item))
+		case OpMove:
+			events = append(events, removed(e.From, e.Item), added(e.Index, e.Item))
+		}
+	}
+	return batch(events)
+}
+
+// splice applies f, which removes items from i, returns them and inserts
+// others at i, then returns the events reproducing it
+func (o *arrayObservable) splice(i int, f func() []interface{}) []observer.Event {
+	length := o.a.Len()
+	items := f()
+	inserted := o.a.Len() - length + len(items)
+	events := make([]observer.Event, 0, len(items)+inserted)
+	for _, item := range items {
+		events = append(events, removed(i, item))
+	}
+	for k := i; k < i+inserted; k++ {
+		events = append(events, added(k, o.a.Get(k)))
+	}
+	return batch(events)
+}
+
+// rewrite applies f, which replaces items in place, then returns the events reproducing it
+func (o *arrayObservable) rewrite(f func()) []observer.Event {
+	before := o.a.Slice()
+	f()
+	var events []observer.Event
+	for i, item := range before {
+		if substitute := o.a.Get(i); substitute != item {
+			events = append(events, replaced(i, item, substitute))
+		}
+	}
+	return batch(events)
+}
+
+func (o *arrayObservable) Get(i int) interface{} {
+	o.l.RLock()
+	defer o.l.RUnlock()
+	return o.a.Get(i)
+}
+
+func (o *arrayObservable) IndexOf(item interface{}) (int, error) {
+	o.l.RLock()
+	defer o.l.RUnlock()
+	return o.a.IndexOf(item)
+}
+
+func (o *arrayObservable) Find(pred func(item interface{}) bool) (interface{}, error) {
+	o.l.RLock()
+	defer o.l.RUnlock()
+	return o.a.Find(pred)
+}
+
+func (o *arrayObservable) FindIndex(pred func(item interface{}) bool) (int, error) {
+	o.l.RLock()
+	defer o.l.RUnlock()
+	return o.a.FindIndex(pred)
+}
+
+func (o *arrayObservable) FindLast(pred func(item interface{}) bool) (interface{}, error) {
+	o.l.RLock()
+	defer o.l.RUnlock()
+	return o.a.FindLast(pred)
+}
+
+func (o *arrayObservable) LastIndexOf(item interface{}) (int, error) {
+	o.l.RLock()
+	defer o.l.RUnlock()
+	return o.a.LastIndexOf(item)
+}
+
+func (o *arrayObservable) IndexOfFrom(item interface{}, start int) (int, error) {
+	o.l.RLock()
+	defer o.l.RUnlock()
+	return o.a.IndexOfFrom(item, start)
+}
+
+func (o *arrayObservable) Count(pred func(item interface{}) bool) int {
+	o.l.RLock()
+	defer o.l.RUnlock()
+	return o.a.Count(pred)
+}
+
+func (o *arrayObservable) Has(items ...interface{}) bool {
+	o.l.RLock()
+	defer o.l.RUnlock()
+	return o.a.Has(items...)
+}
+
+func (o *arrayObservable) Each(f func(item interface{}) bool) {
+	o.l.RLock()
+	defer o.l.RUnlock()
+	o.a.Each(f)
+}
+
+func (o *arrayObservable) Len() int {
+	o.l.RLock()
+	defer o.l.RUnlock()
+	return o.a.Len()
+}
+
+func (o *arrayObservable) IsEmpty() bool {
+	o.l.RLock()
+	defer o.l.RUnlock()
+	return o.a.IsEmpty()
+}
+
+func (o *arrayObservable) IsEqual(t collection.ReadOnly) bool {
+	o.l.RLock()
+	defer o.l.RUnlock()
+	return o.a.IsEqual(o.operand(t))
+}
+
+func (o *arrayObservable) String() string {
+	o.l.RLock()
+	defer o.l.RUnlock()
+	return o.a.String()
+}
+
+func (o *arrayObservable) Format(f fmt.State, verb rune) {
+	o.l.RLock()
+	defer o.l.RUnlock()
+	format.Delegate(f, verb, o.a)
+}
+
+func (o *arrayObservable) Slice() []interface{} {
+	o.l.RLock()
+	defer o.l.RUnlock()
+	return o.a.Slice()
+}
+
+// SubArray returns a copy of items from i to j, which does not publish events.
+func (o *arrayObservable) SubArray(i, j int) Interface {
+	o.l.RLock()
+	defer o.l.RUnlock()
+	return o.a.SubArray(i, j)
+}
+
+// SliceRange returns a copy of items from i included to j excluded, which does not publish events.
+func (o *arrayObservable) SliceRange(i, j int) Interface {
+	o.l.RLock()
+	defer o.l.RUnlock()
+	return o.a.SliceRange(i, j)
+}
+
+// Repeat returns a new array holding the items n times, which does not publish events.
+func (o *arrayObservable) Repeat(n int) Interface {
+	o.l.RLock()
+	defer o.l.RUnlock()
+	return o.a.Repeat(n)
+}
+
+// Concat returns a new array holding the items followed by those of each t,
+// which does not publish events.
+func (o *arrayObservable) Concat(t ...collection.ReadOnly) Interface {
+	o.l.RLock()
+	defer o.l.RUnlock()
+	operands := make([]collection.ReadOnly, 0, len(t))
+	for _, c := range t {
+		operands = append(operands, o.operand(c))
+	}
+	return o.a.Concat(operands...)
+}
+
+// CopyArr returns a copy of the wrapped array, which does not publish events.
+func (o *arrayObservable) CopyArr() Interface {
+	o.l.RLock()
+	defer o.l.RUnlock()
+	return o.a.CopyArr()
+}
+
+func (o *arrayObservable) CopyCollection() collection.Interface {
+	return o.CopyArr()
+}
+
+func added(i int, item interface{}) observer.Event {
+	return observer.Event{Op: observer.Add, Item: item, Index: i, New: item}
+}
+
+func removed(i int, item interface{}) observer.Event {
+	return observer.Event{Op: observer.Remove, Item: item, Index: i, Old: item}
+}
+
+func replaced(i int, item, substitute interface{}) observer.Event {
+	return observer.Event{Op: observer.Replace, Item: item, Index: i, Old: item, New: substitute}
+}
+
+// batch groups the events of a Merge, Separate, Retain or Swap
+func batch(events []observer.Event) []observer.Event {
+	if len(events) == 0 {
+		return nil
+	}
+	return []observer.Event{{Op: observer.Batch, Index: -1, Batch: events}}
+}
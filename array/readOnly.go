@@ -0,0 +1,191 @@
+package array
+
+import (
+	"fmt"
+
+	"github.com/khezen/struct/collection"
+	"github.com/khezen/struct/internal/format"
+)
+
+// arrayReadOnly forwards reads to the wrapped array and panics on mutation
+type arrayReadOnly struct {
+	a Interface
+}
+
+// NewReadOnly returns a view of a which panics with collection.ErrReadOnly on mutation.
+// The view reflects later modifications of a.
+func NewReadOnly(a Interface) Interface {
+	if _, ok := a.(*arrayReadOnly); ok {
+		return a
+	}
+	return &arrayReadOnly{a}
+}
+
+func (r *arrayReadOnly) Add(items ...interface{}) {
+	panic(collection.ErrReadOnly)
+}
+
+func (r *arrayReadOnly) Insert(i int, items ...interface{}) {
+	panic(collection.ErrReadOnly)
+}
+
+func (r *arrayReadOnly) Remove(items ...interface{}) {
+	panic(collection.ErrReadOnly)
+}
+
+func (r *arrayReadOnly) RemoveAt(i int) interface{} {
+	panic(collection.ErrReadOnly)
+}
+
+func (r *arrayReadOnly) Replace(toBeReplaced, substitute interface{}) {
+	panic(collection.ErrReadOnly)
+}
+
+func (r *arrayReadOnly) ReplaceAt(i int, substitute interface{}) interface{} {
+	panic(collection.ErrReadOnly)
+}
+
+func (r *arrayReadOnly) Swap(i, j int) {
+	panic(collection.ErrReadOnly)
+}
+
+func (r *arrayReadOnly) Clear() {
+	panic(collection.ErrReadOnly)
+}
+
+func (r *arrayReadOnly) Merge(t collection.Interface) {
+	panic(collection.ErrReadOnly)
+}
+
+func (r *arrayReadOnly) Separate(t collection.Interface) {
+	panic(collection.ErrReadOnly)
+}
+
+func (r *arrayReadOnly) Retain(t collection.Interface) {
+	panic(collection.ErrReadOnly)
+}
+
+func (r *arrayReadOnly) Splice(i, deleteCount int, items ...interface{}) []interface{} {
+	panic(collection.ErrReadOnly)
+}
+
+func (r *arrayReadOnly) RemoveRange(i, j int) {
+	panic(collection.ErrReadOnly)
+}
+
+func (r *arrayReadOnly) InsertAll(i int, t collection.ReadOnly) {
+	panic(collection.ErrReadOnly)
+}
+
+func (r *arrayReadOnly) Reverse() {
+	panic(collection.ErrReadOnly)
+}
+
+func (r *arrayReadOnly) Rotate(k int) {
+	panic(collection.ErrReadOnly)
+}
+
+func (r *arrayReadOnly) Fill(i, j int, item interface{}) {
+	panic(collection.ErrReadOnly)
+}
+
+func (r *arrayReadOnly) RemoveIf(pred func(item interface{}) bool) int {
+	panic(collection.ErrReadOnly)
+}
+
+func (r *arrayReadOnly) ReplaceIf(pred func(item interface{}) bool, substitute func(item interface{}) interface{}) int {
+	panic(collection.ErrReadOnly)
+}
+
+func (r *arrayReadOnly) Get(i int) interface{} {
+	return r.a.Get(i)
+}
+
+func (r *arrayReadOnly) IndexOf(item interface{}) (int, error) {
+	return r.a.IndexOf(item)
+}
+
+func (r *arrayReadOnly) Find(pred func(item interface{}) bool) (interface{}, error) {
+	return r.a.Find(pred)
+}
+
+func (r *arrayReadOnly) FindIndex(pred func(item interface{}) bool) (int, error) {
+	return r.a.FindIndex(pred)
+}
+
+func (r *arrayReadOnly) FindLast(pred func(item interface{}) bool) (interface{}, error) {
+	return r.a.FindLast(pred)
+}
+
+func (r *arrayReadOnly) LastIndexOf(item interface{}) (int, error) {
+	return r.a.LastIndexOf(item)
+}
+
+func (r *arrayReadOnly) IndexOfFrom(item interface{}, start int) (int, error) {
+	return r.a.IndexOfFrom(item, start)
+}
+
+func (r *arrayReadOnly) Count(pred func(item interface{}) bool) int {
+	return r.a.Count(pred)
+}
+
+func (r *arrayReadOnly) Has(items ...interface{}) bool {
+	return r.a.Has(items...)
+}
+
+func (r *arrayReadOnly) Each(f func(item interface{}) bool) {
+	r.a.Each(f)
+}
+
+func (r *arrayReadOnly) Len() int {
+	return r.a.Len()
+}
+
+func (r *arrayReadOnly) IsEmpty() bool {
+	return r.a.IsEmpty()
+}
+
+func (r *arrayReadOnly) IsEqual(t collection.ReadOnly) bool {
+	return r.a.IsEqual(t)
+}
+
+func (r *arrayReadOnly) String() string {
+	return r.a.String()
+}
+
+func (r *arrayReadOnly) Format(f fmt.State, verb rune) {
+	format.Delegate(f, verb, r.a)
+}
+
+func (r *arrayReadOnly) Slice() []interface{} {
+	return r.a.Slice()
+}
+
+// SubArray returns a mutable array holding items from i to j.
+func (r *arrayReadOnly) SubArray(i, j int) Interface {
+	return r.a.SubArray(i, j)
+}
+
+// SliceRange returns a mutable array holding items from i included to j excluded.
+func (r *arrayReadOnly) SliceRange(i, j int) Interface {
+	return r.a.SliceRange(i, j)
+}
+
+// Repeat returns a mutable array holding the items n times.
+func (r *arrayReadOnly) Repeat(n int) Interface {
+	return r.a.Repeat(n)
+}
+
+// Concat returns a mutable array holding the items followed by those of each t.
+func (r *arrayReadOnly) Concat(t ...collection.ReadOnly) Interface {
+	return r.a.Concat(t...)
+}
+
+// CopyArr returns a mutable copy of the array.
+func (r *arrayReadOnly) CopyArr() Interface {
+	return r.a.CopyArr()
+}
+
+func (r *arrayReadOnly) CopyCollection() collection.Interface {
+	return r.a.CopyCollection()
+}
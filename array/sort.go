@@ -0,0 +1,51 @@
+package array
+
+import (
+	"sort"
+
+	"github.com/khezen/struct/compare"
+)
+
+// SortStable sorts the items of a with less, keeping equivalent items in
+// their order. Arrays of other packages are rewritten with a single Splice.
+func SortStable(a Interface, less func(slice []interface{}, i, j int) bool) {
+	switch conv := a.(type) {
+	case *array:
+		conv.stable(less)
+	case *arraySync:
+		conv.l.Lock()
+		defer conv.l.Unlock()
+		conv.array.stable(less)
+	case *arraySort:
+		conv.rewrite(&conv.array, func() {
+			conv.array.stable(less)
+		})
+	case *arraySortSync:
+		conv.l.Lock()
+		defer conv.l.Unlock()
+		conv.rewrite(&conv.array, func() {
+			conv.array.stable(less)
+		})
+	default:
+		items := a.Slice()
+		sort.SliceStable(items, func(i, j int) bool {
+			return less(items, i, j)
+		})
+		a.Splice(0, len(items), items...)
+	}
+}
+
+// SortFunc sorts the items of a with cmp, keeping equivalent items in their order.
+func SortFunc(a Interface, cmp compare.Func) {
+	SortStable(a, compare.Less(cmp))
+}
+
+// SortBy sorts the items of a by the natural order of their keys, keeping
+// items with equal keys in their order.
+func SortBy(a Interface, key func(item interface{}) interface{}) {
+	SortFunc(a, compare.By(key, compare.Natural))
+}
+
+func (a *array) stable(less func(slice []interface{}, i, j int) bool) {
+	sort.Stable(sorter{a.s, less})
+}
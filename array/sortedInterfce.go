@@ -1,8 +1,30 @@
 package array
 
-// Sorted is the interface for sortable arrays
+// Sorted is the interface for sortable arrays. Once the items are known to be
+// sorted, Has and IndexOf use a binary search.
 type Sorted interface {
 	Interface
 	Sort()
 	Less(i, j int) bool
+	// SortStable sorts the array, keeping equivalent items in their order
+	SortStable()
+	// SetLess changes the comparison used to sort the array
+	SetLess(less func(slice []interface{}, i, j int) bool)
+	// IsSorted reports whether the items are sorted
+	IsSorted() bool
+	// SearchSorted returns the index of item and true if it is found, or the
+	// index where it would be inserted and false otherwise
+	SearchSorted(item interface{}) (int, bool)
+	// InsertSorted inserts the items at their place, sorting the array first
+	// if needed
+	InsertSorted(items ...interface{})
+	// BinaryIndexOf returns the index of item in the sorted array
+	BinaryIndexOf(item interface{}) (int, error)
+	// LowerBound returns the index of the first item not sorting before item
+	LowerBound(item interface{}) int
+	// UpperBound returns the index of the first item sorting after item
+	UpperBound(item interface{}) int
+	// SetAutoSort sorts the array and makes Add, Insert, ReplaceAt and the
+	// other modifications keep it sorted, ignoring the given indexes
+	SetAutoSort(auto bool)
 }
@@ -0,0 +1,222 @@
+package bimap
+
+import (
+	"fmt"
+
+	"github.com/khezen/struct/hashmap"
+	"github.com/khezen/struct/internal/format"
+)
+
+type bimap struct {
+	kv     map[interface{}]interface{}
+	vk     map[interface{}]interface{}
+	policy Policy
+}
+
+// New creates a new bidirectional map
+func New(policy Policy, pairs ...interface{}) Interface {
+	b := &bimap{
+		kv:     make(map[interface{}]interface{}),
+		vk:     make(map[interface{}]interface{}),
+		policy: policy,
+	}
+	length := len(pairs)
+	for i := 0; i < length-1; i += 2 {
+		b.Put(pairs[i], pairs[i+1])
+	}
+	return b
+}
+
+func (b *bimap) Get(k interface{}) (interface{}, error) {
+	v, ok := b.kv[k]
+	if !ok {
+		return nil, fmt.Errorf("%v not found", k)
+	}
+	return v, nil
+}
+
+// Put binds k to v. What happens when v is already bound to another key
+// depends on the policy.
+func (b *bimap) Put(k, v interface{}) {
+	err := b.TryPut(k, v)
+	if err != nil && b.policy == PanicOnDuplicate {
+		panic(err)
+	}
+}
+
+// TryPut binds k to v. It returns ErrDuplicateValue if v is already bound to
+// another key and the policy is not OverwriteOnDuplicate.
+func (b *bimap) TryPut(k, v interface{}) error {
+	if other, ok := b.vk[v]; ok {
+		if other == k {
+			return nil
+		}
+		if b.policy != OverwriteOnDuplicate {
+			return ErrDuplicateValue
+		}
+		delete(b.kv, other)
+	}
+	if old, ok := b.kv[k]; ok {
+		delete(b.vk, old)
+	}
+	b.kv[k] = v
+	b.vk[v] = k
+	return nil
+}
+
+func (b *bimap) Remove(keys ...interface{}) {
+	for _, k := range keys {
+		if v, ok := b.kv[k]; ok {
+			delete(b.kv, k)
+			delete(b.vk, v)
+		}
+	}
+}
+
+func (b *bimap) Has(keys ...interface{}) bool {
+	for _, k := range keys {
+		if _, ok := b.kv[k]; !ok {
+			return false
+		}
+	}
+	return true
+}
+
+func (b *bimap) HasValue(values ...interface{}) bool {
+	for _, v := range values {
+		if _, ok := b.vk[v]; !ok {
+			return false
+		}
+	}
+	return true
+}
+
+func (b *bimap) KeyOf(value interface{}) (interface{}, error) {
+	k, ok := b.vk[value]
+	if !ok {
+		return nil, fmt.Errorf("%v not found", value)
+	}
+	return k, nil
+}
+
+func (b *bimap) Each(f func(k, v interface{}) bool) {
+	for k, v := range b.kv {
+		if !f(k, v) {
+			break
+		}
+	}
+}
+
+func (b *bimap) Len() int {
+	return len(b.kv)
+}
+
+// Clear removes all pairs. Maps are emptied in place so inverse views remain live.
+func (b *bimap) Clear() {
+	for k := range b.kv {
+		delete(b.kv, k)
+	}
+	for v := range b.vk {
+		delete(b.vk, v)
+	}
+}
+
+func (b *bimap) IsEmpty() bool {
+	return b.Len() == 0
+}
+
+func (b *bimap) IsEqual(t hashmap.ReadOnly) bool {
+	// Force locking only if given map is threadsafe.
+	if conv, ok := t.(*bimapSync); ok {
+		conv.l.RLock()
+		defer conv.l.RUnlock()
+	}
+	if b.Len() != t.Len() {
+		return false
+	}
+	equal := true
+	t.Each(func(k, v interface{}) bool {
+		value, ok := b.kv[k]
+		equal = equal && ok && value == v
+		return equal
+	})
+	return equal
+}
+
+// String returns a string representation of b, sorted by keys when they are orderable
+func (b *bimap) String() string {
+	return format.String(b)
+}
+
+// Format implements fmt.Formatter: %v prints the pairs, sorted by keys when
+// they are orderable, %+v adds the type and the length and %#v the Go syntax.
+// A precision truncates the pairs: %.3v prints the 3 first followed by "...".
+func (b *bimap) Format(f fmt.State, verb rune) {
+	b.format(f, verb, "bimap", "bimap.New")
+}
+
+func (b *bimap) format(f fmt.State, verb rune, name, constructor string) {
+	keys := make([]interface{}, 0, len(b.kv))
+	values := make([]interface{}, 0, len(b.kv))
+	for k, v := range b.kv {
+		keys = append(keys, k)
+		values = append(values, v)
+	}
+	format.Map(f, verb, name, constructor, keys, values, "bimap."+b.policy.String())
+}
+
+func (b *bimap) Keys() []interface{} {
+	keys := make([]interface{}, 0, b.Len())
+	for k := range b.kv {
+		keys = append(keys, k)
+	}
+	return keys
+}
+
+func (b *bimap) Values() []interface{} {
+	values := make([]interface{}, 0, b.Len())
+	for v := range b.vk {
+		values = append(values, v)
+	}
+	return values
+}
+
+// Map returns a copy of the key to value pairs. Altering it does not affect the bimap.
+func (b *bimap) Map() map[interface{}]interface{} {
+	m := make(map[interface{}]interface{}, b.Len())
+	for k, v := range b.kv {
+		m[k] = v
+	}
+	return m
+}
+
+func (b *bimap) Copy() hashmap.Interface {
+	return b.copy()
+}
+
+func (b *bimap) copy() *bimap {
+	cpy := New(b.policy).(*bimap)
+	for k, v := range b.kv {
+		cpy.kv[k] = v
+		cpy.vk[v] = k
+	}
+	return cpy
+}
+
+// Inverse returns a live view of the bimap in which values are keys. Changes
+// on either side are visible on the other.
+func (b *bimap) Inverse() Interface {
+	return b.inverse()
+}
+
+func (b *bimap) inverse() *bimap {
+	return &bimap{
+		kv:     b.vk,
+		vk:     b.kv,
+		policy: b.policy,
+	}
+}
+
+func (b *bimap) Policy() Policy {
+	return b.policy
+}
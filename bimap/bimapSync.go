@@ -0,0 +1,151 @@
+package bimap
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/khezen/struct/hashmap"
+	"github.com/khezen/struct/internal/format"
+)
+
+type bimapSync struct {
+	bimap
+	l *sync.RWMutex // shared with inverse views
+}
+
+// NewSync creates a new thread safe bidirectional map
+func NewSync(policy Policy, pairs ...interface{}) Interface {
+	return &bimapSync{
+		*New(policy, pairs...).(*bimap),
+		&sync.RWMutex{},
+	}
+}
+
+func (b *bimapSync) Get(k interface{}) (interface{}, error) {
+	b.l.RLock()
+	defer b.l.RUnlock()
+	return b.bimap.Get(k)
+}
+
+func (b *bimapSync) Put(k, v interface{}) {
+	b.l.Lock()
+	defer b.l.Unlock()
+	b.bimap.Put(k, v)
+}
+
+func (b *bimapSync) TryPut(k, v interface{}) error {
+	b.l.Lock()
+	defer b.l.Unlock()
+	return b.bimap.TryPut(k, v)
+}
+
+func (b *bimapSync) Remove(keys ...interface{}) {
+	b.l.Lock()
+	defer b.l.Unlock()
+	b.bimap.Remove(keys...)
+}
+
+func (b *bimapSync) Has(keys ...interface{}) bool {
+	b.l.RLock()
+	defer b.l.RUnlock()
+	return b.bimap.Has(keys...)
+}
+
+func (b *bimapSync) HasValue(values ...interface{}) bool {
+	b.l.RLock()
+	defer b.l.RUnlock()
+	return b.bimap.HasValue(values...)
+}
+
+func (b *bimapSync) KeyOf(value interface{}) (interface{}, error) {
+	b.l.RLock()
+	defer b.l.RUnlock()
+	return b.bimap.KeyOf(value)
+}
+
+func (b *bimapSync) Each(f func(k, v interface{}) bool) {
+	b.l.RLock()
+	defer b.l.RUnlock()
+	b.bimap.Each(f)
+}
+
+func (b *bimapSync) Len() int {
+	b.l.RLock()
+	defer b.l.RUnlock()
+	return b.bimap.Len()
+}
+
+func (b *bimapSync) Clear() {
+	b.l.Lock()
+	defer b.l.Unlock()
+	b.bimap.Clear()
+}
+
+func (b *bimapSync) IsEmpty() bool {
+	b.l.RLock()
+	defer b.l.RUnlock()
+	return b.bimap.IsEmpty()
+}
+
+func (b *bimapSync) IsEqual(t hashmap.ReadOnly) bool {
+	b.l.RLock()
+	defer b.l.RUnlock()
+	if conv, ok := t.(*bimapSync); ok && conv.l == b.l {
+		// t shares our lock, which is already held.
+		return b.bimap.IsEqual(&conv.bimap)
+	}
+	return b.bimap.IsEqual(t)
+}
+
+func (b *bimapSync) String() string {
+	return format.String(b)
+}
+
+func (b *bimapSync) Format(f fmt.State, verb rune) {
+	b.l.RLock()
+	defer b.l.RUnlock()
+	b.format(f, verb, "bimap.Sync", "bimap.NewSync")
+}
+
+func (b *bimapSync) Keys() []interface{} {
+	b.l.RLock()
+	defer b.l.RUnlock()
+	return b.bimap.Keys()
+}
+
+func (b *bimapSync) Values() []interface{} {
+	b.l.RLock()
+	defer b.l.RUnlock()
+	return b.bimap.Values()
+}
+
+func (b *bimapSync) Map() map[interface{}]interface{} {
+	b.l.RLock()
+	defer b.l.RUnlock()
+	return b.bimap.Map()
+}
+
+func (b *bimapSync) Copy() hashmap.Interface {
+	b.l.RLock()
+	defer b.l.RUnlock()
+	return &bimapSync{
+		*b.bimap.copy(),
+		&sync.RWMutex{},
+	}
+}
+
+// Inverse returns a live, thread safe view of the bimap in which values are
+// keys. The view shares the lock of the bimap.
+func (b *bimapSync) Inverse() Interface {
+	return &bimapSync{
+		*b.bimap.inverse(),
+		b.l,
+	}
+}
+
+// Snapshot returns a non-threadsafe copy of b consistent at the time of the call.
+func (b *bimapSync) Snapshot() Interface {
+	b.l.RLock()
+	defer b.l.RUnlock()
+	return b.bimap.copy()
+}
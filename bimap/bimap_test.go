@@ -0,0 +1,286 @@
+package bimap
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/khezen/struct/hashmap"
+)
+
+func testErr(err error, expectErr bool, t *testing.T) {
+	if (expectErr && err == nil) || (!expectErr && err != nil) {
+		t.Errorf(" Error expected? %v. Got: %v.", expectErr, err)
+	}
+}
+
+func TestGet(t *testing.T) {
+	cases := []struct {
+		b             Interface
+		key, expected interface{}
+		expectErr     bool
+	}{
+		{New(ErrorOnDuplicate, "1", 1, "42", 42), "42", 42, false},
+		{New(ErrorOnDuplicate, "1", 1, "42", 42), "1000", nil, true},
+		{NewSync(ErrorOnDuplicate, "1", 1, "42", 42), "42", 42, false},
+		{NewSync(ErrorOnDuplicate, "1", 1, "42", 42), "1000", nil, true},
+	}
+	for _, c := range cases {
+		item, err := c.b.Get(c.key)
+		testErr(err, c.expectErr, t)
+		if item != c.expected {
+			t.Errorf("Expected %v. Got %v.", c.expected, item)
+		}
+	}
+}
+
+func TestTryPut(t *testing.T) {
+	cases := []struct {
+		b, expected Interface
+		key, value  interface{}
+		expectErr   bool
+	}{
+		{New(ErrorOnDuplicate, "1", 1), New(ErrorOnDuplicate, "1", 1, "2", 2), "2", 2, false},
+		{New(ErrorOnDuplicate, "1", 1), New(ErrorOnDuplicate, "1", 2), "1", 2, false},
+		{New(ErrorOnDuplicate, "1", 1), New(ErrorOnDuplicate, "1", 1), "1", 1, false},
+		{New(ErrorOnDuplicate, "1", 1), New(ErrorOnDuplicate, "1", 1), "2", 1, true},
+		{New(OverwriteOnDuplicate, "1", 1), New(ErrorOnDuplicate, "2", 1), "2", 1, false},
+		{New(PanicOnDuplicate, "1", 1), New(ErrorOnDuplicate, "1", 1), "2", 1, true},
+		{NewSync(ErrorOnDuplicate, "1", 1), New(ErrorOnDuplicate, "1", 1), "2", 1, true},
+		{NewSync(OverwriteOnDuplicate, "1", 1), New(ErrorOnDuplicate, "2", 1), "2", 1, false},
+	}
+	for _, c := range cases {
+		err := c.b.TryPut(c.key, c.value)
+		testErr(err, c.expectErr, t)
+		if !c.b.IsEqual(c.expected) {
+			t.Errorf("Expected %v. Got %v.", c.expected, c.b)
+		}
+		if c.b.Len() != len(c.b.Values()) {
+			t.Errorf("Reverse index out of sync: %v keys, %v values", c.b.Len(), len(c.b.Values()))
+		}
+	}
+}
+
+func TestPut(t *testing.T) {
+	cases := []struct {
+		b, expected Interface
+		key, value  interface{}
+	}{
+		{New(ErrorOnDuplicate, "1", 1), New(ErrorOnDuplicate, "1", 1), "2", 1},
+		{New(OverwriteOnDuplicate, "1", 1), New(ErrorOnDuplicate, "2", 1), "2", 1},
+		{NewSync(ErrorOnDuplicate, "1", 1), New(ErrorOnDuplicate, "1", 1), "2", 1},
+		{NewSync(OverwriteOnDuplicate, "1", 1), New(ErrorOnDuplicate, "2", 1), "2", 1},
+	}
+	for _, c := range cases {
+		c.b.Put(c.key, c.value)
+		if !c.b.IsEqual(c.expected) {
+			t.Errorf("Expected %v. Got %v.", c.expected, c.b)
+		}
+	}
+}
+
+func TestPutPanic(t *testing.T) {
+	cases := []struct {
+		b Interface
+	}{
+		{New(PanicOnDuplicate, "1", 1)},
+		{NewSync(PanicOnDuplicate, "1", 1)},
+	}
+	for _, c := range cases {
+		func() {
+			defer func() {
+				if r := recover(); r != ErrDuplicateValue {
+					t.Errorf("Expected %v. Got %v.", ErrDuplicateValue, r)
+				}
+			}()
+			c.b.Put("2", 1)
+		}()
+	}
+}
+
+func TestRemove(t *testing.T) {
+	cases := []struct {
+		b, expected Interface
+		keys        []interface{}
+	}{
+		{New(ErrorOnDuplicate, "1", 1, "42", 42), New(ErrorOnDuplicate, "42", 42), []interface{}{"1", "-1"}},
+		{NewSync(ErrorOnDuplicate, "1", 1, "42", 42), New(ErrorOnDuplicate, "42", 42), []interface{}{"1", "-1"}},
+	}
+	for _, c := range cases {
+		c.b.Remove(c.keys...)
+		if !c.b.IsEqual(c.expected) {
+			t.Errorf("Expected %v. Got %v.", c.expected, c.b)
+		}
+		if c.b.HasValue(1) {
+			t.Error("Value of removed key should be unbound")
+		}
+	}
+}
+
+func TestKeyOf(t *testing.T) {
+	cases := []struct {
+		b              Interface
+		value, key     interface{}
+		expectErr, has bool
+	}{
+		{New(ErrorOnDuplicate, "1", 1, "42", 42), 42, "42", false, true},
+		{New(ErrorOnDuplicate, "1", 1, "42", 42), 1000, nil, true, false},
+		{NewSync(ErrorOnDuplicate, "1", 1, "42", 42), 42, "42", false, true},
+		{NewSync(ErrorOnDuplicate, "1", 1, "42", 42), 1000, nil, true, false},
+	}
+	for _, c := range cases {
+		k, err := c.b.KeyOf(c.value)
+		testErr(err, c.expectErr, t)
+		if k != c.key {
+			t.Errorf("Expected %v. Got %v.", c.key, k)
+		}
+		if c.b.HasValue(c.value) != c.has {
+			t.Errorf("Expected %v. Got %v.", c.has, !c.has)
+		}
+	}
+}
+
+func TestInverse(t *testing.T) {
+	cases := []struct {
+		b Interface
+	}{
+		{New(ErrorOnDuplicate, "1", 1, "42", 42)},
+		{NewSync(ErrorOnDuplicate, "1", 1, "42", 42)},
+	}
+	for _, c := range cases {
+		inv := c.b.Inverse()
+		if !inv.IsEqual(hashmap.New(1, "1", 42, "42")) {
+			t.Errorf("Expected %v. Got %v.", hashmap.New(1, "1", 42, "42"), inv)
+		}
+		inv.Put(-8, "-8")
+		if v, _ := c.b.Get("-8"); v != -8 {
+			t.Errorf("Inverse should be a live view. Got %v.", c.b)
+		}
+		c.b.Remove("1")
+		if inv.Has(1) {
+			t.Errorf("Inverse should be a live view. Got %v.", inv)
+		}
+		c.b.Clear()
+		if !inv.IsEmpty() {
+			t.Errorf("Inverse should be a live view. Got %v.", inv)
+		}
+		if !inv.Inverse().IsEqual(c.b) {
+			t.Errorf("Expected %v. Got %v.", c.b, inv.Inverse())
+		}
+	}
+}
+
+func TestEach(t *testing.T) {
+	cases := []struct {
+		b                 Interface
+		counter, expected int
+	}{
+		{New(ErrorOnDuplicate, "1", 1, "42", 42, "-8", -8), 0, 2},
+		{NewSync(ErrorOnDuplicate, "1", 1, "42", 42, "-8", -8), 0, 2},
+	}
+	for _, c := range cases {
+		c.b.Each(func(k, v interface{}) bool {
+			c.counter++
+			return c.counter < c.expected
+		})
+		if c.counter != c.expected {
+			t.Errorf("Expected %v. Got %v.", c.expected, c.counter)
+		}
+	}
+}
+
+func TestKeysValues(t *testing.T) {
+	cases := []struct {
+		b Interface
+	}{
+		{New(ErrorOnDuplicate, "1", 1, "42", 42)},
+		{NewSync(ErrorOnDuplicate, "1", 1, "42", 42)},
+	}
+	for _, c := range cases {
+		if len(c.b.Keys()) != 2 || len(c.b.Values()) != 2 {
+			t.Errorf("Expected 2 keys and values. Got %v and %v.", c.b.Keys(), c.b.Values())
+		}
+		m := c.b.Map()
+		m["1000"] = 1000
+		if c.b.Has("1000") {
+			t.Error("Map should return a copy")
+		}
+	}
+}
+
+func TestCopy(t *testing.T) {
+	cases := []struct {
+		b Interface
+	}{
+		{New(OverwriteOnDuplicate, "1", 1, "42", 42)},
+		{NewSync(OverwriteOnDuplicate, "1", 1, "42", 42)},
+	}
+	for _, c := range cases {
+		cpy := c.b.Copy()
+		if !cpy.IsEqual(c.b) {
+			t.Errorf("Expected %v. Got %v.", c.b, cpy)
+		}
+		cpy.Put("1", 2)
+		if v, _ := c.b.Get("1"); v != 1 {
+			t.Errorf("Copy should not alter the original. Got %v.", c.b)
+		}
+		if cpy.(Interface).Policy() != c.b.Policy() {
+			t.Error("Copy should keep the policy")
+		}
+	}
+}
+
+func TestLenClear(t *testing.T) {
+	cases := []struct {
+		b Interface
+	}{
+		{New(ErrorOnDuplicate, "1", 1, "42", 42)},
+		{NewSync(ErrorOnDuplicate, "1", 1, "42", 42)},
+	}
+	for _, c := range cases {
+		if c.b.Len() != 2 || c.b.IsEmpty() {
+			t.Errorf("Expected 2. Got %v.", c.b.Len())
+		}
+		c.b.Clear()
+		if !c.b.IsEmpty() || c.b.HasValue(1) {
+			t.Error("Bimap should be empty")
+		}
+		if c.b.String() != "map[]" {
+			t.Errorf("Expected map[]. Got %v.", c.b.String())
+		}
+	}
+}
+
+func TestFormat(t *testing.T) {
+	cases := []struct {
+		format   string
+		b        Interface
+		expected string
+	}{
+		{"%v", New(ErrorOnDuplicate, "b", 2, "a", 1), "map[a:1 b:2]"},
+		{"%+v", NewSync(ErrorOnDuplicate, "a", 1), "bimap.Sync(len=1) map[a:1]"},
+		{"%#v", New(OverwriteOnDuplicate, "a", 1), `bimap.New(bimap.OverwriteOnDuplicate, "a", 1)`},
+		{"%#v", NewSync(PanicOnDuplicate), "bimap.NewSync(bimap.PanicOnDuplicate)"},
+		{"%v", NewSync(ErrorOnDuplicate, "a", 1).Inverse(), "map[1:a]"},
+	}
+	for _, c := range cases {
+		if got := fmt.Sprintf(c.format, c.b); got != c.expected {
+			t.Errorf("Expected %v. Got %v.", c.expected, got)
+		}
+	}
+	if str := Policy(42).String(); str != "Policy(42)" {
+		t.Errorf("Expected %v. Got %v.", "Policy(42)", str)
+	}
+}
+
+func TestSnapshot(t *testing.T) {
+	b := NewSync(ErrorOnDuplicate, "a", 1)
+	snapshot := b.(interface{ Snapshot() Interface }).Snapshot()
+	b.Put("b", 2)
+	snapshot.Put("c", 3)
+	if _, ok := snapshot.(*bimap); !ok || !snapshot.IsEqual(New(ErrorOnDuplicate, "a", 1, "c", 3)) {
+		t.Errorf("Expected %v. Got %v.", New(ErrorOnDuplicate, "a", 1, "c", 3), snapshot)
+	}
+	if k, _ := snapshot.Inverse().Get(3); k != "c" || b.Has("c") {
+		t.Errorf("Expected %v. Got %v.", "c", k)
+	}
+}
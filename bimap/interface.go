@@ -0,0 +1,50 @@
+// Package bimap provides both threadsafe and non-threadsafe implementations of
+// a bidirectional map. Each value is bound to exactly one key, which allows
+// constant time reverse lookups and a live inverse view of the map.
+package bimap
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/khezen/struct/hashmap"
+)
+
+// Interface describes functions a bidirectional map must expose
+type Interface interface {
+	hashmap.Interface
+	TryPut(k, v interface{}) error
+	Inverse() Interface
+	Policy() Policy
+}
+
+// Policy decides what happens when a value already bound to another key is put
+type Policy int
+
+const (
+	// ErrorOnDuplicate rejects the put. TryPut reports ErrDuplicateValue while
+	// Put silently ignores the conflicting pair.
+	ErrorOnDuplicate Policy = iota
+	// OverwriteOnDuplicate unbinds the value from its previous key.
+	OverwriteOnDuplicate
+	// PanicOnDuplicate panics with ErrDuplicateValue.
+	PanicOnDuplicate
+)
+
+// String returns the name of the policy
+func (p Policy) String() string {
+	switch p {
+	case ErrorOnDuplicate:
+		return "ErrorOnDuplicate"
+	case OverwriteOnDuplicate:
+		return "OverwriteOnDuplicate"
+	case PanicOnDuplicate:
+		return "PanicOnDuplicate"
+	}
+	return fmt.Sprintf("Policy(%d)", int(p))
+}
+
+var (
+	// ErrDuplicateValue - value is already bound to another key
+	ErrDuplicateValue = errors.New("ErrDuplicateValue - value is already bound to another key")
+)
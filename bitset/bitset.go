@@ -0,0 +1,427 @@
+package bitset
+
+import (
+	"fmt"
+	"math/bits"
+
+	"github.com/khezen/struct/collection"
+	"github.com/khezen/struct/internal/format"
+	"github.com/khezen/struct/set"
+)
+
+// bitset defines a non-thread safe bitset. Bit i of w[i/64] tells whether i is in the set.
+type bitset struct {
+	w []uint64
+}
+
+// New creates a non thread safe bitset. It panics with ErrBadItem if any item
+// is not a non-negative int.
+func New(items ...int) Interface {
+	b := &bitset{}
+	for _, item := range items {
+		b.add(item)
+	}
+	return b
+}
+
+// FromSet creates a bitset holding the items of c. It returns ErrBadItem if
+// any item is not a non-negative int.
+func FromSet(c collection.Interface) (Interface, error) {
+	b := &bitset{}
+	var err error
+	c.Each(func(item interface{}) bool {
+		i, ok := toIndex(item)
+		if !ok {
+			err = ErrBadItem
+			return false
+		}
+		b.add(i)
+		return true
+	})
+	if err != nil {
+		return nil, err
+	}
+	return b, nil
+}
+
+func toIndex(item interface{}) (int, bool) {
+	i, ok := item.(int)
+	return i, ok && i >= 0
+}
+
+func (b *bitset) add(i int) {
+	if i < 0 {
+		panic(ErrBadItem)
+	}
+	b.grow(i/wordSize + 1)
+	b.w[i/wordSize] |= 1 << uint(i%wordSize)
+}
+
+func (b *bitset) has(i int) bool {
+	word := i / wordSize
+	return word < len(b.w) && b.w[word]&(1<<uint(i%wordSize)) != 0
+}
+
+func (b *bitset) remove(i int) {
+	if word := i / wordSize; word < len(b.w) {
+		b.w[word] &^= 1 << uint(i%wordSize)
+	}
+}
+
+// grow makes sure b holds at least n words.
+func (b *bitset) grow(n int) {
+	if n <= len(b.w) {
+		return
+	}
+	if n <= cap(b.w) {
+		b.w = b.w[:n]
+		return
+	}
+	w := make([]uint64, n, 2*n)
+	copy(w, b.w)
+	b.w = w
+}
+
+// trim drops trailing empty words.
+func (b *bitset) trim() {
+	n := len(b.w)
+	for n > 0 && b.w[n-1] == 0 {
+		n--
+	}
+	b.w = b.w[:n]
+}
+
+// Add includes the specified items. It panics with ErrBadItem if any item is
+// not a non-negative int.
+func (b *bitset) Add(items ...interface{}) {
+	for _, item := range items {
+		i, ok := toIndex(item)
+		if !ok {
+			panic(ErrBadItem)
+		}
+		b.add(i)
+	}
+}
+
+func (b *bitset) Remove(items ...interface{}) {
+	for _, item := range items {
+		if i, ok := toIndex(item); ok {
+			b.remove(i)
+		}
+	}
+	b.trim()
+}
+
+// Pop deletes and returns the lowest item. If the bitset is empty, nil is returned.
+func (b *bitset) Pop() interface{} {
+	i, ok := b.NextSet(0)
+	if !ok {
+		return nil
+	}
+	b.remove(i)
+	b.trim()
+	return i
+}
+
+func (b *bitset) Replace(item, substitute interface{}) {
+	if b.Has(item) {
+		b.Remove(item)
+		b.Add(substitute)
+	}
+}
+
+func (b *bitset) Has(items ...interface{}) bool {
+	for _, item := range items {
+		i, ok := toIndex(item)
+		if !ok || !b.has(i) {
+			return false
+		}
+	}
+	return true
+}
+
+// Each traverses the items in ascending order until all have been visited or
+// the closure returns false.
+func (b *bitset) Each(f func(item interface{}) bool) {
+	for word, w := range b.w {
+		for w != 0 {
+			tz := bits.TrailingZeros64(w)
+			if !f(word*wordSize + tz) {
+				return
+			}
+			w &^= 1 << uint(tz)
+		}
+	}
+}
+
+func (b *bitset) Len() int {
+	return b.PopCount()
+}
+
+// PopCount returns the number of items.
+func (b *bitset) PopCount() int {
+	count := 0
+	for _, w := range b.w {
+		count += bits.OnesCount64(w)
+	}
+	return count
+}
+
+// Rank returns the number of items lower than or equal to i.
+func (b *bitset) Rank(i int) int {
+	if i < 0 {
+		return 0
+	}
+	word := i / wordSize
+	if word >= len(b.w) {
+		return b.PopCount()
+	}
+	rank := 0
+	for _, w := range b.w[:word] {
+		rank += bits.OnesCount64(w)
+	}
+	shift := uint(wordSize - 1 - i%wordSize)
+	return rank + bits.OnesCount64(b.w[word]<<shift)
+}
+
+// NextSet returns the lowest item greater than or equal to i.
+func (b *bitset) NextSet(i int) (int, bool) {
+	if i < 0 {
+		i = 0
+	}
+	word := i / wordSize
+	if word >= len(b.w) {
+		return -1, false
+	}
+	w := b.w[word] >> uint(i%wordSize)
+	if w != 0 {
+		return i + bits.TrailingZeros64(w), true
+	}
+	for word++; word < len(b.w); word++ {
+		if b.w[word] != 0 {
+			return word*wordSize + bits.TrailingZeros64(b.w[word]), true
+		}
+	}
+	return -1, false
+}
+
+func (b *bitset) Clear() {
+	b.w = nil
+}
+
+func (b *bitset) IsEmpty() bool {
+	for _, w := range b.w {
+		if w != 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// wordsOf returns the words of t if t is a bitset. Callers must hold the lock of t.
+func wordsOf(t collection.ReadOnly) ([]uint64, bool) {
+	switch conv := t.(type) {
+	case *bitset:
+		return conv.w, true
+	case *bitsetSync:
+		return conv.w, true
+	}
+	return nil, false
+}
+
+func (b *bitset) IsEqual(t collection.ReadOnly) bool {
+	// Force locking only if given bitset is threadsafe.
+	if conv, ok := t.(*bitsetSync); ok {
+		conv.l.RLock()
+		defer conv.l.RUnlock()
+	}
+	if w, ok := wordsOf(t); ok {
+		long, short := b.w, w
+		if len(long) < len(short) {
+			long, short = short, long
+		}
+		for i := range short {
+			if long[i] != short[i] {
+				return false
+			}
+		}
+		for _, w := range long[len(short):] {
+			if w != 0 {
+				return false
+			}
+		}
+		return true
+	}
+	if b.Len() != t.Len() {
+		return false
+	}
+	equal := true
+	t.Each(func(item interface{}) bool {
+		equal = b.Has(item)
+		return equal
+	})
+	return equal
+}
+
+// IsSubset tests whether t is a subset of b.
+func (b *bitset) IsSubset(t set.ReadOnly) bool {
+	if conv, ok := t.(*bitsetSync); ok {
+		conv.l.RLock()
+		defer conv.l.RUnlock()
+	}
+	if w, ok := wordsOf(t); ok {
+		for i := range w {
+			var mine uint64
+			if i < len(b.w) {
+				mine = b.w[i]
+			}
+			if w[i]&^mine != 0 {
+				return false
+			}
+		}
+		return true
+	}
+	subset := true
+	t.Each(func(item interface{}) bool {
+		subset = b.Has(item)
+		return subset
+	})
+	return subset
+}
+
+// IsSuperset tests whether t is a superset of b.
+func (b *bitset) IsSuperset(t set.ReadOnly) bool {
+	if conv, ok := t.(*bitsetSync); ok {
+		conv.l.RLock()
+		defer conv.l.RUnlock()
+		return conv.bitset.IsSubset(b)
+	}
+	return t.IsSubset(b)
+}
+
+// Merge adds the items of t to b. It panics with ErrBadItem if t holds
+// anything but non-negative ints.
+func (b *bitset) Merge(t collection.Interface) {
+	if conv, ok := t.(*bitsetSync); ok {
+		conv.l.RLock()
+		defer conv.l.RUnlock()
+	}
+	if w, ok := wordsOf(t); ok {
+		b.grow(len(w))
+		for i := range w {
+			b.w[i] |= w[i]
+		}
+		return
+	}
+	t.Each(func(item interface{}) bool {
+		b.Add(item)
+		return true
+	})
+}
+
+// Separate removes the items of t from b.
+func (b *bitset) Separate(t collection.Interface) {
+	if conv, ok := t.(*bitsetSync); ok {
+		conv.l.RLock()
+		defer conv.l.RUnlock()
+	}
+	if w, ok := wordsOf(t); ok {
+		for i := 0; i < len(w) && i < len(b.w); i++ {
+			b.w[i] &^= w[i]
+		}
+		b.trim()
+		return
+	}
+	b.Remove(t.Slice()...)
+}
+
+// Retain removes the items of b which are not in t.
+func (b *bitset) Retain(t collection.Interface) {
+	if conv, ok := t.(*bitsetSync); ok {
+		conv.l.RLock()
+		defer conv.l.RUnlock()
+	}
+	if w, ok := wordsOf(t); ok {
+		if len(w) < len(b.w) {
+			b.w = b.w[:len(w)]
+		}
+		for i := range b.w {
+			b.w[i] &= w[i]
+		}
+		b.trim()
+		return
+	}
+	b.Each(func(item interface{}) bool {
+		if !t.Has(item) {
+			b.remove(item.(int))
+		}
+		return true
+	})
+	b.trim()
+}
+
+// Union returns a new bitset holding the items of both b and t.
+func (b *bitset) Union(t Interface) Interface {
+	u := b.copy()
+	u.Merge(t)
+	return u
+}
+
+// Intersection returns a new bitset holding the items in both b and t.
+func (b *bitset) Intersection(t Interface) Interface {
+	u := b.copy()
+	u.Retain(t)
+	return u
+}
+
+// Difference returns a new bitset holding the items of b which are not in t.
+func (b *bitset) Difference(t Interface) Interface {
+	u := b.copy()
+	u.Separate(t)
+	return u
+}
+
+// String returns a string representation of b in ascending order
+func (b *bitset) String() string {
+	return format.String(b)
+}
+
+// Format implements fmt.Formatter: %v prints the items in ascending order,
+// %+v adds the type and the length and %#v the Go syntax. A precision
+// truncates the items: %.3v prints the 3 first followed by "...".
+func (b *bitset) Format(f fmt.State, verb rune) {
+	format.Seq(f, verb, "bitset", "bitset.New", b.Slice())
+}
+
+// Slice returns the items in ascending order.
+func (b *bitset) Slice() []interface{} {
+	items := make([]interface{}, 0, b.Len())
+	b.Each(func(item interface{}) bool {
+		items = append(items, item)
+		return true
+	})
+	return items
+}
+
+// Set returns a new map based set holding the items of b.
+func (b *bitset) Set() set.Interface {
+	return set.New(b.Slice()...)
+}
+
+func (b *bitset) copy() *bitset {
+	w := make([]uint64, len(b.w))
+	copy(w, b.w)
+	return &bitset{w}
+}
+
+func (b *bitset) CopyBitset() Interface {
+	return b.copy()
+}
+
+func (b *bitset) CopySet() set.Interface {
+	return b.copy()
+}
+
+func (b *bitset) CopyCollection() collection.Interface {
+	return b.copy()
+}
@@ -0,0 +1,211 @@
+package bitset
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/khezen/struct/collection"
+	"github.com/khezen/struct/internal/format"
+	"github.com/khezen/struct/set"
+)
+
+// bitsetSync defines a thread safe bitset.
+type bitsetSync struct {
+	bitset
+	l sync.RWMutex // we name it because we don't want to expose it
+}
+
+// NewSync creates a thread safe bitset. It panics with ErrBadItem if any item
+// is not a non-negative int.
+func NewSync(items ...int) Interface {
+	return &bitsetSync{
+		*New(items...).(*bitset),
+		sync.RWMutex{},
+	}
+}
+
+// self returns the unsynchronized bitset if t is b itself, whose lock is
+// already held by the caller.
+func (b *bitsetSync) self(t collection.ReadOnly) collection.ReadOnly {
+	if conv, ok := t.(*bitsetSync); ok && conv == b {
+		return &b.bitset
+	}
+	return t
+}
+
+func (b *bitsetSync) Add(items ...interface{}) {
+	if len(items) > 0 {
+		b.l.Lock()
+		defer b.l.Unlock()
+		b.bitset.Add(items...)
+	}
+}
+
+func (b *bitsetSync) Remove(items ...interface{}) {
+	if len(items) > 0 {
+		b.l.Lock()
+		defer b.l.Unlock()
+		b.bitset.Remove(items...)
+	}
+}
+
+func (b *bitsetSync) Pop() interface{} {
+	b.l.Lock()
+	defer b.l.Unlock()
+	return b.bitset.Pop()
+}
+
+func (b *bitsetSync) Replace(item, substitute interface{}) {
+	b.l.Lock()
+	defer b.l.Unlock()
+	b.bitset.Replace(item, substitute)
+}
+
+func (b *bitsetSync) Has(items ...interface{}) bool {
+	if len(items) > 0 {
+		b.l.RLock()
+		defer b.l.RUnlock()
+		return b.bitset.Has(items...)
+	}
+	return true
+}
+
+func (b *bitsetSync) Each(f func(item interface{}) bool) {
+	b.l.RLock()
+	defer b.l.RUnlock()
+	b.bitset.Each(f)
+}
+
+func (b *bitsetSync) Len() int {
+	b.l.RLock()
+	defer b.l.RUnlock()
+	return b.bitset.Len()
+}
+
+func (b *bitsetSync) PopCount() int {
+	b.l.RLock()
+	defer b.l.RUnlock()
+	return b.bitset.PopCount()
+}
+
+func (b *bitsetSync) Rank(i int) int {
+	b.l.RLock()
+	defer b.l.RUnlock()
+	return b.bitset.Rank(i)
+}
+
+func (b *bitsetSync) NextSet(i int) (int, bool) {
+	b.l.RLock()
+	defer b.l.RUnlock()
+	return b.bitset.NextSet(i)
+}
+
+func (b *bitsetSync) Clear() {
+	b.l.Lock()
+	defer b.l.Unlock()
+	b.bitset.Clear()
+}
+
+func (b *bitsetSync) IsEmpty() bool {
+	b.l.RLock()
+	defer b.l.RUnlock()
+	return b.bitset.IsEmpty()
+}
+
+func (b *bitsetSync) IsEqual(t collection.ReadOnly) bool {
+	b.l.RLock()
+	defer b.l.RUnlock()
+	return b.bitset.IsEqual(b.self(t))
+}
+
+func (b *bitsetSync) IsSubset(t set.ReadOnly) bool {
+	b.l.RLock()
+	defer b.l.RUnlock()
+	return b.bitset.IsSubset(b.self(t).(set.ReadOnly))
+}
+
+func (b *bitsetSync) IsSuperset(t set.ReadOnly) bool {
+	b.l.RLock()
+	defer b.l.RUnlock()
+	return b.bitset.IsSuperset(b.self(t).(set.ReadOnly))
+}
+
+func (b *bitsetSync) Merge(t collection.Interface) {
+	b.l.Lock()
+	defer b.l.Unlock()
+	b.bitset.Merge(b.self(t).(collection.Interface))
+}
+
+func (b *bitsetSync) Separate(t collection.Interface) {
+	b.l.Lock()
+	defer b.l.Unlock()
+	b.bitset.Separate(b.self(t).(collection.Interface))
+}
+
+func (b *bitsetSync) Retain(t collection.Interface) {
+	b.l.Lock()
+	defer b.l.Unlock()
+	b.bitset.Retain(b.self(t).(collection.Interface))
+}
+
+func (b *bitsetSync) Union(t Interface) Interface {
+	u := b.CopyBitset()
+	u.Merge(t)
+	return u
+}
+
+func (b *bitsetSync) Intersection(t Interface) Interface {
+	u := b.CopyBitset()
+	u.Retain(t)
+	return u
+}
+
+func (b *bitsetSync) Difference(t Interface) Interface {
+	u := b.CopyBitset()
+	u.Separate(t)
+	return u
+}
+
+func (b *bitsetSync) String() string {
+	return format.String(b)
+}
+
+func (b *bitsetSync) Format(f fmt.State, verb rune) {
+	format.Seq(f, verb, "bitset.Sync", "bitset.NewSync", b.Slice())
+}
+
+func (b *bitsetSync) Slice() []interface{} {
+	b.l.RLock()
+	defer b.l.RUnlock()
+	return b.bitset.Slice()
+}
+
+func (b *bitsetSync) Set() set.Interface {
+	b.l.RLock()
+	defer b.l.RUnlock()
+	return set.NewSync(b.bitset.Slice()...)
+}
+
+func (b *bitsetSync) CopyBitset() Interface {
+	b.l.RLock()
+	defer b.l.RUnlock()
+	return &bitsetSync{
+		*b.bitset.copy(),
+		sync.RWMutex{},
+	}
+}
+
+func (b *bitsetSync) CopySet() set.Interface {
+	return b.CopyBitset()
+}
+
+func (b *bitsetSync) CopyCollection() collection.Interface {
+	return b.CopyBitset()
+}
+
+// Snapshot returns a non-threadsafe copy of b consistent at the time of the call.
+func (b *bitsetSync) Snapshot() Interface {
+	b.l.RLock()
+	defer b.l.RUnlock()
+	return b.bitset.copy()
+}
@@ -0,0 +1,427 @@
+package bitset
+
+import (
+	"testing"
+
+	"github.com/khezen/struct/collection"
+	"github.com/khezen/struct/set"
+)
+
+func TestAdd(t *testing.T) {
+	cases := []struct {
+		b         Interface
+		toBeAdded []interface{}
+		expected  Interface
+	}{
+		{New(1, 4, 8), []interface{}{42, 1000}, New(1, 4, 8, 42, 1000)},
+		{New(), []interface{}{0, 63, 64}, New(0, 63, 64)},
+		{NewSync(1, 4, 8), []interface{}{42, 1000}, NewSync(1, 4, 8, 42, 1000)},
+	}
+	for _, c := range cases {
+		c.b.Add(c.toBeAdded...)
+		if !c.b.IsEqual(c.expected) {
+			t.Errorf("Expected %v. Got %v.", c.expected, c.b)
+		}
+	}
+}
+
+func TestAddPanics(t *testing.T) {
+	cases := []struct {
+		item interface{}
+	}{
+		{-1},
+		{"1"},
+		{int64(1)},
+	}
+	for _, c := range cases {
+		func() {
+			defer func() {
+				if r := recover(); r != ErrBadItem {
+					t.Errorf("Expected %v. Got %v.", ErrBadItem, r)
+				}
+			}()
+			New().Add(c.item)
+		}()
+	}
+}
+
+func TestRemove(t *testing.T) {
+	cases := []struct {
+		b           Interface
+		toBeRemoved []interface{}
+		expected    Interface
+	}{
+		{New(1, 4, 8), []interface{}{4, 42, "1", -1}, New(1, 8)},
+		{New(1, 1000), []interface{}{1000}, New(1)},
+		{NewSync(1, 4, 8), []interface{}{4, 42}, NewSync(1, 8)},
+	}
+	for _, c := range cases {
+		c.b.Remove(c.toBeRemoved...)
+		if !c.b.IsEqual(c.expected) {
+			t.Errorf("Expected %v. Got %v.", c.expected, c.b)
+		}
+	}
+}
+
+func TestPop(t *testing.T) {
+	cases := []struct {
+		b Interface
+	}{
+		{New(130, 2, 65)},
+		{NewSync(130, 2, 65)},
+	}
+	for _, c := range cases {
+		for _, expected := range []interface{}{2, 65, 130, nil} {
+			if item := c.b.Pop(); item != expected {
+				t.Errorf("Expected %v. Got %v.", expected, item)
+			}
+		}
+	}
+}
+
+func TestReplace(t *testing.T) {
+	cases := []struct {
+		b                Interface
+		item, substitute interface{}
+		expected         Interface
+	}{
+		{New(1, 4), 1, 200, New(4, 200)},
+		{New(1, 4), 2, 200, New(1, 4)},
+		{NewSync(1, 4), 1, 200, New(4, 200)},
+	}
+	for _, c := range cases {
+		c.b.Replace(c.item, c.substitute)
+		if !c.b.IsEqual(c.expected) {
+			t.Errorf("Expected %v. Got %v.", c.expected, c.b)
+		}
+	}
+}
+
+func TestHas(t *testing.T) {
+	cases := []struct {
+		b        Interface
+		items    []interface{}
+		expected bool
+	}{
+		{New(1, 42, 8), []interface{}{1, 42, 8}, true},
+		{New(1, 42, 8), []interface{}{2}, false},
+		{New(1, 42, 8), []interface{}{"1"}, false},
+		{New(1, 42, 8), []interface{}{10000}, false},
+		{New(1, 42, 8), []interface{}{}, true},
+		{NewSync(1, 42, 8), []interface{}{1, 42}, true},
+		{NewSync(1, 42, 8), []interface{}{-1}, false},
+		{NewSync(), []interface{}{}, true},
+	}
+	for _, c := range cases {
+		if has := c.b.Has(c.items...); has != c.expected {
+			t.Errorf("Expected %v. Got %v.", c.expected, has)
+		}
+	}
+}
+
+func TestEach(t *testing.T) {
+	cases := []struct {
+		b        Interface
+		expected []interface{}
+	}{
+		{New(200, 3, 64, 0), []interface{}{0, 3, 64}},
+		{NewSync(200, 3, 64, 0), []interface{}{0, 3, 64}},
+	}
+	for _, c := range cases {
+		items := make([]interface{}, 0)
+		c.b.Each(func(item interface{}) bool {
+			items = append(items, item)
+			return item.(int) < 64
+		})
+		if len(items) != len(c.expected) {
+			t.Fatalf("Expected %v. Got %v.", c.expected, items)
+		}
+		for i := range items {
+			if items[i] != c.expected[i] {
+				t.Errorf("Expected %v. Got %v.", c.expected, items)
+			}
+		}
+	}
+}
+
+func TestLen(t *testing.T) {
+	cases := []struct {
+		b   Interface
+		len int
+	}{
+		{New(), 0},
+		{New(1, 1, 1000), 2},
+		{NewSync(1, 64, 128), 3},
+	}
+	for _, c := range cases {
+		if c.b.Len() != c.len || c.b.PopCount() != c.len {
+			t.Errorf("Expected %v. Got %v.", c.len, c.b.Len())
+		}
+		if c.b.IsEmpty() != (c.len == 0) {
+			t.Errorf("Expected %v. Got %v.", c.len == 0, c.b.IsEmpty())
+		}
+		c.b.Clear()
+		if !c.b.IsEmpty() {
+			t.Error("Bitset should be empty")
+		}
+	}
+}
+
+func TestRank(t *testing.T) {
+	cases := []struct {
+		b        Interface
+		i        int
+		expected int
+	}{
+		{New(1, 5, 64, 200), 0, 0},
+		{New(1, 5, 64, 200), 1, 1},
+		{New(1, 5, 64, 200), 63, 2},
+		{New(1, 5, 64, 200), 64, 3},
+		{New(1, 5, 64, 200), 10000, 4},
+		{New(1, 5, 64, 200), -1, 0},
+		{NewSync(1, 5, 64, 200), 199, 3},
+	}
+	for _, c := range cases {
+		if rank := c.b.Rank(c.i); rank != c.expected {
+			t.Errorf("Rank(%v): expected %v. Got %v.", c.i, c.expected, rank)
+		}
+	}
+}
+
+func TestNextSet(t *testing.T) {
+	cases := []struct {
+		b        Interface
+		i        int
+		expected int
+		ok       bool
+	}{
+		{New(1, 5, 64, 200), -3, 1, true},
+		{New(1, 5, 64, 200), 2, 5, true},
+		{New(1, 5, 64, 200), 6, 64, true},
+		{New(1, 5, 64, 200), 65, 200, true},
+		{New(1, 5, 64, 200), 201, -1, false},
+		{New(1, 5, 64, 200), 10000, -1, false},
+		{NewSync(1, 5, 64, 200), 64, 64, true},
+	}
+	for _, c := range cases {
+		i, ok := c.b.NextSet(c.i)
+		if i != c.expected || ok != c.ok {
+			t.Errorf("NextSet(%v): expected %v, %v. Got %v, %v.", c.i, c.expected, c.ok, i, ok)
+		}
+	}
+}
+
+func TestIsEqual(t *testing.T) {
+	cases := []struct {
+		b        Interface
+		t        collection.Interface
+		expected bool
+	}{
+		{New(1, 42), New(1, 42), true},
+		{New(1, 42), New(1, 42, 1000), false},
+		{New(1, 42, 1000), New(1, 42), false},
+		{New(1, 42), NewSync(1, 42), true},
+		{New(1, 42), set.New(1, 42), true},
+		{New(1, 42), set.New(1, "42"), false},
+		{NewSync(1, 42), set.New(1, 42), true},
+	}
+	for _, c := range cases {
+		if equal := c.b.IsEqual(c.t); equal != c.expected {
+			t.Errorf("Expected %v to be equal to %v? %v. Got %v.", c.b, c.t, c.expected, equal)
+		}
+	}
+	b := NewSync(1)
+	if !b.IsEqual(b) {
+		t.Error("A bitset should be equal to itself")
+	}
+}
+
+func TestIsSubset(t *testing.T) {
+	cases := []struct {
+		b, sub            set.Interface
+		isSub, isSuperset bool
+	}{
+		{New(1, 2, 300), New(1, 300), true, false},
+		{New(1, 2), New(1, 300), false, false},
+		{New(1, 2), NewSync(1, 2, 300), false, true},
+		{NewSync(1, 2, 300), set.New(1, 2), true, false},
+		{NewSync(1, 2), set.New(1, 2, 3), false, true},
+	}
+	for _, c := range cases {
+		if ok := c.b.IsSubset(c.sub); ok != c.isSub {
+			t.Errorf("Expected %v. Got %v.", c.isSub, ok)
+		}
+		if ok := c.b.IsSuperset(c.sub); ok != c.isSuperset {
+			t.Errorf("Expected %v. Got %v.", c.isSuperset, ok)
+		}
+	}
+}
+
+func TestMergeSeparateRetain(t *testing.T) {
+	cases := []struct {
+		b, other                    func() collection.Interface
+		merged, separated, retained Interface
+	}{
+		{
+			func() collection.Interface { return New(1, 2, 300) },
+			func() collection.Interface { return New(2, 3, 1000) },
+			New(1, 2, 3, 300, 1000), New(1, 300), New(2),
+		},
+		{
+			func() collection.Interface { return NewSync(1, 2, 300) },
+			func() collection.Interface { return NewSync(2, 3) },
+			New(1, 2, 3, 300), New(1, 300), New(2),
+		},
+		{
+			func() collection.Interface { return New(1, 2, 300) },
+			func() collection.Interface { return set.New(2, 3, 1000) },
+			New(1, 2, 3, 300, 1000), New(1, 300), New(2),
+		},
+		{
+			func() collection.Interface { return NewSync(1, 2, 300) },
+			func() collection.Interface { return set.NewSync(2, 3) },
+			New(1, 2, 3, 300), New(1, 300), New(2),
+		},
+	}
+	for _, c := range cases {
+		b := c.b()
+		b.Merge(c.other())
+		if !b.IsEqual(c.merged) {
+			t.Errorf("Expected %v. Got %v.", c.merged, b)
+		}
+		b = c.b()
+		b.Separate(c.other())
+		if !b.IsEqual(c.separated) {
+			t.Errorf("Expected %v. Got %v.", c.separated, b)
+		}
+		b = c.b()
+		b.Retain(c.other())
+		if !b.IsEqual(c.retained) {
+			t.Errorf("Expected %v. Got %v.", c.retained, b)
+		}
+	}
+	b := NewSync(1, 2)
+	b.Merge(b)
+	b.Retain(b)
+	b.Separate(b)
+	if !b.IsEmpty() {
+		t.Errorf("Expected empty. Got %v.", b)
+	}
+}
+
+func TestUnionIntersectionDifference(t *testing.T) {
+	cases := []struct {
+		a, b                            Interface
+		union, intersection, difference Interface
+	}{
+		{New(1, 2, 300), New(2, 3, 1000), New(1, 2, 3, 300, 1000), New(2), New(1, 300)},
+		{NewSync(1, 2, 300), New(2, 3), New(1, 2, 3, 300), New(2), New(1, 300)},
+	}
+	for _, c := range cases {
+		if u := c.a.Union(c.b); !u.IsEqual(c.union) {
+			t.Errorf("Expected %v. Got %v.", c.union, u)
+		}
+		if i := c.a.Intersection(c.b); !i.IsEqual(c.intersection) {
+			t.Errorf("Expected %v. Got %v.", c.intersection, i)
+		}
+		if d := c.a.Difference(c.b); !d.IsEqual(c.difference) {
+			t.Errorf("Expected %v. Got %v.", c.difference, d)
+		}
+		if c.a.Len() != 3 {
+			t.Errorf("Operands should not be modified. Got %v.", c.a)
+		}
+	}
+}
+
+func TestCollectionFunctions(t *testing.T) {
+	cases := []struct {
+		bitsets                  []collection.Interface
+		union, intersection, dif Interface
+	}{
+		{[]collection.Interface{New(1, 42, 8), New(5, 42, 6)}, New(1, 5, 6, 8, 42), New(42), New(1, 8)},
+		{[]collection.Interface{NewSync(1, 42, 8), NewSync(5, 42, 6)}, New(1, 5, 6, 8, 42), New(42), New(1, 8)},
+	}
+	for _, c := range cases {
+		if u := collection.Union(c.bitsets...); !u.IsEqual(c.union) {
+			t.Errorf("Expected %v. Got %v.", c.union, u)
+		}
+		if i := collection.Intersection(c.bitsets...); !i.IsEqual(c.intersection) {
+			t.Errorf("Expected %v. Got %v.", c.intersection, i)
+		}
+		if d := collection.Difference(c.bitsets...); !d.IsEqual(c.dif) {
+			t.Errorf("Expected %v. Got %v.", c.dif, d)
+		}
+	}
+}
+
+func TestConversion(t *testing.T) {
+	cases := []struct {
+		s         collection.Interface
+		expectErr bool
+	}{
+		{set.New(1, 64, 3), false},
+		{set.New(1, -1), true},
+		{set.New(1, "a"), true},
+	}
+	for _, c := range cases {
+		b, err := FromSet(c.s)
+		if (err != nil) != c.expectErr {
+			t.Errorf("Error expected? %v. Got %v.", c.expectErr, err)
+		}
+		if err == nil && (!b.IsEqual(c.s) || !b.Set().IsEqual(c.s)) {
+			t.Errorf("Expected %v. Got %v.", c.s, b)
+		}
+	}
+	if !NewSync(1, 2).Set().IsEqual(set.New(1, 2)) {
+		t.Error("Expected [1 2]")
+	}
+}
+
+func TestString(t *testing.T) {
+	cases := []struct {
+		b        Interface
+		expected string
+	}{
+		{New(), "[]"},
+		{New(65, 3, 1), "[1 3 65]"},
+		{NewSync(65, 3, 1), "[1 3 65]"},
+	}
+	for _, c := range cases {
+		if str := c.b.String(); str != c.expected {
+			t.Errorf("Expected %v. Got %v.", c.expected, str)
+		}
+	}
+}
+
+func TestCopy(t *testing.T) {
+	cases := []struct {
+		b Interface
+	}{
+		{New(1, 42, 800)},
+		{NewSync(1, 42, 800)},
+	}
+	for _, c := range cases {
+		for _, cpy := range []collection.Interface{c.b.CopyBitset(), c.b.CopySet(), c.b.CopyCollection()} {
+			if !cpy.IsEqual(c.b) {
+				t.Errorf("Expected %v. Got %v.", c.b, cpy)
+			}
+			cpy.Add(3)
+			if c.b.Has(3) {
+				t.Error("Copy should not alter the original")
+			}
+		}
+		if len(c.b.Slice()) != 3 {
+			t.Errorf("Expected 3 items. Got %v.", c.b.Slice())
+		}
+	}
+}
+
+func TestSnapshot(t *testing.T) {
+	b := NewSync(1, 2)
+	snapshot := b.(interface{ Snapshot() Interface }).Snapshot()
+	b.Add(3)
+	snapshot.Add(4)
+	if _, ok := snapshot.(*bitset); !ok || !snapshot.IsEqual(New(1, 2, 4)) || !b.IsEqual(New(1, 2, 3)) {
+		t.Errorf("Expected %v. Got %v.", New(1, 2, 4), snapshot)
+	}
+}
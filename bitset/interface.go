@@ -0,0 +1,31 @@
+// Package bitset provides both threadsafe and non-threadsafe implementations of
+// a set of non-negative ints packed into 64 bit words. It implements
+// set.Interface while using one bit per possible item, which suits dense
+// integer identifiers. Mixing operations between bitsets run a word at a time.
+package bitset
+
+import (
+	"errors"
+
+	"github.com/khezen/struct/set"
+)
+
+// Interface describes a set of non-negative ints
+type Interface interface {
+	set.Interface
+	Union(Interface) Interface
+	Intersection(Interface) Interface
+	Difference(Interface) Interface
+	Rank(i int) int
+	NextSet(i int) (int, bool)
+	PopCount() int
+	Set() set.Interface
+	CopyBitset() Interface
+}
+
+var (
+	// ErrBadItem - bitsets only hold non-negative ints
+	ErrBadItem = errors.New("ErrBadItem - bitsets only hold non-negative ints")
+)
+
+const wordSize = 64
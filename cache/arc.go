@@ -0,0 +1,148 @@
+package cache
+
+import (
+	"container/list"
+)
+
+// arc implements the Adaptive Replacement Cache algorithm described by
+// Megiddo and Modha. t1 and t2 hold resident keys seen once and at least
+// twice; b1 and b2 are ghost lists remembering keys recently evicted from t1
+// and t2. p is the adaptive target size of t1.
+type arc struct {
+	capacity       int
+	p              int
+	t1, t2, b1, b2 *list.List
+	items          map[interface{}]*list.Element
+	lists          map[interface{}]*list.List
+}
+
+func newARC(capacity int) *arc {
+	return &arc{
+		capacity: capacity,
+		t1:       list.New(),
+		t2:       list.New(),
+		b1:       list.New(),
+		b2:       list.New(),
+		items:    make(map[interface{}]*list.Element),
+		lists:    make(map[interface{}]*list.List),
+	}
+}
+
+func (p *arc) touch(k interface{}) {
+	if l := p.lists[k]; l == p.t1 || l == p.t2 {
+		p.move(k, p.t2)
+	}
+}
+
+func (p *arc) admit(k interface{}) (victim interface{}, evicted bool) {
+	full := p.t1.Len()+p.t2.Len() >= p.capacity
+	switch p.lists[k] {
+	case p.b1:
+		p.p = minInt(p.capacity, p.p+maxInt(p.b2.Len()/p.b1.Len(), 1))
+		if full {
+			victim, evicted = p.replace(false)
+		}
+		p.move(k, p.t2)
+		return victim, evicted
+	case p.b2:
+		p.p = maxInt(0, p.p-maxInt(p.b1.Len()/p.b2.Len(), 1))
+		if full {
+			victim, evicted = p.replace(true)
+		}
+		p.move(k, p.t2)
+		return victim, evicted
+	}
+	if p.t1.Len()+p.b1.Len() >= p.capacity {
+		if p.t1.Len() < p.capacity {
+			p.drop(p.b1.Back().Value)
+			if full {
+				victim, evicted = p.replace(false)
+			}
+		} else {
+			victim, evicted = p.t1.Back().Value, true
+			p.drop(victim)
+		}
+	} else if total := p.t1.Len() + p.t2.Len() + p.b1.Len() + p.b2.Len(); total >= p.capacity {
+		if total >= 2*p.capacity {
+			p.drop(p.b2.Back().Value)
+		}
+		if full {
+			victim, evicted = p.replace(false)
+		}
+	}
+	p.lists[k] = p.t1
+	p.items[k] = p.t1.PushFront(k)
+	return victim, evicted
+}
+
+// replace evicts the LRU key of t1 or t2 into the matching ghost list.
+func (p *arc) replace(inB2 bool) (interface{}, bool) {
+	t1 := p.t1.Len()
+	if t1 > 0 && (t1 > p.p || (inB2 && t1 == p.p) || p.t2.Len() == 0) {
+		victim := p.t1.Back().Value
+		p.move(victim, p.b1)
+		return victim, true
+	}
+	if p.t2.Len() > 0 {
+		victim := p.t2.Back().Value
+		p.move(victim, p.b2)
+		return victim, true
+	}
+	return nil, false
+}
+
+func (p *arc) remove(k interface{}) {
+	if l := p.lists[k]; l == p.t1 || l == p.t2 {
+		p.drop(k)
+	}
+}
+
+func (p *arc) keys() []interface{} {
+	keys := make([]interface{}, 0, p.t1.Len()+p.t2.Len())
+	for _, l := range []*list.List{p.t1, p.t2} {
+		for e := l.Back(); e != nil; e = e.Prev() {
+			keys = append(keys, e.Value)
+		}
+	}
+	return keys
+}
+
+func (p *arc) clear() {
+	p.p = 0
+	p.t1.Init()
+	p.t2.Init()
+	p.b1.Init()
+	p.b2.Init()
+	p.items = make(map[interface{}]*list.Element)
+	p.lists = make(map[interface{}]*list.List)
+}
+
+// move places k at the MRU end of l.
+func (p *arc) move(k interface{}, l *list.List) {
+	p.drop(k)
+	p.lists[k] = l
+	p.items[k] = l.PushFront(k)
+}
+
+// drop forgets k whichever list holds it.
+func (p *arc) drop(k interface{}) {
+	if l, ok := p.lists[k]; ok {
+		l.Remove(p.items[k])
+		delete(p.items, k)
+		delete(p.lists, k)
+	}
+}
+
+func minInt(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
+
+func maxInt(a, b int) int {
+	if a > b {
+		return a
+	}
+	return b
+}
@@ -0,0 +1,368 @@
+package cache
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/khezen/struct/hashmap"
+	"github.com/khezen/struct/internal/format"
+)
+
+type entry struct {
+	v       interface{}
+	expires time.Time
+}
+
+type eviction struct {
+	k, v   interface{}
+	reason Reason
+}
+
+type cache struct {
+	m        map[interface{}]*entry
+	kind     Policy
+	policy   policy
+	capacity int
+	ttls     int // number of entries with an expiration date
+	stats    Stats
+	onEvict  func(k, v interface{}, reason Reason)
+	pending  []eviction
+	now      func() time.Time
+}
+
+// New creates a cache holding at most capacity entries
+func New(p Policy, capacity int) Interface {
+	if capacity <= 0 {
+		panic(ErrBadCapacity)
+	}
+	return &cache{
+		m:        make(map[interface{}]*entry),
+		kind:     p,
+		policy:   newPolicy(p, capacity),
+		capacity: capacity,
+		now:      time.Now,
+	}
+}
+
+func (c *cache) Get(k interface{}) (interface{}, error) {
+	v, err := c.get(k)
+	c.flush()
+	return v, err
+}
+
+func (c *cache) Peek(k interface{}) (interface{}, error) {
+	e, ok := c.m[k]
+	if !ok || c.expired(e) {
+		return nil, fmt.Errorf("%v not found", k)
+	}
+	return e.v, nil
+}
+
+func (c *cache) Put(k, v interface{}) {
+	c.put(k, v, 0)
+	c.flush()
+}
+
+// PutWithTTL inserts or replaces k. The entry expires once ttl has elapsed.
+// A ttl lower than or equal to zero means the entry never expires.
+func (c *cache) PutWithTTL(k, v interface{}, ttl time.Duration) {
+	c.put(k, v, ttl)
+	c.flush()
+}
+
+// GetOrLoad returns the value of k, calling loader and caching its result on
+// a miss. Errors returned by loader are not cached.
+func (c *cache) GetOrLoad(k interface{}, loader func(k interface{}) (interface{}, error)) (interface{}, error) {
+	defer c.flush()
+	if v, err := c.get(k); err == nil {
+		return v, nil
+	}
+	v, err := loader(k)
+	if err != nil {
+		return nil, err
+	}
+	c.put(k, v, 0)
+	return v, nil
+}
+
+func (c *cache) Remove(keys ...interface{}) {
+	c.remove(keys...)
+	c.flush()
+}
+
+func (c *cache) Has(keys ...interface{}) bool {
+	for _, k := range keys {
+		if e, ok := c.m[k]; !ok || c.expired(e) {
+			return false
+		}
+	}
+	return true
+}
+
+func (c *cache) HasValue(values ...interface{}) bool {
+	for _, value := range values {
+		if _, err := c.KeyOf(value); err != nil {
+			return false
+		}
+	}
+	return true
+}
+
+func (c *cache) KeyOf(value interface{}) (interface{}, error) {
+	for k, e := range c.m {
+		if e.v == value && !c.expired(e) {
+			return k, nil
+		}
+	}
+	return nil, fmt.Errorf("%v not found", value)
+}
+
+// Each traverses live entries without affecting their recency or frequency.
+func (c *cache) Each(f func(k, v interface{}) bool) {
+	for k, e := range c.m {
+		if c.expired(e) {
+			continue
+		}
+		if !f(k, e.v) {
+			break
+		}
+	}
+}
+
+// Len returns the number of live entries.
+func (c *cache) Len() int {
+	if c.ttls == 0 {
+		return len(c.m)
+	}
+	length := 0
+	for _, e := range c.m {
+		if !c.expired(e) {
+			length++
+		}
+	}
+	return length
+}
+
+func (c *cache) Clear() {
+	c.clear()
+	c.flush()
+}
+
+func (c *cache) IsEmpty() bool {
+	return c.Len() == 0
+}
+
+func (c *cache) IsEqual(t hashmap.ReadOnly) bool {
+	// Force locking only if given cache is threadsafe.
+	if conv, ok := t.(*cacheSync); ok {
+		conv.l.RLock()
+		defer conv.l.RUnlock()
+	}
+	if c.Len() != t.Len() {
+		return false
+	}
+	equal := true
+	t.Each(func(k, v interface{}) bool {
+		value, err := c.Peek(k)
+		equal = equal && err == nil && value == v
+		return equal
+	})
+	return equal
+}
+
+// String returns a string representation of the live entries, sorted by keys
+// when they are orderable
+func (c *cache) String() string {
+	return format.String(c)
+}
+
+// Format implements fmt.Formatter: %v prints the live pairs, sorted by keys
+// when they are orderable, %+v adds the type and the length and %#v the Go
+// syntax of an equal hashmap. A precision truncates the pairs: %.3v prints the
+// 3 first followed by "...".
+func (c *cache) Format(f fmt.State, verb rune) {
+	format.Pairs(f, verb, "cache", "hashmap.New", c.Each)
+}
+
+func (c *cache) Keys() []interface{} {
+	keys := make([]interface{}, 0, len(c.m))
+	c.Each(func(k, v interface{}) bool {
+		keys = append(keys, k)
+		return true
+	})
+	return keys
+}
+
+func (c *cache) Values() []interface{} {
+	values := make([]interface{}, 0, len(c.m))
+	c.Each(func(k, v interface{}) bool {
+		values = append(values, v)
+		return true
+	})
+	return values
+}
+
+// Map returns a copy of the live entries.
+func (c *cache) Map() map[interface{}]interface{} {
+	m := make(map[interface{}]interface{}, len(c.m))
+	c.Each(func(k, v interface{}) bool {
+		m[k] = v
+		return true
+	})
+	return m
+}
+
+// Copy returns a new cache with the same policy, capacity and live entries.
+// Statistics and the eviction callback are not copied.
+func (c *cache) Copy() hashmap.Interface {
+	return c.copy()
+}
+
+func (c *cache) copy() *cache {
+	cpy := New(c.kind, c.capacity).(*cache)
+	cpy.now = c.now
+	for _, k := range c.policy.keys() {
+		e := c.m[k]
+		if c.expired(e) {
+			continue
+		}
+		cpy.m[k] = &entry{e.v, e.expires}
+		if !e.expires.IsZero() {
+			cpy.ttls++
+		}
+		cpy.policy.admit(k)
+	}
+	return cpy
+}
+
+// OnEvict registers a callback called each time an entry leaves the cache.
+func (c *cache) OnEvict(f func(k, v interface{}, reason Reason)) {
+	c.onEvict = f
+}
+
+func (c *cache) Stats() Stats {
+	return c.stats
+}
+
+func (c *cache) Cap() int {
+	return c.capacity
+}
+
+func (c *cache) get(k interface{}) (interface{}, error) {
+	e, ok := c.m[k]
+	if ok && c.expired(e) {
+		c.delete(k, e, Expired)
+		ok = false
+	}
+	if !ok {
+		c.stats.Misses++
+		return nil, fmt.Errorf("%v not found", k)
+	}
+	c.stats.Hits++
+	c.policy.touch(k)
+	return e.v, nil
+}
+
+func (c *cache) put(k, v interface{}, ttl time.Duration) {
+	var expires time.Time
+	if ttl > 0 {
+		expires = c.now().Add(ttl)
+	}
+	if e, ok := c.m[k]; ok {
+		if e.expires.IsZero() != expires.IsZero() {
+			if expires.IsZero() {
+				c.ttls--
+			} else {
+				c.ttls++
+			}
+		}
+		e.v, e.expires = v, expires
+		c.policy.touch(k)
+		return
+	}
+	if len(c.m) >= c.capacity {
+		c.purge()
+	}
+	if victim, evicted := c.policy.admit(k); evicted {
+		if e, ok := c.m[victim]; ok {
+			c.forget(victim, e)
+			c.stats.Evictions++
+			c.pending = append(c.pending, eviction{victim, e.v, Evicted})
+		}
+	}
+	c.m[k] = &entry{v, expires}
+	if !expires.IsZero() {
+		c.ttls++
+	}
+}
+
+func (c *cache) remove(keys ...interface{}) {
+	for _, k := range keys {
+		if e, ok := c.m[k]; ok {
+			c.delete(k, e, Removed)
+		}
+	}
+}
+
+func (c *cache) clear() {
+	for k, e := range c.m {
+		c.pending = append(c.pending, eviction{k, e.v, Removed})
+	}
+	c.m = make(map[interface{}]*entry)
+	c.policy.clear()
+	c.ttls = 0
+}
+
+// purge removes every expired entry.
+func (c *cache) purge() {
+	if c.ttls == 0 {
+		return
+	}
+	for k, e := range c.m {
+		if c.expired(e) {
+			c.delete(k, e, Expired)
+		}
+	}
+}
+
+func (c *cache) delete(k interface{}, e *entry, reason Reason) {
+	c.forget(k, e)
+	c.policy.remove(k)
+	if reason == Expired {
+		c.stats.Expirations++
+	}
+	c.pending = append(c.pending, eviction{k, e.v, reason})
+}
+
+func (c *cache) forget(k interface{}, e *entry) {
+	delete(c.m, k)
+	if !e.expires.IsZero() {
+		c.ttls--
+	}
+}
+
+func (c *cache) expired(e *entry) bool {
+	return !e.expires.IsZero() && !c.now().Before(e.expires)
+}
+
+// drain returns evictions awaiting notification.
+func (c *cache) drain() []eviction {
+	pending := c.pending
+	c.pending = nil
+	return pending
+}
+
+func (c *cache) flush() {
+	notify(c.onEvict, c.drain())
+}
+
+// notify calls the eviction callback. Callers must not hold any lock so the
+// callback may use the cache.
+func notify(f func(k, v interface{}, reason Reason), evictions []eviction) {
+	if f == nil {
+		return
+	}
+	for _, e := range evictions {
+		f(e.k, e.v, e.reason)
+	}
+}
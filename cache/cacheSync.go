@@ -0,0 +1,209 @@
+package cache
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/khezen/struct/hashmap"
+	"github.com/khezen/struct/internal/format"
+)
+
+// call is an in-flight or completed GetOrLoad call
+type call struct {
+	wg  sync.WaitGroup
+	v   interface{}
+	err error
+}
+
+// testHookWait is called by GetOrLoad before waiting for an in-flight call
+var testHookWait = func() {}
+
+type cacheSync struct {
+	cache
+	l        sync.RWMutex
+	inflight map[interface{}]*call
+}
+
+// NewSync creates a thread safe cache holding at most capacity entries.
+// Concurrent GetOrLoad calls for the same key share a single loader call.
+func NewSync(p Policy, capacity int) Interface {
+	return &cacheSync{
+		*New(p, capacity).(*cache),
+		sync.RWMutex{},
+		make(map[interface{}]*call),
+	}
+}
+
+// unlock releases the write lock, then runs the eviction callback.
+func (c *cacheSync) unlock() {
+	pending, f := c.cache.drain(), c.cache.onEvict
+	c.l.Unlock()
+	notify(f, pending)
+}
+
+// Get takes the write lock since a hit updates recency or frequency.
+func (c *cacheSync) Get(k interface{}) (interface{}, error) {
+	c.l.Lock()
+	defer c.unlock()
+	return c.cache.get(k)
+}
+
+func (c *cacheSync) Peek(k interface{}) (interface{}, error) {
+	c.l.RLock()
+	defer c.l.RUnlock()
+	return c.cache.Peek(k)
+}
+
+func (c *cacheSync) Put(k, v interface{}) {
+	c.l.Lock()
+	defer c.unlock()
+	c.cache.put(k, v, 0)
+}
+
+func (c *cacheSync) PutWithTTL(k, v interface{}, ttl time.Duration) {
+	c.l.Lock()
+	defer c.unlock()
+	c.cache.put(k, v, ttl)
+}
+
+func (c *cacheSync) GetOrLoad(k interface{}, loader func(k interface{}) (interface{}, error)) (interface{}, error) {
+	c.l.Lock()
+	if v, err := c.cache.get(k); err == nil {
+		c.unlock()
+		return v, nil
+	}
+	if cl, ok := c.inflight[k]; ok {
+		c.unlock()
+		testHookWait()
+		cl.wg.Wait()
+		return cl.v, cl.err
+	}
+	cl := &call{err: ErrLoaderPanic}
+	cl.wg.Add(1)
+	c.inflight[k] = cl
+	c.unlock()
+
+	// released even if loader panics, its waiters getting ErrLoaderPanic
+	defer func() {
+		c.l.Lock()
+		delete(c.inflight, k)
+		if cl.err == nil {
+			c.cache.put(k, cl.v, 0)
+		}
+		c.unlock()
+		cl.wg.Done()
+	}()
+	cl.v, cl.err = loader(k)
+	return cl.v, cl.err
+}
+
+func (c *cacheSync) Remove(keys ...interface{}) {
+	c.l.Lock()
+	defer c.unlock()
+	c.cache.remove(keys...)
+}
+
+func (c *cacheSync) Has(keys ...interface{}) bool {
+	c.l.RLock()
+	defer c.l.RUnlock()
+	return c.cache.Has(keys...)
+}
+
+func (c *cacheSync) HasValue(values ...interface{}) bool {
+	c.l.RLock()
+	defer c.l.RUnlock()
+	return c.cache.HasValue(values...)
+}
+
+func (c *cacheSync) KeyOf(value interface{}) (interface{}, error) {
+	c.l.RLock()
+	defer c.l.RUnlock()
+	return c.cache.KeyOf(value)
+}
+
+func (c *cacheSync) Each(f func(k, v interface{}) bool) {
+	c.l.RLock()
+	defer c.l.RUnlock()
+	c.cache.Each(f)
+}
+
+func (c *cacheSync) Len() int {
+	c.l.RLock()
+	defer c.l.RUnlock()
+	return c.cache.Len()
+}
+
+func (c *cacheSync) Clear() {
+	c.l.Lock()
+	defer c.unlock()
+	c.cache.clear()
+}
+
+func (c *cacheSync) IsEmpty() bool {
+	c.l.RLock()
+	defer c.l.RUnlock()
+	return c.cache.IsEmpty()
+}
+
+func (c *cacheSync) IsEqual(t hashmap.ReadOnly) bool {
+	c.l.RLock()
+	defer c.l.RUnlock()
+	return c.cache.IsEqual(t)
+}
+
+func (c *cacheSync) String() string {
+	return format.String(c)
+}
+
+func (c *cacheSync) Format(f fmt.State, verb rune) {
+	format.Pairs(f, verb, "cache.Sync", "hashmap.NewSync", c.Each)
+}
+
+func (c *cacheSync) Keys() []interface{} {
+	c.l.RLock()
+	defer c.l.RUnlock()
+	return c.cache.Keys()
+}
+
+func (c *cacheSync) Values() []interface{} {
+	c.l.RLock()
+	defer c.l.RUnlock()
+	return c.cache.Values()
+}
+
+func (c *cacheSync) Map() map[interface{}]interface{} {
+	c.l.RLock()
+	defer c.l.RUnlock()
+	return c.cache.Map()
+}
+
+func (c *cacheSync) Copy() hashmap.Interface {
+	c.l.RLock()
+	defer c.l.RUnlock()
+	return &cacheSync{
+		*c.cache.copy(),
+		sync.RWMutex{},
+		make(map[interface{}]*call),
+	}
+}
+
+func (c *cacheSync) OnEvict(f func(k, v interface{}, reason Reason)) {
+	c.l.Lock()
+	defer c.l.Unlock()
+	c.cache.OnEvict(f)
+}
+
+func (c *cacheSync) Stats() Stats {
+	c.l.RLock()
+	defer c.l.RUnlock()
+	return c.cache.Stats()
+}
+
+// Snapshot returns a non-threadsafe copy of c consistent at the time of the call.
+// Statistics and the eviction callback are not copied.
+func (c *cacheSync) Snapshot() Interface {
+	c.l.RLock()
+	defer c.l.RUnlock()
+	return c.cache.copy()
+}
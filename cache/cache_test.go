@@ -0,0 +1,469 @@
+package cache
+
+import (
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/khezen/struct/hashmap"
+)
+
+func testErr(err error, expectErr bool, t *testing.T) {
+	if (expectErr && err == nil) || (!expectErr && err != nil) {
+		t.Errorf(" Error expected? %v. Got: %v.", expectErr, err)
+	}
+}
+
+// clock is a manual clock for deterministic TTL tests
+type clock struct {
+	t time.Time
+}
+
+func (c *clock) now() time.Time {
+	return c.t
+}
+
+func withClock(c Interface, clk *clock) Interface {
+	switch conv := c.(type) {
+	case *cache:
+		conv.now = clk.now
+	case *cacheSync:
+		conv.now = clk.now
+	}
+	return c
+}
+
+func TestHashmapInterface(t *testing.T) {
+	var _ hashmap.Interface = New(LRU, 1)
+	var _ hashmap.Interface = NewSync(LRU, 1)
+}
+
+func TestNewPanics(t *testing.T) {
+	cases := []struct {
+		p        Policy
+		capacity int
+		expected error
+	}{
+		{LRU, 0, ErrBadCapacity},
+		{Policy(42), 1, ErrUnknownPolicy},
+	}
+	for _, c := range cases {
+		func() {
+			defer func() {
+				if r := recover(); r != c.expected {
+					t.Errorf("Expected %v. Got %v.", c.expected, r)
+				}
+			}()
+			New(c.p, c.capacity)
+		}()
+	}
+}
+
+func TestGetPut(t *testing.T) {
+	cases := []struct {
+		c         Interface
+		key       interface{}
+		expected  interface{}
+		expectErr bool
+	}{
+		{New(LRU, 2), "1", 1, false},
+		{New(LFU, 2), "1", 1, false},
+		{New(ARC, 2), "1", 1, false},
+		{NewSync(LRU, 2), "1", 1, false},
+		{NewSync(ARC, 2), "1000", nil, true},
+	}
+	for _, c := range cases {
+		c.c.Put("1", 1)
+		v, err := c.c.Get(c.key)
+		testErr(err, c.expectErr, t)
+		if v != c.expected {
+			t.Errorf("Expected %v. Got %v.", c.expected, v)
+		}
+	}
+}
+
+func TestEviction(t *testing.T) {
+	cases := []struct {
+		c       Interface
+		ops     []interface{} // strings are gets, ints are puts
+		evicted []interface{}
+	}{
+		{New(LRU, 2), []interface{}{1, 2, "1", 3}, []interface{}{2}},
+		{New(LRU, 2), []interface{}{1, 2, 3, 4}, []interface{}{1, 2}},
+		{New(LFU, 2), []interface{}{1, 2, "1", "1", "2", 3}, []interface{}{2}},
+		{New(LFU, 2), []interface{}{1, 2, "2", 3, 4}, []interface{}{1, 3}},
+		{New(ARC, 2), []interface{}{1, 2, "1", 3}, []interface{}{2}},
+		{New(ARC, 2), []interface{}{1, "1", 2, 3, 4}, []interface{}{2, 3}},
+		{NewSync(LRU, 2), []interface{}{1, 2, "1", 3}, []interface{}{2}},
+		{NewSync(LFU, 2), []interface{}{1, 2, "1", "1", "2", 3}, []interface{}{2}},
+		{NewSync(ARC, 2), []interface{}{1, 2, "1", 3}, []interface{}{2}},
+	}
+	for _, c := range cases {
+		evicted := make([]interface{}, 0)
+		c.c.OnEvict(func(k, v interface{}, reason Reason) {
+			if reason != Evicted {
+				t.Errorf("Expected %v. Got %v.", Evicted, reason)
+			}
+			evicted = append(evicted, k)
+		})
+		for _, op := range c.ops {
+			switch op := op.(type) {
+			case int:
+				c.c.Put(op, op)
+			case string:
+				c.c.Get(int(op[0] - '0'))
+			}
+		}
+		if len(evicted) != len(c.evicted) {
+			t.Fatalf("Expected %v. Got %v.", c.evicted, evicted)
+		}
+		for i := range evicted {
+			if evicted[i] != c.evicted[i] {
+				t.Errorf("Expected %v. Got %v.", c.evicted, evicted)
+			}
+		}
+		if c.c.Len() > c.c.Cap() {
+			t.Errorf("Expected at most %v entries. Got %v.", c.c.Cap(), c.c.Len())
+		}
+		if c.c.Stats().Evictions != uint64(len(c.evicted)) {
+			t.Errorf("Expected %v. Got %v.", len(c.evicted), c.c.Stats().Evictions)
+		}
+	}
+}
+
+func TestARCScanResistance(t *testing.T) {
+	c := New(ARC, 4)
+	for _, k := range []int{1, 2} {
+		c.Put(k, k)
+		c.Get(k)
+	}
+	for k := 100; k < 200; k++ {
+		c.Put(k, k)
+	}
+	if !c.Has(1, 2) {
+		t.Errorf("Frequently used keys should survive a scan. Got %v.", c.Keys())
+	}
+	if c.Len() != 4 {
+		t.Errorf("Expected 4. Got %v.", c.Len())
+	}
+}
+
+func TestTTL(t *testing.T) {
+	cases := []struct {
+		c Interface
+	}{
+		{New(LRU, 4)},
+		{New(LFU, 4)},
+		{New(ARC, 4)},
+		{NewSync(LRU, 4)},
+	}
+	for _, c := range cases {
+		clk := &clock{time.Unix(0, 0)}
+		withClock(c.c, clk)
+		reasons := make(map[interface{}]Reason)
+		c.c.OnEvict(func(k, v interface{}, reason Reason) {
+			reasons[k] = reason
+		})
+		c.c.PutWithTTL("1", 1, time.Second)
+		c.c.Put("2", 2)
+		if c.c.Len() != 2 || !c.c.Has("1") {
+			t.Errorf("Expected 2 live entries. Got %v.", c.c)
+		}
+		clk.t = clk.t.Add(time.Second)
+		if c.c.Len() != 1 || c.c.Has("1") || c.c.HasValue(1) {
+			t.Errorf("Expected 1 live entry. Got %v.", c.c)
+		}
+		if _, err := c.c.Peek("1"); err == nil {
+			t.Error("Peek should not return expired entries")
+		}
+		_, err := c.c.Get("1")
+		testErr(err, true, t)
+		if reasons["1"] != Expired {
+			t.Errorf("Expected %v. Got %v.", Expired, reasons["1"])
+		}
+		if c.c.Stats().Expirations != 1 {
+			t.Errorf("Expected 1. Got %v.", c.c.Stats().Expirations)
+		}
+	}
+}
+
+func TestTTLPurgeBeforeEviction(t *testing.T) {
+	clk := &clock{time.Unix(0, 0)}
+	c := withClock(New(LRU, 2), clk)
+	c.Put("1", 1)
+	c.PutWithTTL("2", 2, time.Second)
+	clk.t = clk.t.Add(time.Second)
+	c.Put("3", 3)
+	if !c.Has("1", "3") {
+		t.Errorf("Expired entries should be purged before live ones are evicted. Got %v.", c)
+	}
+	if c.Stats().Evictions != 0 {
+		t.Errorf("Expected 0. Got %v.", c.Stats().Evictions)
+	}
+}
+
+func TestStats(t *testing.T) {
+	cases := []struct {
+		c Interface
+	}{
+		{New(LRU, 2)},
+		{NewSync(LFU, 2)},
+	}
+	for _, c := range cases {
+		c.c.Put("1", 1)
+		c.c.Get("1")
+		c.c.Get("1")
+		c.c.Get("2")
+		s := c.c.Stats()
+		if s.Hits != 2 || s.Misses != 1 {
+			t.Errorf("Expected 2 hits and 1 miss. Got %+v.", s)
+		}
+		if ratio := s.HitRatio(); ratio < 0.66 || ratio > 0.67 {
+			t.Errorf("Expected 0.66. Got %v.", ratio)
+		}
+		c.c.Peek("2")
+		if c.c.Stats() != s {
+			t.Error("Peek should not alter stats")
+		}
+	}
+	if (Stats{}).HitRatio() != 0 {
+		t.Error("Expected 0")
+	}
+}
+
+func TestGetOrLoad(t *testing.T) {
+	cases := []struct {
+		c Interface
+	}{
+		{New(LRU, 2)},
+		{NewSync(ARC, 2)},
+	}
+	errLoad := errors.New("load")
+	for _, c := range cases {
+		calls := 0
+		loader := func(k interface{}) (interface{}, error) {
+			calls++
+			if k == "bad" {
+				return nil, errLoad
+			}
+			return 42, nil
+		}
+		for i := 0; i < 3; i++ {
+			v, err := c.c.GetOrLoad("1", loader)
+			testErr(err, false, t)
+			if v != 42 {
+				t.Errorf("Expected 42. Got %v.", v)
+			}
+		}
+		if calls != 1 {
+			t.Errorf("Expected 1. Got %v.", calls)
+		}
+		if _, err := c.c.GetOrLoad("bad", loader); err != errLoad {
+			t.Errorf("Expected %v. Got %v.", errLoad, err)
+		}
+		if c.c.Has("bad") {
+			t.Error("Errors should not be cached")
+		}
+	}
+}
+
+// waiting reports each GetOrLoad call about to wait for an in-flight call,
+// until the returned function is called
+func waiting() (<-chan struct{}, func()) {
+	ch := make(chan struct{}, 16)
+	testHookWait = func() {
+		ch <- struct{}{}
+	}
+	return ch, func() {
+		testHookWait = func() {}
+	}
+}
+
+func TestGetOrLoadSingleFlight(t *testing.T) {
+	c := NewSync(LRU, 8)
+	var calls int32
+	started, release := make(chan struct{}), make(chan struct{})
+	loader := func(k interface{}) (interface{}, error) {
+		atomic.AddInt32(&calls, 1)
+		close(started)
+		<-release
+		return k, nil
+	}
+	waiters, reset := waiting()
+	defer reset()
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		c.GetOrLoad("k", loader)
+	}()
+	<-started
+	for i := 0; i < 15; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			v, err := c.GetOrLoad("k", loader)
+			if err != nil || v != "k" {
+				t.Errorf("Expected k. Got %v, %v.", v, err)
+			}
+		}()
+	}
+	for i := 0; i < 15; i++ {
+		<-waiters
+	}
+	close(release)
+	wg.Wait()
+	if calls != 1 {
+		t.Errorf("Expected 1. Got %v.", calls)
+	}
+}
+
+func TestGetOrLoadPanic(t *testing.T) {
+	c := NewSync(LRU, 8)
+	started, release := make(chan struct{}), make(chan struct{})
+	loader := func(k interface{}) (interface{}, error) {
+		close(started)
+		<-release
+		panic("load")
+	}
+	waiters, reset := waiting()
+	defer reset()
+	go func() {
+		defer func() {
+			recover()
+		}()
+		c.GetOrLoad("k", loader)
+	}()
+	<-started
+	done := make(chan error)
+	go func() {
+		_, err := c.GetOrLoad("k", func(k interface{}) (interface{}, error) {
+			return k, nil
+		})
+		done <- err
+	}()
+	<-waiters
+	close(release)
+	select {
+	case err := <-done:
+		if err != ErrLoaderPanic {
+			t.Errorf("Expected %v. Got %v.", ErrLoaderPanic, err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Expected waiters to be released")
+	}
+	v, err := c.GetOrLoad("k", func(k interface{}) (interface{}, error) {
+		return 42, nil
+	})
+	if err != nil || v != 42 {
+		t.Errorf("Expected %v. Got %v, %v.", 42, v, err)
+	}
+}
+
+func TestConcurrency(t *testing.T) {
+	c := NewSync(LFU, 16)
+	c.OnEvict(func(k, v interface{}, reason Reason) {
+		c.Has(k) // callbacks may use the cache
+	})
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			for j := 0; j < 200; j++ {
+				c.Put(j%32, i)
+				c.Get((j + i) % 32)
+				c.Len()
+			}
+		}(i)
+	}
+	wg.Wait()
+	if c.Len() != 16 {
+		t.Errorf("Expected 16. Got %v.", c.Len())
+	}
+}
+
+func TestRemoveClear(t *testing.T) {
+	cases := []struct {
+		c Interface
+	}{
+		{New(LRU, 4)},
+		{New(LFU, 4)},
+		{New(ARC, 4)},
+		{NewSync(ARC, 4)},
+	}
+	for _, c := range cases {
+		removed := 0
+		c.c.OnEvict(func(k, v interface{}, reason Reason) {
+			if reason == Removed {
+				removed++
+			}
+		})
+		c.c.Put("1", 1)
+		c.c.Put("2", 2)
+		c.c.Put("3", 3)
+		c.c.Remove("1", "1000")
+		if c.c.Has("1") || c.c.Len() != 2 {
+			t.Errorf("Expected 2 entries. Got %v.", c.c)
+		}
+		c.c.Put("4", 4)
+		c.c.Put("5", 5)
+		if c.c.Len() != 4 {
+			t.Errorf("Removal should free room. Got %v.", c.c)
+		}
+		c.c.Clear()
+		if !c.c.IsEmpty() || removed != 5 {
+			t.Errorf("Expected 5 removals. Got %v.", removed)
+		}
+	}
+}
+
+func TestMapMethods(t *testing.T) {
+	cases := []struct {
+		c Interface
+	}{
+		{New(LRU, 4)},
+		{NewSync(LFU, 4)},
+	}
+	for _, c := range cases {
+		c.c.Put("1", 1)
+		c.c.Put("42", 42)
+		expected := hashmap.New("1", 1, "42", 42)
+		if !c.c.IsEqual(expected) {
+			t.Errorf("Expected %v. Got %v.", expected, c.c)
+		}
+		if len(c.c.Keys()) != 2 || len(c.c.Values()) != 2 || len(c.c.Map()) != 2 {
+			t.Errorf("Expected 2 entries. Got %v.", c.c)
+		}
+		if k, err := c.c.KeyOf(42); err != nil || k != "42" {
+			t.Errorf("Expected 42. Got %v.", k)
+		}
+		if _, err := c.c.KeyOf(1000); err == nil {
+			t.Error("Expected an error")
+		}
+		cpy := c.c.Copy()
+		if !cpy.IsEqual(c.c) {
+			t.Errorf("Expected %v. Got %v.", c.c, cpy)
+		}
+		cpy.Put("1", 2)
+		if v, _ := c.c.Peek("1"); v != 1 {
+			t.Error("Copy should not alter the original")
+		}
+		if c.c.String() != "map[1:1 42:42]" {
+			t.Errorf("Expected map[1:1 42:42]. Got %v.", c.c.String())
+		}
+	}
+}
+
+func TestSnapshot(t *testing.T) {
+	c := NewSync(LRU, 2)
+	c.Put("a", 1)
+	snapshot := c.(interface{ Snapshot() Interface }).Snapshot()
+	c.Put("b", 2)
+	snapshot.Put("c", 3)
+	if _, ok := snapshot.(*cache); !ok || snapshot.Has("b") || !snapshot.Has("a", "c") || c.Has("c") {
+		t.Errorf("Expected %v. Got %v.", "map[a:1 c:3]", snapshot)
+	}
+}
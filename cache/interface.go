@@ -0,0 +1,100 @@
+// Package cache provides both threadsafe and non-threadsafe implementations of
+// a bounded cache. The cache exposes hashmap.Interface so it can be used
+// wherever a map is expected, and evicts entries according to an LRU, LFU or
+// ARC policy once its capacity is reached.
+package cache
+
+import (
+	"errors"
+	"time"
+
+	"github.com/khezen/struct/hashmap"
+)
+
+// Interface describes functions a cache must expose
+type Interface interface {
+	hashmap.Interface
+	PutWithTTL(k, v interface{}, ttl time.Duration)
+	Peek(k interface{}) (interface{}, error)
+	GetOrLoad(k interface{}, loader func(k interface{}) (interface{}, error)) (interface{}, error)
+	OnEvict(func(k, v interface{}, reason Reason))
+	Stats() Stats
+	Cap() int
+}
+
+// Policy selects which entry is evicted when the cache is full
+type Policy int
+
+const (
+	// LRU evicts the least recently used entry.
+	LRU Policy = iota
+	// LFU evicts the least frequently used entry. Ties are broken by recency.
+	LFU
+	// ARC balances recency and frequency using the Adaptive Replacement Cache algorithm.
+	ARC
+)
+
+// Reason tells why an entry left the cache
+type Reason int
+
+const (
+	// Evicted - the entry was evicted to make room for another one
+	Evicted Reason = iota
+	// Expired - the entry outlived its TTL
+	Expired
+	// Removed - the entry was removed explicitly by Remove or Clear
+	Removed
+)
+
+// Stats holds cache usage counters
+type Stats struct {
+	Hits        uint64
+	Misses      uint64
+	Evictions   uint64
+	Expirations uint64
+}
+
+// HitRatio returns the share of lookups which were hits
+func (s Stats) HitRatio() float64 {
+	total := s.Hits + s.Misses
+	if total == 0 {
+		return 0
+	}
+	return float64(s.Hits) / float64(total)
+}
+
+var (
+	// ErrBadCapacity - capacity must be strictly positive
+	ErrBadCapacity = errors.New("ErrBadCapacity - capacity must be strictly positive")
+	// ErrUnknownPolicy - policy is not one of LRU, LFU or ARC
+	ErrUnknownPolicy = errors.New("ErrUnknownPolicy - policy must be one of LRU, LFU or ARC")
+	// ErrLoaderPanic - the loader panicked while others waited for its result
+	ErrLoaderPanic = errors.New("ErrLoaderPanic - the loader panicked while others waited for its result")
+)
+
+// policy keeps track of resident keys and picks eviction victims.
+type policy interface {
+	// touch records a hit on a resident key.
+	touch(k interface{})
+	// admit records a new resident key, evicting another one first if the
+	// policy is at capacity.
+	admit(k interface{}) (victim interface{}, evicted bool)
+	// remove forgets a resident key.
+	remove(k interface{})
+	// keys returns resident keys from the next to be evicted to the last one.
+	keys() []interface{}
+	clear()
+}
+
+func newPolicy(p Policy, capacity int) policy {
+	switch p {
+	case LRU:
+		return newLRU(capacity)
+	case LFU:
+		return newLFU(capacity)
+	case ARC:
+		return newARC(capacity)
+	default:
+		panic(ErrUnknownPolicy)
+	}
+}
@@ -0,0 +1,111 @@
+package cache
+
+import (
+	"container/list"
+	"sort"
+)
+
+type lfuEntry struct {
+	key  interface{}
+	freq int
+}
+
+type lfu struct {
+	capacity int
+	items    map[interface{}]*list.Element
+	freqs    map[int]*list.List
+	min      int
+}
+
+func newLFU(capacity int) *lfu {
+	return &lfu{
+		capacity: capacity,
+		items:    make(map[interface{}]*list.Element),
+		freqs:    make(map[int]*list.List),
+	}
+}
+
+func (p *lfu) touch(k interface{}) {
+	e, ok := p.items[k]
+	if !ok {
+		return
+	}
+	entry := e.Value.(*lfuEntry)
+	p.unlink(e)
+	if p.min == entry.freq && p.freqs[entry.freq] == nil {
+		p.min++
+	}
+	entry.freq++
+	p.items[k] = p.bucket(entry.freq).PushFront(entry)
+}
+
+func (p *lfu) admit(k interface{}) (victim interface{}, evicted bool) {
+	if len(p.items) >= p.capacity {
+		e := p.freqs[p.min].Back()
+		victim = e.Value.(*lfuEntry).key
+		p.unlink(e)
+		delete(p.items, victim)
+		evicted = true
+	}
+	p.items[k] = p.bucket(1).PushFront(&lfuEntry{k, 1})
+	p.min = 1
+	return victim, evicted
+}
+
+func (p *lfu) remove(k interface{}) {
+	e, ok := p.items[k]
+	if !ok {
+		return
+	}
+	freq := e.Value.(*lfuEntry).freq
+	p.unlink(e)
+	delete(p.items, k)
+	if p.min == freq && p.freqs[freq] == nil {
+		p.min = 0
+		for f := range p.freqs {
+			if p.min == 0 || f < p.min {
+				p.min = f
+			}
+		}
+	}
+}
+
+func (p *lfu) keys() []interface{} {
+	freqs := make([]int, 0, len(p.freqs))
+	for f := range p.freqs {
+		freqs = append(freqs, f)
+	}
+	sort.Ints(freqs)
+	keys := make([]interface{}, 0, len(p.items))
+	for _, f := range freqs {
+		for e := p.freqs[f].Back(); e != nil; e = e.Prev() {
+			keys = append(keys, e.Value.(*lfuEntry).key)
+		}
+	}
+	return keys
+}
+
+func (p *lfu) clear() {
+	p.items = make(map[interface{}]*list.Element)
+	p.freqs = make(map[int]*list.List)
+	p.min = 0
+}
+
+func (p *lfu) bucket(freq int) *list.List {
+	l, ok := p.freqs[freq]
+	if !ok {
+		l = list.New()
+		p.freqs[freq] = l
+	}
+	return l
+}
+
+// unlink removes e from its frequency bucket, dropping the bucket once empty.
+func (p *lfu) unlink(e *list.Element) {
+	freq := e.Value.(*lfuEntry).freq
+	l := p.freqs[freq]
+	l.Remove(e)
+	if l.Len() == 0 {
+		delete(p.freqs, freq)
+	}
+}
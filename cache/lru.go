@@ -0,0 +1,55 @@
+package cache
+
+import (
+	"container/list"
+)
+
+type lru struct {
+	capacity int
+	l        *list.List
+	items    map[interface{}]*list.Element
+}
+
+func newLRU(capacity int) *lru {
+	return &lru{
+		capacity: capacity,
+		l:        list.New(),
+		items:    make(map[interface{}]*list.Element),
+	}
+}
+
+func (p *lru) touch(k interface{}) {
+	if e, ok := p.items[k]; ok {
+		p.l.MoveToFront(e)
+	}
+}
+
+func (p *lru) admit(k interface{}) (victim interface{}, evicted bool) {
+	if p.l.Len() >= p.capacity {
+		victim = p.l.Remove(p.l.Back())
+		delete(p.items, victim)
+		evicted = true
+	}
+	p.items[k] = p.l.PushFront(k)
+	return victim, evicted
+}
+
+func (p *lru) remove(k interface{}) {
+	if e, ok := p.items[k]; ok {
+		p.l.Remove(e)
+		delete(p.items, k)
+	}
+}
+
+func (p *lru) keys() []interface{} {
+	keys := make([]interface{}, 0, p.l.Len())
+	for e := p.l.Back(); e != nil; e = e.Prev() {
+		keys = append(keys, e.Value)
+	}
+	return keys
+}
+
+func (p *lru) clear() {
+	p.l.Init()
+	p.items = make(map[interface{}]*list.Element)
+}
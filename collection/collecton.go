@@ -1,24 +1,36 @@
 package collection
 
+import "errors"
+
+// ErrReadOnly - collection is read only
+var ErrReadOnly = errors.New("ErrReadOnly - collection is read only")
+
+// ReadOnly describes methods of a collection which do not modify it
+type ReadOnly interface {
+	Has(...interface{}) bool
+	Each(func(item interface{}) bool)
+
+	Len() int
+	IsEmpty() bool
+	IsEqual(ReadOnly) bool
+
+	String() string
+	Slice() []interface{}
+}
+
 // Interface describes method exposed by a collection
 type Interface interface {
+	ReadOnly
 	Add(...interface{})
 	Remove(...interface{})
 	Replace(item, substitute interface{})
-	Has(...interface{}) bool
-	Each(func(item interface{}) bool)
 
-	Len() int
 	Clear()
-	IsEmpty() bool
-	IsEqual(Interface) bool
 
 	Merge(Interface)
 	Separate(Interface)
 	Retain(Interface)
 
-	String() string
-	Slice() []interface{}
 	CopyCollection() Interface
 }
 
@@ -0,0 +1,68 @@
+package collection
+
+import (
+	"errors"
+	"reflect"
+
+	"github.com/khezen/struct/internal/convert"
+)
+
+var (
+	// ErrNotSlice - the value is not a slice or a pointer to a slice
+	ErrNotSlice = errors.New("ErrNotSlice - the value is not a slice or a pointer to a slice")
+	// ErrType - an item does not have the type of the destination
+	ErrType = errors.New("ErrType - an item does not have the type of the destination")
+)
+
+// Items returns the elements of any slice or array, such as a []string, or of
+// the one a pointer points to, to be passed to the constructors taking
+// ...interface{}.
+func Items(slice interface{}) ([]interface{}, error) {
+	v := reflect.ValueOf(slice)
+	if v.Kind() == reflect.Ptr && !v.IsNil() {
+		v = v.Elem()
+	}
+	if v.Kind() != reflect.Slice && v.Kind() != reflect.Array {
+		return nil, ErrNotSlice
+	}
+	items := make([]interface{}, v.Len())
+	for i := range items {
+		items[i] = v.Index(i).Interface()
+	}
+	return items, nil
+}
+
+// ToSlice replaces the slice ptr points to, such as a *[]string, with the
+// items of c. It fails with ErrType, leaving the slice unchanged, when an
+// item is not assignable to the element type.
+func ToSlice(c ReadOnly, ptr interface{}) error {
+	dst, ok := convert.Target(ptr, reflect.Slice)
+	if !ok {
+		return ErrNotSlice
+	}
+	items := c.Slice()
+	s := reflect.MakeSlice(dst.Type(), len(items), len(items))
+	for i, item := range items {
+		v, err := convert.Value(item, dst.Type().Elem(), ErrType)
+		if err != nil {
+			return err
+		}
+		s.Index(i).Set(v)
+	}
+	dst.Set(s)
+	return nil
+}
+
+// StringSlice returns the items of c, which must all be strings.
+func StringSlice(c ReadOnly) ([]string, error) {
+	var s []string
+	err := ToSlice(c, &s)
+	return s, err
+}
+
+// IntSlice returns the items of c, which must all be ints.
+func IntSlice(c ReadOnly) ([]int, error) {
+	var s []int
+	err := ToSlice(c, &s)
+	return s, err
+}
@@ -0,0 +1,126 @@
+package collection
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"testing"
+)
+
+// list is a minimal ReadOnly collection
+type list []interface{}
+
+func (l list) Has(items ...interface{}) bool {
+	return false
+}
+
+func (l list) Each(f func(item interface{}) bool) {
+	for _, item := range l {
+		if !f(item) {
+			return
+		}
+	}
+}
+
+func (l list) Len() int {
+	return len(l)
+}
+
+func (l list) IsEmpty() bool {
+	return len(l) == 0
+}
+
+func (l list) IsEqual(c ReadOnly) bool {
+	return reflect.DeepEqual(c.Slice(), l.Slice())
+}
+
+func (l list) String() string {
+	return fmt.Sprint([]interface{}(l))
+}
+
+func (l list) Slice() []interface{} {
+	return append([]interface{}{}, l...)
+}
+
+func TestItems(t *testing.T) {
+	strs := []string{"a", "b"}
+	var nilPtr *[]int
+	cases := []struct {
+		slice    interface{}
+		expected []interface{}
+		err      error
+	}{
+		{strs, []interface{}{"a", "b"}, nil},
+		{&strs, []interface{}{"a", "b"}, nil},
+		{[2]int{2, 1}, []interface{}{2, 1}, nil},
+		{[]interface{}{nil}, []interface{}{nil}, nil},
+		{[]int(nil), []interface{}{}, nil},
+		{"a", nil, ErrNotSlice},
+		{nil, nil, ErrNotSlice},
+		{nilPtr, nil, ErrNotSlice},
+		{map[int]int{}, nil, ErrNotSlice},
+	}
+	for _, c := range cases {
+		got, err := Items(c.slice)
+		if err != c.err {
+			t.Errorf("Expected %v. Got %v.", c.err, err)
+		}
+		if c.err == nil && !reflect.DeepEqual(got, c.expected) {
+			t.Errorf("Expected %v. Got %v.", c.expected, got)
+		}
+	}
+}
+
+func TestToSlice(t *testing.T) {
+	var strs []string
+	var ints []int
+	var errs []error
+	floats := []float64{1}
+	var nilPtr *[]int
+	cases := []struct {
+		c        ReadOnly
+		ptr      interface{}
+		expected interface{}
+		err      error
+	}{
+		{list{"a", "b"}, &strs, []string{"a", "b"}, nil},
+		{list{}, &ints, []int{}, nil},
+		{list{nil, errors.New("e")}, &errs, []error{nil, errors.New("e")}, nil},
+		{list{1, "2"}, &ints, []int{}, ErrType},
+		{list{nil}, &floats, []float64{1}, ErrType},
+		{list{1}, floats, []float64{1}, ErrNotSlice},
+		{list{1}, nilPtr, nil, ErrNotSlice},
+		{list{1}, new(int), nil, ErrNotSlice},
+	}
+	for _, c := range cases {
+		err := ToSlice(c.c, c.ptr)
+		if !errors.Is(err, c.err) {
+			t.Errorf("Expected %v. Got %v.", c.err, err)
+		}
+		if c.expected == nil {
+			continue
+		}
+		if got := reflect.ValueOf(c.ptr); got.Kind() == reflect.Ptr {
+			if !reflect.DeepEqual(got.Elem().Interface(), c.expected) {
+				t.Errorf("Expected %v. Got %v.", c.expected, got.Elem())
+			}
+		}
+	}
+}
+
+func TestStringSlice(t *testing.T) {
+	strs, err := StringSlice(list{"a", "b"})
+	if err != nil || !reflect.DeepEqual(strs, []string{"a", "b"}) {
+		t.Errorf("Expected %v. Got %v, %v.", []string{"a", "b"}, strs, err)
+	}
+	if _, err := StringSlice(list{1}); !errors.Is(err, ErrType) {
+		t.Errorf("Expected %v. Got %v.", ErrType, err)
+	}
+	ints, err := IntSlice(list{2, 1})
+	if err != nil || !reflect.DeepEqual(ints, []int{2, 1}) {
+		t.Errorf("Expected %v. Got %v, %v.", []int{2, 1}, ints, err)
+	}
+	if _, err := IntSlice(list{int64(1)}); !errors.Is(err, ErrType) {
+		t.Errorf("Expected %v. Got %v.", ErrType, err)
+	}
+}
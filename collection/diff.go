@@ -0,0 +1,38 @@
+package collection
+
+// Delta lists the items added and removed between two versions of a collection
+type Delta struct {
+	Added   []interface{}
+	Removed []interface{}
+}
+
+// IsEmpty reports whether both versions hold the same items.
+func (d Delta) IsEmpty() bool {
+	return len(d.Added) == 0 && len(d.Removed) == 0
+}
+
+// Diff returns the items of b which are not in a as Added, and the items of a
+// which are not in b as Removed, in the order of traversal of each collection.
+// Items are compared as in Has, so order and multiplicity are ignored.
+func Diff(a, b ReadOnly) Delta {
+	var d Delta
+	b.Each(func(item interface{}) bool {
+		if !a.Has(item) {
+			d.Added = append(d.Added, item)
+		}
+		return true
+	})
+	a.Each(func(item interface{}) bool {
+		if !b.Has(item) {
+			d.Removed = append(d.Removed, item)
+		}
+		return true
+	})
+	return d
+}
+
+// Apply removes the removed items from c and adds the added ones.
+func Apply(c Interface, d Delta) {
+	c.Remove(d.Removed...)
+	c.Add(d.Added...)
+}
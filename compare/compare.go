@@ -0,0 +1,245 @@
+package compare
+
+import (
+	"errors"
+	"math"
+	"reflect"
+)
+
+// ErrNotComparable - items have no natural order
+var ErrNotComparable = errors.New("ErrNotComparable - items have no natural order")
+
+// Func returns a negative number when x sorts before y, a positive one when
+// x sorts after y and 0 when they are equivalent.
+type Func func(x, y interface{}) int
+
+// Less adapts cmp to the less functions taken by sorted arrays and ordered sets.
+func Less(cmp Func) func(slice []interface{}, i, j int) bool {
+	return func(slice []interface{}, i, j int) bool {
+		return cmp(slice[i], slice[j]) < 0
+	}
+}
+
+// ThenBy returns a comparator ordering items with the first of cmps telling
+// them apart.
+func ThenBy(cmps ...Func) Func {
+	return func(x, y interface{}) int {
+		for _, cmp := range cmps {
+			if c := cmp(x, y); c != 0 {
+				return c
+			}
+		}
+		return 0
+	}
+}
+
+// By returns a comparator ordering items by the key they are mapped to,
+// compared with cmp.
+func By(key func(item interface{}) interface{}, cmp Func) Func {
+	return func(x, y interface{}) int {
+		return cmp(key(x), key(y))
+	}
+}
+
+// Reversed returns a comparator ordering items in the reverse order of cmp.
+func Reversed(cmp Func) Func {
+	return func(x, y interface{}) int {
+		return cmp(y, x)
+	}
+}
+
+// NullsFirst returns a comparator sorting nil items first and the others
+// with cmp.
+func NullsFirst(cmp Func) Func {
+	return nulls(cmp, -1)
+}
+
+// NullsLast returns a comparator sorting nil items last and the others
+// with cmp.
+func NullsLast(cmp Func) Func {
+	return nulls(cmp, 1)
+}
+
+// nulls returns a comparator for which nil items compare as sign to the others
+func nulls(cmp Func, sign int) Func {
+	return func(x, y interface{}) int {
+		switch {
+		case isNil(x) && isNil(y):
+			return 0
+		case isNil(x):
+			return sign
+		case isNil(y):
+			return -sign
+		}
+		return cmp(x, y)
+	}
+}
+
+// isNil reports whether item is nil or a nil pointer, map, slice...
+func isNil(item interface{}) bool {
+	if item == nil {
+		return true
+	}
+	v := reflect.ValueOf(item)
+	switch v.Kind() {
+	case reflect.Ptr, reflect.Map, reflect.Slice, reflect.Interface, reflect.Chan, reflect.Func:
+		return v.IsNil()
+	}
+	return false
+}
+
+// Natural compares booleans, false first, strings and numbers of any built-in
+// type, NaN first. Numbers of different kinds are compared as float64. It
+// panics with ErrNotComparable for other items.
+func Natural(x, y interface{}) int {
+	vx, vy := reflect.ValueOf(x), reflect.ValueOf(y)
+	kx, ky := kind(vx), kind(vy)
+	switch {
+	case kx == invalid || ky == invalid:
+		panic(ErrNotComparable)
+	case kx == ky:
+		switch kx {
+		case signed:
+			return Int64(vx.Int(), vy.Int())
+		case unsigned:
+			return Uint64(vx.Uint(), vy.Uint())
+		case float:
+			return Float64(vx.Float(), vy.Float())
+		case str:
+			return String(vx.String(), vy.String())
+		case boolean:
+			return Bool(vx.Bool(), vy.Bool())
+		}
+	case kx.isNumber() && ky.isNumber():
+		return Float64(toFloat(vx), toFloat(vy))
+	}
+	panic(ErrNotComparable)
+}
+
+// Orderable reports whether Natural can compare any two of items.
+func Orderable(items ...interface{}) bool {
+	numbers, first := true, invalid
+	for i, item := range items {
+		k := kind(reflect.ValueOf(item))
+		if k == invalid {
+			return false
+		}
+		if i == 0 {
+			first = k
+		}
+		numbers = numbers && k.isNumber()
+		if !numbers && k != first {
+			return false
+		}
+	}
+	return true
+}
+
+// Int compares ints.
+func Int(x, y interface{}) int {
+	return Int64(int64(x.(int)), int64(y.(int)))
+}
+
+// Int64 compares int64s.
+func Int64(x, y interface{}) int {
+	a, b := x.(int64), y.(int64)
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+	return 0
+}
+
+// Uint64 compares uint64s.
+func Uint64(x, y interface{}) int {
+	a, b := x.(uint64), y.(uint64)
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+	return 0
+}
+
+// Float64 compares float64s, NaN sorting before any other number.
+func Float64(x, y interface{}) int {
+	a, b := x.(float64), y.(float64)
+	switch {
+	case math.IsNaN(a) && math.IsNaN(b):
+		return 0
+	case math.IsNaN(a) || a < b:
+		return -1
+	case math.IsNaN(b) || a > b:
+		return 1
+	}
+	return 0
+}
+
+// String compares strings.
+func String(x, y interface{}) int {
+	a, b := x.(string), y.(string)
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+	return 0
+}
+
+// Bool compares booleans, false sorting first.
+func Bool(x, y interface{}) int {
+	a, b := x.(bool), y.(bool)
+	switch {
+	case a == b:
+		return 0
+	case b:
+		return -1
+	}
+	return 1
+}
+
+// family groups the reflect kinds Natural compares alike
+type family int
+
+const (
+	invalid family = iota
+	signed
+	unsigned
+	float
+	str
+	boolean
+)
+
+func (f family) isNumber() bool {
+	return f == signed || f == unsigned || f == float
+}
+
+func kind(v reflect.Value) family {
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return signed
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return unsigned
+	case reflect.Float32, reflect.Float64:
+		return float
+	case reflect.String:
+		return str
+	case reflect.Bool:
+		return boolean
+	}
+	return invalid
+}
+
+func toFloat(v reflect.Value) float64 {
+	switch kind(v) {
+	case signed:
+		return float64(v.Int())
+	case unsigned:
+		return float64(v.Uint())
+	}
+	return v.Float()
+}
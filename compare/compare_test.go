@@ -0,0 +1,117 @@
+package compare
+
+import (
+	"math"
+	"testing"
+)
+
+func TestNatural(t *testing.T) {
+	var nilPtr *int
+	cases := []struct {
+		cmp      Func
+		x, y     interface{}
+		expected int
+	}{
+		{Natural, 1, 2, -1},
+		{Natural, int8(3), int64(3), 0},
+		{Natural, uint(5), uint16(2), 1},
+		{Natural, 1.5, float32(1.5), 0},
+		{Natural, math.NaN(), -1.0, -1},
+		{Natural, 2.0, math.NaN(), 1},
+		{Natural, math.NaN(), math.NaN(), 0},
+		{Natural, 1, 1.5, -1},
+		{Natural, uint(2), -1, 1},
+		{Natural, "b", "a", 1},
+		{Natural, false, true, -1},
+		{Natural, true, false, 1},
+		{Natural, true, true, 0},
+		{Int, 4, 2, 1},
+		{Reversed(Natural), 1, 2, 1},
+		{NullsFirst(Natural), nil, 1, -1},
+		{NullsFirst(Natural), 1, nil, 1},
+		{NullsFirst(Natural), nil, nil, 0},
+		{NullsLast(Natural), nil, 1, 1},
+		{NullsLast(Int), 1, 2, -1},
+		{NullsFirst(Natural), nilPtr, 1, -1},
+	}
+	for _, c := range cases {
+		if got := c.cmp(c.x, c.y); got != c.expected {
+			t.Errorf("Expected %v. Got %v.", c.expected, got)
+		}
+	}
+}
+
+func TestNotComparable(t *testing.T) {
+	cases := []struct {
+		x, y interface{}
+	}{
+		{nil, 1},
+		{"1", 1},
+		{true, 1},
+		{struct{}{}, struct{}{}},
+	}
+	for _, c := range cases {
+		func() {
+			defer func() {
+				if r := recover(); r != ErrNotComparable {
+					t.Errorf("Expected %v. Got %v.", ErrNotComparable, r)
+				}
+			}()
+			Natural(c.x, c.y)
+		}()
+	}
+}
+
+func TestOrderable(t *testing.T) {
+	cases := []struct {
+		items    []interface{}
+		expected bool
+	}{
+		{nil, true},
+		{[]interface{}{1, 2.5, uint8(3)}, true},
+		{[]interface{}{"a", "b"}, true},
+		{[]interface{}{"a", 1}, false},
+		{[]interface{}{1, "a"}, false},
+		{[]interface{}{true, false}, true},
+		{[]interface{}{1, nil}, false},
+		{[]interface{}{struct{}{}}, false},
+	}
+	for _, c := range cases {
+		if got := Orderable(c.items...); got != c.expected {
+			t.Errorf("Expected %v. Got %v.", c.expected, got)
+		}
+	}
+}
+
+func TestThenBy(t *testing.T) {
+	type person struct {
+		name string
+		age  int
+	}
+	name := By(func(item interface{}) interface{} {
+		return item.(person).name
+	}, String)
+	age := By(func(item interface{}) interface{} {
+		return item.(person).age
+	}, Natural)
+	cmp := ThenBy(name, Reversed(age))
+	cases := []struct {
+		x, y     person
+		expected int
+	}{
+		{person{"a", 30}, person{"b", 20}, -1},
+		{person{"a", 30}, person{"a", 20}, -1},
+		{person{"a", 20}, person{"a", 30}, 1},
+		{person{"a", 20}, person{"a", 20}, 0},
+	}
+	for _, c := range cases {
+		if got := cmp(c.x, c.y); got != c.expected {
+			t.Errorf("Expected %v. Got %v.", c.expected, got)
+		}
+	}
+	less := Less(cmp)
+	slice := []interface{}{person{"a", 20}, person{"a", 30}}
+	if !less(slice, 1, 0) || less(slice, 0, 1) {
+		t.Errorf("Expected %v. Got %v.", true, less(slice, 1, 0))
+	}
+}
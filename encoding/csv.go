@@ -0,0 +1,65 @@
+package encoding
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+
+	"github.com/khezen/struct/array"
+)
+
+// WriteCSV writes each record of a as a CSV row and flushes w. Records are
+// []string, []interface{} or collections, whose fields are printed with
+// fmt.Sprint. Other records fail with ErrUnsupportedType.
+func WriteCSV(w *csv.Writer, a array.ReadOnly) error {
+	var err error
+	a.Each(func(record interface{}) bool {
+		var row []string
+		if row, err = csvRow(record); err == nil {
+			err = w.Write(row)
+		}
+		return err == nil
+	})
+	if err != nil {
+		return err
+	}
+	w.Flush()
+	return w.Error()
+}
+
+// ReadCSV adds each row read from r to a as a []string record, until the end
+// of the input.
+func ReadCSV(r *csv.Reader, a array.Interface) error {
+	for {
+		row, err := r.Read()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		a.Add(row)
+	}
+}
+
+func csvRow(record interface{}) ([]string, error) {
+	switch record := record.(type) {
+	case []string:
+		return record, nil
+	case []interface{}:
+		return csvFields(record), nil
+	}
+	v, err := Value(record)
+	if fields, ok := v.([]interface{}); ok && err == nil {
+		return csvFields(fields), nil
+	}
+	return nil, ErrUnsupportedType
+}
+
+func csvFields(items []interface{}) []string {
+	row := make([]string, len(items))
+	for i, item := range items {
+		row[i] = fmt.Sprint(item)
+	}
+	return row
+}
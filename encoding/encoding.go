@@ -0,0 +1,265 @@
+// Package encoding converts sets, arrays, ordered sets and hashmaps to and
+// from JSON, MessagePack, YAML, TOML and CSV without any dependency.
+//
+// Collections are first converted to plain values: a []interface{} for sets,
+// arrays and ordered sets, and a map[interface{}]interface{} for hashmaps. A
+// Codec encodes plain values, and the Set, Array, Oset and Hashmap wrappers
+// implement the marshaling hooks of encoding/json, gopkg.in/yaml and
+// github.com/BurntSushi/toml through them.
+package encoding
+
+import (
+	"encoding/json"
+	"errors"
+	"math"
+	"sort"
+
+	"github.com/khezen/struct/array"
+	"github.com/khezen/struct/collection"
+	"github.com/khezen/struct/compare"
+	"github.com/khezen/struct/hashmap"
+	"github.com/khezen/struct/oset"
+)
+
+var (
+	// ErrUnsupportedCollection - the value is neither a collection nor a hashmap
+	ErrUnsupportedCollection = errors.New("ErrUnsupportedCollection - the value is neither a collection nor a hashmap")
+	// ErrUnsupportedType - the value has no representation in the encoding
+	ErrUnsupportedType = errors.New("ErrUnsupportedType - the value has no representation in the encoding")
+	// ErrMismatch - the decoded value does not fit the collection
+	ErrMismatch = errors.New("ErrMismatch - the decoded value does not fit the collection")
+	// ErrMalformed - the data is not valid in the encoding
+	ErrMalformed = errors.New("ErrMalformed - the data is not valid in the encoding")
+	// ErrUnhashable - a decoded map key or set item is not hashable
+	ErrUnhashable = errors.New("ErrUnhashable - a decoded map key or set item is not hashable")
+)
+
+// Codec converts plain values to bytes: nil, booleans, numbers, strings,
+// []byte, []interface{} and map[interface{}]interface{}, nested at will.
+// Decoded integers are ints, unless they overflow int.
+type Codec interface {
+	Encode(v interface{}) ([]byte, error)
+	Decode(b []byte) (interface{}, error)
+}
+
+// Marshal encodes the collection c with codec.
+func Marshal(codec Codec, c interface{}) ([]byte, error) {
+	v, err := Value(c)
+	if err != nil {
+		return nil, err
+	}
+	return codec.Encode(v)
+}
+
+// Unmarshal replaces the content of the collection c with the one decoded by codec.
+func Unmarshal(codec Codec, b []byte, c interface{}) error {
+	v, err := codec.Decode(b)
+	if err != nil {
+		return err
+	}
+	return Fill(c, v)
+}
+
+// Value converts a collection to a plain value. Arrays and ordered sets keep
+// their order, while sets are sorted when their items are orderable so that
+// encodings are deterministic. Nested collections are converted as well, and
+// a nil collection converts to nil.
+func Value(c interface{}) (interface{}, error) {
+	switch c := c.(type) {
+	case nil:
+		return nil, nil
+	case hashmap.ReadOnly:
+		m := make(map[interface{}]interface{}, c.Len())
+		c.Each(func(k, v interface{}) bool {
+			m[k] = plain(v)
+			return true
+		})
+		return m, nil
+	case array.ReadOnly:
+		return plainItems(c.Slice()), nil
+	case collection.ReadOnly:
+		items := plainItems(c.Slice())
+		sortItems(items)
+		return items, nil
+	}
+	return nil, ErrUnsupportedCollection
+}
+
+// plain converts v when it is a collection
+func plain(v interface{}) interface{} {
+	switch v.(type) {
+	case hashmap.ReadOnly, collection.ReadOnly:
+		v, _ = Value(v)
+	}
+	return v
+}
+
+func plainItems(items []interface{}) []interface{} {
+	for i, item := range items {
+		items[i] = plain(item)
+	}
+	return items
+}
+
+// Fill replaces the content of the collection c with v, a decoded plain
+// value. Maps may have string keys, as most decoders produce, and integers of
+// any type are converted to int when they fit. Sets and ordered sets fail with
+// ErrUnhashable, unchanged, when an item is a list or a map.
+func Fill(c interface{}, v interface{}) error {
+	v, err := normalize(v)
+	if err != nil {
+		return err
+	}
+	switch c := c.(type) {
+	case hashmap.Interface:
+		m, ok := v.(map[interface{}]interface{})
+		if !ok && v != nil {
+			return ErrMismatch
+		}
+		c.Clear()
+		for k, v := range m {
+			c.Put(k, v)
+		}
+		return nil
+	case collection.Interface:
+		items, ok := v.([]interface{})
+		if !ok && v != nil {
+			return ErrMismatch
+		}
+		if hashed(c) {
+			for _, item := range items {
+				if !hashable(item) {
+					return ErrUnhashable
+				}
+			}
+		}
+		c.Clear()
+		c.Add(items...)
+		return nil
+	}
+	return ErrUnsupportedCollection
+}
+
+// normalize converts maps with string keys to map[interface{}]interface{},
+// json numbers and integers to int, recursively.
+func normalize(v interface{}) (interface{}, error) {
+	switch v := v.(type) {
+	case []interface{}:
+		items := make([]interface{}, len(v))
+		for i, item := range v {
+			item, err := normalize(item)
+			if err != nil {
+				return nil, err
+			}
+			items[i] = item
+		}
+		return items, nil
+	case map[string]interface{}:
+		m := make(map[interface{}]interface{}, len(v))
+		for k, value := range v {
+			value, err := normalize(value)
+			if err != nil {
+				return nil, err
+			}
+			m[k] = value
+		}
+		return m, nil
+	case map[interface{}]interface{}:
+		m := make(map[interface{}]interface{}, len(v))
+		for k, value := range v {
+			k, err := normalize(k)
+			if err != nil {
+				return nil, err
+			}
+			if !hashable(k) {
+				return nil, ErrUnhashable
+			}
+			value, err = normalize(value)
+			if err != nil {
+				return nil, err
+			}
+			m[k] = value
+		}
+		return m, nil
+	case json.Number:
+		if i, err := v.Int64(); err == nil {
+			return toInt(i), nil
+		}
+		f, err := v.Float64()
+		if err != nil {
+			return nil, ErrMalformed
+		}
+		return f, nil
+	case int8:
+		return int(v), nil
+	case int16:
+		return int(v), nil
+	case int32:
+		return int(v), nil
+	case int64:
+		return toInt(v), nil
+	case uint:
+		return toUint(uint64(v)), nil
+	case uint8:
+		return int(v), nil
+	case uint16:
+		return int(v), nil
+	case uint32:
+		return toUint(uint64(v)), nil
+	case uint64:
+		return toUint(v), nil
+	}
+	return v, nil
+}
+
+// toInt converts i to int unless it overflows
+func toInt(i int64) interface{} {
+	if i < math.MinInt || i > math.MaxInt {
+		return i
+	}
+	return int(i)
+}
+
+// toUint converts u to int unless it overflows
+func toUint(u uint64) interface{} {
+	if u > math.MaxInt {
+		return u
+	}
+	return int(u)
+}
+
+// hashed reports whether c stores its items as map keys, like sets and
+// ordered sets
+func hashed(c collection.Interface) bool {
+	_, isOset := c.(oset.Interface)
+	_, isArray := c.(array.Interface)
+	return isOset || !isArray
+}
+
+// hashable reports whether k can be a map key among the decoded values
+func hashable(k interface{}) bool {
+	switch k.(type) {
+	case []interface{}, map[interface{}]interface{}, []byte:
+		return false
+	}
+	return true
+}
+
+// sortItems sorts items in natural order when they are orderable
+func sortItems(items []interface{}) {
+	if compare.Orderable(items...) {
+		sort.SliceStable(items, func(i, j int) bool {
+			return compare.Natural(items[i], items[j]) < 0
+		})
+	}
+}
+
+// sortedKeys returns the keys of m, sorted when they are orderable
+func sortedKeys(m map[interface{}]interface{}) []interface{} {
+	keys := make([]interface{}, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sortItems(keys)
+	return keys
+}
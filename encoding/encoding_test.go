@@ -0,0 +1,449 @@
+package encoding
+
+import (
+	"bytes"
+	"database/sql"
+	"database/sql/driver"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"io"
+	"math"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/khezen/struct/array"
+	"github.com/khezen/struct/collection"
+	"github.com/khezen/struct/hashmap"
+	"github.com/khezen/struct/oset"
+	"github.com/khezen/struct/set"
+)
+
+func testErr(err error, expectErr bool, t *testing.T) {
+	if (expectErr && err == nil) || (!expectErr && err != nil) {
+		t.Errorf(" Error expected? %v. Got: %v.", expectErr, err)
+	}
+}
+
+func TestMarshal(t *testing.T) {
+	cases := []struct {
+		codec    Codec
+		c, empty interface{}
+		expected interface{}
+	}{
+		{JSON, set.New(3, 1, 2), set.New(), []interface{}{1, 2, 3}},
+		{JSON, array.New("b", 1.5, true, nil), array.New(), []interface{}{"b", 1.5, true, nil}},
+		{JSON, oset.New(3, 1, 2), oset.New(), []interface{}{3, 1, 2}},
+		{JSON, hashmap.New("a", 1, "b", []interface{}{"c"}), hashmap.New(), map[interface{}]interface{}{"a": 1, "b": []interface{}{"c"}}},
+		{MessagePack, set.NewSync("b", "a"), set.New(), []interface{}{"a", "b"}},
+		{MessagePack, array.New(-1, -200, 70000, uint64(math.MaxUint64), float32(1.5), []byte{1}), array.New(), []interface{}{-1, -200, 70000, uint64(math.MaxUint64), float32(1.5), []byte{1}}},
+		{MessagePack, oset.NewSync(2, 1), oset.New(), []interface{}{2, 1}},
+		{MessagePack, hashmap.New(1, "a", true, nil), hashmap.NewSync(), map[interface{}]interface{}{1: "a", true: nil}},
+	}
+	for _, c := range cases {
+		b, err := Marshal(c.codec, c.c)
+		testErr(err, false, t)
+		testErr(Unmarshal(c.codec, b, c.empty), false, t)
+		got, err := Value(c.empty)
+		testErr(err, false, t)
+		if !reflect.DeepEqual(got, c.expected) {
+			t.Errorf("Expected %v. Got %v.", c.expected, got)
+		}
+	}
+}
+
+func TestMarshalErrors(t *testing.T) {
+	_, err := Marshal(JSON, 42)
+	testErr(err, true, t)
+	_, err = Marshal(MessagePack, array.New(struct{}{}))
+	testErr(err, true, t)
+	testErr(Unmarshal(JSON, []byte(`{"a": 1}`), set.New()), true, t)
+	testErr(Unmarshal(JSON, []byte(`[1]`), hashmap.New()), true, t)
+	testErr(Unmarshal(JSON, []byte(`[1] 2`), set.New()), true, t)
+	testErr(Unmarshal(JSON, []byte(`[1]`), 42), true, t)
+	testErr(Unmarshal(JSON, []byte(`[`), set.New()), true, t)
+	cases := []struct {
+		in string
+		c  collection.Interface
+	}{
+		{`[[1,2]]`, set.New(0)},
+		{`[{"a":1}]`, set.NewSync(0)},
+		{`[0,[1]]`, oset.New(0)},
+		{`[{"a":1}]`, oset.NewSync(0)},
+	}
+	for _, c := range cases {
+		if err := Unmarshal(JSON, []byte(c.in), c.c); err != ErrUnhashable {
+			t.Errorf("Expected %v. Got %v.", ErrUnhashable, err)
+		}
+		if !c.c.Has(0) || c.c.Len() != 1 {
+			t.Errorf("Expected %v. Got %v.", "[0]", c.c)
+		}
+	}
+	a := array.New()
+	testErr(Unmarshal(JSON, []byte(`[[1,2],{"a":1}]`), a), false, t)
+	if a.Len() != 2 {
+		t.Errorf("Expected %v. Got %v.", 2, a.Len())
+	}
+}
+
+func TestMessagePack(t *testing.T) {
+	cases := []struct {
+		v        interface{}
+		expected []byte
+	}{
+		{nil, []byte{0xc0}},
+		{false, []byte{0xc2}},
+		{127, []byte{0x7f}},
+		{-32, []byte{0xe0}},
+		{-33, []byte{0xd0, 0xdf}},
+		{200, []byte{0xcc, 0xc8}},
+		{int8(-128), []byte{0xd0, 0x80}},
+		{int16(-300), []byte{0xd1, 0xfe, 0xd4}},
+		{1.0, []byte{0xcb, 0x3f, 0xf0, 0, 0, 0, 0, 0, 0}},
+		{"ab", []byte{0xa2, 'a', 'b'}},
+		{[]interface{}{1, true}, []byte{0x92, 0x01, 0xc3}},
+		{map[string]interface{}{"b": 2, "a": 1}, []byte{0x82, 0xa1, 'a', 0x01, 0xa1, 'b', 0x02}},
+	}
+	for _, c := range cases {
+		got, err := MessagePack.Encode(c.v)
+		testErr(err, false, t)
+		if !bytes.Equal(got, c.expected) {
+			t.Errorf("Expected %x. Got %x.", c.expected, got)
+		}
+	}
+	long := []interface{}{strings.Repeat("x", 40), strings.Repeat("y", 300), int32(-70000), int64(math.MinInt64), uint16(60000), uint32(70000)}
+	long = append(long, make([]interface{}, 20)...)
+	b, err := MessagePack.Encode(long)
+	testErr(err, false, t)
+	got, err := MessagePack.Decode(b)
+	testErr(err, false, t)
+	expected, _ := normalize(long)
+	if !reflect.DeepEqual(got, expected) {
+		t.Errorf("Expected %v. Got %v.", expected, got)
+	}
+	malformed := [][]byte{
+		{},
+		{0x92, 0x01},
+		{0xd9, 0x05, 'a'},
+		{0x81, 0x91, 0x01, 0x01},
+		{0xc1},
+		{0x01, 0x02},
+	}
+	for _, b := range malformed {
+		_, err := MessagePack.Decode(b)
+		testErr(err, true, t)
+	}
+}
+
+type config struct {
+	Tags  Set     `json:"tags"`
+	Steps Array   `json:"steps"`
+	Hosts Oset    `json:"hosts"`
+	Ports Hashmap `json:"ports"`
+}
+
+func TestJSONHooks(t *testing.T) {
+	in := config{
+		Tags:  Set{set.New("b", "a")},
+		Steps: Array{array.New(2, 1)},
+		Hosts: Oset{oset.New("y", "x")},
+		Ports: Hashmap{hashmap.New("http", 80)},
+	}
+	b, err := json.Marshal(in)
+	testErr(err, false, t)
+	expected := `{"tags":["a","b"],"steps":[2,1],"hosts":["y","x"],"ports":{"http":80}}`
+	if string(b) != expected {
+		t.Errorf("Expected %v. Got %v.", expected, string(b))
+	}
+	var out config
+	testErr(json.Unmarshal(b, &out), false, t)
+	if !out.Tags.IsEqual(in.Tags) || !out.Steps.IsEqual(in.Steps) || !out.Hosts.IsEqual(in.Hosts) {
+		t.Errorf("Expected %v. Got %v.", in, out)
+	}
+	if v, _ := out.Ports.Get("http"); v != 80 {
+		t.Errorf("Expected %v. Got %v.", 80, v)
+	}
+}
+
+// yamlUnmarshal mimics the function yaml.v2 passes to UnmarshalYAML
+func yamlUnmarshal(v interface{}) func(interface{}) error {
+	return func(out interface{}) error {
+		reflect.ValueOf(out).Elem().Set(reflect.ValueOf(v))
+		return nil
+	}
+}
+
+func TestYAMLHooks(t *testing.T) {
+	var s Set
+	testErr(s.UnmarshalYAML(yamlUnmarshal([]interface{}{"b", "a", "b"})), false, t)
+	var a Array
+	testErr(a.UnmarshalYAML(yamlUnmarshal([]interface{}{int64(2), 1})), false, t)
+	var o Oset
+	testErr(o.UnmarshalYAML(yamlUnmarshal([]interface{}{"y", "x", "y"})), false, t)
+	var m Hashmap
+	testErr(m.UnmarshalYAML(yamlUnmarshal(map[interface{}]interface{}{"a": map[string]interface{}{"b": 1}})), false, t)
+	cases := []struct {
+		marshal  func() (interface{}, error)
+		expected interface{}
+	}{
+		{s.MarshalYAML, []interface{}{"a", "b"}},
+		{a.MarshalYAML, []interface{}{2, 1}},
+		{o.MarshalYAML, []interface{}{"y", "x"}},
+		{m.MarshalYAML, map[interface{}]interface{}{"a": map[interface{}]interface{}{"b": 1}}},
+		{Set{}.MarshalYAML, nil},
+	}
+	for _, c := range cases {
+		got, err := c.marshal()
+		testErr(err, false, t)
+		if !reflect.DeepEqual(got, c.expected) {
+			t.Errorf("Expected %v. Got %v.", c.expected, got)
+		}
+	}
+	testErr(s.UnmarshalYAML(yamlUnmarshal(map[interface{}]interface{}{})), true, t)
+	testErr(m.UnmarshalYAML(yamlUnmarshal(map[interface{}]interface{}{1: []interface{}{}, 2.5: 1})), false, t)
+}
+
+func TestTOMLHooks(t *testing.T) {
+	cases := []struct {
+		marshal  func() ([]byte, error)
+		expected string
+	}{
+		{Set{set.New(int64(2), 1)}.MarshalTOML, `[1, 2]`},
+		{Array{array.New("a\"\n\x01é", 1.0, 1e21, math.Inf(-1), float32(0.5), true)}.MarshalTOML, `["a\"\n\u0001é", 1.0, 1e+21, -inf, 0.5, true]`},
+		{Oset{oset.New(math.NaN(), math.Inf(1))}.MarshalTOML, `[nan, inf]`},
+		{Hashmap{hashmap.New(1, []interface{}{"x"}, "a b", hashmap.New())}.MarshalTOML, `{ "1" = ["x"], "a b" = {} }`},
+		{Hashmap{hashmap.New()}.MarshalTOML, `{}`},
+	}
+	for _, c := range cases {
+		got, err := c.marshal()
+		testErr(err, false, t)
+		if string(got) != c.expected {
+			t.Errorf("Expected %v. Got %v.", c.expected, string(got))
+		}
+	}
+	_, err := Array{array.New(nil)}.MarshalTOML()
+	testErr(err, true, t)
+	_, err = Set{}.MarshalTOML()
+	testErr(err, true, t)
+
+	var s Set
+	testErr(s.UnmarshalTOML([]interface{}{int64(1), int64(2)}), false, t)
+	if !s.Has(1, 2) {
+		t.Errorf("Expected %v. Got %v.", true, false)
+	}
+	var a Array
+	testErr(a.UnmarshalTOML([]interface{}{"x"}), false, t)
+	var o Oset
+	testErr(o.UnmarshalTOML([]interface{}{"x", "x"}), false, t)
+	var m Hashmap
+	testErr(m.UnmarshalTOML(map[string]interface{}{"port": int64(80)}), false, t)
+	if v, _ := m.Get("port"); v != 80 || a.Len() != 1 || o.Len() != 1 {
+		t.Errorf("Expected %v. Got %v.", 80, v)
+	}
+}
+
+func TestCSV(t *testing.T) {
+	records := array.New(
+		[]string{"name", "tags"},
+		[]interface{}{"a,b", 1},
+		array.New("c", 2.5),
+		set.New(2, 1),
+	)
+	var buf bytes.Buffer
+	testErr(WriteCSV(csv.NewWriter(&buf), records), false, t)
+	expected := "name,tags\n\"a,b\",1\nc,2.5\n1,2\n"
+	if buf.String() != expected {
+		t.Errorf("Expected %q. Got %q.", expected, buf.String())
+	}
+	got := array.New()
+	testErr(ReadCSV(csv.NewReader(&buf), got), false, t)
+	want := array.New([]string{"name", "tags"}, []string{"a,b", "1"}, []string{"c", "2.5"}, []string{"1", "2"})
+	if !reflect.DeepEqual(got.Slice(), want.Slice()) {
+		t.Errorf("Expected %v. Got %v.", want, got)
+	}
+	testErr(WriteCSV(csv.NewWriter(&buf), array.New(42)), true, t)
+	testErr(WriteCSV(csv.NewWriter(&buf), array.New(hashmap.New())), true, t)
+	testErr(ReadCSV(csv.NewReader(strings.NewReader("a,b\nc\n")), array.New()), true, t)
+}
+
+func TestPostgresArray(t *testing.T) {
+	atoi := func(elem string) (interface{}, error) {
+		return strconv.Atoi(elem)
+	}
+	cases := []struct {
+		codec    Codec
+		in       string
+		expected interface{}
+		err      bool
+	}{
+		{PostgresArray, `{}`, []interface{}{}, false},
+		{PostgresArray, ` { a , "b \\\"c" ,NULL,"NULL",{d,""}} `, []interface{}{"a", `b \"c`, nil, "NULL", []interface{}{"d", ""}}, false},
+		{PostgresArray, `{a\,b}`, []interface{}{"a,b"}, false},
+		{PostgresArrayOf(atoi), `{1,-2}`, []interface{}{1, -2}, false},
+		{PostgresArrayOf(atoi), `{x}`, nil, true},
+		{PostgresArray, `a`, nil, true},
+		{PostgresArray, `{a`, nil, true},
+		{PostgresArray, `{a,}`, nil, true},
+		{PostgresArray, `{a}b`, nil, true},
+		{PostgresArray, `{"a}`, nil, true},
+		{PostgresArray, `{"a"b}`, nil, true},
+		{PostgresArray, `{a"b"}`, nil, true},
+		{PostgresArray, `{a,`, nil, true},
+	}
+	for _, c := range cases {
+		got, err := c.codec.Decode([]byte(c.in))
+		testErr(err, c.err, t)
+		if !c.err && !reflect.DeepEqual(got, c.expected) {
+			t.Errorf("Expected %#v. Got %#v.", c.expected, got)
+		}
+	}
+	b, err := PostgresArray.Encode([]interface{}{"a", `b"\`, nil, 1, 2.5, true, []interface{}{}})
+	testErr(err, false, t)
+	expected := `{"a","b\"\\",NULL,1,2.5,true,{}}`
+	if string(b) != expected {
+		t.Errorf("Expected %v. Got %v.", expected, string(b))
+	}
+	_, err = PostgresArray.Encode(map[interface{}]interface{}{})
+	testErr(err, true, t)
+	_, err = PostgresArray.Encode([]interface{}{[]byte{}})
+	testErr(err, true, t)
+}
+
+// fakeDriver stores values by key: "put" takes a key and a value, and "get"
+// takes a key and returns a row with its value, as bytes like real drivers.
+type fakeDriver struct {
+	l    sync.Mutex
+	rows map[string]driver.Value
+}
+
+type fakeConn struct {
+	d *fakeDriver
+}
+
+type fakeStmt struct {
+	d     *fakeDriver
+	query string
+}
+
+type fakeRows struct {
+	value driver.Value
+	done  bool
+}
+
+var fake = &fakeDriver{rows: make(map[string]driver.Value)}
+
+func init() {
+	sql.Register("encoding_fake", fake)
+}
+
+func (d *fakeDriver) Open(name string) (driver.Conn, error) {
+	return fakeConn{d}, nil
+}
+
+func (c fakeConn) Prepare(query string) (driver.Stmt, error) {
+	return fakeStmt{c.d, query}, nil
+}
+
+func (c fakeConn) Close() error {
+	return nil
+}
+
+func (c fakeConn) Begin() (driver.Tx, error) {
+	return nil, errors.New("no transactions")
+}
+
+func (s fakeStmt) Close() error {
+	return nil
+}
+
+func (s fakeStmt) NumInput() int {
+	return -1
+}
+
+func (s fakeStmt) Exec(args []driver.Value) (driver.Result, error) {
+	s.d.l.Lock()
+	defer s.d.l.Unlock()
+	s.d.rows[args[0].(string)] = args[1]
+	return driver.RowsAffected(1), nil
+}
+
+func (s fakeStmt) Query(args []driver.Value) (driver.Rows, error) {
+	s.d.l.Lock()
+	defer s.d.l.Unlock()
+	value := s.d.rows[args[0].(string)]
+	if str, ok := value.(string); ok {
+		value = []byte(str)
+	}
+	return &fakeRows{value: value}, nil
+}
+
+func (r *fakeRows) Columns() []string {
+	return []string{"value"}
+}
+
+func (r *fakeRows) Close() error {
+	return nil
+}
+
+func (r *fakeRows) Next(dest []driver.Value) error {
+	if r.done {
+		return io.EOF
+	}
+	dest[0], r.done = r.value, true
+	return nil
+}
+
+func TestColumn(t *testing.T) {
+	db, err := sql.Open("encoding_fake", "")
+	testErr(err, false, t)
+	defer db.Close()
+	cases := []struct {
+		in, out  Column
+		stored   driver.Value
+		expected interface{}
+	}{
+		{Column{set.New("b", "a"), nil, false}, Column{set.New("c"), nil, false}, `["a","b"]`, []interface{}{"a", "b"}},
+		{Column{oset.NewSync(3, 1, 2), nil, false}, Column{oset.New(), nil, false}, `[3,1,2]`, []interface{}{3, 1, 2}},
+		{Column{hashmap.New("a", 1), nil, false}, Column{hashmap.New(), nil, false}, `{"a":1}`, map[interface{}]interface{}{"a": 1}},
+		{Column{array.New("x y", "x y", nil), PostgresArray, false}, Column{array.New(), PostgresArray, false}, `{"x y","x y",NULL}`, []interface{}{"x y", "x y", nil}},
+		{Column{oset.New(2, 1), PostgresArray, false}, Column{oset.New(), PostgresArrayOf(func(s string) (interface{}, error) { return strconv.Atoi(s) }), false}, `{2,1}`, []interface{}{2, 1}},
+		{Column{set.New(), PostgresArray, true}, Column{set.New(1), PostgresArray, true}, nil, []interface{}{}},
+		{Column{set.New(), PostgresArray, false}, Column{set.New(1), PostgresArray, true}, `{}`, []interface{}{}},
+		{Column{nil, nil, false}, Column{hashmap.New(1, 2), nil, true}, nil, map[interface{}]interface{}{}},
+	}
+	for i, c := range cases {
+		key := strconv.Itoa(i)
+		_, err := db.Exec("put", key, c.in)
+		testErr(err, false, t)
+		if stored := fake.rows[key]; stored != c.stored {
+			t.Errorf("Expected %v. Got %v.", c.stored, stored)
+		}
+		testErr(db.QueryRow("get", key).Scan(c.out), false, t)
+		got, _ := Value(c.out.Collection)
+		if !reflect.DeepEqual(got, c.expected) {
+			t.Errorf("Expected %v. Got %v.", c.expected, got)
+		}
+	}
+	if err := db.QueryRow("get", "5").Scan(Column{set.New(), nil, false}); !errors.Is(err, ErrNull) {
+		t.Errorf("Expected %v. Got %v.", ErrNull, err)
+	}
+	_, err = db.Exec("put", "x", Column{hashmap.New(1, 2), PostgresArray, false})
+	testErr(err, true, t)
+	_, err = db.Exec("put", "x", Column{42, nil, false})
+	testErr(err, true, t)
+	_, err = db.Exec("put", "2d", Column{array.New([]interface{}{"a"}, []interface{}{"b"}), PostgresArray, false})
+	testErr(err, false, t)
+	tags := set.New("c")
+	if err := db.QueryRow("get", "2d").Scan(Column{tags, PostgresArray, false}); !errors.Is(err, ErrUnhashable) {
+		t.Errorf("Expected %v. Got %v.", ErrUnhashable, err)
+	}
+	if !tags.IsEqual(set.New("c")) {
+		t.Errorf("Expected %v. Got %v.", set.New("c"), tags)
+	}
+	testErr(Column{set.New(), PostgresArray, false}.Scan("{{a},{b}}"), true, t)
+	testErr(Column{set.New(), nil, false}.Scan(42), true, t)
+	testErr(Column{set.New(), nil, false}.Scan(`[1]`), false, t)
+}
@@ -0,0 +1,66 @@
+package encoding
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+)
+
+// JSON encodes plain values as JSON. Object keys are strings, so map keys of
+// other types are printed with fmt.Sprint, and []byte are base64 strings.
+var JSON Codec = jsonCodec{}
+
+type jsonCodec struct{}
+
+func (jsonCodec) Encode(v interface{}) ([]byte, error) {
+	v, err := jsonValue(v)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(v)
+}
+
+func (jsonCodec) Decode(b []byte) (interface{}, error) {
+	dec := json.NewDecoder(bytes.NewReader(b))
+	dec.UseNumber()
+	var v interface{}
+	if err := dec.Decode(&v); err != nil {
+		return nil, err
+	}
+	if _, err := dec.Token(); err != io.EOF {
+		return nil, ErrMalformed
+	}
+	return normalize(v)
+}
+
+// jsonValue converts maps to map[string]interface{}, recursively
+func jsonValue(v interface{}) (interface{}, error) {
+	switch v := v.(type) {
+	case []interface{}:
+		items := make([]interface{}, len(v))
+		for i, item := range v {
+			item, err := jsonValue(item)
+			if err != nil {
+				return nil, err
+			}
+			items[i] = item
+		}
+		return items, nil
+	case map[interface{}]interface{}:
+		m := make(map[string]interface{}, len(v))
+		for k, value := range v {
+			value, err := jsonValue(value)
+			if err != nil {
+				return nil, err
+			}
+			key, ok := k.(string)
+			if !ok {
+				key = fmt.Sprint(k)
+			}
+			m[key] = value
+		}
+		return m, nil
+	}
+	return v, nil
+}
@@ -0,0 +1,297 @@
+package encoding
+
+import (
+	"encoding/binary"
+	"math"
+)
+
+// MessagePack encodes plain values in the MessagePack format. Maps are
+// written with their keys sorted when they are orderable, so that equal
+// collections have equal encodings.
+var MessagePack Codec = msgpackCodec{}
+
+type msgpackCodec struct{}
+
+func (msgpackCodec) Encode(v interface{}) ([]byte, error) {
+	return appendMsgpack(nil, v)
+}
+
+func (msgpackCodec) Decode(b []byte) (interface{}, error) {
+	d := msgpackDecoder{b: b}
+	v, err := d.value()
+	if err != nil {
+		return nil, err
+	}
+	if d.i != len(d.b) {
+		return nil, ErrMalformed
+	}
+	return v, nil
+}
+
+func appendMsgpack(b []byte, v interface{}) ([]byte, error) {
+	switch v := v.(type) {
+	case nil:
+		return append(b, 0xc0), nil
+	case bool:
+		if v {
+			return append(b, 0xc3), nil
+		}
+		return append(b, 0xc2), nil
+	case int:
+		return appendMsgpackInt(b, int64(v)), nil
+	case int8:
+		return appendMsgpackInt(b, int64(v)), nil
+	case int16:
+		return appendMsgpackInt(b, int64(v)), nil
+	case int32:
+		return appendMsgpackInt(b, int64(v)), nil
+	case int64:
+		return appendMsgpackInt(b, v), nil
+	case uint:
+		return appendMsgpackUint(b, uint64(v)), nil
+	case uint8:
+		return appendMsgpackUint(b, uint64(v)), nil
+	case uint16:
+		return appendMsgpackUint(b, uint64(v)), nil
+	case uint32:
+		return appendMsgpackUint(b, uint64(v)), nil
+	case uint64:
+		return appendMsgpackUint(b, v), nil
+	case float32:
+		return binary.BigEndian.AppendUint32(append(b, 0xca), math.Float32bits(v)), nil
+	case float64:
+		return binary.BigEndian.AppendUint64(append(b, 0xcb), math.Float64bits(v)), nil
+	case string:
+		b = appendMsgpackHeader(b, len(v), 0xa0, 32, 0xd9, 0xda, 0xdb)
+		return append(b, v...), nil
+	case []byte:
+		b = appendMsgpackHeader(b, len(v), 0, 0, 0xc4, 0xc5, 0xc6)
+		return append(b, v...), nil
+	case []interface{}:
+		b = appendMsgpackHeader(b, len(v), 0x90, 16, 0, 0xdc, 0xdd)
+		var err error
+		for _, item := range v {
+			if b, err = appendMsgpack(b, item); err != nil {
+				return nil, err
+			}
+		}
+		return b, nil
+	case map[string]interface{}:
+		m := make(map[interface{}]interface{}, len(v))
+		for k, value := range v {
+			m[k] = value
+		}
+		return appendMsgpack(b, m)
+	case map[interface{}]interface{}:
+		b = appendMsgpackHeader(b, len(v), 0x80, 16, 0, 0xde, 0xdf)
+		var err error
+		for _, k := range sortedKeys(v) {
+			if b, err = appendMsgpack(b, k); err != nil {
+				return nil, err
+			}
+			if b, err = appendMsgpack(b, v[k]); err != nil {
+				return nil, err
+			}
+		}
+		return b, nil
+	}
+	return nil, ErrUnsupportedType
+}
+
+func appendMsgpackInt(b []byte, i int64) []byte {
+	switch {
+	case i >= 0:
+		return appendMsgpackUint(b, uint64(i))
+	case i >= -32:
+		return append(b, byte(i))
+	case i >= math.MinInt8:
+		return append(b, 0xd0, byte(i))
+	case i >= math.MinInt16:
+		return binary.BigEndian.AppendUint16(append(b, 0xd1), uint16(i))
+	case i >= math.MinInt32:
+		return binary.BigEndian.AppendUint32(append(b, 0xd2), uint32(i))
+	}
+	return binary.BigEndian.AppendUint64(append(b, 0xd3), uint64(i))
+}
+
+func appendMsgpackUint(b []byte, u uint64) []byte {
+	switch {
+	case u < 128:
+		return append(b, byte(u))
+	case u <= math.MaxUint8:
+		return append(b, 0xcc, byte(u))
+	case u <= math.MaxUint16:
+		return binary.BigEndian.AppendUint16(append(b, 0xcd), uint16(u))
+	case u <= math.MaxUint32:
+		return binary.BigEndian.AppendUint32(append(b, 0xce), uint32(u))
+	}
+	return binary.BigEndian.AppendUint64(append(b, 0xcf), u)
+}
+
+// appendMsgpackHeader writes the length n with the fix format when n < fixMax,
+// or with the 8, 16 or 32 bit format. A zero format is not available.
+func appendMsgpackHeader(b []byte, n int, fix byte, fixMax int, f8, f16, f32 byte) []byte {
+	switch {
+	case n < fixMax:
+		return append(b, fix|byte(n))
+	case f8 != 0 && n <= math.MaxUint8:
+		return append(b, f8, byte(n))
+	case n <= math.MaxUint16:
+		return binary.BigEndian.AppendUint16(append(b, f16), uint16(n))
+	}
+	return binary.BigEndian.AppendUint32(append(b, f32), uint32(n))
+}
+
+type msgpackDecoder struct {
+	b []byte
+	i int
+}
+
+// next returns the n following bytes
+func (d *msgpackDecoder) next(n int) ([]byte, error) {
+	if n < 0 || n > len(d.b)-d.i {
+		return nil, ErrMalformed
+	}
+	b := d.b[d.i : d.i+n]
+	d.i += n
+	return b, nil
+}
+
+// uint reads a big endian integer of size bytes
+func (d *msgpackDecoder) uint(size int) (uint64, error) {
+	b, err := d.next(size)
+	if err != nil {
+		return 0, err
+	}
+	var u uint64
+	for _, c := range b {
+		u = u<<8 | uint64(c)
+	}
+	return u, nil
+}
+
+// length reads a length of size bytes
+func (d *msgpackDecoder) length(size int) (int, error) {
+	n, err := d.uint(size)
+	if err != nil {
+		return 0, err
+	}
+	if n > uint64(len(d.b)-d.i) {
+		return 0, ErrMalformed
+	}
+	return int(n), nil
+}
+
+func (d *msgpackDecoder) value() (interface{}, error) {
+	b, err := d.next(1)
+	if err != nil {
+		return nil, err
+	}
+	c := b[0]
+	switch {
+	case c <= 0x7f:
+		return int(c), nil
+	case c >= 0xe0:
+		return int(int8(c)), nil
+	case c&0xf0 == 0x80:
+		return d.mapOf(int(c & 0x0f))
+	case c&0xf0 == 0x90:
+		return d.array(int(c & 0x0f))
+	case c&0xe0 == 0xa0:
+		return d.str(int(c & 0x1f))
+	}
+	switch c {
+	case 0xc0:
+		return nil, nil
+	case 0xc2:
+		return false, nil
+	case 0xc3:
+		return true, nil
+	case 0xc4, 0xc5, 0xc6:
+		n, err := d.length(1 << (c - 0xc4))
+		if err != nil {
+			return nil, err
+		}
+		b, err := d.next(n)
+		return append([]byte{}, b...), err
+	case 0xca:
+		u, err := d.uint(4)
+		return math.Float32frombits(uint32(u)), err
+	case 0xcb:
+		u, err := d.uint(8)
+		return math.Float64frombits(u), err
+	case 0xcc, 0xcd, 0xce, 0xcf:
+		u, err := d.uint(1 << (c - 0xcc))
+		return toUint(u), err
+	case 0xd0:
+		u, err := d.uint(1)
+		return int(int8(u)), err
+	case 0xd1:
+		u, err := d.uint(2)
+		return int(int16(u)), err
+	case 0xd2:
+		u, err := d.uint(4)
+		return int(int32(u)), err
+	case 0xd3:
+		u, err := d.uint(8)
+		return toInt(int64(u)), err
+	case 0xd9, 0xda, 0xdb:
+		n, err := d.length(1 << (c - 0xd9))
+		if err != nil {
+			return nil, err
+		}
+		return d.str(n)
+	case 0xdc, 0xdd:
+		n, err := d.length(2 << (c - 0xdc))
+		if err != nil {
+			return nil, err
+		}
+		return d.array(n)
+	case 0xde, 0xdf:
+		n, err := d.length(2 << (c - 0xde))
+		if err != nil {
+			return nil, err
+		}
+		return d.mapOf(n)
+	}
+	return nil, ErrUnsupportedType
+}
+
+func (d *msgpackDecoder) str(n int) (interface{}, error) {
+	b, err := d.next(n)
+	if err != nil {
+		return nil, err
+	}
+	return string(b), nil
+}
+
+func (d *msgpackDecoder) array(n int) (interface{}, error) {
+	items := make([]interface{}, n)
+	for i := range items {
+		item, err := d.value()
+		if err != nil {
+			return nil, err
+		}
+		items[i] = item
+	}
+	return items, nil
+}
+
+func (d *msgpackDecoder) mapOf(n int) (interface{}, error) {
+	m := make(map[interface{}]interface{}, n)
+	for i := 0; i < n; i++ {
+		k, err := d.value()
+		if err != nil {
+			return nil, err
+		}
+		if !hashable(k) {
+			return nil, ErrUnhashable
+		}
+		v, err := d.value()
+		if err != nil {
+			return nil, err
+		}
+		m[k] = v
+	}
+	return m, nil
+}
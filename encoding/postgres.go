@@ -0,0 +1,158 @@
+package encoding
+
+import (
+	"fmt"
+	"strings"
+)
+
+// PostgresArray encodes lists in the text format of Postgres arrays, such as
+// {1,"a b",NULL}. Elements are decoded as strings, or nil for NULL.
+var PostgresArray Codec = postgresCodec{}
+
+// PostgresArrayOf returns a PostgresArray codec converting the decoded
+// elements with parse, so that {1,2} decodes to ints rather than strings.
+func PostgresArrayOf(parse func(elem string) (interface{}, error)) Codec {
+	return postgresCodec{parse}
+}
+
+type postgresCodec struct {
+	parse func(elem string) (interface{}, error)
+}
+
+func (postgresCodec) Encode(v interface{}) ([]byte, error) {
+	if _, ok := v.([]interface{}); !ok {
+		return nil, ErrUnsupportedType
+	}
+	return appendPostgres(nil, v)
+}
+
+func (c postgresCodec) Decode(b []byte) (interface{}, error) {
+	p := postgresParser{s: string(b), parse: c.parse}
+	items, err := p.array()
+	if err != nil {
+		return nil, err
+	}
+	if strings.TrimSpace(p.s[p.i:]) != "" {
+		return nil, ErrMalformed
+	}
+	return items, nil
+}
+
+func appendPostgres(b []byte, v interface{}) ([]byte, error) {
+	switch v := v.(type) {
+	case nil:
+		return append(b, "NULL"...), nil
+	case bool, int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64, float32, float64:
+		return fmt.Append(b, v), nil
+	case string:
+		b = append(b, '"')
+		for i := 0; i < len(v); i++ {
+			if v[i] == '"' || v[i] == '\\' {
+				b = append(b, '\\')
+			}
+			b = append(b, v[i])
+		}
+		return append(b, '"'), nil
+	case []interface{}:
+		b = append(b, '{')
+		var err error
+		for i, item := range v {
+			if i > 0 {
+				b = append(b, ',')
+			}
+			if b, err = appendPostgres(b, item); err != nil {
+				return nil, err
+			}
+		}
+		return append(b, '}'), nil
+	}
+	return nil, ErrUnsupportedType
+}
+
+type postgresParser struct {
+	s     string
+	i     int
+	parse func(elem string) (interface{}, error)
+}
+
+// skip skips the spaces allowed around elements
+func (p *postgresParser) skip() {
+	for p.i < len(p.s) && strings.IndexByte(" \t\n\r\v\f", p.s[p.i]) >= 0 {
+		p.i++
+	}
+}
+
+func (p *postgresParser) array() ([]interface{}, error) {
+	if p.skip(); p.i >= len(p.s) || p.s[p.i] != '{' {
+		return nil, ErrMalformed
+	}
+	p.i++
+	items := []interface{}{}
+	if p.skip(); p.i < len(p.s) && p.s[p.i] == '}' {
+		p.i++
+		return items, nil
+	}
+	for {
+		item, err := p.element()
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, item)
+		if p.skip(); p.i >= len(p.s) {
+			return nil, ErrMalformed
+		}
+		p.i++
+		switch p.s[p.i-1] {
+		case '}':
+			return items, nil
+		case ',':
+		default:
+			return nil, ErrMalformed
+		}
+	}
+}
+
+func (p *postgresParser) element() (interface{}, error) {
+	if p.skip(); p.i >= len(p.s) {
+		return nil, ErrMalformed
+	}
+	if p.s[p.i] == '{' {
+		return p.array()
+	}
+	quoted := p.s[p.i] == '"'
+	if quoted {
+		p.i++
+	}
+	var elem strings.Builder
+	for ; p.i < len(p.s); p.i++ {
+		c := p.s[p.i]
+		switch {
+		case c == '\\' && p.i+1 < len(p.s):
+			p.i++
+			c = p.s[p.i]
+		case quoted && c == '"':
+			p.i++
+			return p.value(elem.String())
+		case !quoted && (c == ',' || c == '}'):
+			s := strings.TrimSpace(elem.String())
+			if s == "" {
+				return nil, ErrMalformed
+			}
+			if strings.EqualFold(s, "NULL") {
+				return nil, nil
+			}
+			return p.value(s)
+		case !quoted && (c == '{' || c == '"'):
+			return nil, ErrMalformed
+		}
+		elem.WriteByte(c)
+	}
+	return nil, ErrMalformed
+}
+
+func (p *postgresParser) value(elem string) (interface{}, error) {
+	if p.parse == nil {
+		return elem, nil
+	}
+	return p.parse(elem)
+}
@@ -0,0 +1,73 @@
+package encoding
+
+import (
+	"database/sql/driver"
+	"errors"
+)
+
+// ErrNull - the column is NULL
+var ErrNull = errors.New("ErrNull - the column is NULL")
+
+// Column adapts a collection to a database column, as a sql.Scanner and a
+// driver.Valuer. Codec is JSON when nil, or PostgresArray for Postgres array
+// columns. Arrays and ordered sets keep their order, and sets are sorted when
+// their items are orderable.
+//
+//	row.Scan(encoding.Column{Collection: tags, Codec: encoding.PostgresArray})
+type Column struct {
+	Collection interface{}
+	Codec      Codec
+	// NullEmpty scans NULL as an empty collection, and stores empty
+	// collections as NULL. Otherwise, scanning NULL fails with ErrNull.
+	NullEmpty bool
+}
+
+func (c Column) codec() Codec {
+	if c.Codec == nil {
+		return JSON
+	}
+	return c.Codec
+}
+
+// Scan implements sql.Scanner
+func (c Column) Scan(src interface{}) error {
+	switch src := src.(type) {
+	case nil:
+		if !c.NullEmpty {
+			return ErrNull
+		}
+		return Fill(c.Collection, nil)
+	case []byte:
+		return Unmarshal(c.codec(), src, c.Collection)
+	case string:
+		return Unmarshal(c.codec(), []byte(src), c.Collection)
+	}
+	return ErrUnsupportedType
+}
+
+// Value implements driver.Valuer. The encoding is returned as a string,
+// since drivers may send []byte as binary data.
+func (c Column) Value() (driver.Value, error) {
+	v, err := Value(c.Collection)
+	if err != nil {
+		return nil, err
+	}
+	if v == nil || (c.NullEmpty && isEmpty(v)) {
+		return nil, nil
+	}
+	b, err := c.codec().Encode(v)
+	if err != nil {
+		return nil, err
+	}
+	return string(b), nil
+}
+
+func isEmpty(v interface{}) bool {
+	switch v := v.(type) {
+	case []interface{}:
+		return len(v) == 0
+	case map[interface{}]interface{}:
+		return len(v) == 0
+	}
+	return false
+}
@@ -0,0 +1,162 @@
+package encoding
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// The TOML hooks follow the github.com/BurntSushi/toml Marshaler and
+// Unmarshaler interfaces. Collections are written as TOML values: lists as
+// arrays and hashmaps as inline tables, whose keys are printed with fmt.Sprint
+// unless they are strings. TOML has no null, so nil items cannot be written.
+
+// MarshalTOML implements toml.Marshaler
+func (s Set) MarshalTOML() ([]byte, error) {
+	return marshalTOML(s.Interface)
+}
+
+// UnmarshalTOML implements toml.Unmarshaler
+func (s *Set) UnmarshalTOML(v interface{}) error {
+	return Fill(s.target(), v)
+}
+
+// MarshalTOML implements toml.Marshaler
+func (a Array) MarshalTOML() ([]byte, error) {
+	return marshalTOML(a.Interface)
+}
+
+// UnmarshalTOML implements toml.Unmarshaler
+func (a *Array) UnmarshalTOML(v interface{}) error {
+	return Fill(a.target(), v)
+}
+
+// MarshalTOML implements toml.Marshaler
+func (s Oset) MarshalTOML() ([]byte, error) {
+	return marshalTOML(s.Interface)
+}
+
+// UnmarshalTOML implements toml.Unmarshaler
+func (s *Oset) UnmarshalTOML(v interface{}) error {
+	return Fill(s.target(), v)
+}
+
+// MarshalTOML implements toml.Marshaler
+func (m Hashmap) MarshalTOML() ([]byte, error) {
+	return marshalTOML(m.Interface)
+}
+
+// UnmarshalTOML implements toml.Unmarshaler
+func (m *Hashmap) UnmarshalTOML(v interface{}) error {
+	return Fill(m.target(), v)
+}
+
+func marshalTOML(c interface{}) ([]byte, error) {
+	v, err := Value(c)
+	if err != nil {
+		return nil, err
+	}
+	return appendTOML(nil, v)
+}
+
+func appendTOML(b []byte, v interface{}) ([]byte, error) {
+	switch v := v.(type) {
+	case bool:
+		return strconv.AppendBool(b, v), nil
+	case int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64:
+		return fmt.Append(b, v), nil
+	case float32:
+		return appendTOMLFloat(b, float64(v), 32), nil
+	case float64:
+		return appendTOMLFloat(b, v, 64), nil
+	case string:
+		return appendTOMLString(b, v), nil
+	case []interface{}:
+		b = append(b, '[')
+		var err error
+		for i, item := range v {
+			if i > 0 {
+				b = append(b, ", "...)
+			}
+			if b, err = appendTOML(b, item); err != nil {
+				return nil, err
+			}
+		}
+		return append(b, ']'), nil
+	case map[interface{}]interface{}:
+		values := make(map[string]interface{}, len(v))
+		keys := make([]string, 0, len(v))
+		for k, value := range v {
+			key, ok := k.(string)
+			if !ok {
+				key = fmt.Sprint(k)
+			}
+			values[key] = value
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		b = append(b, '{')
+		var err error
+		for i, key := range keys {
+			if i > 0 {
+				b = append(b, ',')
+			}
+			b = appendTOMLString(append(b, ' '), key)
+			if b, err = appendTOML(append(b, " = "...), values[key]); err != nil {
+				return nil, err
+			}
+		}
+		if len(v) > 0 {
+			b = append(b, ' ')
+		}
+		return append(b, '}'), nil
+	}
+	return nil, ErrUnsupportedType
+}
+
+func appendTOMLFloat(b []byte, f float64, bitSize int) []byte {
+	switch {
+	case math.IsNaN(f):
+		return append(b, "nan"...)
+	case math.IsInf(f, 1):
+		return append(b, "inf"...)
+	case math.IsInf(f, -1):
+		return append(b, "-inf"...)
+	}
+	s := strconv.FormatFloat(f, 'g', -1, bitSize)
+	if !strings.ContainsAny(s, ".e") {
+		s += ".0"
+	}
+	return append(b, s...)
+}
+
+// appendTOMLString writes s as a basic string
+func appendTOMLString(b []byte, s string) []byte {
+	b = append(b, '"')
+	for _, r := range s {
+		switch r {
+		case '"', '\\':
+			b = append(b, '\\', byte(r))
+		case '\b':
+			b = append(b, `\b`...)
+		case '\t':
+			b = append(b, `\t`...)
+		case '\n':
+			b = append(b, `\n`...)
+		case '\f':
+			b = append(b, `\f`...)
+		case '\r':
+			b = append(b, `\r`...)
+		default:
+			if r < 0x20 || r == 0x7f {
+				b = fmt.Appendf(b, `\u%04X`, r)
+			} else {
+				b = utf8.AppendRune(b, r)
+			}
+		}
+	}
+	return append(b, '"')
+}
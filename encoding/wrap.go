@@ -0,0 +1,101 @@
+package encoding
+
+import (
+	"github.com/khezen/struct/array"
+	"github.com/khezen/struct/hashmap"
+	"github.com/khezen/struct/oset"
+	"github.com/khezen/struct/set"
+)
+
+// Set wraps a set to marshal it as a list, sorted when its items are
+// orderable. Unmarshaling into a nil Set creates it with set.New.
+type Set struct {
+	set.Interface
+}
+
+// Array wraps an array to marshal it as a list. Unmarshaling into a nil
+// Array creates it with array.New.
+type Array struct {
+	array.Interface
+}
+
+// Oset wraps an ordered set to marshal it as a list. Unmarshaling into a nil
+// Oset creates it with oset.New.
+type Oset struct {
+	oset.Interface
+}
+
+// Hashmap wraps a hashmap to marshal it as a map. Unmarshaling into a nil
+// Hashmap creates it with hashmap.New.
+type Hashmap struct {
+	hashmap.Interface
+}
+
+// target returns the wrapped collection, created if needed
+func (s *Set) target() interface{} {
+	if s.Interface == nil {
+		s.Interface = set.New()
+	}
+	return s.Interface
+}
+
+func (a *Array) target() interface{} {
+	if a.Interface == nil {
+		a.Interface = array.New()
+	}
+	return a.Interface
+}
+
+func (s *Oset) target() interface{} {
+	if s.Interface == nil {
+		s.Interface = oset.New()
+	}
+	return s.Interface
+}
+
+func (m *Hashmap) target() interface{} {
+	if m.Interface == nil {
+		m.Interface = hashmap.New()
+	}
+	return m.Interface
+}
+
+// MarshalJSON implements json.Marshaler
+func (s Set) MarshalJSON() ([]byte, error) {
+	return Marshal(JSON, s.Interface)
+}
+
+// UnmarshalJSON implements json.Unmarshaler
+func (s *Set) UnmarshalJSON(b []byte) error {
+	return Unmarshal(JSON, b, s.target())
+}
+
+// MarshalJSON implements json.Marshaler
+func (a Array) MarshalJSON() ([]byte, error) {
+	return Marshal(JSON, a.Interface)
+}
+
+// UnmarshalJSON implements json.Unmarshaler
+func (a *Array) UnmarshalJSON(b []byte) error {
+	return Unmarshal(JSON, b, a.target())
+}
+
+// MarshalJSON implements json.Marshaler
+func (s Oset) MarshalJSON() ([]byte, error) {
+	return Marshal(JSON, s.Interface)
+}
+
+// UnmarshalJSON implements json.Unmarshaler
+func (s *Oset) UnmarshalJSON(b []byte) error {
+	return Unmarshal(JSON, b, s.target())
+}
+
+// MarshalJSON implements json.Marshaler
+func (m Hashmap) MarshalJSON() ([]byte, error) {
+	return Marshal(JSON, m.Interface)
+}
+
+// UnmarshalJSON implements json.Unmarshaler
+func (m *Hashmap) UnmarshalJSON(b []byte) error {
+	return Unmarshal(JSON, b, m.target())
+}
@@ -0,0 +1,53 @@
+package encoding
+
+// The YAML hooks follow the gopkg.in/yaml.v2 Marshaler and Unmarshaler
+// interfaces, which gopkg.in/yaml.v3 supports as well, so that no YAML
+// library is imported.
+
+// MarshalYAML implements yaml.Marshaler
+func (s Set) MarshalYAML() (interface{}, error) {
+	return Value(s.Interface)
+}
+
+// UnmarshalYAML implements yaml.Unmarshaler
+func (s *Set) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	return unmarshalYAML(unmarshal, s.target())
+}
+
+// MarshalYAML implements yaml.Marshaler
+func (a Array) MarshalYAML() (interface{}, error) {
+	return Value(a.Interface)
+}
+
+// UnmarshalYAML implements yaml.Unmarshaler
+func (a *Array) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	return unmarshalYAML(unmarshal, a.target())
+}
+
+// MarshalYAML implements yaml.Marshaler
+func (s Oset) MarshalYAML() (interface{}, error) {
+	return Value(s.Interface)
+}
+
+// UnmarshalYAML implements yaml.Unmarshaler
+func (s *Oset) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	return unmarshalYAML(unmarshal, s.target())
+}
+
+// MarshalYAML implements yaml.Marshaler
+func (m Hashmap) MarshalYAML() (interface{}, error) {
+	return Value(m.Interface)
+}
+
+// UnmarshalYAML implements yaml.Unmarshaler
+func (m *Hashmap) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	return unmarshalYAML(unmarshal, m.target())
+}
+
+func unmarshalYAML(unmarshal func(interface{}) error, c interface{}) error {
+	var v interface{}
+	if err := unmarshal(&v); err != nil {
+		return err
+	}
+	return Fill(c, v)
+}
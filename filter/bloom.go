@@ -0,0 +1,191 @@
+package filter
+
+import (
+	"encoding/binary"
+	"math"
+	"math/bits"
+
+	"github.com/khezen/struct/internal/digest"
+)
+
+const (
+	bloomTag    = 'B'
+	countingTag = 'C'
+	cuckooTag   = 'K'
+	version     = 1
+	// maxHashes bounds the number of hash functions of Bloom filters
+	maxHashes = 64
+)
+
+type bloom struct {
+	m, k uint64
+	w    []uint64
+}
+
+// NewBloom creates a Bloom filter sized to hold capacity items with the given false positive rate
+func NewBloom(capacity int, fpRate float64) Interface {
+	checkParameters(capacity, fpRate)
+	m, k := bloomSizing(capacity, fpRate)
+	return &bloom{m, k, make([]uint64, (m+63)/64)}
+}
+
+// bloomSizing returns the optimal number of bits and hash functions
+func bloomSizing(capacity int, fpRate float64) (m, k uint64) {
+	n := float64(capacity)
+	bitCount := math.Ceil(-n * math.Log(fpRate) / (math.Ln2 * math.Ln2))
+	m = uint64(bitCount)
+	k = uint64(math.Round(bitCount / n * math.Ln2))
+	if k < 1 {
+		k = 1
+	}
+	if k > maxHashes {
+		k = maxHashes
+	}
+	return m, k
+}
+
+// location returns the i-th position of an item using double hashing
+func location(h1, h2, i, m uint64) uint64 {
+	return (h1 + i*h2) % m
+}
+
+// estimate returns the number of distinct items which set x out of m slots using k hashes
+func estimate(x, m, k uint64) int {
+	if x == 0 {
+		return 0
+	}
+	if x >= m {
+		x = m - 1
+	}
+	return int(math.Round(-float64(m) / float64(k) * math.Log(1-float64(x)/float64(m))))
+}
+
+func (b *bloom) Add(items ...interface{}) {
+	for _, item := range items {
+		h1, h2 := digest.Item(item)
+		for i := uint64(0); i < b.k; i++ {
+			loc := location(h1, h2, i, b.m)
+			b.w[loc/64] |= 1 << (loc % 64)
+		}
+	}
+}
+
+func (b *bloom) Has(items ...interface{}) bool {
+	for _, item := range items {
+		h1, h2 := digest.Item(item)
+		for i := uint64(0); i < b.k; i++ {
+			loc := location(h1, h2, i, b.m)
+			if b.w[loc/64]&(1<<(loc%64)) == 0 {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+func (b *bloom) popCount() uint64 {
+	var x int
+	for _, w := range b.w {
+		x += bits.OnesCount64(w)
+	}
+	return uint64(x)
+}
+
+// Len estimates the number of distinct items added to the filter
+func (b *bloom) Len() int {
+	return estimate(b.popCount(), b.m, b.k)
+}
+
+func (b *bloom) Clear() {
+	for i := range b.w {
+		b.w[i] = 0
+	}
+}
+
+func (b *bloom) IsEmpty() bool {
+	return b.popCount() == 0
+}
+
+// Merge adds every item of the given filter into this one
+func (b *bloom) Merge(t Interface) error {
+	t, release := operand(t)
+	defer release()
+	o, ok := t.(*bloom)
+	if !ok || o.m != b.m || o.k != b.k {
+		return ErrIncompatible
+	}
+	for i := range b.w {
+		b.w[i] |= o.w[i]
+	}
+	return nil
+}
+
+// Union returns a new filter holding items of both filters
+func (b *bloom) Union(t Interface) (Interface, error) {
+	return union(b, t)
+}
+
+// FalsePositiveRate estimates the current probability of a false positive
+func (b *bloom) FalsePositiveRate() float64 {
+	return math.Pow(float64(b.popCount())/float64(b.m), float64(b.k))
+}
+
+func (b *bloom) Copy() Interface {
+	w := make([]uint64, len(b.w))
+	copy(w, b.w)
+	return &bloom{b.m, b.k, w}
+}
+
+// MarshalBinary encodes the filter in a portable little endian format
+func (b *bloom) MarshalBinary() ([]byte, error) {
+	buf := header(bloomTag, 16+8*len(b.w))
+	buf = binary.LittleEndian.AppendUint64(buf, b.m)
+	buf = binary.LittleEndian.AppendUint64(buf, b.k)
+	for _, w := range b.w {
+		buf = binary.LittleEndian.AppendUint64(buf, w)
+	}
+	return buf, nil
+}
+
+// UnmarshalBinary replaces the filter with the one encoded in data
+func (b *bloom) UnmarshalBinary(data []byte) error {
+	data, err := checkHeader(bloomTag, data)
+	if err != nil {
+		return err
+	}
+	if len(data) < 16 {
+		return ErrBadFormat
+	}
+	m, k := binary.LittleEndian.Uint64(data), binary.LittleEndian.Uint64(data[8:])
+	data = data[16:]
+	if m == 0 || k == 0 || k > maxHashes || len(data)%8 != 0 || uint64(len(data)/8) != (m-1)/64+1 {
+		return ErrBadFormat
+	}
+	w := make([]uint64, len(data)/8)
+	for i := range w {
+		w[i] = binary.LittleEndian.Uint64(data[8*i:])
+	}
+	b.m, b.k, b.w = m, k, w
+	return nil
+}
+
+func header(tag byte, size int) []byte {
+	buf := make([]byte, 2, 2+size)
+	buf[0], buf[1] = tag, version
+	return buf
+}
+
+func checkHeader(tag byte, data []byte) ([]byte, error) {
+	if len(data) < 2 || data[0] != tag || data[1] != version {
+		return nil, ErrBadFormat
+	}
+	return data[2:], nil
+}
+
+func union(f, t Interface) (Interface, error) {
+	u := f.Copy()
+	if err := u.Merge(t); err != nil {
+		return nil, err
+	}
+	return u, nil
+}
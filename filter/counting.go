@@ -0,0 +1,148 @@
+package filter
+
+import (
+	"encoding/binary"
+	"math"
+
+	"github.com/khezen/struct/internal/digest"
+)
+
+type counting struct {
+	m, k uint64
+	c    []uint8
+}
+
+// NewCountingBloom creates a counting Bloom filter sized to hold capacity items with the given false positive rate.
+// Counters saturate at 255 and are never decremented once saturated.
+func NewCountingBloom(capacity int, fpRate float64) Remover {
+	checkParameters(capacity, fpRate)
+	m, k := bloomSizing(capacity, fpRate)
+	return &counting{m, k, make([]uint8, m)}
+}
+
+func (b *counting) Add(items ...interface{}) {
+	for _, item := range items {
+		h1, h2 := digest.Item(item)
+		for i := uint64(0); i < b.k; i++ {
+			loc := location(h1, h2, i, b.m)
+			if b.c[loc] < math.MaxUint8 {
+				b.c[loc]++
+			}
+		}
+	}
+}
+
+func (b *counting) Has(items ...interface{}) bool {
+	for _, item := range items {
+		h1, h2 := digest.Item(item)
+		for i := uint64(0); i < b.k; i++ {
+			if b.c[location(h1, h2, i, b.m)] == 0 {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// Remove removes items from the filter. Items which are not in the filter are ignored.
+func (b *counting) Remove(items ...interface{}) {
+	for _, item := range items {
+		if !b.Has(item) {
+			continue
+		}
+		h1, h2 := digest.Item(item)
+		for i := uint64(0); i < b.k; i++ {
+			loc := location(h1, h2, i, b.m)
+			if b.c[loc] < math.MaxUint8 {
+				b.c[loc]--
+			}
+		}
+	}
+}
+
+func (b *counting) nonZero() uint64 {
+	var x uint64
+	for _, c := range b.c {
+		if c != 0 {
+			x++
+		}
+	}
+	return x
+}
+
+// Len estimates the number of distinct items in the filter
+func (b *counting) Len() int {
+	return estimate(b.nonZero(), b.m, b.k)
+}
+
+func (b *counting) Clear() {
+	for i := range b.c {
+		b.c[i] = 0
+	}
+}
+
+func (b *counting) IsEmpty() bool {
+	return b.nonZero() == 0
+}
+
+// Merge adds every item of the given filter into this one
+func (b *counting) Merge(t Interface) error {
+	t, release := operand(t)
+	defer release()
+	o, ok := t.(*counting)
+	if !ok || o.m != b.m || o.k != b.k {
+		return ErrIncompatible
+	}
+	for i, c := range o.c {
+		if sum := int(b.c[i]) + int(c); sum < math.MaxUint8 {
+			b.c[i] = uint8(sum)
+		} else {
+			b.c[i] = math.MaxUint8
+		}
+	}
+	return nil
+}
+
+// Union returns a new filter holding items of both filters
+func (b *counting) Union(t Interface) (Interface, error) {
+	return union(b, t)
+}
+
+// FalsePositiveRate estimates the current probability of a false positive
+func (b *counting) FalsePositiveRate() float64 {
+	return math.Pow(float64(b.nonZero())/float64(b.m), float64(b.k))
+}
+
+func (b *counting) Copy() Interface {
+	c := make([]uint8, len(b.c))
+	copy(c, b.c)
+	return &counting{b.m, b.k, c}
+}
+
+// MarshalBinary encodes the filter in a portable little endian format
+func (b *counting) MarshalBinary() ([]byte, error) {
+	buf := header(countingTag, 16+len(b.c))
+	buf = binary.LittleEndian.AppendUint64(buf, b.m)
+	buf = binary.LittleEndian.AppendUint64(buf, b.k)
+	return append(buf, b.c...), nil
+}
+
+// UnmarshalBinary replaces the filter with the one encoded in data
+func (b *counting) UnmarshalBinary(data []byte) error {
+	data, err := checkHeader(countingTag, data)
+	if err != nil {
+		return err
+	}
+	if len(data) < 16 {
+		return ErrBadFormat
+	}
+	m, k := binary.LittleEndian.Uint64(data), binary.LittleEndian.Uint64(data[8:])
+	data = data[16:]
+	if m == 0 || k == 0 || k > maxHashes || uint64(len(data)) != m {
+		return ErrBadFormat
+	}
+	c := make([]uint8, m)
+	copy(c, data)
+	b.m, b.k, b.c = m, k, c
+	return nil
+}
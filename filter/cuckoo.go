@@ -0,0 +1,256 @@
+package filter
+
+import (
+	"encoding/binary"
+	"math"
+	"math/bits"
+
+	"github.com/khezen/struct/internal/digest"
+)
+
+const (
+	bucketSize = 4
+	maxKicks   = 500
+	loadFactor = 0.95
+	seed       = 0x9e3779b97f4a7c15
+)
+
+// Cuckoo is a filter storing fingerprints in a cuckoo hash table.
+// Unlike Bloom filters it may run out of room: Add panics with ErrFull while TryAdd reports it.
+type Cuckoo interface {
+	Remover
+	TryAdd(items ...interface{}) error
+}
+
+type cuckoo struct {
+	fp     []uint16
+	mask   uint64
+	fpBits uint
+	count  int
+	rnd    uint64
+}
+
+// NewCuckoo creates a Cuckoo filter sized to hold capacity items with the given false positive rate.
+// Fingerprints are at most 16 bits long which bounds the false positive rate to about 1.2e-4.
+func NewCuckoo(capacity int, fpRate float64) Cuckoo {
+	checkParameters(capacity, fpRate)
+	buckets := uint64(math.Ceil(float64(capacity) / (bucketSize * loadFactor)))
+	if buckets&(buckets-1) != 0 {
+		buckets = 1 << bits.Len64(buckets)
+	}
+	fpBits := uint(math.Ceil(math.Log2(2 * bucketSize / fpRate)))
+	if fpBits > 16 {
+		fpBits = 16
+	}
+	return &cuckoo{make([]uint16, buckets*bucketSize), buckets - 1, fpBits, 0, seed}
+}
+
+func (c *cuckoo) locate(item interface{}) (uint64, uint16) {
+	h1, h2 := digest.Item(item)
+	fp := uint16(h2 & (1<<c.fpBits - 1))
+	if fp == 0 {
+		fp = 1
+	}
+	return h1 & c.mask, fp
+}
+
+func (c *cuckoo) alt(i uint64, fp uint16) uint64 {
+	return (i ^ uint64(fp)*0x5bd1e995) & c.mask
+}
+
+func (c *cuckoo) bucket(i uint64) []uint16 {
+	return c.fp[i*bucketSize : (i+1)*bucketSize]
+}
+
+func (c *cuckoo) put(i uint64, fp uint16) bool {
+	b := c.bucket(i)
+	for slot := range b {
+		if b[slot] == 0 {
+			b[slot] = fp
+			c.count++
+			return true
+		}
+	}
+	return false
+}
+
+func (c *cuckoo) lookup(i uint64, fp uint16) int {
+	for slot, v := range c.bucket(i) {
+		if v == fp {
+			return slot
+		}
+	}
+	return -1
+}
+
+func (c *cuckoo) random() uint64 {
+	c.rnd ^= c.rnd << 13
+	c.rnd ^= c.rnd >> 7
+	c.rnd ^= c.rnd << 17
+	return c.rnd
+}
+
+// insert stores fp in bucket i or its alternate, relocating fingerprints if needed.
+// The table is left untouched when insertion fails.
+func (c *cuckoo) insert(i uint64, fp uint16) bool {
+	if c.put(i, fp) || c.put(c.alt(i, fp), fp) {
+		return true
+	}
+	type kick struct {
+		i    uint64
+		slot uint64
+		old  uint16
+	}
+	kicks := make([]kick, 0, maxKicks)
+	if c.random()&1 == 1 {
+		i = c.alt(i, fp)
+	}
+	for n := 0; n < maxKicks; n++ {
+		slot := c.random() % bucketSize
+		b := c.bucket(i)
+		kicks = append(kicks, kick{i, slot, b[slot]})
+		fp, b[slot] = b[slot], fp
+		i = c.alt(i, fp)
+		if c.put(i, fp) {
+			return true
+		}
+	}
+	for n := len(kicks) - 1; n >= 0; n-- {
+		c.bucket(kicks[n].i)[kicks[n].slot] = kicks[n].old
+	}
+	return false
+}
+
+func (c *cuckoo) Add(items ...interface{}) {
+	if err := c.TryAdd(items...); err != nil {
+		panic(err)
+	}
+}
+
+// TryAdd adds items to the filter and returns ErrFull if one of them does not fit.
+// Items preceding the one which did not fit remain in the filter.
+func (c *cuckoo) TryAdd(items ...interface{}) error {
+	for _, item := range items {
+		i, fp := c.locate(item)
+		if !c.insert(i, fp) {
+			return ErrFull
+		}
+	}
+	return nil
+}
+
+func (c *cuckoo) Has(items ...interface{}) bool {
+	for _, item := range items {
+		i, fp := c.locate(item)
+		if c.lookup(i, fp) < 0 && c.lookup(c.alt(i, fp), fp) < 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// Remove removes items from the filter. Items which are not in the filter are ignored.
+func (c *cuckoo) Remove(items ...interface{}) {
+	for _, item := range items {
+		i, fp := c.locate(item)
+		for _, j := range [2]uint64{i, c.alt(i, fp)} {
+			if slot := c.lookup(j, fp); slot >= 0 {
+				c.bucket(j)[slot] = 0
+				c.count--
+				break
+			}
+		}
+	}
+}
+
+// Len returns the number of fingerprints stored in the filter
+func (c *cuckoo) Len() int {
+	return c.count
+}
+
+func (c *cuckoo) Clear() {
+	for i := range c.fp {
+		c.fp[i] = 0
+	}
+	c.count = 0
+}
+
+func (c *cuckoo) IsEmpty() bool {
+	return c.count == 0
+}
+
+// Merge adds every fingerprint of the given filter into this one.
+// The filter is left untouched if it returns ErrFull.
+func (c *cuckoo) Merge(t Interface) error {
+	t, release := operand(t)
+	defer release()
+	o, ok := t.(*cuckoo)
+	if !ok || o.mask != c.mask || o.fpBits != c.fpBits {
+		return ErrIncompatible
+	}
+	merged := c.Copy().(*cuckoo)
+	for n, fp := range o.fp {
+		if fp != 0 && !merged.insert(uint64(n/bucketSize), fp) {
+			return ErrFull
+		}
+	}
+	*c = *merged
+	return nil
+}
+
+// Union returns a new filter holding items of both filters
+func (c *cuckoo) Union(t Interface) (Interface, error) {
+	return union(c, t)
+}
+
+// FalsePositiveRate estimates the current probability of a false positive
+func (c *cuckoo) FalsePositiveRate() float64 {
+	load := float64(c.count) / float64(len(c.fp))
+	return 1 - math.Pow(1-1/float64(uint64(1)<<c.fpBits), 2*bucketSize*load)
+}
+
+func (c *cuckoo) Copy() Interface {
+	fp := make([]uint16, len(c.fp))
+	copy(fp, c.fp)
+	return &cuckoo{fp, c.mask, c.fpBits, c.count, c.rnd}
+}
+
+// MarshalBinary encodes the filter in a portable little endian format
+func (c *cuckoo) MarshalBinary() ([]byte, error) {
+	buf := header(cuckooTag, 9+2*len(c.fp))
+	buf = binary.LittleEndian.AppendUint64(buf, c.mask+1)
+	buf = append(buf, byte(c.fpBits))
+	for _, fp := range c.fp {
+		buf = binary.LittleEndian.AppendUint16(buf, fp)
+	}
+	return buf, nil
+}
+
+// UnmarshalBinary replaces the filter with the one encoded in data
+func (c *cuckoo) UnmarshalBinary(data []byte) error {
+	data, err := checkHeader(cuckooTag, data)
+	if err != nil {
+		return err
+	}
+	if len(data) < 9 {
+		return ErrBadFormat
+	}
+	buckets, fpBits := binary.LittleEndian.Uint64(data), uint(data[8])
+	data = data[9:]
+	if buckets == 0 || buckets&(buckets-1) != 0 || fpBits == 0 || fpBits > 16 ||
+		len(data)%(bucketSize*2) != 0 || uint64(len(data)/(bucketSize*2)) != buckets {
+		return ErrBadFormat
+	}
+	fps, count := make([]uint16, buckets*bucketSize), 0
+	for n := range fps {
+		fps[n] = binary.LittleEndian.Uint16(data[2*n:])
+		if fps[n] >= 1<<fpBits {
+			return ErrBadFormat
+		}
+		if fps[n] != 0 {
+			count++
+		}
+	}
+	c.fp, c.mask, c.fpBits, c.count, c.rnd = fps, buckets-1, fpBits, count, seed
+	return nil
+}
@@ -0,0 +1,142 @@
+package filter
+
+import "sync"
+
+type filterSync struct {
+	f Interface
+	l sync.RWMutex
+}
+
+type removerSync struct {
+	filterSync
+}
+
+type cuckooSync struct {
+	removerSync
+}
+
+// NewBloomSync creates a threadsafe Bloom filter
+func NewBloomSync(capacity int, fpRate float64) Interface {
+	return wrap(NewBloom(capacity, fpRate))
+}
+
+// NewCountingBloomSync creates a threadsafe counting Bloom filter
+func NewCountingBloomSync(capacity int, fpRate float64) Remover {
+	return wrap(NewCountingBloom(capacity, fpRate)).(Remover)
+}
+
+// NewCuckooSync creates a threadsafe Cuckoo filter
+func NewCuckooSync(capacity int, fpRate float64) Cuckoo {
+	return wrap(NewCuckoo(capacity, fpRate)).(Cuckoo)
+}
+
+func wrap(f Interface) Interface {
+	switch f.(type) {
+	case Cuckoo:
+		return &cuckooSync{removerSync{filterSync{f: f}}}
+	case Remover:
+		return &removerSync{filterSync{f: f}}
+	default:
+		return &filterSync{f: f}
+	}
+}
+
+// operand returns the filter behind t, read locked if t is threadsafe, and the function releasing it
+func operand(t Interface) (Interface, func()) {
+	if conv, ok := t.(interface{ sync() *filterSync }); ok {
+		s := conv.sync()
+		s.l.RLock()
+		return s.f, s.l.RUnlock
+	}
+	return t, func() {}
+}
+
+func (s *filterSync) sync() *filterSync {
+	return s
+}
+
+func (s *filterSync) Add(items ...interface{}) {
+	s.l.Lock()
+	defer s.l.Unlock()
+	s.f.Add(items...)
+}
+
+func (s *filterSync) Has(items ...interface{}) bool {
+	s.l.RLock()
+	defer s.l.RUnlock()
+	return s.f.Has(items...)
+}
+
+func (s *filterSync) Len() int {
+	s.l.RLock()
+	defer s.l.RUnlock()
+	return s.f.Len()
+}
+
+func (s *filterSync) Clear() {
+	s.l.Lock()
+	defer s.l.Unlock()
+	s.f.Clear()
+}
+
+func (s *filterSync) IsEmpty() bool {
+	s.l.RLock()
+	defer s.l.RUnlock()
+	return s.f.IsEmpty()
+}
+
+func (s *filterSync) Merge(t Interface) error {
+	s.l.Lock()
+	defer s.l.Unlock()
+	if conv, ok := t.(interface{ sync() *filterSync }); ok && conv.sync() == s {
+		return s.f.Merge(s.f)
+	}
+	return s.f.Merge(t)
+}
+
+func (s *filterSync) Union(t Interface) (Interface, error) {
+	return union(s, t)
+}
+
+func (s *filterSync) FalsePositiveRate() float64 {
+	s.l.RLock()
+	defer s.l.RUnlock()
+	return s.f.FalsePositiveRate()
+}
+
+func (s *filterSync) Copy() Interface {
+	s.l.RLock()
+	defer s.l.RUnlock()
+	return wrap(s.f.Copy())
+}
+
+func (s *filterSync) MarshalBinary() ([]byte, error) {
+	s.l.RLock()
+	defer s.l.RUnlock()
+	return s.f.MarshalBinary()
+}
+
+func (s *filterSync) UnmarshalBinary(data []byte) error {
+	s.l.Lock()
+	defer s.l.Unlock()
+	return s.f.UnmarshalBinary(data)
+}
+
+func (s *removerSync) Remove(items ...interface{}) {
+	s.l.Lock()
+	defer s.l.Unlock()
+	s.f.(Remover).Remove(items...)
+}
+
+func (s *cuckooSync) TryAdd(items ...interface{}) error {
+	s.l.Lock()
+	defer s.l.Unlock()
+	return s.f.(Cuckoo).TryAdd(items...)
+}
+
+// Snapshot returns a non-threadsafe copy of the filter consistent at the time of the call.
+func (s *filterSync) Snapshot() Interface {
+	s.l.RLock()
+	defer s.l.RUnlock()
+	return s.f.Copy()
+}
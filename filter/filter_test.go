@@ -0,0 +1,327 @@
+package filter
+
+import (
+	"fmt"
+	"math"
+	"sync"
+	"testing"
+)
+
+type point struct {
+	x, y int
+}
+
+func constructors() []func() Interface {
+	return []func() Interface{
+		func() Interface { return NewBloom(1000, 0.01) },
+		func() Interface { return NewBloomSync(1000, 0.01) },
+		func() Interface { return NewCountingBloom(1000, 0.01) },
+		func() Interface { return NewCountingBloomSync(1000, 0.01) },
+		func() Interface { return NewCuckoo(1000, 0.01) },
+		func() Interface { return NewCuckooSync(1000, 0.01) },
+	}
+}
+
+func TestAddHas(t *testing.T) {
+	items := []interface{}{1, "1", int64(1), 2.5, true, nil, []byte("b"), point{1, 2}, uint8(7)}
+	for _, newFilter := range constructors() {
+		f := newFilter()
+		if !f.IsEmpty() || f.Has(1) {
+			t.Errorf("Expected %v. Got %v.", true, f.IsEmpty())
+		}
+		f.Add(items...)
+		if !f.Has(items...) {
+			t.Errorf("Expected %v. Got %v.", true, false)
+		}
+		if f.IsEmpty() {
+			t.Errorf("Expected %v. Got %v.", false, true)
+		}
+		f.Clear()
+		if !f.IsEmpty() || f.Len() != 0 {
+			t.Errorf("Expected %v. Got %v.", 0, f.Len())
+		}
+	}
+}
+
+func TestFalsePositiveRate(t *testing.T) {
+	cases := []struct {
+		f      Interface
+		fpRate float64
+	}{
+		{NewBloom(10000, 0.01), 0.01},
+		{NewBloom(10000, 0.001), 0.001},
+		{NewCountingBloom(10000, 0.01), 0.01},
+		{NewCuckoo(10000, 0.01), 0.01},
+		{NewCuckoo(10000, 0.001), 0.001},
+	}
+	for _, c := range cases {
+		for i := 0; i < 10000; i++ {
+			c.f.Add(i)
+		}
+		for i := 0; i < 10000; i++ {
+			if !c.f.Has(i) {
+				t.Errorf("Expected %v. Got %v.", true, false)
+			}
+		}
+		fp := 0
+		for i := 10000; i < 110000; i++ {
+			if c.f.Has(i) {
+				fp++
+			}
+		}
+		if measured := float64(fp) / 100000; measured > 2*c.fpRate {
+			t.Errorf("Expected %v. Got %v.", c.fpRate, measured)
+		}
+		if estimated := c.f.FalsePositiveRate(); estimated > 2*c.fpRate {
+			t.Errorf("Expected %v. Got %v.", c.fpRate, estimated)
+		}
+	}
+}
+
+func TestLen(t *testing.T) {
+	for _, newFilter := range constructors() {
+		f := newFilter()
+		for i := 0; i < 500; i++ {
+			f.Add(i, i)
+		}
+		if _, ok := f.(Cuckoo); ok {
+			if f.Len() != 1000 {
+				t.Errorf("Expected %v. Got %v.", 1000, f.Len())
+			}
+		} else if math.Abs(float64(f.Len()-500)) > 25 {
+			t.Errorf("Expected %v. Got %v.", 500, f.Len())
+		}
+	}
+}
+
+func TestRemove(t *testing.T) {
+	cases := []Remover{
+		NewCountingBloom(1000, 0.01),
+		NewCountingBloomSync(1000, 0.01),
+		NewCuckoo(1000, 0.01),
+		NewCuckooSync(1000, 0.01),
+	}
+	for _, f := range cases {
+		for i := 0; i < 100; i++ {
+			f.Add(i)
+		}
+		f.Remove(1000)
+		for i := 0; i < 50; i++ {
+			f.Remove(i)
+		}
+		for i := 50; i < 100; i++ {
+			if !f.Has(i) {
+				t.Errorf("Expected %v. Got %v.", true, false)
+			}
+		}
+		removed := 0
+		for i := 0; i < 50; i++ {
+			if !f.Has(i) {
+				removed++
+			}
+		}
+		if removed < 45 {
+			t.Errorf("Expected %v. Got %v.", 50, removed)
+		}
+		for i := 50; i < 100; i++ {
+			f.Remove(i)
+		}
+		if !f.IsEmpty() {
+			t.Errorf("Expected %v. Got %v.", true, false)
+		}
+	}
+}
+
+func TestCuckooFull(t *testing.T) {
+	f := NewCuckoo(8, 0.01)
+	var err error
+	n := 0
+	for ; err == nil; n++ {
+		err = f.TryAdd(n)
+	}
+	if err != ErrFull {
+		t.Errorf("Expected %v. Got %v.", ErrFull, err)
+	}
+	if f.Len() != n-1 {
+		t.Errorf("Expected %v. Got %v.", n-1, f.Len())
+	}
+	for i := 0; i < n-1; i++ {
+		if !f.Has(i) {
+			t.Errorf("Expected %v. Got %v.", true, false)
+		}
+	}
+	defer func() {
+		if r := recover(); r != ErrFull {
+			t.Errorf("Expected %v. Got %v.", ErrFull, r)
+		}
+	}()
+	f.Add(n)
+}
+
+func TestUnion(t *testing.T) {
+	for _, newFilter := range constructors() {
+		a, b := newFilter(), newFilter()
+		a.Add(1, 2, 3)
+		b.Add("a", "b")
+		u, err := a.Union(b)
+		if err != nil {
+			t.Errorf("Expected %v. Got %v.", nil, err)
+			continue
+		}
+		if !u.Has(1, 2, 3, "a", "b") {
+			t.Errorf("Expected %v. Got %v.", true, false)
+		}
+		if a.Has("a") || b.Has(1) {
+			t.Errorf("Expected %v. Got %v.", false, true)
+		}
+		if err = a.Merge(a); err != nil || !a.Has(1, 2, 3) {
+			t.Errorf("Expected %v. Got %v.", nil, err)
+		}
+	}
+}
+
+func TestUnionIncompatible(t *testing.T) {
+	cases := []struct {
+		a, b Interface
+	}{
+		{NewBloom(1000, 0.01), NewBloom(2000, 0.01)},
+		{NewBloom(1000, 0.01), NewCountingBloom(1000, 0.01)},
+		{NewCountingBloom(1000, 0.01), NewCountingBloom(1000, 0.1)},
+		{NewCuckoo(1000, 0.01), NewCuckoo(1000, 0.0001)},
+		{NewCuckooSync(1000, 0.01), NewBloomSync(1000, 0.01)},
+	}
+	for _, c := range cases {
+		if _, err := c.a.Union(c.b); err != ErrIncompatible {
+			t.Errorf("Expected %v. Got %v.", ErrIncompatible, err)
+		}
+	}
+}
+
+func TestSerialization(t *testing.T) {
+	for _, newFilter := range constructors() {
+		f := newFilter()
+		f.Add(1, "2", 3.0)
+		data, err := f.MarshalBinary()
+		if err != nil {
+			t.Errorf("Expected %v. Got %v.", nil, err)
+		}
+		decoded := newFilter()
+		decoded.Add(42)
+		if err = decoded.UnmarshalBinary(data); err != nil {
+			t.Errorf("Expected %v. Got %v.", nil, err)
+		}
+		if !decoded.Has(1, "2", 3.0) || decoded.Len() != f.Len() {
+			t.Errorf("Expected %v. Got %v.", f.Len(), decoded.Len())
+		}
+		if err = decoded.UnmarshalBinary(data[:len(data)-1]); err != ErrBadFormat {
+			t.Errorf("Expected %v. Got %v.", ErrBadFormat, err)
+		}
+	}
+	cases := []struct {
+		data     []byte
+		expected []byte
+	}{
+		{mustMarshal(NewBloom(1, 0.5)), []byte{'B', 1, 2, 0, 0, 0, 0, 0, 0, 0, 1, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0}},
+		{mustMarshal(NewCountingBloom(1, 0.5)), []byte{'C', 1, 2, 0, 0, 0, 0, 0, 0, 0, 1, 0, 0, 0, 0, 0, 0, 0, 0, 0}},
+		{mustMarshal(NewCuckoo(1, 0.5)), []byte{'K', 1, 1, 0, 0, 0, 0, 0, 0, 0, 4, 0, 0, 0, 0, 0, 0, 0, 0}},
+	}
+	for _, c := range cases {
+		if string(c.data) != string(c.expected) {
+			t.Errorf("Expected %v. Got %v.", c.expected, c.data)
+		}
+	}
+	if err := NewBloom(1, 0.5).UnmarshalBinary(mustMarshal(NewCuckoo(1, 0.5))); err != ErrBadFormat {
+		t.Errorf("Expected %v. Got %v.", ErrBadFormat, err)
+	}
+}
+
+func TestMalformedHeaders(t *testing.T) {
+	cases := []struct {
+		f    Interface
+		data []byte
+	}{
+		// m = 2^64-11 overflows the size of the words
+		{NewBloom(1, 0.5), []byte{'B', 1, 0xf5, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 3, 0, 0, 0, 0, 0, 0, 0}},
+		// k = 2^64-1
+		{NewBloom(1, 0.5), []byte{'B', 1, 2, 0, 0, 0, 0, 0, 0, 0, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0, 0, 0, 0, 0, 0, 0, 0}},
+		{NewBloom(1, 0.5), []byte{'B', 1, 2, 0, 0, 0, 0, 0, 0, 0, 65, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0}},
+		{NewCountingBloom(1, 0.5), []byte{'C', 1, 2, 0, 0, 0, 0, 0, 0, 0, 65, 0, 0, 0, 0, 0, 0, 0, 0, 0}},
+		// buckets = 2^62 overflows the size of the fingerprints
+		{NewCuckoo(1, 0.5), []byte{'K', 1, 0, 0, 0, 0, 0, 0, 0, 0x40, 4}},
+		{NewCuckoo(1, 0.5), []byte{'K', 1, 1, 0, 0, 0, 0, 0, 0, 0, 4, 0, 0, 0, 0, 0, 0, 0, 0, 0}},
+	}
+	for _, c := range cases {
+		if err := c.f.UnmarshalBinary(c.data); err != ErrBadFormat {
+			t.Errorf("Expected %v. Got %v.", ErrBadFormat, err)
+		}
+		c.f.Add(1)
+		if !c.f.Has(1) {
+			t.Errorf("Expected %v. Got %v.", true, false)
+		}
+	}
+	if f := NewBloom(1, 1e-30); f.(*bloom).k != maxHashes {
+		t.Errorf("Expected %v. Got %v.", maxHashes, f.(*bloom).k)
+	}
+}
+
+func mustMarshal(f Interface) []byte {
+	data, err := f.MarshalBinary()
+	if err != nil {
+		panic(err)
+	}
+	return data
+}
+
+func TestBadParameters(t *testing.T) {
+	cases := []struct {
+		capacity int
+		fpRate   float64
+	}{
+		{0, 0.01},
+		{100, 0},
+		{100, 1},
+	}
+	for _, c := range cases {
+		func() {
+			defer func() {
+				if r := recover(); r != ErrBadParameters {
+					t.Errorf("Expected %v. Got %v.", ErrBadParameters, r)
+				}
+			}()
+			NewBloom(c.capacity, c.fpRate)
+		}()
+	}
+}
+
+func TestConcurrency(t *testing.T) {
+	a, b := NewCuckooSync(10000, 0.01), NewCuckooSync(10000, 0.01)
+	var wg sync.WaitGroup
+	for g := 0; g < 4; g++ {
+		wg.Add(1)
+		go func(g int) {
+			defer wg.Done()
+			for i := 0; i < 500; i++ {
+				item := fmt.Sprintf("%d-%d", g, i)
+				a.Add(item)
+				b.Has(item)
+				a.Merge(b)
+			}
+		}(g)
+	}
+	wg.Wait()
+	if a.Len() != 2000 {
+		t.Errorf("Expected %v. Got %v.", 2000, a.Len())
+	}
+}
+
+func TestSnapshot(t *testing.T) {
+	for _, f := range []Interface{NewBloomSync(100, 0.01), NewCountingBloomSync(100, 0.01), NewCuckooSync(100, 0.01)} {
+		f.Add(1)
+		snapshot := f.(interface{ Snapshot() Interface }).Snapshot()
+		f.Add(2)
+		snapshot.Add(3)
+		if _, ok := snapshot.(interface{ sync() *filterSync }); ok || !snapshot.Has(1, 3) || snapshot.Has(2) || f.Has(3) {
+			t.Errorf("Expected %v. Got %v.", false, snapshot.Has(2))
+		}
+	}
+}
@@ -0,0 +1,53 @@
+// Package filter provides approximate membership structures: Bloom filters,
+// counting Bloom filters and Cuckoo filters. Filters never report false
+// negatives but may report false positives at a configurable rate. Their
+// methods are named after set.Interface, and each filter comes with a
+// threadsafe variant.
+package filter
+
+import (
+	"encoding"
+	"errors"
+)
+
+// Interface describes functions every filter exposes
+type Interface interface {
+	Add(items ...interface{})
+	Has(items ...interface{}) bool
+
+	Len() int
+	Clear()
+	IsEmpty() bool
+
+	Merge(Interface) error
+	Union(Interface) (Interface, error)
+	FalsePositiveRate() float64
+	Copy() Interface
+
+	encoding.BinaryMarshaler
+	encoding.BinaryUnmarshaler
+}
+
+// Remover is a filter which supports removing items. Removing an item which
+// was never added may remove another one sharing its fingerprint.
+type Remover interface {
+	Interface
+	Remove(items ...interface{})
+}
+
+var (
+	// ErrBadParameters - capacity must be positive and the false positive rate in ]0, 1[
+	ErrBadParameters = errors.New("ErrBadParameters - capacity must be positive and the false positive rate in ]0, 1[")
+	// ErrIncompatible - filters must be of the same kind and built with the same parameters
+	ErrIncompatible = errors.New("ErrIncompatible - filters must be of the same kind and built with the same parameters")
+	// ErrFull - no room left for the item in the Cuckoo filter
+	ErrFull = errors.New("ErrFull - no room left for the item in the Cuckoo filter")
+	// ErrBadFormat - data is not a serialized filter of that kind
+	ErrBadFormat = errors.New("ErrBadFormat - data is not a serialized filter of that kind")
+)
+
+func checkParameters(capacity int, fpRate float64) {
+	if capacity <= 0 || fpRate <= 0 || fpRate >= 1 {
+		panic(ErrBadParameters)
+	}
+}
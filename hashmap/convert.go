@@ -0,0 +1,77 @@
+package hashmap
+
+import (
+	"errors"
+	"reflect"
+
+	"github.com/khezen/struct/collection"
+	"github.com/khezen/struct/internal/convert"
+)
+
+// ErrNotMap - the value is not a map or a pointer to a map
+var ErrNotMap = errors.New("ErrNotMap - the value is not a map or a pointer to a map")
+
+// FromMap creates a hashmap with the entries of any map, such as a
+// map[string]int, or of the one a pointer points to. It fails with ErrNotMap
+// for other values.
+func FromMap(m interface{}) (Interface, error) {
+	pairs, err := entries(m)
+	if err != nil {
+		return nil, err
+	}
+	return New(pairs...), nil
+}
+
+// FromMapSync creates a thread safe hashmap with the entries of any map.
+func FromMapSync(m interface{}) (Interface, error) {
+	pairs, err := entries(m)
+	if err != nil {
+		return nil, err
+	}
+	return NewSync(pairs...), nil
+}
+
+// entries returns the keys and values of m as k, v, k, v...
+func entries(m interface{}) ([]interface{}, error) {
+	v := reflect.ValueOf(m)
+	if v.Kind() == reflect.Ptr && !v.IsNil() {
+		v = v.Elem()
+	}
+	if v.Kind() != reflect.Map {
+		return nil, ErrNotMap
+	}
+	pairs := make([]interface{}, 0, 2*v.Len())
+	for it := v.MapRange(); it.Next(); {
+		pairs = append(pairs, it.Key().Interface(), it.Value().Interface())
+	}
+	return pairs, nil
+}
+
+// ToMap replaces the map ptr points to, such as a *map[string]int, with the
+// entries of m. It fails with collection.ErrType, leaving the map unchanged,
+// when a key or a value does not fit.
+func ToMap(m ReadOnly, ptr interface{}) error {
+	dst, ok := convert.Target(ptr, reflect.Map)
+	if !ok {
+		return ErrNotMap
+	}
+	t := dst.Type()
+	res := reflect.MakeMapWithSize(t, m.Len())
+	var err error
+	m.Each(func(k, v interface{}) bool {
+		var key, value reflect.Value
+		if key, err = convert.Value(k, t.Key(), collection.ErrType); err != nil {
+			return false
+		}
+		if value, err = convert.Value(v, t.Elem(), collection.ErrType); err != nil {
+			return false
+		}
+		res.SetMapIndex(key, value)
+		return true
+	})
+	if err != nil {
+		return err
+	}
+	dst.Set(res)
+	return nil
+}
@@ -0,0 +1,58 @@
+package hashmap
+
+import "reflect"
+
+// Change describes a key whose value differs between two versions of a hashmap.
+// Old is nil for added keys and New is nil for removed keys.
+type Change struct {
+	Key, Old, New interface{}
+}
+
+// Delta lists the keys added, removed and changed between two versions of a hashmap
+type Delta struct {
+	Added   []Change
+	Removed []Change
+	Changed []Change
+}
+
+// IsEmpty reports whether both versions hold the same entries.
+func (d Delta) IsEmpty() bool {
+	return len(d.Added) == 0 && len(d.Removed) == 0 && len(d.Changed) == 0
+}
+
+// Diff returns the entries of b whose key is not in a as Added, the entries of
+// a whose key is not in b as Removed, and the keys whose value differs as Changed.
+// Values are compared with reflect.DeepEqual, so they need not be comparable.
+func Diff(a, b ReadOnly) Delta {
+	var d Delta
+	b.Each(func(k, v interface{}) bool {
+		old, err := a.Get(k)
+		switch {
+		case err != nil:
+			d.Added = append(d.Added, Change{k, nil, v})
+		case !reflect.DeepEqual(old, v):
+			d.Changed = append(d.Changed, Change{k, old, v})
+		}
+		return true
+	})
+	a.Each(func(k, v interface{}) bool {
+		if !b.Has(k) {
+			d.Removed = append(d.Removed, Change{k, v, nil})
+		}
+		return true
+	})
+	return d
+}
+
+// Apply removes the removed keys from h and puts the added and changed entries.
+func Apply(h Interface, d Delta) {
+	for _, c := range d.Removed {
+		h.Remove(c.Key)
+	}
+	for _, c := range d.Added {
+		h.Put(c.Key, c.New)
+	}
+	for _, c := range d.Changed {
+		h.Put(c.Key, c.New)
+	}
+}
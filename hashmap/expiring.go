@@ -0,0 +1,289 @@
+package hashmap
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/khezen/struct/internal/expiry"
+	"github.com/khezen/struct/internal/format"
+)
+
+// Expiring is a thread safe hashmap whose entries expire after a time to live
+type Expiring interface {
+	Interface
+	PutWithTTL(k, v interface{}, ttl time.Duration)
+	OnExpire(func(k, v interface{}))
+	SetClock(now func() time.Time)
+	StartJanitor(interval time.Duration)
+	Close() error
+}
+
+type hashmapExpiring struct {
+	s        *expiry.Store
+	ttl      time.Duration
+	now      func() time.Time
+	onExpire func(k, v interface{})
+	janitor  *expiry.Janitor
+	pending  []*expiry.Entry
+	l        sync.Mutex
+}
+
+// NewExpiring creates a thread safe hashmap in which entries put with Put
+// expire after defaultTTL. A defaultTTL lower than or equal to zero means
+// those entries never expire. Expired entries are removed lazily, or
+// periodically once StartJanitor is called.
+func NewExpiring(defaultTTL time.Duration, pairs ...interface{}) Expiring {
+	return NewExpiringWithClock(defaultTTL, time.Now, pairs...)
+}
+
+// NewExpiringWithClock is like NewExpiring, but tells the current time with
+// now, including for the deadlines of the given entries.
+func NewExpiringWithClock(defaultTTL time.Duration, now func() time.Time, pairs ...interface{}) Expiring {
+	h := &hashmapExpiring{
+		s:   expiry.NewStore(),
+		ttl: defaultTTL,
+		now: now,
+	}
+	length := len(pairs)
+	for i := 0; i < length-1; i += 2 {
+		h.s.Put(pairs[i], pairs[i+1], expiry.Deadline(h.now(), defaultTTL))
+	}
+	return h
+}
+
+// lock acquires the lock and drops expired entries.
+func (h *hashmapExpiring) lock() {
+	h.l.Lock()
+	h.pending = append(h.pending, h.s.Expire(h.now())...)
+}
+
+// unlock releases the lock, then calls the expiry callback.
+func (h *hashmapExpiring) unlock() {
+	pending, f := h.pending, h.onExpire
+	h.pending = nil
+	h.l.Unlock()
+	if f != nil {
+		for _, e := range pending {
+			f(e.Key, e.Value)
+		}
+	}
+}
+
+func (h *hashmapExpiring) Get(k interface{}) (interface{}, error) {
+	h.lock()
+	defer h.unlock()
+	e, ok := h.s.Get(k)
+	if !ok {
+		return nil, fmt.Errorf("%v not found", k)
+	}
+	return e.Value, nil
+}
+
+func (h *hashmapExpiring) Put(k, v interface{}) {
+	h.PutWithTTL(k, v, h.ttl)
+}
+
+// PutWithTTL inserts or replaces k. The entry expires once ttl has elapsed.
+// A ttl lower than or equal to zero means the entry never expires.
+func (h *hashmapExpiring) PutWithTTL(k, v interface{}, ttl time.Duration) {
+	h.lock()
+	defer h.unlock()
+	h.s.Put(k, v, expiry.Deadline(h.now(), ttl))
+}
+
+func (h *hashmapExpiring) Remove(keys ...interface{}) {
+	h.lock()
+	defer h.unlock()
+	for _, k := range keys {
+		h.s.Delete(k)
+	}
+}
+
+func (h *hashmapExpiring) Has(keys ...interface{}) bool {
+	h.lock()
+	defer h.unlock()
+	for _, k := range keys {
+		if _, ok := h.s.Get(k); !ok {
+			return false
+		}
+	}
+	return true
+}
+
+func (h *hashmapExpiring) HasValue(values ...interface{}) bool {
+	h.lock()
+	defer h.unlock()
+	for _, value := range values {
+		if _, err := h.keyOf(value); err != nil {
+			return false
+		}
+	}
+	return true
+}
+
+func (h *hashmapExpiring) KeyOf(value interface{}) (interface{}, error) {
+	h.lock()
+	defer h.unlock()
+	return h.keyOf(value)
+}
+
+func (h *hashmapExpiring) keyOf(value interface{}) (k interface{}, err error) {
+	err = fmt.Errorf("%v not found", value)
+	h.s.Each(func(e *expiry.Entry) bool {
+		if e.Value == value {
+			k, err = e.Key, nil
+			return false
+		}
+		return true
+	})
+	return k, err
+}
+
+// Each calls f on a snapshot of the live entries, without holding the lock,
+// so f may call the methods of h.
+func (h *hashmapExpiring) Each(f func(k, v interface{}) bool) {
+	h.lock()
+	pairs := make([]interface{}, 0, 2*h.s.Len())
+	h.s.Each(func(e *expiry.Entry) bool {
+		pairs = append(pairs, e.Key, e.Value)
+		return true
+	})
+	h.unlock()
+	for i := 0; i < len(pairs); i += 2 {
+		if !f(pairs[i], pairs[i+1]) {
+			return
+		}
+	}
+}
+
+func (h *hashmapExpiring) Len() int {
+	h.lock()
+	defer h.unlock()
+	return h.s.Len()
+}
+
+func (h *hashmapExpiring) Clear() {
+	h.lock()
+	defer h.unlock()
+	h.s.Clear()
+}
+
+func (h *hashmapExpiring) IsEmpty() bool {
+	return h.Len() == 0
+}
+
+func (h *hashmapExpiring) IsEqual(t ReadOnly) bool {
+	if t == Interface(h) {
+		return true
+	}
+	m := make(map[interface{}]interface{}, t.Len())
+	t.Each(func(k, v interface{}) bool {
+		m[k] = v
+		return true
+	})
+	h.lock()
+	defer h.unlock()
+	if h.s.Len() != len(m) {
+		return false
+	}
+	for k, v := range m {
+		e, ok := h.s.Get(k)
+		if !ok || e.Value != v {
+			return false
+		}
+	}
+	return true
+}
+
+func (h *hashmapExpiring) String() string {
+	return format.String(h)
+}
+
+// Format prints the live pairs. With %#v, it prints the syntax of an equal
+// hashmap which does not expire.
+func (h *hashmapExpiring) Format(f fmt.State, verb rune) {
+	format.Pairs(f, verb, "hashmap.Expiring", "hashmap.NewSync", h.Each)
+}
+
+func (h *hashmapExpiring) Keys() []interface{} {
+	keys := make([]interface{}, 0)
+	h.Each(func(k, v interface{}) bool {
+		keys = append(keys, k)
+		return true
+	})
+	return keys
+}
+
+func (h *hashmapExpiring) Values() []interface{} {
+	values := make([]interface{}, 0)
+	h.Each(func(k, v interface{}) bool {
+		values = append(values, v)
+		return true
+	})
+	return values
+}
+
+// Map returns a copy of the live entries.
+func (h *hashmapExpiring) Map() map[interface{}]interface{} {
+	m := make(map[interface{}]interface{})
+	h.Each(func(k, v interface{}) bool {
+		m[k] = v
+		return true
+	})
+	return m
+}
+
+// Copy returns a new expiring hashmap with the same entries, deadlines, default
+// TTL and clock. The expiry callback and the janitor are not copied.
+func (h *hashmapExpiring) Copy() Interface {
+	h.lock()
+	defer h.unlock()
+	cpy := NewExpiringWithClock(h.ttl, h.now).(*hashmapExpiring)
+	h.s.Each(func(e *expiry.Entry) bool {
+		cpy.s.Put(e.Key, e.Value, e.Expires)
+		return true
+	})
+	return cpy
+}
+
+// OnExpire registers a callback called each time an entry expires.
+func (h *hashmapExpiring) OnExpire(f func(k, v interface{})) {
+	h.l.Lock()
+	defer h.l.Unlock()
+	h.onExpire = f
+}
+
+// SetClock replaces the function used to tell the current time. The entries
+// already present keep their deadlines.
+func (h *hashmapExpiring) SetClock(now func() time.Time) {
+	h.l.Lock()
+	defer h.l.Unlock()
+	h.now = now
+}
+
+// StartJanitor purges expired entries every interval in the background,
+// replacing any janitor already running. Call Close to stop it. A
+// non-positive interval only stops the running janitor.
+func (h *hashmapExpiring) StartJanitor(interval time.Duration) {
+	h.l.Lock()
+	defer h.l.Unlock()
+	if h.janitor != nil {
+		h.janitor.Stop()
+	}
+	h.janitor = expiry.StartJanitor(interval, func() {
+		h.lock()
+		h.unlock()
+	})
+}
+
+// Close stops the janitor, if any.
+func (h *hashmapExpiring) Close() error {
+	h.l.Lock()
+	defer h.l.Unlock()
+	if h.janitor != nil {
+		h.janitor.Stop()
+		h.janitor = nil
+	}
+	return nil
+}
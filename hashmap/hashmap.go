@@ -2,6 +2,8 @@ package hashmap
 
 import (
 	"fmt"
+
+	"github.com/khezen/struct/internal/format"
 )
 
 type hashmap struct {
@@ -93,7 +95,7 @@ func (h *hashmap) IsEmpty() bool {
 	return h.Len() == 0
 }
 
-func (h *hashmap) IsEqual(t Interface) bool {
+func (h *hashmap) IsEqual(t ReadOnly) bool {
 	// Force locking only if given set is threadsafe.
 	if conv, ok := t.(*hashmapSync); ok {
 		conv.l.RLock()
@@ -112,8 +114,16 @@ func (h *hashmap) IsEqual(t Interface) bool {
 	return equal
 }
 
+// String returns a string representation of h, sorted by keys when they are orderable
 func (h *hashmap) String() string {
-	return fmt.Sprintf("%v", h.m)
+	return format.String(h)
+}
+
+// Format implements fmt.Formatter: %v prints the pairs, sorted by keys when
+// they are orderable, %+v adds the type and the length and %#v the Go syntax.
+// A precision truncates the pairs: %.3v prints the 3 first followed by "...".
+func (h *hashmap) Format(f fmt.State, verb rune) {
+	format.Pairs(f, verb, "hashmap", "hashmap.New", h.Each)
 }
 
 func (h *hashmap) Keys() []interface{} {
@@ -134,8 +144,26 @@ func (h *hashmap) Values() []interface{} {
 	return values
 }
 
+// Map returns a copy of the pairs. Use UnsafeMap to access them without copy.
 func (h *hashmap) Map() map[interface{}]interface{} {
-	return h.m
+	m := make(map[interface{}]interface{}, len(h.m))
+	for k, v := range h.m {
+		m[k] = v
+	}
+	return m
+}
+
+// UnsafeMap returns the map backing h without copying it when h is one of the
+// hashmaps of this package, and a copy otherwise. Modifying the returned map
+// modifies h, and threadsafe hashmaps are not locked while it is used.
+func UnsafeMap(h Interface) map[interface{}]interface{} {
+	switch conv := h.(type) {
+	case *hashmap:
+		return conv.m
+	case *hashmapSync:
+		return conv.m
+	}
+	return h.Map()
 }
 
 func (h *hashmap) Copy() Interface {
@@ -0,0 +1,125 @@
+package hashmap
+
+import (
+	"fmt"
+	"sync"
+	"sync/atomic"
+
+	"github.com/khezen/struct/internal/format"
+)
+
+// hashmapCOW defines a thread safe copy-on-write hashmap. Readers work on an
+// immutable snapshot without locking while writers clone it, modify the clone
+// and publish it.
+type hashmapCOW struct {
+	snapshot atomic.Value // *hashmap, never modified once published
+	l        sync.Mutex   // serializes writers
+}
+
+// NewCOW creates a thread safe copy-on-write hashmap. Reads, including long Each
+// traversals, never block nor are blocked by writes, which cost O(n) each.
+// It suits hashmaps read far more often than they are modified.
+func NewCOW(pairs ...interface{}) Interface {
+	h := &hashmapCOW{}
+	h.snapshot.Store(New(pairs...).(*hashmap))
+	return h
+}
+
+func (h *hashmapCOW) load() *hashmap {
+	return h.snapshot.Load().(*hashmap)
+}
+
+// write applies f on a clone of the current snapshot and publishes the clone
+func (h *hashmapCOW) write(f func(clone *hashmap)) {
+	h.l.Lock()
+	defer h.l.Unlock()
+	clone := &hashmap{h.load().Map()}
+	f(clone)
+	h.snapshot.Store(clone)
+}
+
+func (h *hashmapCOW) Get(k interface{}) (interface{}, error) {
+	return h.load().Get(k)
+}
+
+func (h *hashmapCOW) Put(k, v interface{}) {
+	h.write(func(clone *hashmap) {
+		clone.Put(k, v)
+	})
+}
+
+func (h *hashmapCOW) Remove(keys ...interface{}) {
+	if len(keys) > 0 {
+		h.write(func(clone *hashmap) {
+			clone.Remove(keys...)
+		})
+	}
+}
+
+func (h *hashmapCOW) Has(keys ...interface{}) bool {
+	return h.load().Has(keys...)
+}
+
+func (h *hashmapCOW) HasValue(values ...interface{}) bool {
+	return h.load().HasValue(values...)
+}
+
+func (h *hashmapCOW) KeyOf(value interface{}) (interface{}, error) {
+	return h.load().KeyOf(value)
+}
+
+// Each traverses the snapshot taken when it is called. Writes performed
+// meanwhile, including by f, are not visited.
+func (h *hashmapCOW) Each(f func(k, v interface{}) bool) {
+	h.load().Each(f)
+}
+
+func (h *hashmapCOW) Len() int {
+	return h.load().Len()
+}
+
+func (h *hashmapCOW) Clear() {
+	h.l.Lock()
+	defer h.l.Unlock()
+	h.snapshot.Store(New().(*hashmap))
+}
+
+func (h *hashmapCOW) IsEmpty() bool {
+	return h.load().IsEmpty()
+}
+
+func (h *hashmapCOW) IsEqual(t ReadOnly) bool {
+	return h.load().IsEqual(t)
+}
+
+func (h *hashmapCOW) String() string {
+	return format.String(h)
+}
+
+func (h *hashmapCOW) Format(f fmt.State, verb rune) {
+	format.Pairs(f, verb, "hashmap.COW", "hashmap.NewCOW", h.Each)
+}
+
+func (h *hashmapCOW) Keys() []interface{} {
+	return h.load().Keys()
+}
+
+func (h *hashmapCOW) Values() []interface{} {
+	return h.load().Values()
+}
+
+func (h *hashmapCOW) Map() map[interface{}]interface{} {
+	return h.load().Map()
+}
+
+// Copy returns a copy-on-write hashmap sharing the current snapshot, in O(1).
+func (h *hashmapCOW) Copy() Interface {
+	cpy := &hashmapCOW{}
+	cpy.snapshot.Store(h.load())
+	return cpy
+}
+
+// Snapshot returns a non-threadsafe copy of h consistent at the time of the call.
+func (h *hashmapCOW) Snapshot() Interface {
+	return h.load().Copy()
+}
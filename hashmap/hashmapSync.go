@@ -1,7 +1,10 @@
 package hashmap
 
 import (
+	"fmt"
 	"sync"
+
+	"github.com/khezen/struct/internal/format"
 )
 
 type hashmapSync struct {
@@ -77,16 +80,18 @@ func (h *hashmapSync) IsEmpty() bool {
 	return h.hashmap.IsEmpty()
 }
 
-func (h *hashmapSync) IsEqual(t Interface) bool {
+func (h *hashmapSync) IsEqual(t ReadOnly) bool {
 	h.l.RLock()
 	defer h.l.RUnlock()
 	return h.hashmap.IsEqual(t)
 }
 
 func (h *hashmapSync) String() string {
-	h.l.RLock()
-	defer h.l.RUnlock()
-	return h.hashmap.String()
+	return format.String(h)
+}
+
+func (h *hashmapSync) Format(f fmt.State, verb rune) {
+	format.Pairs(f, verb, "hashmap.Sync", "hashmap.NewSync", h.Each)
 }
 
 func (h *hashmapSync) Keys() []interface{} {
@@ -111,3 +116,10 @@ func (h *hashmapSync) Copy() Interface {
 	})
 	return cpy
 }
+
+// Snapshot returns a non-threadsafe copy of h consistent at the time of the call.
+func (h *hashmapSync) Snapshot() Interface {
+	h.l.RLock()
+	defer h.l.RUnlock()
+	return h.hashmap.Copy()
+}
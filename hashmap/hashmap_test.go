@@ -1,8 +1,14 @@
 package hashmap
 
 import (
+	"errors"
 	"fmt"
+	"reflect"
 	"testing"
+	"time"
+
+	"github.com/khezen/struct/collection"
+	"github.com/khezen/struct/observer"
 )
 
 func testErr(err error, expectErr bool, t *testing.T) {
@@ -232,6 +238,30 @@ func TestString(t *testing.T) {
 	}
 }
 
+func TestFormat(t *testing.T) {
+	cases := []struct {
+		format   string
+		h        Interface
+		expected string
+	}{
+		{"%v", New("b", 2, "a", 1), "map[a:1 b:2]"},
+		{"%v", NewSync(2, "b", 1, "a"), "map[1:a 2:b]"},
+		{"%v", NewCOW("a", 1), "map[a:1]"},
+		{"%v", NewVersioned("a", 1), "map[a:1]"},
+		{"%v", NewFromStore(NewMemoryStore()), "map[]"},
+		{"%v", NewReadOnly(New("a", 1)), "map[a:1]"},
+		{"%v", NewObservable(New("a", 1)), "map[a:1]"},
+		{"%+v", NewSync("b", 2, "a", 1), "hashmap.Sync(len=2) map[a:1 b:2]"},
+		{"%#v", New("b", 2, "a", 1), `hashmap.New("a", 1, "b", 2)`},
+		{"%.1v", New("b", 2, "a", 1), "map[a:1 ...]"},
+	}
+	for _, c := range cases {
+		if got := fmt.Sprintf(c.format, c.h); got != c.expected {
+			t.Errorf("Expected %v. Got %v.", c.expected, got)
+		}
+	}
+}
+
 func TestKeys(t *testing.T) {
 	keys := []interface{}{"1", "42", "-8"}
 	cases := []struct {
@@ -317,3 +347,561 @@ func TestCopy(t *testing.T) {
 		}
 	}
 }
+
+// clock is a manual clock for deterministic expiry tests
+type clock struct {
+	t time.Time
+}
+
+func (c *clock) now() time.Time {
+	return c.t
+}
+
+func TestExpiringClock(t *testing.T) {
+	clk := &clock{time.Unix(0, 0)}
+	h := NewExpiringWithClock(time.Minute, clk.now, "1", 1)
+	clk.t = clk.t.Add(59 * time.Second)
+	if !h.Has("1") {
+		t.Errorf("Expected %v. Got %v.", true, false)
+	}
+	clk.t = clk.t.Add(2 * time.Second)
+	if h.Has("1") {
+		t.Errorf("Expected %v. Got %v.", false, true)
+	}
+	h.PutWithTTL("2", 2, time.Second)
+	cpy := h.Copy().(Expiring)
+	cpy.SetClock(clk.now)
+	clk.t = clk.t.Add(10 * time.Second)
+	if h.Has("2") || cpy.Has("2") {
+		t.Errorf("Expected %v. Got %v.", false, true)
+	}
+	h.Put("3", 3)
+	h.Each(func(k, v interface{}) bool {
+		if !h.Has(k) || h.Len() != 1 {
+			t.Errorf("Expected %v. Got %v.", k, h)
+		}
+		return true
+	})
+	h.StartJanitor(0)
+	h.StartJanitor(-time.Second)
+	testErr(h.Close(), false, t)
+}
+
+func TestExpiring(t *testing.T) {
+	clk := &clock{time.Unix(0, 0)}
+	h := NewExpiringWithClock(time.Minute, clk.now, "1", 1)
+	expired := make(map[interface{}]interface{})
+	h.OnExpire(func(k, v interface{}) {
+		expired[k] = v
+	})
+	h.Put("1", 1)
+	h.PutWithTTL("42", 42, time.Second)
+	h.PutWithTTL("-8", -8, 0)
+	if h.Len() != 3 {
+		t.Errorf("Expected 3. Got %v.", h.Len())
+	}
+	clk.t = clk.t.Add(time.Second)
+	if h.Len() != 2 || h.Has("42") || h.HasValue(42) {
+		t.Errorf("Expected 42 to be expired. Got %v.", h)
+	}
+	count := 0
+	h.Each(func(k, v interface{}) bool {
+		count++
+		return true
+	})
+	if count != 2 {
+		t.Errorf("Expected 2. Got %v.", count)
+	}
+	if expired["42"] != 42 {
+		t.Errorf("Expected callback for 42. Got %v.", expired)
+	}
+	clk.t = clk.t.Add(time.Hour)
+	if _, err := h.Get("1"); err == nil {
+		t.Error("Expected 1 to be expired")
+	}
+	if v, err := h.Get("-8"); err != nil || v != -8 {
+		t.Errorf("Expected -8. Got %v.", v)
+	}
+	if !h.IsEqual(New("-8", -8)) || !New("-8", -8).IsEqual(h) {
+		t.Errorf("Expected %v. Got %v.", New("-8", -8), h)
+	}
+}
+
+func TestExpiringMethods(t *testing.T) {
+	clk := &clock{time.Unix(0, 0)}
+	h := NewExpiring(time.Second)
+	h.SetClock(clk.now)
+	h.Put("1", 1)
+	h.PutWithTTL("42", 42, time.Hour)
+	cpy := h.Copy()
+	if !cpy.IsEqual(h) {
+		t.Errorf("Expected %v. Got %v.", h, cpy)
+	}
+	if k, err := h.KeyOf(42); err != nil || k != "42" {
+		t.Errorf("Expected 42. Got %v.", k)
+	}
+	if len(h.Keys()) != 2 || len(h.Values()) != 2 {
+		t.Errorf("Expected 2 entries. Got %v.", h)
+	}
+	clk.t = clk.t.Add(time.Second)
+	if cpy.Has("1") || !cpy.Has("42") {
+		t.Errorf("Copy should keep deadlines. Got %v.", cpy)
+	}
+	if h.String() != "map[42:42]" {
+		t.Errorf("Expected map[42:42]. Got %v.", h.String())
+	}
+	h.Remove("42")
+	if !h.IsEmpty() {
+		t.Errorf("Expected empty. Got %v.", h)
+	}
+	cpy.Clear()
+	if !cpy.IsEmpty() {
+		t.Errorf("Expected empty. Got %v.", cpy)
+	}
+}
+
+func TestExpiringJanitor(t *testing.T) {
+	h := NewExpiring(time.Millisecond)
+	expired := make(chan interface{}, 1)
+	h.OnExpire(func(k, v interface{}) {
+		expired <- k
+	})
+	h.Put("1", 1)
+	h.StartJanitor(time.Millisecond)
+	defer h.Close()
+	select {
+	case k := <-expired:
+		if k != "1" {
+			t.Errorf("Expected 1. Got %v.", k)
+		}
+	case <-time.After(time.Second):
+		t.Error("Janitor should have expired 1")
+	}
+	if err := h.Close(); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestMapCopy(t *testing.T) {
+	cases := []Interface{New("a", 1), NewSync("a", 1)}
+	for _, h := range cases {
+		h.Map()["a"] = 42
+		if v, _ := h.Get("a"); v != 1 {
+			t.Errorf("Expected %v. Got %v.", 1, v)
+		}
+		UnsafeMap(h)["a"] = 42
+		if v, _ := h.Get("a"); v != 42 {
+			t.Errorf("Expected %v. Got %v.", 42, v)
+		}
+		UnsafeMap(NewReadOnly(h))["a"] = 1
+		if v, _ := h.Get("a"); v != 42 {
+			t.Errorf("Expected %v. Got %v.", 42, v)
+		}
+	}
+}
+
+func TestReadOnly(t *testing.T) {
+	cases := []Interface{New("a", 1, "b", 2), NewSync("a", 1, "b", 2)}
+	for _, h := range cases {
+		r := NewReadOnly(h)
+		if NewReadOnly(r) != r {
+			t.Errorf("Expected %v. Got %v.", r, NewReadOnly(r))
+		}
+		mutators := []func(){
+			func() { r.Put("c", 3) },
+			func() { r.Remove("a") },
+			func() { r.Clear() },
+		}
+		for _, mutate := range mutators {
+			func() {
+				defer func() {
+					if r := recover(); r != collection.ErrReadOnly {
+						t.Errorf("Expected %v. Got %v.", collection.ErrReadOnly, r)
+					}
+				}()
+				mutate()
+			}()
+		}
+		if !r.IsEqual(New("a", 1, "b", 2)) || r.Len() != 2 || r.IsEmpty() || !r.Has("a") || !r.HasValue(2) {
+			t.Errorf("Expected %v. Got %v.", h, r)
+		}
+		if k, err := r.KeyOf(2); err != nil || k != "b" || r.String() != h.String() {
+			t.Errorf("Expected %v. Got %v.", "b", k)
+		}
+		h.Put("c", 3)
+		if v, err := r.Get("c"); err != nil || v != 3 || len(r.Keys()) != 3 || len(r.Values()) != 3 {
+			t.Errorf("Expected %v. Got %v.", 3, v)
+		}
+		count := 0
+		r.Each(func(k, v interface{}) bool {
+			count++
+			return true
+		})
+		r.Copy().Put("d", 4)
+		r.Map()["d"] = 4
+		if count != 3 || r.Has("d") {
+			t.Errorf("Expected %v. Got %v.", 3, count)
+		}
+	}
+}
+
+func TestCOW(t *testing.T) {
+	h := NewCOW("a", 1, "b", 2)
+	h.Put("c", 3)
+	h.Remove("a")
+	if !h.IsEqual(New("b", 2, "c", 3)) || h.Len() != 2 || h.IsEmpty() || !h.Has("b") || !h.HasValue(3) {
+		t.Errorf("Expected %v. Got %v.", New("b", 2, "c", 3), h)
+	}
+	if v, err := h.Get("c"); err != nil || v != 3 {
+		t.Errorf("Expected %v. Got %v.", 3, v)
+	}
+	if k, err := h.KeyOf(2); err != nil || k != "b" || h.String() != "map[b:2 c:3]" {
+		t.Errorf("Expected %v. Got %v.", "b", k)
+	}
+	if len(h.Keys()) != 2 || len(h.Values()) != 2 || len(h.Map()) != 2 {
+		t.Errorf("Expected %v. Got %v.", 2, len(h.Keys()))
+	}
+	cpy := h.Copy()
+	cpy.Put("d", 4)
+	if h.Has("d") || !cpy.Has("b", "d") {
+		t.Errorf("Expected %v. Got %v.", h, cpy)
+	}
+	visited := 0
+	h.Each(func(k, v interface{}) bool {
+		h.Put(v, k)
+		visited++
+		return true
+	})
+	if visited != 2 || h.Len() != 4 {
+		t.Errorf("Expected %v. Got %v.", 2, visited)
+	}
+	h.Clear()
+	if !h.IsEmpty() {
+		t.Errorf("Expected %v. Got %v.", true, false)
+	}
+}
+
+func TestSnapshot(t *testing.T) {
+	cases := []Interface{NewSync("a", 1), NewCOW("a", 1)}
+	for _, h := range cases {
+		snapshot := h.(Snapshotter).Snapshot()
+		if _, ok := snapshot.(*hashmap); !ok {
+			t.Errorf("Expected %v. Got %T.", "*hashmap", snapshot)
+		}
+		h.Put("b", 2)
+		snapshot.Put("c", 3)
+		if !snapshot.IsEqual(New("a", 1, "c", 3)) || !h.IsEqual(New("a", 1, "b", 2)) {
+			t.Errorf("Expected %v. Got %v.", New("a", 1, "c", 3), snapshot)
+		}
+	}
+}
+
+func TestObservable(t *testing.T) {
+	h := NewObservable(New("a", 1))
+	mirror := New("a", 1)
+	var ops []observer.Op
+	unsubscribe := h.Subscribe(func(e observer.Event) {
+		ops = append(ops, e.Op)
+		switch e.Op {
+		case observer.Put, observer.Replace:
+			mirror.Put(e.Item, e.New)
+		case observer.Remove:
+			mirror.Remove(e.Item)
+		case observer.Clear:
+			mirror.Clear()
+		}
+	})
+	h.Put("b", 2)
+	h.Put("a", 10)
+	h.Remove("b", "z")
+	h.Put("c", 3)
+	if !mirror.IsEqual(h) || !h.IsEqual(New("a", 10, "c", 3)) {
+		t.Errorf("Expected %v. Got %v.", h, mirror)
+	}
+	expected := []observer.Op{observer.Put, observer.Replace, observer.Remove, observer.Put}
+	if len(ops) != len(expected) {
+		t.Fatalf("Expected %v. Got %v.", expected, ops)
+	}
+	for i := range expected {
+		if ops[i] != expected[i] {
+			t.Errorf("Expected %v. Got %v.", expected, ops)
+		}
+	}
+	if v, err := h.Get("a"); err != nil || v != 10 || !h.Has("c") || !h.HasValue(3) || h.Len() != 2 || h.IsEmpty() {
+		t.Errorf("Expected %v. Got %v.", 10, v)
+	}
+	if k, err := h.KeyOf(3); err != nil || k != "c" {
+		t.Errorf("Expected %v. Got %v.", "c", k)
+	}
+	if len(h.Keys()) != 2 || len(h.Values()) != 2 || len(h.Map()) != 2 || h.Copy().Len() != 2 || !h.IsEqual(h) {
+		t.Errorf("Expected %v. Got %v.", 2, h.Len())
+	}
+	visited := 0
+	h.Each(func(k, v interface{}) bool {
+		visited++
+		return true
+	})
+	if visited != 2 || h.String() == "" {
+		t.Errorf("Expected %v. Got %v.", 2, visited)
+	}
+	h.Clear()
+	h.Clear()
+	if !mirror.IsEmpty() || len(ops) != 5 {
+		t.Errorf("Expected %v. Got %v.", 5, len(ops))
+	}
+	unsubscribe()
+	h.Put("d", 4)
+	if !mirror.IsEmpty() {
+		t.Errorf("Expected %v. Got %v.", "{}", mirror)
+	}
+}
+
+func TestObservableChan(t *testing.T) {
+	h := NewObservable(NewSync())
+	events, unsubscribe := h.SubscribeChan()
+	h.Put("a", 1)
+	h.Put("a", 2)
+	h.Remove("a")
+	expected := []observer.Event{
+		{Op: observer.Put, Item: "a", Index: -1, New: 1},
+		{Op: observer.Replace, Item: "a", Index: -1, Old: 1, New: 2},
+		{Op: observer.Remove, Item: "a", Index: -1, Old: 2},
+	}
+	for _, exp := range expected {
+		e := <-events
+		if e.Op != exp.Op || e.Item != exp.Item || e.Index != exp.Index || e.Old != exp.Old || e.New != exp.New {
+			t.Errorf("Expected %v. Got %v.", exp, e)
+		}
+	}
+	unsubscribe()
+	if _, open := <-events; open {
+		t.Errorf("Expected %v. Got %v.", false, open)
+	}
+}
+
+func TestDiff(t *testing.T) {
+	cases := []struct {
+		a, b                    Interface
+		added, removed, changed []Change
+	}{
+		{New("a", 1, "b", 2), New("b", 3, "c", 4), []Change{{"c", nil, 4}}, []Change{{"a", 1, nil}}, []Change{{"b", 2, 3}}},
+		{New("a", 1), NewSync("a", 1), nil, nil, nil},
+		{NewSync(), New("a", 1), []Change{{"a", nil, 1}}, nil, nil},
+	}
+	for _, c := range cases {
+		d := Diff(c.a, c.b)
+		got := [][]Change{d.Added, d.Removed, d.Changed}
+		for i, expected := range [][]Change{c.added, c.removed, c.changed} {
+			if len(got[i]) != len(expected) {
+				t.Errorf("Expected %v. Got %v.", expected, got[i])
+				continue
+			}
+			for j := range expected {
+				if got[i][j] != expected[j] {
+					t.Errorf("Expected %v. Got %v.", expected, got[i])
+				}
+			}
+		}
+		if d.IsEmpty() != (len(c.added)+len(c.removed)+len(c.changed) == 0) {
+			t.Errorf("Expected %v. Got %v.", !d.IsEmpty(), d.IsEmpty())
+		}
+		Apply(c.a, d)
+		if !c.a.IsEqual(c.b) {
+			t.Errorf("Expected %v. Got %v.", c.b, c.a)
+		}
+	}
+}
+
+func TestDiffSlices(t *testing.T) {
+	a := New("a", []int{1}, "b", []int{2})
+	d := Diff(a, New("a", []int{1}, "b", []int{3}))
+	expected := []Change{{"b", []int{2}, []int{3}}}
+	if len(d.Added) != 0 || len(d.Removed) != 0 || !reflect.DeepEqual(d.Changed, expected) {
+		t.Errorf("Expected %v. Got %v.", expected, d)
+	}
+	Apply(a, d)
+	if v, _ := a.Get("b"); !reflect.DeepEqual(v, []int{3}) {
+		t.Errorf("Expected %v. Got %v.", []int{3}, v)
+	}
+}
+
+func TestNewFromStore(t *testing.T) {
+	h := NewFromStore(NewMemoryStore())
+	h.Put("a", 1)
+	h.Put("b", 2)
+	h.Put("c", 3)
+	h.Remove("c", "z")
+	if !h.IsEqual(New("a", 1, "b", 2)) || h.IsEqual(New("a", 1, "b", 3)) || h.IsEqual(New()) {
+		t.Errorf("Expected %v. Got %v.", New("a", 1, "b", 2), h)
+	}
+	if v, err := h.Get("a"); err != nil || v != 1 {
+		t.Errorf("Expected %v. Got %v.", 1, v)
+	}
+	_, err := h.Get("z")
+	testErr(err, true, t)
+	if !h.Has("a", "b") || h.Has("a", "z") || !h.HasValue(1, 2) || h.HasValue(3) {
+		t.Errorf("Expected %v. Got %v.", true, false)
+	}
+	if k, err := h.KeyOf(2); err != nil || k != "b" {
+		t.Errorf("Expected %v. Got %v.", "b", k)
+	}
+	if len(h.Keys()) != 2 || len(h.Values()) != 2 || len(h.Map()) != 2 || h.String() != "map[a:1 b:2]" {
+		t.Errorf("Expected %v. Got %v.", 2, h.Len())
+	}
+	cpy := h.Copy()
+	h.Each(func(k, v interface{}) bool {
+		return false
+	})
+	h.Clear()
+	if !h.IsEmpty() || cpy.Len() != 2 {
+		t.Errorf("Expected %v. Got %v.", 0, h.Len())
+	}
+}
+
+func TestVersioned(t *testing.T) {
+	h := NewVersioned("a", 1)
+	h.Put("b", 2)   // 1
+	h.Put("a", 10)  // 2
+	h.Remove("b")   // 3
+	h.Remove("z")   // no version
+	h.Put("c", 3)   // 4
+	h.Clear()       // 5
+	h.Put("a", 100) // 6
+	if h.Version() != 6 {
+		t.Errorf("Expected %v. Got %v.", 6, h.Version())
+	}
+	getCases := []struct {
+		k, expected interface{}
+		version     uint64
+		expectErr   bool
+	}{
+		{"a", 1, 0, false},
+		{"a", 1, 1, false},
+		{"a", 10, 2, false},
+		{"a", nil, 5, true},
+		{"a", 100, 6, false},
+		{"b", nil, 0, true},
+		{"b", 2, 2, false},
+		{"b", nil, 3, true},
+		{"a", nil, 7, true},
+	}
+	for _, c := range getCases {
+		v, err := h.GetAt(c.k, c.version)
+		testErr(err, c.expectErr, t)
+		if v != c.expected {
+			t.Errorf("Expected %v. Got %v.", c.expected, v)
+		}
+	}
+	snapshotCases := []struct {
+		version   uint64
+		expected  Interface
+		expectErr bool
+	}{
+		{0, New("a", 1), false},
+		{2, New("a", 10, "b", 2), false},
+		{4, New("a", 10, "c", 3), false},
+		{5, New(), false},
+		{6, New("a", 100), false},
+		{7, nil, true},
+	}
+	for _, c := range snapshotCases {
+		s, err := h.SnapshotAt(c.version)
+		testErr(err, c.expectErr, t)
+		if err == nil && !s.IsEqual(c.expected) {
+			t.Errorf("Expected %v. Got %v.", c.expected, s)
+		}
+	}
+	expected := []Revision{{0, 1, false}, {2, 10, false}, {5, nil, true}, {6, 100, false}}
+	if history := h.History("a"); fmt.Sprint(history) != fmt.Sprint(expected) {
+		t.Errorf("Expected %v. Got %v.", expected, history)
+	}
+	h.GC(4)
+	h.GC(1)
+	if h.Watermark() != 4 {
+		t.Errorf("Expected %v. Got %v.", 4, h.Watermark())
+	}
+	_, err := h.GetAt("a", 3)
+	if err != ErrVersionCollected {
+		t.Errorf("Expected %v. Got %v.", ErrVersionCollected, err)
+	}
+	if s, _ := h.SnapshotAt(4); !s.IsEqual(New("a", 10, "c", 3)) {
+		t.Errorf("Expected %v. Got %v.", New("a", 10, "c", 3), s)
+	}
+	if history := h.History("b"); len(history) != 0 {
+		t.Errorf("Expected %v. Got %v.", 0, len(history))
+	}
+	h.GC(100)
+	expected = []Revision{{6, 100, false}}
+	if history := h.History("a"); fmt.Sprint(history) != fmt.Sprint(expected) {
+		t.Errorf("Expected %v. Got %v.", expected, history)
+	}
+	if history := h.History("c"); len(history) != 0 || h.Watermark() != 6 {
+		t.Errorf("Expected %v. Got %v.", 0, len(history))
+	}
+	if !h.IsEqual(New("a", 100)) || !h.IsEqual(h) || !h.Copy().IsEqual(h) || !h.(Snapshotter).Snapshot().IsEqual(h) {
+		t.Errorf("Expected %v. Got %v.", New("a", 100), h)
+	}
+	if v, err := h.Get("a"); err != nil || v != 100 || !h.Has("a") || !h.HasValue(100) || h.IsEmpty() || h.Len() != 1 {
+		t.Errorf("Expected %v. Got %v.", 100, v)
+	}
+	if k, _ := h.KeyOf(100); k != "a" || len(h.Keys()) != 1 || len(h.Values()) != 1 || len(h.Map()) != 1 || h.String() != "map[a:100]" {
+		t.Errorf("Expected %v. Got %v.", "a", k)
+	}
+	h.Each(func(k, v interface{}) bool {
+		return false
+	})
+}
+
+func TestFromMap(t *testing.T) {
+	cases := []struct {
+		m        interface{}
+		expected map[interface{}]interface{}
+		err      bool
+	}{
+		{map[string]int{"a": 1, "b": 2}, map[interface{}]interface{}{"a": 1, "b": 2}, false},
+		{map[int][]string{}, map[interface{}]interface{}{}, false},
+		{&map[string]int{"a": 1}, map[interface{}]interface{}{"a": 1}, false},
+		{(*map[string]int)(nil), nil, true},
+		{[]int{1}, nil, true},
+	}
+	for _, c := range cases {
+		for _, from := range []func(interface{}) (Interface, error){FromMap, FromMapSync} {
+			got, err := from(c.m)
+			testErr(err, c.err, t)
+			if !c.err && !reflect.DeepEqual(got.Map(), c.expected) {
+				t.Errorf("Expected %v. Got %v.", c.expected, got.Map())
+			}
+		}
+	}
+}
+
+func TestToMap(t *testing.T) {
+	m := map[string]int{"c": 3}
+	testErr(ToMap(New("a", 1, "b", 2), &m), false, t)
+	if !reflect.DeepEqual(m, map[string]int{"a": 1, "b": 2}) {
+		t.Errorf("Expected %v. Got %v.", map[string]int{"a": 1, "b": 2}, m)
+	}
+	var errs map[interface{}]error
+	testErr(ToMap(NewSync(1, nil), &errs), false, t)
+	if err, ok := errs[1]; !ok || err != nil {
+		t.Errorf("Expected %v. Got %v.", nil, err)
+	}
+	cases := []struct {
+		m   Interface
+		ptr interface{}
+	}{
+		{New(1, 1), &m},
+		{New("a", "1"), &m},
+		{New("a", 1), m},
+		{New("a", 1), &[]int{}},
+	}
+	for _, c := range cases {
+		if err := ToMap(c.m, c.ptr); err == nil {
+			t.Errorf("Expected %v. Got %v.", collection.ErrType, err)
+		}
+	}
+	err := ToMap(New(1, 1), &m)
+	if !errors.Is(err, collection.ErrType) || len(m) != 2 {
+		t.Errorf("Expected %v. Got %v.", collection.ErrType, err)
+	}
+}
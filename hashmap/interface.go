@@ -1,23 +1,35 @@
 package hashmap
 
-// Interface describes functions a Map must expose
-type Interface interface {
+// ReadOnly describes functions of a Map which do not modify it
+type ReadOnly interface {
 	Get(k interface{}) (interface{}, error)
-	Put(k, v interface{})
-	Remove(keys ...interface{})
 	Has(keys ...interface{}) bool
 	HasValue(values ...interface{}) bool
 	KeyOf(value interface{}) (interface{}, error)
 	Each(func(k, v interface{}) bool)
 
 	Len() int
-	Clear()
 	IsEmpty() bool
-	IsEqual(Interface) bool
+	IsEqual(ReadOnly) bool
 
 	String() string
 	Keys() []interface{}
 	Values() []interface{}
+}
+
+// Interface describes functions a Map must expose
+type Interface interface {
+	ReadOnly
+	Put(k, v interface{})
+	Remove(keys ...interface{})
+	Clear()
+
 	Map() map[interface{}]interface{}
 	Copy() Interface
 }
+
+// Snapshotter is implemented by thread safe hashmaps, which can return a
+// non-threadsafe copy of themselves consistent at the time of the call.
+type Snapshotter interface {
+	Snapshot() Interface
+}
@@ -0,0 +1,170 @@
+package hashmap
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/khezen/struct/internal/format"
+	"github.com/khezen/struct/observer"
+)
+
+// Observable is a thread safe hashmap publishing an event on each modification
+type Observable interface {
+	Interface
+	// Subscribe calls f with every event until unsubscribe is called
+	Subscribe(f func(observer.Event)) (unsubscribe func())
+	// SubscribeChan sends every event on a channel closed by unsubscribe
+	SubscribeChan() (events <-chan observer.Event, unsubscribe func())
+}
+
+// hashmapObservable forwards calls to the wrapped hashmap and publishes its changes
+type hashmapObservable struct {
+	h Interface
+	l sync.RWMutex
+	observer.Subject
+}
+
+// NewObservable wraps h in a thread safe hashmap publishing a Put event when a
+// key is added, a Replace event when the value of a key is overwritten, Remove
+// and Clear events. Events carry the key in Item and Index -1.
+// h must not be modified but through the wrapper.
+func NewObservable(h Interface) Observable {
+	return &hashmapObservable{h: h}
+}
+
+// write applies f under lock and delivers the events it returns once unlocked
+func (o *hashmapObservable) write(f func() []observer.Event) {
+	func() {
+		o.l.Lock()
+		defer o.l.Unlock()
+		o.Queue(f()...)
+	}()
+	o.Flush()
+}
+
+// operand returns the wrapped hashmap when t is o itself, which is already locked
+func (o *hashmapObservable) operand(t ReadOnly) ReadOnly {
+	if t == ReadOnly(o) {
+		return o.h
+	}
+	return t
+}
+
+func (o *hashmapObservable) Put(k, v interface{}) {
+	o.write(func() []observer.Event {
+		old, err := o.h.Get(k)
+		o.h.Put(k, v)
+		if err != nil {
+			return []observer.Event{{Op: observer.Put, Item: k, Index: -1, New: v}}
+		}
+		return []observer.Event{{Op: observer.Replace, Item: k, Index: -1, Old: old, New: v}}
+	})
+}
+
+func (o *hashmapObservable) Remove(keys ...interface{}) {
+	o.write(func() []observer.Event {
+		events := make([]observer.Event, 0, len(keys))
+		for _, k := range keys {
+			if old, err := o.h.Get(k); err == nil {
+				o.h.Remove(k)
+				events = append(events, observer.Event{Op: observer.Remove, Item: k, Index: -1, Old: old})
+			}
+		}
+		return events
+	})
+}
+
+func (o *hashmapObservable) Clear() {
+	o.write(func() []observer.Event {
+		if o.h.IsEmpty() {
+			return nil
+		}
+		o.h.Clear()
+		return []observer.Event{{Op: observer.Clear, Index: -1}}
+	})
+}
+
+func (o *hashmapObservable) Get(k interface{}) (interface{}, error) {
+	o.l.RLock()
+	defer o.l.RUnlock()
+	return o.h.Get(k)
+}
+
+func (o *hashmapObservable) Has(keys ...interface{}) bool {
+	o.l.RLock()
+	defer o.l.RUnlock()
+	return o.h.Has(keys...)
+}
+
+func (o *hashmapObservable) HasValue(values ...interface{}) bool {
+	o.l.RLock()
+	defer o.l.RUnlock()
+	return o.h.HasValue(values...)
+}
+
+func (o *hashmapObservable) KeyOf(value interface{}) (interface{}, error) {
+	o.l.RLock()
+	defer o.l.RUnlock()
+	return o.h.KeyOf(value)
+}
+
+func (o *hashmapObservable) Each(f func(k, v interface{}) bool) {
+	o.l.RLock()
+	defer o.l.RUnlock()
+	o.h.Each(f)
+}
+
+func (o *hashmapObservable) Len() int {
+	o.l.RLock()
+	defer o.l.RUnlock()
+	return o.h.Len()
+}
+
+func (o *hashmapObservable) IsEmpty() bool {
+	o.l.RLock()
+	defer o.l.RUnlock()
+	return o.h.IsEmpty()
+}
+
+func (o *hashmapObservable) IsEqual(t ReadOnly) bool {
+	o.l.RLock()
+	defer o.l.RUnlock()
+	return o.h.IsEqual(o.operand(t))
+}
+
+func (o *hashmapObservable) String() string {
+	o.l.RLock()
+	defer o.l.RUnlock()
+	return o.h.String()
+}
+
+func (o *hashmapObservable) Format(f fmt.State, verb rune) {
+	o.l.RLock()
+	defer o.l.RUnlock()
+	format.Delegate(f, verb, o.h)
+}
+
+func (o *hashmapObservable) Keys() []interface{} {
+	o.l.RLock()
+	defer o.l.RUnlock()
+	return o.h.Keys()
+}
+
+func (o *hashmapObservable) Values() []interface{} {
+	o.l.RLock()
+	defer o.l.RUnlock()
+	return o.h.Values()
+}
+
+func (o *hashmapObservable) Map() map[interface{}]interface{} {
+	o.l.RLock()
+	defer o.l.RUnlock()
+	return o.h.Map()
+}
+
+// Copy returns a copy of the wrapped hashmap, which does not publish events.
+func (o *hashmapObservable) Copy() Interface {
+	o.l.RLock()
+	defer o.l.RUnlock()
+	return o.h.Copy()
+}
@@ -0,0 +1,97 @@
+package hashmap
+
+import (
+	"fmt"
+
+	"github.com/khezen/struct/collection"
+	"github.com/khezen/struct/internal/format"
+)
+
+// hashmapReadOnly forwards reads to the wrapped hashmap and panics on mutation
+type hashmapReadOnly struct {
+	h Interface
+}
+
+// NewReadOnly returns a view of h which panics with collection.ErrReadOnly on mutation.
+// The view reflects later modifications of h.
+func NewReadOnly(h Interface) Interface {
+	if _, ok := h.(*hashmapReadOnly); ok {
+		return h
+	}
+	return &hashmapReadOnly{h}
+}
+
+func (r *hashmapReadOnly) Put(k, v interface{}) {
+	panic(collection.ErrReadOnly)
+}
+
+func (r *hashmapReadOnly) Remove(keys ...interface{}) {
+	panic(collection.ErrReadOnly)
+}
+
+func (r *hashmapReadOnly) Clear() {
+	panic(collection.ErrReadOnly)
+}
+
+func (r *hashmapReadOnly) Get(k interface{}) (interface{}, error) {
+	return r.h.Get(k)
+}
+
+func (r *hashmapReadOnly) Has(keys ...interface{}) bool {
+	return r.h.Has(keys...)
+}
+
+func (r *hashmapReadOnly) HasValue(values ...interface{}) bool {
+	return r.h.HasValue(values...)
+}
+
+func (r *hashmapReadOnly) KeyOf(value interface{}) (interface{}, error) {
+	return r.h.KeyOf(value)
+}
+
+func (r *hashmapReadOnly) Each(f func(k, v interface{}) bool) {
+	r.h.Each(f)
+}
+
+func (r *hashmapReadOnly) Len() int {
+	return r.h.Len()
+}
+
+func (r *hashmapReadOnly) IsEmpty() bool {
+	return r.h.IsEmpty()
+}
+
+func (r *hashmapReadOnly) IsEqual(t ReadOnly) bool {
+	return r.h.IsEqual(t)
+}
+
+func (r *hashmapReadOnly) String() string {
+	return r.h.String()
+}
+
+func (r *hashmapReadOnly) Format(f fmt.State, verb rune) {
+	format.Delegate(f, verb, r.h)
+}
+
+func (r *hashmapReadOnly) Keys() []interface{} {
+	return r.h.Keys()
+}
+
+func (r *hashmapReadOnly) Values() []interface{} {
+	return r.h.Values()
+}
+
+// Map returns a copy of the pairs, even for hashmaps whose Map is zero-copy.
+func (r *hashmapReadOnly) Map() map[interface{}]interface{} {
+	m := make(map[interface{}]interface{}, r.h.Len())
+	r.h.Each(func(k, v interface{}) bool {
+		m[k] = v
+		return true
+	})
+	return m
+}
+
+// Copy returns a mutable copy of the hashmap.
+func (r *hashmapReadOnly) Copy() Interface {
+	return r.h.Copy()
+}
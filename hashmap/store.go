@@ -0,0 +1,213 @@
+package hashmap
+
+import (
+	"fmt"
+
+	"github.com/khezen/struct/internal/format"
+)
+
+// Store is the storage of a hashmap created with NewFromStore
+type Store interface {
+	// Get returns the value of k and whether k was found
+	Get(k interface{}) (v interface{}, found bool, err error)
+	Put(k, v interface{}) error
+	Delete(k interface{}) error
+	// Each calls f for each pair until f returns false
+	Each(f func(k, v interface{}) bool) error
+	Len() int
+	Clear() error
+}
+
+// storeHashmap adapts a Store to Interface
+type storeHashmap struct {
+	s Store
+}
+
+// NewFromStore creates a hashmap over s. Interface has no error results, so
+// errors returned by s are raised as panics, collection.ErrReadOnly included.
+// The hashmap is as thread safe as s.
+func NewFromStore(s Store) Interface {
+	return &storeHashmap{s}
+}
+
+func (h *storeHashmap) Get(k interface{}) (interface{}, error) {
+	v, found, err := h.s.Get(k)
+	if err != nil {
+		return nil, err
+	}
+	if !found {
+		return nil, fmt.Errorf("%v not found", k)
+	}
+	return v, nil
+}
+
+func (h *storeHashmap) Put(k, v interface{}) {
+	if err := h.s.Put(k, v); err != nil {
+		panic(err)
+	}
+}
+
+func (h *storeHashmap) Remove(keys ...interface{}) {
+	for _, k := range keys {
+		if err := h.s.Delete(k); err != nil {
+			panic(err)
+		}
+	}
+}
+
+func (h *storeHashmap) Has(keys ...interface{}) bool {
+	for _, k := range keys {
+		_, found, err := h.s.Get(k)
+		if err != nil {
+			panic(err)
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+func (h *storeHashmap) HasValue(values ...interface{}) bool {
+	for _, value := range values {
+		if _, err := h.KeyOf(value); err != nil {
+			return false
+		}
+	}
+	return true
+}
+
+func (h *storeHashmap) KeyOf(value interface{}) (key interface{}, err error) {
+	err = fmt.Errorf("%v not found", value)
+	h.Each(func(k, v interface{}) bool {
+		if v == value {
+			key, err = k, nil
+			return false
+		}
+		return true
+	})
+	return key, err
+}
+
+func (h *storeHashmap) Each(f func(k, v interface{}) bool) {
+	if err := h.s.Each(f); err != nil {
+		panic(err)
+	}
+}
+
+func (h *storeHashmap) Len() int {
+	return h.s.Len()
+}
+
+func (h *storeHashmap) Clear() {
+	if err := h.s.Clear(); err != nil {
+		panic(err)
+	}
+}
+
+func (h *storeHashmap) IsEmpty() bool {
+	return h.Len() == 0
+}
+
+func (h *storeHashmap) IsEqual(t ReadOnly) bool {
+	if h.Len() != t.Len() {
+		return false
+	}
+	equal := true
+	t.Each(func(k, v interface{}) bool {
+		value, err := h.Get(k)
+		equal = err == nil && value == v
+		return equal
+	})
+	return equal
+}
+
+func (h *storeHashmap) String() string {
+	return format.String(h)
+}
+
+// Format prints the pairs of the store. With %#v, it prints the syntax of an
+// equal in-memory hashmap.
+func (h *storeHashmap) Format(f fmt.State, verb rune) {
+	format.Pairs(f, verb, "hashmap.Store", "hashmap.New", h.Each)
+}
+
+func (h *storeHashmap) Keys() []interface{} {
+	keys := make([]interface{}, 0, h.Len())
+	h.Each(func(k, v interface{}) bool {
+		keys = append(keys, k)
+		return true
+	})
+	return keys
+}
+
+func (h *storeHashmap) Values() []interface{} {
+	values := make([]interface{}, 0, h.Len())
+	h.Each(func(k, v interface{}) bool {
+		values = append(values, v)
+		return true
+	})
+	return values
+}
+
+func (h *storeHashmap) Map() map[interface{}]interface{} {
+	m := make(map[interface{}]interface{}, h.Len())
+	h.Each(func(k, v interface{}) bool {
+		m[k] = v
+		return true
+	})
+	return m
+}
+
+// Copy returns an in-memory copy of the hashmap.
+func (h *storeHashmap) Copy() Interface {
+	cpy := New()
+	h.Each(func(k, v interface{}) bool {
+		cpy.Put(k, v)
+		return true
+	})
+	return cpy
+}
+
+// memoryStore is a Store over a Go map
+type memoryStore struct {
+	m map[interface{}]interface{}
+}
+
+// NewMemoryStore creates a non thread safe in-memory Store.
+func NewMemoryStore() Store {
+	return &memoryStore{make(map[interface{}]interface{})}
+}
+
+func (s *memoryStore) Get(k interface{}) (interface{}, bool, error) {
+	v, found := s.m[k]
+	return v, found, nil
+}
+
+func (s *memoryStore) Put(k, v interface{}) error {
+	s.m[k] = v
+	return nil
+}
+
+func (s *memoryStore) Delete(k interface{}) error {
+	delete(s.m, k)
+	return nil
+}
+
+func (s *memoryStore) Each(f func(k, v interface{}) bool) error {
+	for k, v := range s.m {
+		if !f(k, v) {
+			break
+		}
+	}
+	return nil
+}
+
+func (s *memoryStore) Len() int {
+	return len(s.m)
+}
+
+func (s *memoryStore) Clear() error {
+	s.m = make(map[interface{}]interface{})
+	return nil
+}
@@ -0,0 +1,87 @@
+package store
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/gob"
+)
+
+// Codec converts keys or values to bytes. Stores order keys by their encoding.
+type Codec interface {
+	Encode(v interface{}) ([]byte, error)
+	Decode(b []byte) (interface{}, error)
+}
+
+var (
+	// String encodes strings
+	String Codec = stringCodec{}
+	// Bytes encodes byte slices, which are not hashable so only suit values
+	Bytes Codec = bytesCodec{}
+	// Int encodes ints so that their encodings sort like them
+	Int Codec = intCodec{}
+	// Gob encodes any type registered with gob.Register, as well as predeclared types
+	Gob Codec = gobCodec{}
+)
+
+type stringCodec struct{}
+
+func (stringCodec) Encode(v interface{}) ([]byte, error) {
+	s, ok := v.(string)
+	if !ok {
+		return nil, ErrType
+	}
+	return []byte(s), nil
+}
+
+func (stringCodec) Decode(b []byte) (interface{}, error) {
+	return string(b), nil
+}
+
+type bytesCodec struct{}
+
+func (bytesCodec) Encode(v interface{}) ([]byte, error) {
+	b, ok := v.([]byte)
+	if !ok {
+		return nil, ErrType
+	}
+	return b, nil
+}
+
+func (bytesCodec) Decode(b []byte) (interface{}, error) {
+	return append([]byte(nil), b...), nil
+}
+
+type intCodec struct{}
+
+func (intCodec) Encode(v interface{}) ([]byte, error) {
+	i, ok := v.(int)
+	if !ok {
+		return nil, ErrType
+	}
+	b := make([]byte, 8)
+	// flipping the sign bit makes negative numbers sort first
+	binary.BigEndian.PutUint64(b, uint64(i)^1<<63)
+	return b, nil
+}
+
+func (intCodec) Decode(b []byte) (interface{}, error) {
+	if len(b) != 8 {
+		return nil, ErrCorrupted
+	}
+	return int(binary.BigEndian.Uint64(b) ^ 1<<63), nil
+}
+
+type gobCodec struct{}
+
+func (gobCodec) Encode(v interface{}) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(&v); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func (gobCodec) Decode(b []byte) (v interface{}, err error) {
+	err = gob.NewDecoder(bytes.NewReader(b)).Decode(&v)
+	return v, err
+}
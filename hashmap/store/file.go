@@ -0,0 +1,414 @@
+package store
+
+import (
+	"io"
+	"os"
+	"sort"
+	"sync"
+)
+
+// file is a B+tree stored in a single file. Each mutation is a transaction:
+// modified nodes are written on free pages, flushed, then the meta pointing
+// to the new root is written and flushed.
+type file struct {
+	tree
+	l      sync.RWMutex
+	f      *os.File
+	meta   meta
+	free   []pgid // sorted pages reusable by the next transaction
+	broken error  // set when the file may no longer match the memory state
+	// state of the running transaction
+	tx      *meta
+	pending []pgid // pages released by the transaction, reusable once committed
+}
+
+// child refers to a node written by a transaction
+type child struct {
+	key []byte
+	id  pgid
+}
+
+// OpenFile opens the store held by the file at path, creating it if needed.
+// The store is thread safe. Keys are sorted by their encoding.
+func OpenFile(path string, keys, values Codec) (Store, error) {
+	f, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE, 0644)
+	if err != nil {
+		return nil, err
+	}
+	s := &file{f: f}
+	s.tree = tree{s, keys, values}
+	if err = s.init(); err != nil {
+		f.Close()
+		return nil, err
+	}
+	return s, nil
+}
+
+func (s *file) init() error {
+	info, err := s.f.Stat()
+	if err != nil {
+		return err
+	}
+	if info.Size() == 0 {
+		s.meta = meta{pages: 2}
+		for id := int64(0); id < 2; id++ {
+			if _, err = s.f.WriteAt(s.meta.encode(), id*pageSize); err != nil {
+				return err
+			}
+		}
+		return s.f.Sync()
+	}
+	pages := make([]byte, 2*pageSize)
+	if _, err = s.f.ReadAt(pages, 0); err != nil && err != io.EOF {
+		return err
+	}
+	m, err := latestMeta(pages[:pageSize], pages[pageSize:])
+	if err != nil {
+		return err
+	}
+	s.meta = *m
+	if m.freelist != 0 {
+		b, err := s.page(m.freelist)
+		if err != nil {
+			return err
+		}
+		if s.free, err = decodeFreelist(b); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *file) page(id pgid) ([]byte, error) {
+	limit := s.meta.pages
+	if s.tx != nil {
+		limit = s.tx.pages
+	}
+	if id < 2 || id >= limit {
+		return nil, ErrCorrupted
+	}
+	b := make([]byte, pageSize)
+	if _, err := s.f.ReadAt(b, int64(id)*pageSize); err != nil {
+		return nil, corrupted(err)
+	}
+	if n := overflow(b); n > 0 {
+		if id+pgid(n) >= limit {
+			return nil, ErrCorrupted
+		}
+		run := make([]byte, (n+1)*pageSize)
+		copy(run, b)
+		if _, err := s.f.ReadAt(run[pageSize:], int64(id+1)*pageSize); err != nil {
+			return nil, corrupted(err)
+		}
+		b = run
+	}
+	return b, nil
+}
+
+func corrupted(err error) error {
+	if err == io.EOF || err == io.ErrUnexpectedEOF {
+		return ErrCorrupted
+	}
+	return err
+}
+
+func (s *file) Get(k interface{}) (interface{}, bool, error) {
+	s.l.RLock()
+	defer s.l.RUnlock()
+	return s.get(s.meta.root, k)
+}
+
+// Each calls f for each pair in key order. f must not modify the store.
+func (s *file) Each(f func(k, v interface{}) bool) error {
+	s.l.RLock()
+	defer s.l.RUnlock()
+	_, err := s.each(s.meta.root, f)
+	return err
+}
+
+func (s *file) Len() int {
+	s.l.RLock()
+	defer s.l.RUnlock()
+	return int(s.meta.count)
+}
+
+func (s *file) Put(k, v interface{}) error {
+	key, err := s.keys.Encode(k)
+	if err != nil {
+		return err
+	}
+	val, err := s.values.Encode(v)
+	if err != nil {
+		return err
+	}
+	s.l.Lock()
+	defer s.l.Unlock()
+	return s.update(func(tx *meta) (bool, error) {
+		children, added, err := s.insert(tx.root, key, val)
+		if err != nil {
+			return false, err
+		}
+		if added {
+			tx.count++
+		}
+		return true, s.setRoot(tx, children)
+	})
+}
+
+func (s *file) Delete(k interface{}) error {
+	key, err := s.keys.Encode(k)
+	if err != nil {
+		return err
+	}
+	s.l.Lock()
+	defer s.l.Unlock()
+	return s.update(func(tx *meta) (bool, error) {
+		children, removed, err := s.remove(tx.root, key)
+		if err != nil || !removed {
+			return false, err
+		}
+		tx.count--
+		return true, s.setRoot(tx, children)
+	})
+}
+
+func (s *file) Clear() error {
+	s.l.Lock()
+	defer s.l.Unlock()
+	return s.update(func(tx *meta) (bool, error) {
+		if tx.root == 0 {
+			return false, nil
+		}
+		if err := s.releaseTree(tx.root); err != nil {
+			return false, err
+		}
+		tx.root, tx.count = 0, 0
+		return true, nil
+	})
+}
+
+func (s *file) Close() error {
+	s.l.Lock()
+	defer s.l.Unlock()
+	s.broken = os.ErrClosed
+	return s.f.Close()
+}
+
+// update runs f in a transaction committed when f reports a change.
+func (s *file) update(f func(tx *meta) (bool, error)) error {
+	if s.broken != nil {
+		return s.broken
+	}
+	tx := s.meta
+	free := append([]pgid(nil), s.free...)
+	s.tx = &tx
+	defer func() {
+		s.tx = nil
+		s.pending = nil
+	}()
+	changed, err := f(&tx)
+	if err == nil && changed {
+		err = s.commit(&tx)
+	}
+	if err != nil || !changed {
+		// pages written by the transaction are not referenced by the meta
+		s.free = free
+		return err
+	}
+	s.meta = tx
+	s.free = union(s.free, s.pending)
+	return nil
+}
+
+// commit writes the freelist and the meta of tx
+func (s *file) commit(tx *meta) error {
+	if tx.freelist != 0 {
+		b, err := s.page(tx.freelist)
+		if err != nil {
+			return err
+		}
+		s.release(tx.freelist, overflow(b))
+		tx.freelist = 0
+	}
+	if count := len(s.free) + len(s.pending); count > 0 {
+		pages := freelistPages(count)
+		id := s.alloc(pages)
+		if _, err := s.f.WriteAt(encodeFreelist(union(s.free, s.pending), pages), int64(id)*pageSize); err != nil {
+			return err
+		}
+		tx.freelist = id
+	}
+	if err := s.f.Sync(); err != nil {
+		s.broken = err
+		return err
+	}
+	tx.txid++
+	if _, err := s.f.WriteAt(tx.encode(), int64(tx.txid%2)*pageSize); err != nil {
+		s.broken = err
+		return err
+	}
+	if err := s.f.Sync(); err != nil {
+		s.broken = err
+		return err
+	}
+	return nil
+}
+
+// alloc returns the first page of a run of n free pages, growing the file if needed
+func (s *file) alloc(n int) pgid {
+	for i := 0; i+n <= len(s.free); i++ {
+		if s.free[i+n-1] == s.free[i]+pgid(n-1) {
+			id := s.free[i]
+			s.free = append(s.free[:i:i], s.free[i+n:]...)
+			return id
+		}
+	}
+	id := s.tx.pages
+	s.tx.pages += pgid(n)
+	return id
+}
+
+// release frees the run of pages starting at id once the transaction is committed
+func (s *file) release(id pgid, overflow int) {
+	for i := 0; i <= overflow; i++ {
+		s.pending = append(s.pending, id+pgid(i))
+	}
+}
+
+func (s *file) releaseTree(id pgid) error {
+	n, err := s.node(id)
+	if err != nil {
+		return err
+	}
+	for _, kid := range n.kids {
+		if err = s.releaseTree(kid); err != nil {
+			return err
+		}
+	}
+	s.release(id, n.overflow)
+	return nil
+}
+
+// write writes n, split in as many nodes as needed, on free pages
+func (s *file) write(n *node) ([]child, error) {
+	var children []child
+	for _, part := range n.split() {
+		b := part.encode()
+		id := s.alloc(len(b) / pageSize)
+		if _, err := s.f.WriteAt(b, int64(id)*pageSize); err != nil {
+			return nil, err
+		}
+		children = append(children, child{firstKey(part), id})
+	}
+	return children, nil
+}
+
+// insert puts k in the subtree rooted at id and returns the nodes replacing it
+func (s *file) insert(id pgid, k, v []byte) (children []child, added bool, err error) {
+	n := &node{leaf: true}
+	if id != 0 {
+		if n, err = s.node(id); err != nil {
+			return nil, false, err
+		}
+		s.release(id, n.overflow)
+	}
+	if n.leaf {
+		i, found := n.search(k)
+		if found {
+			n.vals[i] = v
+		} else {
+			n.keys = append(n.keys[:i:i], append([][]byte{k}, n.keys[i:]...)...)
+			n.vals = append(n.vals[:i:i], append([][]byte{v}, n.vals[i:]...)...)
+			added = true
+		}
+	} else {
+		i := n.child(k)
+		var sub []child
+		if sub, added, err = s.insert(n.kids[i], k, v); err != nil {
+			return nil, false, err
+		}
+		n.replace(i, sub)
+	}
+	children, err = s.write(n)
+	return children, added, err
+}
+
+// remove deletes k from the subtree rooted at id and returns the nodes replacing it
+func (s *file) remove(id pgid, k []byte) (children []child, removed bool, err error) {
+	if id == 0 {
+		return nil, false, nil
+	}
+	n, err := s.node(id)
+	if err != nil {
+		return nil, false, err
+	}
+	if n.leaf {
+		i, found := n.search(k)
+		if !found {
+			return nil, false, nil
+		}
+		n.keys = append(n.keys[:i:i], n.keys[i+1:]...)
+		n.vals = append(n.vals[:i:i], n.vals[i+1:]...)
+	} else {
+		i := n.child(k)
+		var sub []child
+		if sub, removed, err = s.remove(n.kids[i], k); err != nil || !removed {
+			return nil, false, err
+		}
+		n.replace(i, sub)
+	}
+	s.release(id, n.overflow)
+	children, err = s.write(n)
+	return children, true, err
+}
+
+// setRoot makes the nodes returned by the root update the new root
+func (s *file) setRoot(tx *meta, children []child) (err error) {
+	for len(children) > 1 {
+		n := &node{}
+		for _, c := range children {
+			n.keys = append(n.keys, c.key)
+			n.kids = append(n.kids, c.id)
+		}
+		if children, err = s.write(n); err != nil {
+			return err
+		}
+	}
+	if len(children) == 0 {
+		tx.root = 0
+		return nil
+	}
+	tx.root = children[0].id
+	for {
+		n, err := s.node(tx.root)
+		if err != nil || n.leaf || len(n.kids) > 1 {
+			return err
+		}
+		s.release(tx.root, n.overflow)
+		tx.root = n.kids[0]
+	}
+}
+
+// replace substitutes the children of a branch at i with the given ones
+func (n *node) replace(i int, children []child) {
+	keys := make([][]byte, 0, len(n.keys)+len(children)-1)
+	kids := make([]pgid, 0, len(n.kids)+len(children)-1)
+	keys = append(keys, n.keys[:i]...)
+	kids = append(kids, n.kids[:i]...)
+	for _, c := range children {
+		keys = append(keys, c.key)
+		kids = append(kids, c.id)
+	}
+	n.keys = append(keys, n.keys[i+1:]...)
+	n.kids = append(kids, n.kids[i+1:]...)
+}
+
+// union returns the sorted union of two sets of pages
+func union(a, b []pgid) []pgid {
+	ids := make([]pgid, 0, len(a)+len(b))
+	ids = append(append(ids, a...), b...)
+	sort.Slice(ids, func(i, j int) bool {
+		return ids[i] < ids[j]
+	})
+	return ids
+}
@@ -0,0 +1,80 @@
+package store
+
+import (
+	"github.com/khezen/struct/collection"
+)
+
+// mapped is a read only store over a file mapped in memory
+type mapped struct {
+	tree
+	data  []byte
+	meta  *meta
+	unmap func() error
+}
+
+// OpenMmap maps the store file at path in memory, read only. Reads do not copy
+// pages, and mutations fail with collection.ErrReadOnly. The file must not be
+// modified while mapped. The store is thread safe.
+func OpenMmap(path string, keys, values Codec) (Store, error) {
+	data, unmap, err := mmap(path)
+	if err != nil {
+		return nil, err
+	}
+	s := &mapped{data: data, unmap: unmap}
+	s.tree = tree{s, keys, values}
+	if len(data) < 2*pageSize {
+		unmap()
+		return nil, ErrCorrupted
+	}
+	if s.meta, err = latestMeta(data[:pageSize], data[pageSize:2*pageSize]); err != nil {
+		unmap()
+		return nil, err
+	}
+	if uint64(s.meta.pages)*pageSize > uint64(len(data)) {
+		unmap()
+		return nil, ErrCorrupted
+	}
+	return s, nil
+}
+
+func (s *mapped) page(id pgid) ([]byte, error) {
+	if id < 2 || id >= s.meta.pages {
+		return nil, ErrCorrupted
+	}
+	start := int(id) * pageSize
+	end := start + (overflow(s.data[start:])+1)*pageSize
+	if end > int(s.meta.pages)*pageSize {
+		return nil, ErrCorrupted
+	}
+	return s.data[start:end], nil
+}
+
+func (s *mapped) Get(k interface{}) (interface{}, bool, error) {
+	return s.get(s.meta.root, k)
+}
+
+// Each calls f for each pair in key order.
+func (s *mapped) Each(f func(k, v interface{}) bool) error {
+	_, err := s.each(s.meta.root, f)
+	return err
+}
+
+func (s *mapped) Len() int {
+	return int(s.meta.count)
+}
+
+func (s *mapped) Put(k, v interface{}) error {
+	return collection.ErrReadOnly
+}
+
+func (s *mapped) Delete(k interface{}) error {
+	return collection.ErrReadOnly
+}
+
+func (s *mapped) Clear() error {
+	return collection.ErrReadOnly
+}
+
+func (s *mapped) Close() error {
+	return s.unmap()
+}
@@ -0,0 +1,255 @@
+package store
+
+import (
+	"bytes"
+	"encoding/binary"
+	"hash/fnv"
+	"sort"
+)
+
+// Files are made of pages. Pages 0 and 1 hold the two last versions of the
+// meta, so that a torn meta write falls back on the previous version. Nodes
+// and the freelist are written on free pages and never modified in place.
+const (
+	pageSize       = 4096
+	pageHeaderSize = 8 // flags, count and overflow
+	metaSize       = 64
+	magic          = 0x42545245
+	version        = 1
+
+	leafFlag     = 1
+	branchFlag   = 2
+	metaFlag     = 4
+	freelistFlag = 8
+)
+
+type pgid uint64
+
+type meta struct {
+	root     pgid // 0 when the tree is empty
+	freelist pgid // 0 when no page is free
+	pages    pgid // number of pages of the file
+	count    uint64
+	txid     uint64
+}
+
+func (m *meta) encode() []byte {
+	b := make([]byte, pageSize)
+	binary.LittleEndian.PutUint16(b, metaFlag)
+	body := b[pageHeaderSize:]
+	binary.LittleEndian.PutUint32(body, magic)
+	binary.LittleEndian.PutUint32(body[4:], version)
+	binary.LittleEndian.PutUint32(body[8:], pageSize)
+	binary.LittleEndian.PutUint64(body[16:], uint64(m.root))
+	binary.LittleEndian.PutUint64(body[24:], uint64(m.freelist))
+	binary.LittleEndian.PutUint64(body[32:], uint64(m.pages))
+	binary.LittleEndian.PutUint64(body[40:], m.count)
+	binary.LittleEndian.PutUint64(body[48:], m.txid)
+	binary.LittleEndian.PutUint64(body[56:], checksum(body[:56]))
+	return b
+}
+
+func decodeMeta(b []byte) (*meta, bool) {
+	if len(b) < pageHeaderSize+metaSize || binary.LittleEndian.Uint16(b) != metaFlag {
+		return nil, false
+	}
+	body := b[pageHeaderSize:]
+	if binary.LittleEndian.Uint32(body) != magic || binary.LittleEndian.Uint32(body[4:]) != version ||
+		binary.LittleEndian.Uint32(body[8:]) != pageSize || binary.LittleEndian.Uint64(body[56:]) != checksum(body[:56]) {
+		return nil, false
+	}
+	return &meta{
+		root:     pgid(binary.LittleEndian.Uint64(body[16:])),
+		freelist: pgid(binary.LittleEndian.Uint64(body[24:])),
+		pages:    pgid(binary.LittleEndian.Uint64(body[32:])),
+		count:    binary.LittleEndian.Uint64(body[40:]),
+		txid:     binary.LittleEndian.Uint64(body[48:]),
+	}, true
+}
+
+// latestMeta returns the valid meta of the highest transaction among the first two pages
+func latestMeta(page0, page1 []byte) (*meta, error) {
+	m0, ok0 := decodeMeta(page0)
+	m1, ok1 := decodeMeta(page1)
+	switch {
+	case ok0 && (!ok1 || m0.txid > m1.txid):
+		return m0, nil
+	case ok1:
+		return m1, nil
+	}
+	return nil, ErrCorrupted
+}
+
+func checksum(b []byte) uint64 {
+	h := fnv.New64a()
+	h.Write(b)
+	return h.Sum64()
+}
+
+// overflow returns the number of pages following the first one of a page run
+func overflow(b []byte) int {
+	return int(binary.LittleEndian.Uint32(b[4:]))
+}
+
+// node is a decoded page of the B+tree. Branches hold the first key of each child.
+type node struct {
+	leaf     bool
+	overflow int
+	keys     [][]byte
+	vals     [][]byte // leaves only
+	kids     []pgid   // branches only
+}
+
+func (n *node) size() int {
+	size := pageHeaderSize
+	for i, k := range n.keys {
+		if n.leaf {
+			size += 8 + len(k) + len(n.vals[i])
+		} else {
+			size += 12 + len(k)
+		}
+	}
+	return size
+}
+
+func (n *node) encode() []byte {
+	pages := (n.size() + pageSize - 1) / pageSize
+	b := make([]byte, pages*pageSize)
+	flag := branchFlag
+	if n.leaf {
+		flag = leafFlag
+	}
+	binary.LittleEndian.PutUint16(b, uint16(flag))
+	binary.LittleEndian.PutUint16(b[2:], uint16(len(n.keys)))
+	binary.LittleEndian.PutUint32(b[4:], uint32(pages-1))
+	off := pageHeaderSize
+	for i, k := range n.keys {
+		binary.LittleEndian.PutUint32(b[off:], uint32(len(k)))
+		if n.leaf {
+			binary.LittleEndian.PutUint32(b[off+4:], uint32(len(n.vals[i])))
+			off += 8
+			off += copy(b[off:], k)
+			off += copy(b[off:], n.vals[i])
+		} else {
+			binary.LittleEndian.PutUint64(b[off+4:], uint64(n.kids[i]))
+			off += 12
+			off += copy(b[off:], k)
+		}
+	}
+	return b
+}
+
+func decodeNode(b []byte) (*node, error) {
+	flag := binary.LittleEndian.Uint16(b)
+	if flag != leafFlag && flag != branchFlag {
+		return nil, ErrCorrupted
+	}
+	n := &node{leaf: flag == leafFlag, overflow: overflow(b)}
+	count := int(binary.LittleEndian.Uint16(b[2:]))
+	off := pageHeaderSize
+	for i := 0; i < count; i++ {
+		if off+12 > len(b) {
+			return nil, ErrCorrupted
+		}
+		klen := int(binary.LittleEndian.Uint32(b[off:]))
+		if n.leaf {
+			vlen := int(binary.LittleEndian.Uint32(b[off+4:]))
+			off += 8
+			if klen < 0 || vlen < 0 || off+klen+vlen > len(b) {
+				return nil, ErrCorrupted
+			}
+			n.keys = append(n.keys, b[off:off+klen])
+			n.vals = append(n.vals, b[off+klen:off+klen+vlen])
+			off += klen + vlen
+		} else {
+			n.kids = append(n.kids, pgid(binary.LittleEndian.Uint64(b[off+4:])))
+			off += 12
+			if klen < 0 || off+klen > len(b) {
+				return nil, ErrCorrupted
+			}
+			n.keys = append(n.keys, b[off:off+klen])
+			off += klen
+		}
+	}
+	return n, nil
+}
+
+// search returns the index of k in a leaf, or where to insert it
+func (n *node) search(k []byte) (int, bool) {
+	i := sort.Search(len(n.keys), func(i int) bool {
+		return bytes.Compare(n.keys[i], k) >= 0
+	})
+	return i, i < len(n.keys) && bytes.Equal(n.keys[i], k)
+}
+
+// child returns the index of the child of a branch which may hold k
+func (n *node) child(k []byte) int {
+	i := sort.Search(len(n.keys), func(i int) bool {
+		return bytes.Compare(n.keys[i], k) > 0
+	})
+	if i > 0 {
+		i--
+	}
+	return i
+}
+
+// split cuts n into nodes fitting in a page, but for entries larger than a page.
+func (n *node) split() []*node {
+	var nodes []*node
+	cur := &node{leaf: n.leaf}
+	size := pageHeaderSize
+	for i, k := range n.keys {
+		entry := 12 + len(k)
+		if n.leaf {
+			entry = 8 + len(k) + len(n.vals[i])
+		}
+		if len(cur.keys) > 0 && size+entry > pageSize {
+			nodes = append(nodes, cur)
+			cur = &node{leaf: n.leaf}
+			size = pageHeaderSize
+		}
+		cur.keys = append(cur.keys, k)
+		if n.leaf {
+			cur.vals = append(cur.vals, n.vals[i])
+		} else {
+			cur.kids = append(cur.kids, n.kids[i])
+		}
+		size += entry
+	}
+	if len(cur.keys) > 0 {
+		nodes = append(nodes, cur)
+	}
+	return nodes
+}
+
+// freelistPages returns the number of pages holding a freelist of count ids
+func freelistPages(count int) int {
+	return (pageHeaderSize + 8 + 8*count + pageSize - 1) / pageSize
+}
+
+// encodeFreelist encodes ids in the given number of pages, which must be enough
+func encodeFreelist(ids []pgid, pages int) []byte {
+	b := make([]byte, pages*pageSize)
+	binary.LittleEndian.PutUint16(b, freelistFlag)
+	binary.LittleEndian.PutUint32(b[4:], uint32(pages-1))
+	binary.LittleEndian.PutUint64(b[pageHeaderSize:], uint64(len(ids)))
+	for i, id := range ids {
+		binary.LittleEndian.PutUint64(b[pageHeaderSize+8+8*i:], uint64(id))
+	}
+	return b
+}
+
+func decodeFreelist(b []byte) ([]pgid, error) {
+	if binary.LittleEndian.Uint16(b) != freelistFlag {
+		return nil, ErrCorrupted
+	}
+	count := binary.LittleEndian.Uint64(b[pageHeaderSize:])
+	if count > uint64(len(b)-pageHeaderSize-8)/8 {
+		return nil, ErrCorrupted
+	}
+	ids := make([]pgid, count)
+	for i := range ids {
+		ids[i] = pgid(binary.LittleEndian.Uint64(b[pageHeaderSize+8+8*i:]))
+	}
+	return ids, nil
+}
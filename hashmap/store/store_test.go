@@ -0,0 +1,241 @@
+package store
+
+import (
+	"fmt"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/khezen/struct/collection"
+	"github.com/khezen/struct/hashmap"
+)
+
+func testErr(err error, expectErr bool, t *testing.T) {
+	if (expectErr && err == nil) || (!expectErr && err != nil) {
+		t.Errorf(" Error expected? %v. Got: %v.", expectErr, err)
+	}
+}
+
+func open(path string, t *testing.T) Store {
+	s, err := OpenFile(path, Int, String)
+	if err != nil {
+		t.Fatalf("Expected %v. Got %v.", nil, err)
+	}
+	return s
+}
+
+// check compares s with expected, and checks that keys come in order
+func check(s Store, expected hashmap.Interface, t *testing.T) {
+	if s.Len() != expected.Len() {
+		t.Errorf("Expected %v. Got %v.", expected.Len(), s.Len())
+	}
+	prev, count := 0, 0
+	err := s.Each(func(k, v interface{}) bool {
+		if count > 0 && k.(int) <= prev {
+			t.Errorf("Expected %v. Got %v.", "key greater than "+fmt.Sprint(prev), k)
+		}
+		prev = k.(int)
+		count++
+		if value, err := expected.Get(k); err != nil || value != v {
+			t.Errorf("Expected %v. Got %v.", value, v)
+		}
+		return true
+	})
+	testErr(err, false, t)
+	if count != expected.Len() {
+		t.Errorf("Expected %v. Got %v.", expected.Len(), count)
+	}
+}
+
+func TestFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "store")
+	s := open(path, t)
+	expected := hashmap.New()
+	r := rand.New(rand.NewSource(42))
+	for i := 0; i < 1500; i++ {
+		k := r.Intn(1000) - 500
+		switch r.Intn(4) {
+		case 0:
+			testErr(s.Delete(k), false, t)
+			expected.Remove(k)
+		default:
+			v := strings.Repeat("v", r.Intn(40))
+			if r.Intn(100) == 0 {
+				v = strings.Repeat("large", 2000)
+			}
+			testErr(s.Put(k, v), false, t)
+			expected.Put(k, v)
+		}
+	}
+	check(s, expected, t)
+	for _, k := range expected.Keys() {
+		v, found, err := s.Get(k)
+		value, _ := expected.Get(k)
+		if err != nil || !found || v != value {
+			t.Errorf("Expected %v. Got %v.", value, v)
+		}
+	}
+	if _, found, err := s.Get(10000); found || err != nil {
+		t.Errorf("Expected %v. Got %v.", false, found)
+	}
+	testErr(s.Close(), false, t)
+	s = open(path, t)
+	check(s, expected, t)
+	testErr(s.Clear(), false, t)
+	check(s, hashmap.New(), t)
+	testErr(s.Put(1, "a"), false, t)
+	testErr(s.Close(), false, t)
+	testErr(s.Put(2, "b"), true, t)
+	s = open(path, t)
+	check(s, hashmap.New(1, "a"), t)
+	s.Close()
+}
+
+func TestFileReusesPages(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "store")
+	s := open(path, t)
+	defer s.Close()
+	for round := 0; round < 10; round++ {
+		for i := 0; i < 200; i++ {
+			testErr(s.Put(i, strings.Repeat("x", 50)), false, t)
+		}
+		for i := 0; i < 200; i++ {
+			testErr(s.Delete(i), false, t)
+		}
+	}
+	info, err := os.Stat(path)
+	testErr(err, false, t)
+	if info.Size() > 64*pageSize {
+		t.Errorf("Expected %v. Got %v.", "pages reused", info.Size()/pageSize)
+	}
+}
+
+func TestFileTornMeta(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "store")
+	s := open(path, t)
+	s.Put(1, "a")
+	s.Put(2, "b")
+	s.Close()
+	// the second Put wrote the meta of transaction 2 on page 0
+	f, err := os.OpenFile(path, os.O_RDWR, 0644)
+	testErr(err, false, t)
+	_, err = f.WriteAt([]byte("torn"), pageHeaderSize+20)
+	testErr(err, false, t)
+	f.Close()
+	s = open(path, t)
+	check(s, hashmap.New(1, "a"), t)
+	s.Put(3, "c")
+	s.Close()
+	s = open(path, t)
+	check(s, hashmap.New(1, "a", 3, "c"), t)
+	s.Close()
+
+	testErr(os.WriteFile(path, []byte("not a store"), 0644), false, t)
+	_, err = OpenFile(path, Int, String)
+	if err != ErrCorrupted {
+		t.Errorf("Expected %v. Got %v.", ErrCorrupted, err)
+	}
+	_, err = OpenMmap(path, Int, String)
+	if err != ErrCorrupted {
+		t.Errorf("Expected %v. Got %v.", ErrCorrupted, err)
+	}
+}
+
+func TestMmap(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "store")
+	s := open(path, t)
+	expected := hashmap.New()
+	for i := 0; i < 2000; i++ {
+		s.Put(i, strings.Repeat("v", i%100))
+		expected.Put(i, strings.Repeat("v", i%100))
+	}
+	s.Put(-1, strings.Repeat("large", 3000))
+	expected.Put(-1, strings.Repeat("large", 3000))
+	s.Close()
+	m, err := OpenMmap(path, Int, String)
+	testErr(err, false, t)
+	check(m, expected, t)
+	if v, found, err := m.Get(-1); err != nil || !found || v != strings.Repeat("large", 3000) {
+		t.Errorf("Expected %v. Got %v.", "large", v)
+	}
+	for _, err := range []error{m.Put(1, "a"), m.Delete(1), m.Clear()} {
+		if err != collection.ErrReadOnly {
+			t.Errorf("Expected %v. Got %v.", collection.ErrReadOnly, err)
+		}
+	}
+	visited := 0
+	m.Each(func(k, v interface{}) bool {
+		visited++
+		return visited < 10
+	})
+	if visited != 10 {
+		t.Errorf("Expected %v. Got %v.", 10, visited)
+	}
+	testErr(m.Close(), false, t)
+	_, err = OpenMmap(filepath.Join(t.TempDir(), "missing"), Int, String)
+	testErr(err, true, t)
+}
+
+func TestNewFromStore(t *testing.T) {
+	s := open(filepath.Join(t.TempDir(), "store"), t)
+	defer s.Close()
+	h := hashmap.NewFromStore(s)
+	h.Put(1, "a")
+	h.Put(2, "b")
+	h.Remove(2, 3)
+	if !h.IsEqual(hashmap.New(1, "a")) || h.String() != "map[1:a]" {
+		t.Errorf("Expected %v. Got %v.", "map[1:a]", h)
+	}
+	func() {
+		defer func() {
+			if r := recover(); r != ErrType {
+				t.Errorf("Expected %v. Got %v.", ErrType, r)
+			}
+		}()
+		h.Put("key", "a")
+	}()
+	if _, err := h.Get("key"); err != ErrType {
+		t.Errorf("Expected %v. Got %v.", ErrType, err)
+	}
+}
+
+func TestCodecs(t *testing.T) {
+	cases := []struct {
+		codec Codec
+		value interface{}
+		wrong interface{}
+	}{
+		{String, "a", 1},
+		{Int, -42, "a"},
+		{Gob, 3.5, func() {}},
+	}
+	for _, c := range cases {
+		b, err := c.codec.Encode(c.value)
+		testErr(err, false, t)
+		v, err := c.codec.Decode(b)
+		testErr(err, false, t)
+		if v != c.value {
+			t.Errorf("Expected %v. Got %v.", c.value, v)
+		}
+		_, err = c.codec.Encode(c.wrong)
+		testErr(err, true, t)
+	}
+	b, err := Bytes.Encode([]byte("ab"))
+	testErr(err, false, t)
+	v, _ := Bytes.Decode(b)
+	b[0] = 'x'
+	if string(v.([]byte)) != "ab" {
+		t.Errorf("Expected %v. Got %v.", "ab", v)
+	}
+	_, err = Bytes.Encode("ab")
+	testErr(err, true, t)
+	_, err = Int.Decode([]byte{1})
+	testErr(err, true, t)
+	low, _ := Int.Encode(-1)
+	high, _ := Int.Encode(1)
+	if string(low) >= string(high) {
+		t.Errorf("Expected %v. Got %v.", "ordered encoding", low)
+	}
+}
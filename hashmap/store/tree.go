@@ -0,0 +1,104 @@
+// Package store provides hashmap.Store implementations persisted in a single
+// file: a B+tree updated with copy-on-write and crash-safe commits, and a
+// memory-mapped read-only view of the same file format.
+package store
+
+import (
+	"errors"
+
+	"github.com/khezen/struct/hashmap"
+)
+
+// Store is a hashmap.Store backed by a file
+type Store interface {
+	hashmap.Store
+	// Close releases the file. The store must not be used afterwards.
+	Close() error
+}
+
+var (
+	// ErrType - value type is not supported by the codec
+	ErrType = errors.New("ErrType - value type is not supported by the codec")
+	// ErrCorrupted - file is not a store or is corrupted
+	ErrCorrupted = errors.New("ErrCorrupted - file is not a store or is corrupted")
+)
+
+// pager returns the page run starting at a page
+type pager interface {
+	page(id pgid) ([]byte, error)
+}
+
+// tree implements the reads shared by stores
+type tree struct {
+	p      pager
+	keys   Codec
+	values Codec
+}
+
+func (t *tree) node(id pgid) (*node, error) {
+	b, err := t.p.page(id)
+	if err != nil {
+		return nil, err
+	}
+	return decodeNode(b)
+}
+
+func (t *tree) get(root pgid, k interface{}) (interface{}, bool, error) {
+	key, err := t.keys.Encode(k)
+	if err != nil {
+		return nil, false, err
+	}
+	for id := root; id != 0; {
+		n, err := t.node(id)
+		if err != nil {
+			return nil, false, err
+		}
+		if !n.leaf {
+			id = n.kids[n.child(key)]
+			continue
+		}
+		i, found := n.search(key)
+		if !found {
+			return nil, false, nil
+		}
+		v, err := t.values.Decode(n.vals[i])
+		return v, err == nil, err
+	}
+	return nil, false, nil
+}
+
+// each calls f for each pair in key order. It returns false once f does.
+func (t *tree) each(id pgid, f func(k, v interface{}) bool) (bool, error) {
+	if id == 0 {
+		return true, nil
+	}
+	n, err := t.node(id)
+	if err != nil {
+		return false, err
+	}
+	for i := range n.keys {
+		if !n.leaf {
+			if ok, err := t.each(n.kids[i], f); !ok || err != nil {
+				return false, err
+			}
+			continue
+		}
+		k, err := t.keys.Decode(n.keys[i])
+		if err != nil {
+			return false, err
+		}
+		v, err := t.values.Decode(n.vals[i])
+		if err != nil {
+			return false, err
+		}
+		if !f(k, v) {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+// firstKey returns the key a parent uses to refer to n
+func firstKey(n *node) []byte {
+	return append([]byte(nil), n.keys[0]...)
+}
@@ -0,0 +1,290 @@
+package hashmap
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/khezen/struct/internal/format"
+)
+
+// Versioned is a thread safe hashmap keeping past versions of its pairs. Each
+// Put, and each Remove or Clear removing at least one key, creates a version.
+type Versioned interface {
+	Interface
+	// Version returns the current version, 0 being the one of the initial pairs
+	Version() uint64
+	// GetAt returns the value of k at the given version
+	GetAt(k interface{}, version uint64) (interface{}, error)
+	// SnapshotAt returns a read only copy of the hashmap at the given version
+	SnapshotAt(version uint64) (Interface, error)
+	// History returns the retained revisions of k, from the oldest to the latest
+	History(k interface{}) []Revision
+	// GC discards the revisions not needed to read versions from watermark onwards
+	GC(watermark uint64)
+	// Watermark returns the oldest version which can still be read
+	Watermark() uint64
+}
+
+// Revision is the value of a key from a version onwards
+type Revision struct {
+	Version uint64
+	Value   interface{}
+	Removed bool
+}
+
+var (
+	// ErrVersionCollected - version is older than the retention watermark
+	ErrVersionCollected = errors.New("ErrVersionCollected - version is older than the retention watermark")
+	// ErrFutureVersion - version has not been created yet
+	ErrFutureVersion = errors.New("ErrFutureVersion - version has not been created yet")
+)
+
+type hashmapVersioned struct {
+	hashmap
+	l         sync.RWMutex
+	revisions map[interface{}][]Revision
+	version   uint64
+	watermark uint64
+}
+
+// NewVersioned creates a thread safe hashmap keeping the past versions of
+// its pairs until they are garbage collected with GC.
+func NewVersioned(pairs ...interface{}) Versioned {
+	h := &hashmapVersioned{
+		hashmap:   *New(pairs...).(*hashmap),
+		revisions: make(map[interface{}][]Revision),
+	}
+	for k, v := range h.m {
+		h.revisions[k] = []Revision{{Value: v}}
+	}
+	return h
+}
+
+func (h *hashmapVersioned) Put(k, v interface{}) {
+	h.l.Lock()
+	defer h.l.Unlock()
+	h.version++
+	h.m[k] = v
+	h.revisions[k] = append(h.revisions[k], Revision{Version: h.version, Value: v})
+}
+
+func (h *hashmapVersioned) Remove(keys ...interface{}) {
+	h.l.Lock()
+	defer h.l.Unlock()
+	h.remove(keys)
+}
+
+// remove removes the keys which are present in a single version
+func (h *hashmapVersioned) remove(keys []interface{}) {
+	version := h.version + 1
+	for _, k := range keys {
+		if _, ok := h.m[k]; ok {
+			delete(h.m, k)
+			h.revisions[k] = append(h.revisions[k], Revision{Version: version, Removed: true})
+			h.version = version
+		}
+	}
+}
+
+func (h *hashmapVersioned) Clear() {
+	h.l.Lock()
+	defer h.l.Unlock()
+	h.remove(h.hashmap.Keys())
+}
+
+func (h *hashmapVersioned) Version() uint64 {
+	h.l.RLock()
+	defer h.l.RUnlock()
+	return h.version
+}
+
+func (h *hashmapVersioned) Watermark() uint64 {
+	h.l.RLock()
+	defer h.l.RUnlock()
+	return h.watermark
+}
+
+// checkVersion returns an error if version cannot be read
+func (h *hashmapVersioned) checkVersion(version uint64) error {
+	switch {
+	case version > h.version:
+		return ErrFutureVersion
+	case version < h.watermark:
+		return ErrVersionCollected
+	}
+	return nil
+}
+
+// at returns the revision of revs in effect at version, if any
+func at(revs []Revision, version uint64) (Revision, bool) {
+	i := sort.Search(len(revs), func(i int) bool {
+		return revs[i].Version > version
+	})
+	if i == 0 || revs[i-1].Removed {
+		return Revision{}, false
+	}
+	return revs[i-1], true
+}
+
+func (h *hashmapVersioned) GetAt(k interface{}, version uint64) (interface{}, error) {
+	h.l.RLock()
+	defer h.l.RUnlock()
+	if err := h.checkVersion(version); err != nil {
+		return nil, err
+	}
+	rev, ok := at(h.revisions[k], version)
+	if !ok {
+		return nil, fmt.Errorf("%v not found", k)
+	}
+	return rev.Value, nil
+}
+
+func (h *hashmapVersioned) SnapshotAt(version uint64) (Interface, error) {
+	h.l.RLock()
+	defer h.l.RUnlock()
+	if err := h.checkVersion(version); err != nil {
+		return nil, err
+	}
+	snapshot := New()
+	for k, revs := range h.revisions {
+		if rev, ok := at(revs, version); ok {
+			snapshot.Put(k, rev.Value)
+		}
+	}
+	return NewReadOnly(snapshot), nil
+}
+
+func (h *hashmapVersioned) History(k interface{}) []Revision {
+	h.l.RLock()
+	defer h.l.RUnlock()
+	revs := h.revisions[k]
+	history := make([]Revision, len(revs))
+	copy(history, revs)
+	return history
+}
+
+// GC keeps, for each key, the revision in effect at watermark and the later
+// ones. Keys removed at watermark are forgotten. A watermark beyond the
+// current version is lowered to it.
+func (h *hashmapVersioned) GC(watermark uint64) {
+	h.l.Lock()
+	defer h.l.Unlock()
+	if watermark > h.version {
+		watermark = h.version
+	}
+	if watermark <= h.watermark {
+		return
+	}
+	h.watermark = watermark
+	for k, revs := range h.revisions {
+		i := sort.Search(len(revs), func(i int) bool {
+			return revs[i].Version > watermark
+		})
+		if i > 0 && !revs[i-1].Removed {
+			i--
+		}
+		if i == len(revs) {
+			delete(h.revisions, k)
+		} else if i > 0 {
+			h.revisions[k] = append([]Revision(nil), revs[i:]...)
+		}
+	}
+}
+
+func (h *hashmapVersioned) Get(k interface{}) (interface{}, error) {
+	h.l.RLock()
+	defer h.l.RUnlock()
+	return h.hashmap.Get(k)
+}
+
+func (h *hashmapVersioned) Has(keys ...interface{}) bool {
+	h.l.RLock()
+	defer h.l.RUnlock()
+	return h.hashmap.Has(keys...)
+}
+
+func (h *hashmapVersioned) HasValue(values ...interface{}) bool {
+	h.l.RLock()
+	defer h.l.RUnlock()
+	return h.hashmap.HasValue(values...)
+}
+
+func (h *hashmapVersioned) KeyOf(value interface{}) (interface{}, error) {
+	h.l.RLock()
+	defer h.l.RUnlock()
+	return h.hashmap.KeyOf(value)
+}
+
+func (h *hashmapVersioned) Each(f func(k, v interface{}) bool) {
+	h.l.RLock()
+	defer h.l.RUnlock()
+	h.hashmap.Each(f)
+}
+
+func (h *hashmapVersioned) Len() int {
+	h.l.RLock()
+	defer h.l.RUnlock()
+	return h.hashmap.Len()
+}
+
+func (h *hashmapVersioned) IsEmpty() bool {
+	h.l.RLock()
+	defer h.l.RUnlock()
+	return h.hashmap.IsEmpty()
+}
+
+func (h *hashmapVersioned) IsEqual(t ReadOnly) bool {
+	h.l.RLock()
+	defer h.l.RUnlock()
+	if t == ReadOnly(h) {
+		return true
+	}
+	return h.hashmap.IsEqual(t)
+}
+
+func (h *hashmapVersioned) String() string {
+	return format.String(h)
+}
+
+// Format prints the pairs of the current version.
+func (h *hashmapVersioned) Format(f fmt.State, verb rune) {
+	format.Pairs(f, verb, "hashmap.Versioned", "hashmap.NewVersioned", h.Each)
+}
+
+func (h *hashmapVersioned) Keys() []interface{} {
+	h.l.RLock()
+	defer h.l.RUnlock()
+	return h.hashmap.Keys()
+}
+
+func (h *hashmapVersioned) Values() []interface{} {
+	h.l.RLock()
+	defer h.l.RUnlock()
+	return h.hashmap.Values()
+}
+
+func (h *hashmapVersioned) Map() map[interface{}]interface{} {
+	h.l.RLock()
+	defer h.l.RUnlock()
+	return h.hashmap.Map()
+}
+
+// Copy returns a thread safe copy of the current version, without history.
+func (h *hashmapVersioned) Copy() Interface {
+	h.l.RLock()
+	defer h.l.RUnlock()
+	cpy := NewSync()
+	for k, v := range h.m {
+		cpy.Put(k, v)
+	}
+	return cpy
+}
+
+// Snapshot returns a non-threadsafe copy of the current version.
+func (h *hashmapVersioned) Snapshot() Interface {
+	h.l.RLock()
+	defer h.l.RUnlock()
+	return h.hashmap.Copy()
+}
@@ -0,0 +1,484 @@
+package history
+
+import (
+	"fmt"
+
+	"github.com/khezen/struct/array"
+	"github.com/khezen/struct/collection"
+	"github.com/khezen/struct/internal/format"
+	"github.com/khezen/struct/set"
+)
+
+// Array is a thread safe array recording its history
+type Array interface {
+	array.Interface
+	History
+}
+
+// arrayHistory also records ordered sets, in which case unique is set and
+// items already present are filtered out before being added.
+type arrayHistory struct {
+	*recorder
+	a      array.Interface
+	unique bool
+}
+
+// NewArray wraps a to record its modifications, keeping at most limit steps
+// of history, or all of them when limit is 0. a must not be modified but
+// through the wrapper.
+func NewArray(a array.Interface, limit int) Array {
+	return &arrayHistory{recorder: newRecorder(limit), a: a}
+}
+
+// operand returns the wrapped array when t is h itself, which is already locked
+func (h *arrayHistory) operand(t collection.ReadOnly) collection.ReadOnly {
+	if t == collection.ReadOnly(h) {
+		return h.a
+	}
+	return t
+}
+
+// fresh filters out items which an ordered set would ignore
+func (h *arrayHistory) fresh(items []interface{}) []interface{} {
+	if !h.unique {
+		return items
+	}
+	fresh, seen := make([]interface{}, 0, len(items)), set.New()
+	for _, item := range items {
+		if !h.a.Has(item) && !seen.Has(item) {
+			fresh = append(fresh, item)
+			seen.Add(item)
+		}
+	}
+	return fresh
+}
+
+// insert inserts items at i, l being held
+func (h *arrayHistory) insert(i int, items []interface{}) {
+	items = h.fresh(items)
+	if len(items) == 0 {
+		return
+	}
+	insertAt(h.a, i, items)
+	h.record(func() {
+		for range items {
+			h.a.RemoveAt(i)
+		}
+	}, func() {
+		insertAt(h.a, i, items)
+	})
+}
+
+// removal is an item removed at index i
+type removal struct {
+	i    int
+	item interface{}
+}
+
+// removeAll records removals already applied in order, l being held
+func (h *arrayHistory) removeAll(removals []removal) {
+	if len(removals) == 0 {
+		return
+	}
+	h.record(func() {
+		for j := len(removals) - 1; j >= 0; j-- {
+			insertAt(h.a, removals[j].i, []interface{}{removals[j].item})
+		}
+	}, func() {
+		for _, r := range removals {
+			h.a.RemoveAt(r.i)
+		}
+	})
+}
+
+// remove removes the first occurrence of each item, l being held
+func (h *arrayHistory) remove(items []interface{}) {
+	var removals []removal
+	for _, item := range items {
+		if i, err := h.a.IndexOf(item); err == nil {
+			removals = append(removals, removal{i, h.a.RemoveAt(i)})
+		}
+	}
+	h.removeAll(removals)
+}
+
+// replaceAt replaces the item at i, l being held. An ordered set drops a
+// substitute already present along with the item it replaces.
+func (h *arrayHistory) replaceAt(i int, substitute interface{}) interface{} {
+	if h.unique && h.a.Has(substitute) && h.a.Get(i) != substitute {
+		item := h.a.RemoveAt(i)
+		h.removeAll([]removal{{i, item}})
+		return item
+	}
+	item := h.a.ReplaceAt(i, substitute)
+	h.record(func() {
+		h.a.ReplaceAt(i, item)
+	}, func() {
+		h.a.ReplaceAt(i, substitute)
+	})
+	return item
+}
+
+func (h *arrayHistory) Add(items ...interface{}) {
+	h.l.Lock()
+	defer h.l.Unlock()
+	h.insert(h.a.Len(), items)
+}
+
+func (h *arrayHistory) Insert(i int, items ...interface{}) {
+	h.l.Lock()
+	defer h.l.Unlock()
+	if i < 0 || i >= h.a.Len() {
+		panic(array.ErrIndexOutOfBounds)
+	}
+	h.insert(i, items)
+}
+
+func (h *arrayHistory) Remove(items ...interface{}) {
+	h.l.Lock()
+	defer h.l.Unlock()
+	h.remove(items)
+}
+
+func (h *arrayHistory) RemoveAt(i int) interface{} {
+	h.l.Lock()
+	defer h.l.Unlock()
+	item := h.a.RemoveAt(i)
+	h.removeAll([]removal{{i, item}})
+	return item
+}
+
+func (h *arrayHistory) Replace(item, substitute interface{}) {
+	h.l.Lock()
+	defer h.l.Unlock()
+	if i, err := h.a.IndexOf(item); err == nil {
+		h.replaceAt(i, substitute)
+	}
+}
+
+func (h *arrayHistory) ReplaceAt(i int, substitute interface{}) interface{} {
+	h.l.Lock()
+	defer h.l.Unlock()
+	return h.replaceAt(i, substitute)
+}
+
+func (h *arrayHistory) Swap(i, j int) {
+	h.l.Lock()
+	defer h.l.Unlock()
+	h.a.Swap(i, j)
+	h.record(func() {
+		h.a.Swap(i, j)
+	}, func() {
+		h.a.Swap(i, j)
+	})
+}
+
+func (h *arrayHistory) Clear() {
+	h.l.Lock()
+	defer h.l.Unlock()
+	items := h.a.Slice()
+	if len(items) == 0 {
+		return
+	}
+	h.a.Clear()
+	h.record(func() {
+		h.a.Add(items...)
+	}, func() {
+		h.a.Clear()
+	})
+}
+
+func (h *arrayHistory) Merge(t collection.Interface) {
+	h.l.Lock()
+	defer h.l.Unlock()
+	var items []interface{}
+	h.operand(t).Each(func(item interface{}) bool {
+		if !h.a.Has(item) {
+			items = append(items, item)
+		}
+		return true
+	})
+	h.insert(h.a.Len(), items)
+}
+
+func (h *arrayHistory) Separate(t collection.Interface) {
+	h.l.Lock()
+	defer h.l.Unlock()
+	h.remove(h.operand(t).Slice())
+}
+
+// Retain removes items from the last to the first, as the wrapped array does.
+func (h *arrayHistory) Retain(t collection.Interface) {
+	h.l.Lock()
+	defer h.l.Unlock()
+	t = h.operand(t).(collection.Interface)
+	var removals []removal
+	for i := h.a.Len() - 1; i >= 0; i-- {
+		if !t.Has(h.a.Get(i)) {
+			removals = append(removals, removal{i, h.a.RemoveAt(i)})
+		}
+	}
+	h.removeAll(removals)
+}
+
+// splice applies f, which removes items from i, returns them and inserts
+// others at i, then records the modification, l being held
+func (h *arrayHistory) splice(i int, f func() []interface{}, redo func()) []interface{} {
+	length := h.a.Len()
+	removed := f()
+	inserted := h.a.Len() - length + len(removed)
+	if len(removed) > 0 || inserted > 0 {
+		h.record(func() {
+			h.a.Splice(i, inserted, removed...)
+		}, redo)
+	}
+	return removed
+}
+
+func (h *arrayHistory) Splice(i, deleteCount int, items ...interface{}) []interface{} {
+	h.l.Lock()
+	defer h.l.Unlock()
+	return h.splice(i, func() []interface{} {
+		return h.a.Splice(i, deleteCount, items...)
+	}, func() {
+		h.a.Splice(i, deleteCount, items...)
+	})
+}
+
+func (h *arrayHistory) RemoveRange(i, j int) {
+	h.Splice(i, j-i)
+}
+
+func (h *arrayHistory) InsertAll(i int, t collection.ReadOnly) {
+	h.l.Lock()
+	defer h.l.Unlock()
+	items := h.operand(t).Slice()
+	h.splice(i, func() []interface{} {
+		return h.a.Splice(i, 0, items...)
+	}, func() {
+		h.a.Splice(i, 0, items...)
+	})
+}
+
+// Fill records the items from i to j as removed and the filling ones as inserted.
+func (h *arrayHistory) Fill(i, j int, item interface{}) {
+	h.l.Lock()
+	defer h.l.Unlock()
+	fill := func() {
+		h.a.Fill(i, j, item)
+	}
+	h.splice(i, func() []interface{} {
+		removed := h.a.SliceRange(i, j).Slice()
+		fill()
+		return removed
+	}, fill)
+}
+
+// RemoveIf records the items before and after the removal.
+func (h *arrayHistory) RemoveIf(pred func(item interface{}) bool) (count int) {
+	h.l.Lock()
+	defer h.l.Unlock()
+	h.rewrite(func() {
+		count = h.a.RemoveIf(pred)
+	})
+	return count
+}
+
+// ReplaceIf records the items before and after the replacement.
+func (h *arrayHistory) ReplaceIf(pred func(item interface{}) bool, substitute func(item interface{}) interface{}) (count int) {
+	h.l.Lock()
+	defer h.l.Unlock()
+	h.rewrite(func() {
+		count = h.a.ReplaceIf(pred, substitute)
+	})
+	return count
+}
+
+// rewrite applies f, then records the items before and after it if they
+// differ, l being held
+func (h *arrayHistory) rewrite(f func()) {
+	before := h.a.Slice()
+	f()
+	after := h.a.Slice()
+	if array.New(before...).IsEqual(array.New(after...)) {
+		return
+	}
+	h.record(func() {
+		h.a.Splice(0, h.a.Len(), before...)
+	}, func() {
+		h.a.Splice(0, h.a.Len(), after...)
+	})
+}
+
+func (h *arrayHistory) Reverse() {
+	h.l.Lock()
+	defer h.l.Unlock()
+	if h.a.Len() < 2 {
+		return
+	}
+	h.a.Reverse()
+	h.record(h.a.Reverse, h.a.Reverse)
+}
+
+func (h *arrayHistory) Rotate(k int) {
+	h.l.Lock()
+	defer h.l.Unlock()
+	if h.a.Len() == 0 || k%h.a.Len() == 0 {
+		return
+	}
+	h.a.Rotate(k)
+	h.record(func() {
+		h.a.Rotate(-k)
+	}, func() {
+		h.a.Rotate(k)
+	})
+}
+
+func (h *arrayHistory) Get(i int) interface{} {
+	h.l.RLock()
+	defer h.l.RUnlock()
+	return h.a.Get(i)
+}
+
+func (h *arrayHistory) IndexOf(item interface{}) (int, error) {
+	h.l.RLock()
+	defer h.l.RUnlock()
+	return h.a.IndexOf(item)
+}
+
+func (h *arrayHistory) Find(pred func(item interface{}) bool) (interface{}, error) {
+	h.l.RLock()
+	defer h.l.RUnlock()
+	return h.a.Find(pred)
+}
+
+func (h *arrayHistory) FindIndex(pred func(item interface{}) bool) (int, error) {
+	h.l.RLock()
+	defer h.l.RUnlock()
+	return h.a.FindIndex(pred)
+}
+
+func (h *arrayHistory) FindLast(pred func(item interface{}) bool) (interface{}, error) {
+	h.l.RLock()
+	defer h.l.RUnlock()
+	return h.a.FindLast(pred)
+}
+
+func (h *arrayHistory) LastIndexOf(item interface{}) (int, error) {
+	h.l.RLock()
+	defer h.l.RUnlock()
+	return h.a.LastIndexOf(item)
+}
+
+func (h *arrayHistory) IndexOfFrom(item interface{}, start int) (int, error) {
+	h.l.RLock()
+	defer h.l.RUnlock()
+	return h.a.IndexOfFrom(item, start)
+}
+
+func (h *arrayHistory) Count(pred func(item interface{}) bool) int {
+	h.l.RLock()
+	defer h.l.RUnlock()
+	return h.a.Count(pred)
+}
+
+func (h *arrayHistory) Has(items ...interface{}) bool {
+	h.l.RLock()
+	defer h.l.RUnlock()
+	return h.a.Has(items...)
+}
+
+func (h *arrayHistory) Each(f func(item interface{}) bool) {
+	h.l.RLock()
+	defer h.l.RUnlock()
+	h.a.Each(f)
+}
+
+func (h *arrayHistory) Len() int {
+	h.l.RLock()
+	defer h.l.RUnlock()
+	return h.a.Len()
+}
+
+func (h *arrayHistory) IsEmpty() bool {
+	h.l.RLock()
+	defer h.l.RUnlock()
+	return h.a.IsEmpty()
+}
+
+func (h *arrayHistory) IsEqual(t collection.ReadOnly) bool {
+	h.l.RLock()
+	defer h.l.RUnlock()
+	return h.a.IsEqual(h.operand(t))
+}
+
+func (h *arrayHistory) String() string {
+	h.l.RLock()
+	defer h.l.RUnlock()
+	return h.a.String()
+}
+
+func (h *arrayHistory) Format(f fmt.State, verb rune) {
+	h.l.RLock()
+	defer h.l.RUnlock()
+	format.Delegate(f, verb, h.a)
+}
+
+func (h *arrayHistory) Slice() []interface{} {
+	h.l.RLock()
+	defer h.l.RUnlock()
+	return h.a.Slice()
+}
+
+// SubArray returns a copy of items from i to j, without history.
+func (h *arrayHistory) SubArray(i, j int) array.Interface {
+	h.l.RLock()
+	defer h.l.RUnlock()
+	return h.a.SubArray(i, j)
+}
+
+// SliceRange returns a copy of items from i included to j excluded, without history.
+func (h *arrayHistory) SliceRange(i, j int) array.Interface {
+	h.l.RLock()
+	defer h.l.RUnlock()
+	return h.a.SliceRange(i, j)
+}
+
+// Repeat returns a new array holding the items n times, without history.
+func (h *arrayHistory) Repeat(n int) array.Interface {
+	h.l.RLock()
+	defer h.l.RUnlock()
+	return h.a.Repeat(n)
+}
+
+// Concat returns a new array holding the items followed by those of each t,
+// without history.
+func (h *arrayHistory) Concat(t ...collection.ReadOnly) array.Interface {
+	h.l.RLock()
+	defer h.l.RUnlock()
+	operands := make([]collection.ReadOnly, 0, len(t))
+	for _, c := range t {
+		operands = append(operands, h.operand(c))
+	}
+	return h.a.Concat(operands...)
+}
+
+// CopyArr returns a copy of the wrapped array, without history.
+func (h *arrayHistory) CopyArr() array.Interface {
+	h.l.RLock()
+	defer h.l.RUnlock()
+	return h.a.CopyArr()
+}
+
+func (h *arrayHistory) CopyCollection() collection.Interface {
+	return h.CopyArr()
+}
+
+// insertAt inserts items at i, which may be the length of a
+func insertAt(a array.Interface, i int, items []interface{}) {
+	if i == a.Len() {
+		a.Add(items...)
+	} else {
+		a.Insert(i, items...)
+	}
+}
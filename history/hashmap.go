@@ -0,0 +1,163 @@
+package history
+
+import (
+	"fmt"
+
+	"github.com/khezen/struct/hashmap"
+	"github.com/khezen/struct/internal/format"
+)
+
+// Hashmap is a thread safe hashmap recording its history
+type Hashmap interface {
+	hashmap.Interface
+	History
+}
+
+type hashmapHistory struct {
+	*recorder
+	h hashmap.Interface
+}
+
+// NewHashmap wraps h to record its modifications, keeping at most limit steps
+// of history, or all of them when limit is 0. h must not be modified but
+// through the wrapper.
+func NewHashmap(h hashmap.Interface, limit int) Hashmap {
+	return &hashmapHistory{newRecorder(limit), h}
+}
+
+func (h *hashmapHistory) Put(k, v interface{}) {
+	h.l.Lock()
+	defer h.l.Unlock()
+	old, err := h.h.Get(k)
+	h.h.Put(k, v)
+	h.record(func() {
+		if err != nil {
+			h.h.Remove(k)
+		} else {
+			h.h.Put(k, old)
+		}
+	}, func() {
+		h.h.Put(k, v)
+	})
+}
+
+func (h *hashmapHistory) Remove(keys ...interface{}) {
+	h.l.Lock()
+	defer h.l.Unlock()
+	h.remove(keys)
+}
+
+// remove removes the keys which are present, l being held
+func (h *hashmapHistory) remove(keys []interface{}) {
+	var removed, values []interface{}
+	for _, k := range keys {
+		if v, err := h.h.Get(k); err == nil {
+			h.h.Remove(k)
+			removed = append(removed, k)
+			values = append(values, v)
+		}
+	}
+	if len(removed) > 0 {
+		h.record(func() {
+			for i, k := range removed {
+				h.h.Put(k, values[i])
+			}
+		}, func() {
+			h.h.Remove(removed...)
+		})
+	}
+}
+
+func (h *hashmapHistory) Clear() {
+	h.l.Lock()
+	defer h.l.Unlock()
+	h.remove(h.h.Keys())
+}
+
+func (h *hashmapHistory) Get(k interface{}) (interface{}, error) {
+	h.l.RLock()
+	defer h.l.RUnlock()
+	return h.h.Get(k)
+}
+
+func (h *hashmapHistory) Has(keys ...interface{}) bool {
+	h.l.RLock()
+	defer h.l.RUnlock()
+	return h.h.Has(keys...)
+}
+
+func (h *hashmapHistory) HasValue(values ...interface{}) bool {
+	h.l.RLock()
+	defer h.l.RUnlock()
+	return h.h.HasValue(values...)
+}
+
+func (h *hashmapHistory) KeyOf(value interface{}) (interface{}, error) {
+	h.l.RLock()
+	defer h.l.RUnlock()
+	return h.h.KeyOf(value)
+}
+
+func (h *hashmapHistory) Each(f func(k, v interface{}) bool) {
+	h.l.RLock()
+	defer h.l.RUnlock()
+	h.h.Each(f)
+}
+
+func (h *hashmapHistory) Len() int {
+	h.l.RLock()
+	defer h.l.RUnlock()
+	return h.h.Len()
+}
+
+func (h *hashmapHistory) IsEmpty() bool {
+	h.l.RLock()
+	defer h.l.RUnlock()
+	return h.h.IsEmpty()
+}
+
+func (h *hashmapHistory) IsEqual(t hashmap.ReadOnly) bool {
+	h.l.RLock()
+	defer h.l.RUnlock()
+	if t == hashmap.ReadOnly(h) {
+		return true
+	}
+	return h.h.IsEqual(t)
+}
+
+func (h *hashmapHistory) String() string {
+	h.l.RLock()
+	defer h.l.RUnlock()
+	return h.h.String()
+}
+
+func (h *hashmapHistory) Format(f fmt.State, verb rune) {
+	h.l.RLock()
+	defer h.l.RUnlock()
+	format.Delegate(f, verb, h.h)
+}
+
+func (h *hashmapHistory) Keys() []interface{} {
+	h.l.RLock()
+	defer h.l.RUnlock()
+	return h.h.Keys()
+}
+
+func (h *hashmapHistory) Values() []interface{} {
+	h.l.RLock()
+	defer h.l.RUnlock()
+	return h.h.Values()
+}
+
+func (h *hashmapHistory) Map() map[interface{}]interface{} {
+	h.l.RLock()
+	defer h.l.RUnlock()
+	return h.h.Map()
+}
+
+// Copy returns a copy of the wrapped hashmap, without history.
+func (h *hashmapHistory) Copy() hashmap.Interface {
+	h.l.RLock()
+	defer h.l.RUnlock()
+	return h.h.Copy()
+}
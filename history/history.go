@@ -0,0 +1,226 @@
+// Package history wraps sets, arrays, ordered sets and hashmaps to record the
+// inverse of every modification, so that modifications can be undone and
+// redone, grouped into transactions, and rolled back to checkpoints.
+package history
+
+import (
+	"errors"
+	"sync"
+)
+
+// History describes the methods shared by the collections of this package
+type History interface {
+	// Undo reverts the last modification or transaction. It returns false when there is none.
+	Undo() bool
+	// Redo reapplies the last undone modification or transaction. It returns false when there is none.
+	Redo() bool
+	// Begin opens a transaction grouping the following modifications into a
+	// single step of the history. Transactions may be nested.
+	Begin()
+	// Commit closes the transaction opened by the matching Begin.
+	Commit()
+	// Rollback reverts and discards the modifications of the open transaction.
+	Rollback()
+	// Checkpoint identifies the current state of the collection
+	Checkpoint() Checkpoint
+	// RestoreTo undoes or redoes modifications until the collection is in the state of c.
+	RestoreTo(c Checkpoint) error
+	// ClearHistory forgets every recorded modification
+	ClearHistory()
+}
+
+// Checkpoint identifies a state of a collection in its history
+type Checkpoint uint64
+
+var (
+	// ErrTransaction - operation not allowed with respect to the open transaction
+	ErrTransaction = errors.New("ErrTransaction - operation not allowed with respect to the open transaction")
+	// ErrUnknownCheckpoint - checkpoint is not in the history anymore
+	ErrUnknownCheckpoint = errors.New("ErrUnknownCheckpoint - checkpoint is not in the history anymore")
+)
+
+// step is a modification and its inverse
+type step struct {
+	undo, redo func()
+}
+
+// group is a step of the history, made of one modification or of a transaction
+type group struct {
+	id    uint64
+	steps []step
+}
+
+func (g *group) undo() {
+	for i := len(g.steps) - 1; i >= 0; i-- {
+		g.steps[i].undo()
+	}
+}
+
+func (g *group) redo() {
+	for _, s := range g.steps {
+		s.redo()
+	}
+}
+
+// recorder implements History. Its lock also guards the wrapped collection.
+type recorder struct {
+	l     sync.RWMutex
+	limit int
+	undos []*group
+	redos []*group
+	tx    *group
+	depth int
+	// last is the last group id given, base the state preceding the oldest undo
+	last, base uint64
+}
+
+func newRecorder(limit int) *recorder {
+	return &recorder{limit: limit}
+}
+
+// record adds a modification applied on the collection, l being held
+func (r *recorder) record(undo, redo func()) {
+	if r.tx != nil {
+		r.tx.steps = append(r.tx.steps, step{undo, redo})
+		return
+	}
+	r.push(&group{steps: []step{{undo, redo}}})
+}
+
+func (r *recorder) push(g *group) {
+	r.last++
+	g.id = r.last
+	r.undos = append(r.undos, g)
+	r.redos = nil
+	if r.limit > 0 && len(r.undos) > r.limit {
+		r.base = r.undos[0].id
+		r.undos[0] = nil
+		r.undos = r.undos[1:]
+	}
+}
+
+func (r *recorder) Undo() bool {
+	r.l.Lock()
+	defer r.l.Unlock()
+	if r.tx != nil {
+		panic(ErrTransaction)
+	}
+	return r.undo()
+}
+
+func (r *recorder) undo() bool {
+	if len(r.undos) == 0 {
+		return false
+	}
+	g := r.undos[len(r.undos)-1]
+	r.undos = r.undos[:len(r.undos)-1]
+	g.undo()
+	r.redos = append(r.redos, g)
+	return true
+}
+
+func (r *recorder) Redo() bool {
+	r.l.Lock()
+	defer r.l.Unlock()
+	if r.tx != nil {
+		panic(ErrTransaction)
+	}
+	return r.redo()
+}
+
+func (r *recorder) redo() bool {
+	if len(r.redos) == 0 {
+		return false
+	}
+	g := r.redos[len(r.redos)-1]
+	r.redos = r.redos[:len(r.redos)-1]
+	g.redo()
+	r.undos = append(r.undos, g)
+	return true
+}
+
+func (r *recorder) Begin() {
+	r.l.Lock()
+	defer r.l.Unlock()
+	if r.tx == nil {
+		r.tx = &group{}
+	}
+	r.depth++
+}
+
+func (r *recorder) Commit() {
+	r.l.Lock()
+	defer r.l.Unlock()
+	if r.tx == nil {
+		panic(ErrTransaction)
+	}
+	r.depth--
+	if r.depth == 0 {
+		if len(r.tx.steps) > 0 {
+			r.push(r.tx)
+		}
+		r.tx = nil
+	}
+}
+
+func (r *recorder) Rollback() {
+	r.l.Lock()
+	defer r.l.Unlock()
+	if r.tx == nil {
+		panic(ErrTransaction)
+	}
+	r.tx.undo()
+	r.tx = nil
+	r.depth = 0
+}
+
+func (r *recorder) Checkpoint() Checkpoint {
+	r.l.RLock()
+	defer r.l.RUnlock()
+	return r.current()
+}
+
+func (r *recorder) current() Checkpoint {
+	if len(r.undos) == 0 {
+		return Checkpoint(r.base)
+	}
+	return Checkpoint(r.undos[len(r.undos)-1].id)
+}
+
+func (r *recorder) RestoreTo(c Checkpoint) error {
+	r.l.Lock()
+	defer r.l.Unlock()
+	if r.tx != nil {
+		panic(ErrTransaction)
+	}
+	switch {
+	case Checkpoint(r.base) == c || contains(r.undos, c):
+		for r.current() != c {
+			r.undo()
+		}
+	case contains(r.redos, c):
+		for r.current() != c {
+			r.redo()
+		}
+	default:
+		return ErrUnknownCheckpoint
+	}
+	return nil
+}
+
+func contains(groups []*group, c Checkpoint) bool {
+	for _, g := range groups {
+		if Checkpoint(g.id) == c {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *recorder) ClearHistory() {
+	r.l.Lock()
+	defer r.l.Unlock()
+	r.base = uint64(r.current())
+	r.undos = nil
+	r.redos = nil
+}
@@ -0,0 +1,332 @@
+package history
+
+import (
+	"fmt"
+	"sort"
+	"testing"
+
+	"github.com/khezen/struct/array"
+	"github.com/khezen/struct/collection"
+	"github.com/khezen/struct/hashmap"
+	"github.com/khezen/struct/oset"
+	"github.com/khezen/struct/set"
+)
+
+func testErr(err error, expectErr bool, t *testing.T) {
+	if (expectErr && err == nil) || (!expectErr && err != nil) {
+		t.Errorf(" Error expected? %v. Got: %v.", expectErr, err)
+	}
+}
+
+// sorted formats items in a deterministic order
+func sorted(items []interface{}) string {
+	strs := make([]string, 0, len(items))
+	for _, item := range items {
+		strs = append(strs, fmt.Sprint(item))
+	}
+	sort.Strings(strs)
+	return fmt.Sprint(strs)
+}
+
+// testUndoRedo applies ops one by one, then undoes and redoes all of them,
+// comparing the collection to the state it had after each op. Ops which do
+// not modify the collection are not recorded.
+func testUndoRedo(h History, state func() string, ops []func(), t *testing.T) {
+	states := []string{state()}
+	for _, op := range ops {
+		op()
+		if st := state(); st != states[len(states)-1] {
+			states = append(states, st)
+		}
+	}
+	for i := len(states) - 2; i >= 0; i-- {
+		if !h.Undo() {
+			t.Errorf("Expected %v. Got %v.", true, false)
+		}
+		if got := state(); got != states[i] {
+			t.Errorf("Expected %v. Got %v.", states[i], got)
+		}
+	}
+	if h.Undo() {
+		t.Errorf("Expected %v. Got %v.", false, true)
+	}
+	for i := 1; i < len(states); i++ {
+		if !h.Redo() {
+			t.Errorf("Expected %v. Got %v.", true, false)
+		}
+		if got := state(); got != states[i] {
+			t.Errorf("Expected %v. Got %v.", states[i], got)
+		}
+	}
+	if h.Redo() {
+		t.Errorf("Expected %v. Got %v.", false, true)
+	}
+}
+
+func TestSet(t *testing.T) {
+	s := NewSet(set.New(1, 2), 0)
+	state := func() string {
+		return sorted(s.Slice())
+	}
+	testUndoRedo(s, state, []func(){
+		func() { s.Add(2, 3, 4) },
+		func() { s.Remove(1, 5) },
+		func() { s.Replace(2, 3) },
+		func() { s.Replace(3, 7) },
+		func() { s.Pop() },
+		func() { s.Merge(set.New(8, 9)) },
+		func() { s.Separate(set.New(8)) },
+		func() { s.Retain(set.New(9, 7, 4)) },
+		func() { s.Merge(s) },
+		func() { s.Clear() },
+	}, t)
+	c := s.Checkpoint()
+	s.Remove(1000)
+	s.Replace(1000, 1)
+	if s.Checkpoint() != c {
+		t.Errorf("Expected %v. Got %v.", c, s.Checkpoint())
+	}
+}
+
+func TestArray(t *testing.T) {
+	a := NewArray(array.New(1, 2, 3), 0)
+	state := func() string {
+		return a.String()
+	}
+	testUndoRedo(a, state, []func(){
+		func() { a.Add(4, 4) },
+		func() { a.Insert(0, 0, 1) },
+		func() { a.Remove(1, 4, 1000) },
+		func() { a.RemoveAt(a.Len() - 1) },
+		func() { a.Replace(3, 30) },
+		func() { a.ReplaceAt(0, -1) },
+		func() { a.Swap(0, 1) },
+		func() { a.Merge(array.New(1, 5, 6)) },
+		func() { a.Separate(array.New(5, 1)) },
+		func() { a.Retain(array.New(30, 6, -1)) },
+		func() { a.Merge(a) },
+		func() { a.Splice(1, 1, 7, 8) },
+		func() { a.InsertAll(0, a) },
+		func() { a.RemoveRange(1, 3) },
+		func() { a.Reverse() },
+		func() { a.Rotate(2) },
+		func() { a.Rotate(a.Len()) },
+		func() { a.Fill(0, 2, 9) },
+		func() { a.Splice(0, 0) },
+		func() { a.RemoveIf(func(item interface{}) bool { return item == 9 }) },
+		func() {
+			a.ReplaceIf(func(item interface{}) bool { return true }, func(item interface{}) interface{} { return 1 })
+		},
+		func() {
+			a.ReplaceIf(func(item interface{}) bool { return true }, func(item interface{}) interface{} { return 1 })
+		},
+		func() { a.Clear() },
+	}, t)
+}
+
+func TestOset(t *testing.T) {
+	s := NewOset(oset.New(1, 2, 3), 0)
+	state := func() string {
+		return s.String()
+	}
+	testUndoRedo(s, state, []func(){
+		func() { s.Add(3, 4, 4, 5) },
+		func() { s.Insert(1, 1, 6, 6, 7) },
+		func() { s.Remove(1, 4) },
+		func() { s.RemoveAt(0) },
+		func() { s.Replace(3, 30) },
+		func() { s.ReplaceAt(0, 60) },
+		func() { s.Replace(2, 60) },
+		func() { s.Add(2) },
+		func() { s.Swap(0, 2) },
+		func() { s.Merge(oset.New(2, 8, 9)) },
+		func() { s.Separate(oset.New(8)) },
+		func() { s.Retain(oset.New(60, 9, 5)) },
+		func() { s.Retain(s) },
+		func() { s.Splice(1, 1, 7, 60, 8) },
+		func() { s.InsertAll(0, oset.New(10, 11, 7)) },
+		func() { s.InsertAll(0, s) },
+		func() { s.RemoveRange(0, 1) },
+		func() { s.Reverse() },
+		func() { s.Rotate(-1) },
+		func() { s.Fill(1, 3, 5) },
+		func() { s.RemoveIf(func(item interface{}) bool { return item == 5 }) },
+		func() {
+			s.ReplaceIf(func(item interface{}) bool { return true }, func(item interface{}) interface{} { return 1 })
+		},
+		func() { s.Clear() },
+	}, t)
+	s.Undo()
+	if !s.Set().IsEqual(set.New(s.Slice()...)) || !s.Arr().IsEqual(array.New(s.Slice()...)) {
+		t.Errorf("Expected %v. Got %v.", s, s.Arr())
+	}
+	if !s.Concat(s).IsEqual(s) || !s.Repeat(2).IsEqual(s) || !s.SliceRange(0, s.Len()).IsEqual(s) {
+		t.Errorf("Expected %v. Got %v.", true, false)
+	}
+	if !s.IsSubset(oset.New(s.Get(0))) || !s.IsSuperset(s) || !s.CopyOset().IsEqual(s.Subset(0, s.Len()-1)) {
+		t.Errorf("Expected %v. Got %v.", true, false)
+	}
+}
+
+func TestHashmap(t *testing.T) {
+	h := NewHashmap(hashmap.New("a", 1, "b", 2), 0)
+	state := func() string {
+		var pairs []interface{}
+		h.Each(func(k, v interface{}) bool {
+			pairs = append(pairs, fmt.Sprint(k, v))
+			return true
+		})
+		return sorted(pairs)
+	}
+	testUndoRedo(h, state, []func(){
+		func() { h.Put("c", 3) },
+		func() { h.Put("a", 10) },
+		func() { h.Remove("b", "z") },
+		func() { h.Clear() },
+	}, t)
+}
+
+func TestTransaction(t *testing.T) {
+	a := NewArray(array.New(), 0)
+	a.Add(1)
+	a.Begin()
+	a.Add(2)
+	a.Begin()
+	a.Add(3)
+	a.Commit()
+	a.Add(4)
+	a.Commit()
+	a.Begin()
+	a.Commit()
+	expected := array.New(1)
+	if a.Undo(); !a.IsEqual(expected) {
+		t.Errorf("Expected %v. Got %v.", expected, a)
+	}
+	expected = array.New(1, 2, 3, 4)
+	if a.Redo(); !a.IsEqual(expected) {
+		t.Errorf("Expected %v. Got %v.", expected, a)
+	}
+	a.Begin()
+	a.RemoveAt(0)
+	a.Begin()
+	a.Swap(0, 1)
+	a.Rollback()
+	if !a.IsEqual(expected) {
+		t.Errorf("Expected %v. Got %v.", expected, a)
+	}
+	a.Undo()
+	expected = array.New(1)
+	if !a.IsEqual(expected) {
+		t.Errorf("Expected %v. Got %v.", expected, a)
+	}
+}
+
+func TestTransactionPanic(t *testing.T) {
+	a := NewArray(array.New(), 0)
+	cases := []func(){
+		func() { a.Commit() },
+		func() { a.Rollback() },
+		func() { a.Begin(); a.Undo() },
+		func() { a.Redo() },
+		func() { a.RestoreTo(0) },
+	}
+	for _, c := range cases {
+		func() {
+			defer func() {
+				if r := recover(); r != ErrTransaction {
+					t.Errorf("Expected %v. Got %v.", ErrTransaction, r)
+				}
+			}()
+			c()
+		}()
+	}
+}
+
+func TestLimit(t *testing.T) {
+	s := NewSet(set.New(), 2)
+	start := s.Checkpoint()
+	s.Add(1)
+	s.Add(2)
+	s.Add(3)
+	for s.Undo() {
+	}
+	expected := set.New(1)
+	if !s.IsEqual(expected) {
+		t.Errorf("Expected %v. Got %v.", expected, s)
+	}
+	testErr(s.RestoreTo(start), true, t)
+	testErr(s.RestoreTo(s.Checkpoint()), false, t)
+}
+
+func TestCheckpoint(t *testing.T) {
+	h := NewHashmap(hashmap.New(), 0)
+	c0 := h.Checkpoint()
+	h.Put(1, 1)
+	c1 := h.Checkpoint()
+	h.Put(2, 2)
+	h.Put(3, 3)
+	c3 := h.Checkpoint()
+	cases := []struct {
+		c         Checkpoint
+		expected  hashmap.Interface
+		expectErr bool
+	}{
+		{c1, hashmap.New(1, 1), false},
+		{c3, hashmap.New(1, 1, 2, 2, 3, 3), false},
+		{c0, hashmap.New(), false},
+		{c1, hashmap.New(1, 1), false},
+		{Checkpoint(1000), hashmap.New(1, 1), true},
+	}
+	for _, c := range cases {
+		testErr(h.RestoreTo(c.c), c.expectErr, t)
+		if !h.IsEqual(c.expected) {
+			t.Errorf("Expected %v. Got %v.", c.expected, h)
+		}
+	}
+	h.Put(4, 4)
+	testErr(h.RestoreTo(c3), true, t)
+	h.ClearHistory()
+	if h.Undo() || h.Redo() {
+		t.Errorf("Expected %v. Got %v.", false, true)
+	}
+	testErr(h.RestoreTo(c1), true, t)
+	testErr(h.RestoreTo(h.Checkpoint()), false, t)
+	expected := hashmap.New(1, 1, 4, 4)
+	if !h.IsEqual(expected) {
+		t.Errorf("Expected %v. Got %v.", expected, h)
+	}
+}
+
+func TestCopy(t *testing.T) {
+	cases := []struct {
+		c        collection.Interface
+		expected collection.Interface
+	}{
+		{NewSet(set.New(1, 2), 0), set.New(1, 2)},
+		{NewArray(array.New(1, 2), 0), array.New(1, 2)},
+		{NewOset(oset.New(1, 2), 0), oset.New(1, 2)},
+	}
+	a := NewArray(array.New(1, 2), 0)
+	one := func(item interface{}) bool { return item == 1 }
+	i, _ := a.FindIndex(one)
+	j, _ := a.LastIndexOf(1)
+	k, _ := a.IndexOfFrom(1, 0)
+	v, _ := a.Find(one)
+	w, _ := a.FindLast(one)
+	if i != 0 || j != 0 || k != 0 || v != 1 || w != 1 || a.Count(one) != 1 {
+		t.Errorf("Expected %v. Got %v.", 0, i)
+	}
+	if !a.Concat(a).IsEqual(array.New(1, 2, 1, 2)) || !a.Repeat(2).IsEqual(a.Concat(a)) || !a.SliceRange(1, 2).IsEqual(array.New(2)) {
+		t.Errorf("Expected %v. Got %v.", true, false)
+	}
+	for _, c := range cases {
+		cp := c.c.CopyCollection()
+		if _, ok := cp.(History); ok || !cp.IsEqual(c.expected) || !c.c.IsEqual(c.c) {
+			t.Errorf("Expected %v. Got %v.", c.expected, cp)
+		}
+	}
+	h := NewHashmap(hashmap.New(1, 1), 0)
+	if cp := h.Copy(); !cp.IsEqual(h) || !h.IsEqual(h) || !h.Has(1) || !h.HasValue(1) {
+		t.Errorf("Expected %v. Got %v.", h, cp)
+	}
+}
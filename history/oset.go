@@ -0,0 +1,110 @@
+package history
+
+import (
+	"github.com/khezen/struct/array"
+	"github.com/khezen/struct/collection"
+	"github.com/khezen/struct/oset"
+	"github.com/khezen/struct/set"
+)
+
+// Oset is a thread safe ordered set recording its history
+type Oset interface {
+	oset.Interface
+	History
+}
+
+type osetHistory struct {
+	*arrayHistory
+	s oset.Interface
+}
+
+// NewOset wraps s to record its modifications, keeping at most limit steps
+// of history, or all of them when limit is 0. s must not be modified but
+// through the wrapper.
+func NewOset(s oset.Interface, limit int) Oset {
+	h := &osetHistory{s: s}
+	h.arrayHistory = &arrayHistory{recorder: newRecorder(limit), a: s, unique: true}
+	return h
+}
+
+// operand returns the wrapped ordered set when t is h itself, which is already locked
+func (h *osetHistory) operand(t collection.ReadOnly) collection.ReadOnly {
+	if t == collection.ReadOnly(h) {
+		return h.s
+	}
+	return t
+}
+
+func (h *osetHistory) Merge(t collection.Interface) {
+	h.arrayHistory.Merge(h.operand(t).(collection.Interface))
+}
+
+func (h *osetHistory) Separate(t collection.Interface) {
+	h.arrayHistory.Separate(h.operand(t).(collection.Interface))
+}
+
+func (h *osetHistory) Retain(t collection.Interface) {
+	h.arrayHistory.Retain(h.operand(t).(collection.Interface))
+}
+
+func (h *osetHistory) InsertAll(i int, t collection.ReadOnly) {
+	h.arrayHistory.InsertAll(i, h.operand(t))
+}
+
+func (h *osetHistory) Concat(t ...collection.ReadOnly) array.Interface {
+	operands := make([]collection.ReadOnly, 0, len(t))
+	for _, c := range t {
+		operands = append(operands, h.operand(c))
+	}
+	return h.arrayHistory.Concat(operands...)
+}
+
+func (h *osetHistory) IsEqual(t collection.ReadOnly) bool {
+	return h.arrayHistory.IsEqual(h.operand(t))
+}
+
+func (h *osetHistory) IsSubset(t oset.ReadOnly) bool {
+	h.l.RLock()
+	defer h.l.RUnlock()
+	return h.s.IsSubset(h.operand(t).(oset.ReadOnly))
+}
+
+func (h *osetHistory) IsSuperset(t oset.ReadOnly) bool {
+	h.l.RLock()
+	defer h.l.RUnlock()
+	return h.s.IsSuperset(h.operand(t).(oset.ReadOnly))
+}
+
+// Subset returns a copy of items from i to j, without history.
+func (h *osetHistory) Subset(i, j int) oset.Interface {
+	h.l.RLock()
+	defer h.l.RUnlock()
+	return h.s.Subset(i, j)
+}
+
+// Arr returns a read only copy of the underlying array.
+func (h *osetHistory) Arr() array.Interface {
+	return array.NewReadOnly(h.CopyArr())
+}
+
+// Set returns a read only copy of the underlying set.
+func (h *osetHistory) Set() set.Interface {
+	return set.NewReadOnly(h.CopySet())
+}
+
+// CopyOset returns a copy of the wrapped ordered set, without history.
+func (h *osetHistory) CopyOset() oset.Interface {
+	h.l.RLock()
+	defer h.l.RUnlock()
+	return h.s.CopyOset()
+}
+
+func (h *osetHistory) CopySet() set.Interface {
+	h.l.RLock()
+	defer h.l.RUnlock()
+	return h.s.CopySet()
+}
+
+func (h *osetHistory) CopyCollection() collection.Interface {
+	return h.CopyOset()
+}
@@ -0,0 +1,219 @@
+package history
+
+import (
+	"fmt"
+
+	"github.com/khezen/struct/collection"
+	"github.com/khezen/struct/internal/format"
+	"github.com/khezen/struct/set"
+)
+
+// Set is a thread safe set recording its history
+type Set interface {
+	set.Interface
+	History
+}
+
+type setHistory struct {
+	*recorder
+	s set.Interface
+}
+
+// NewSet wraps s to record its modifications, keeping at most limit steps of
+// history, or all of them when limit is 0. s must not be modified but through
+// the wrapper.
+func NewSet(s set.Interface, limit int) Set {
+	return &setHistory{newRecorder(limit), s}
+}
+
+// operand returns the wrapped set when t is h itself, which is already locked
+func (h *setHistory) operand(t collection.ReadOnly) collection.ReadOnly {
+	if t == collection.ReadOnly(h) {
+		return h.s
+	}
+	return t
+}
+
+// add adds the items which are missing, l being held
+func (h *setHistory) add(items []interface{}) {
+	var added []interface{}
+	for _, item := range items {
+		if !h.s.Has(item) {
+			h.s.Add(item)
+			added = append(added, item)
+		}
+	}
+	if len(added) > 0 {
+		h.record(func() {
+			h.s.Remove(added...)
+		}, func() {
+			h.s.Add(added...)
+		})
+	}
+}
+
+// remove removes the items which are present, l being held
+func (h *setHistory) remove(items []interface{}) {
+	var removed []interface{}
+	for _, item := range items {
+		if h.s.Has(item) {
+			h.s.Remove(item)
+			removed = append(removed, item)
+		}
+	}
+	if len(removed) > 0 {
+		h.record(func() {
+			h.s.Add(removed...)
+		}, func() {
+			h.s.Remove(removed...)
+		})
+	}
+}
+
+func (h *setHistory) Add(items ...interface{}) {
+	h.l.Lock()
+	defer h.l.Unlock()
+	h.add(items)
+}
+
+func (h *setHistory) Remove(items ...interface{}) {
+	h.l.Lock()
+	defer h.l.Unlock()
+	h.remove(items)
+}
+
+func (h *setHistory) Pop() (item interface{}) {
+	h.l.Lock()
+	defer h.l.Unlock()
+	if h.s.IsEmpty() {
+		return nil
+	}
+	item = h.s.Pop()
+	h.record(func() {
+		h.s.Add(item)
+	}, func() {
+		h.s.Remove(item)
+	})
+	return item
+}
+
+func (h *setHistory) Replace(item, substitute interface{}) {
+	h.l.Lock()
+	defer h.l.Unlock()
+	if !h.s.Has(item) || item == substitute {
+		return
+	}
+	present := h.s.Has(substitute)
+	h.s.Replace(item, substitute)
+	h.record(func() {
+		if !present {
+			h.s.Remove(substitute)
+		}
+		h.s.Add(item)
+	}, func() {
+		h.s.Replace(item, substitute)
+	})
+}
+
+func (h *setHistory) Clear() {
+	h.l.Lock()
+	defer h.l.Unlock()
+	h.remove(h.s.Slice())
+}
+
+func (h *setHistory) Merge(t collection.Interface) {
+	h.l.Lock()
+	defer h.l.Unlock()
+	h.add(h.operand(t).Slice())
+}
+
+func (h *setHistory) Separate(t collection.Interface) {
+	h.l.Lock()
+	defer h.l.Unlock()
+	h.remove(h.operand(t).Slice())
+}
+
+func (h *setHistory) Retain(t collection.Interface) {
+	h.l.Lock()
+	defer h.l.Unlock()
+	other := h.operand(t)
+	var items []interface{}
+	h.s.Each(func(item interface{}) bool {
+		if !other.Has(item) {
+			items = append(items, item)
+		}
+		return true
+	})
+	h.remove(items)
+}
+
+func (h *setHistory) Has(items ...interface{}) bool {
+	h.l.RLock()
+	defer h.l.RUnlock()
+	return h.s.Has(items...)
+}
+
+func (h *setHistory) Each(f func(item interface{}) bool) {
+	h.l.RLock()
+	defer h.l.RUnlock()
+	h.s.Each(f)
+}
+
+func (h *setHistory) Len() int {
+	h.l.RLock()
+	defer h.l.RUnlock()
+	return h.s.Len()
+}
+
+func (h *setHistory) IsEmpty() bool {
+	h.l.RLock()
+	defer h.l.RUnlock()
+	return h.s.IsEmpty()
+}
+
+func (h *setHistory) IsEqual(t collection.ReadOnly) bool {
+	h.l.RLock()
+	defer h.l.RUnlock()
+	return h.s.IsEqual(h.operand(t))
+}
+
+func (h *setHistory) IsSubset(t set.ReadOnly) bool {
+	h.l.RLock()
+	defer h.l.RUnlock()
+	return h.s.IsSubset(h.operand(t).(set.ReadOnly))
+}
+
+func (h *setHistory) IsSuperset(t set.ReadOnly) bool {
+	h.l.RLock()
+	defer h.l.RUnlock()
+	return h.s.IsSuperset(h.operand(t).(set.ReadOnly))
+}
+
+func (h *setHistory) String() string {
+	h.l.RLock()
+	defer h.l.RUnlock()
+	return h.s.String()
+}
+
+func (h *setHistory) Format(f fmt.State, verb rune) {
+	h.l.RLock()
+	defer h.l.RUnlock()
+	format.Delegate(f, verb, h.s)
+}
+
+func (h *setHistory) Slice() []interface{} {
+	h.l.RLock()
+	defer h.l.RUnlock()
+	return h.s.Slice()
+}
+
+// CopySet returns a copy of the wrapped set, without history.
+func (h *setHistory) CopySet() set.Interface {
+	h.l.RLock()
+	defer h.l.RUnlock()
+	return h.s.CopySet()
+}
+
+func (h *setHistory) CopyCollection() collection.Interface {
+	return h.CopySet()
+}
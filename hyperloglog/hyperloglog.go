@@ -0,0 +1,280 @@
+package hyperloglog
+
+import (
+	"encoding/binary"
+	"math"
+	"math/bits"
+	"sort"
+
+	"github.com/khezen/struct/collection"
+	"github.com/khezen/struct/internal/digest"
+)
+
+const (
+	// sparsePrecision is the precision of sparse entries, which keeps small counts accurate
+	sparsePrecision = 25
+	tag             = 'H'
+	version         = 1
+	sparseMode      = 0
+	denseMode       = 1
+)
+
+type hyperloglog struct {
+	p         uint8
+	sparse    map[uint32]uint8
+	registers []uint8
+}
+
+// New creates a sketch with 2^precision registers. Its standard error is about 1.04/sqrt(2^precision).
+func New(precision uint8) Interface {
+	if precision < MinPrecision || precision > MaxPrecision {
+		panic(ErrBadPrecision)
+	}
+	return &hyperloglog{precision, make(map[uint32]uint8), nil}
+}
+
+// FromCollection creates a sketch holding the items of the given collection
+func FromCollection(precision uint8, c collection.Interface) Interface {
+	h := New(precision)
+	c.Each(func(item interface{}) bool {
+		h.Add(item)
+		return true
+	})
+	return h
+}
+
+// rho returns the position of the first set bit of the hash bits left once the index is taken
+func rho(x uint64, p uint8) uint8 {
+	return uint8(bits.LeadingZeros64(x<<p|1<<(p-1))) + 1
+}
+
+// threshold is the number of sparse entries above which dense registers use less memory
+func (h *hyperloglog) threshold() int {
+	return 1 << h.p / 4
+}
+
+func (h *hyperloglog) Add(items ...interface{}) {
+	for _, item := range items {
+		x, _ := digest.Item(item)
+		if h.registers == nil {
+			idx := uint32(x >> (64 - sparsePrecision))
+			if r := rho(x, sparsePrecision); r > h.sparse[idx] {
+				h.sparse[idx] = r
+			}
+			if len(h.sparse) > h.threshold() {
+				h.densify()
+			}
+			continue
+		}
+		idx := x >> (64 - h.p)
+		if r := rho(x, h.p); r > h.registers[idx] {
+			h.registers[idx] = r
+		}
+	}
+}
+
+// dense converts a sparse entry to its register index and value
+func (h *hyperloglog) dense(idx uint32, r uint8) (uint32, uint8) {
+	shift := sparsePrecision - h.p
+	low := idx & (1<<shift - 1)
+	if low == 0 {
+		return idx >> shift, r + shift
+	}
+	return idx >> shift, uint8(bits.LeadingZeros32(low)) - (32 - shift) + 1
+}
+
+func (h *hyperloglog) densify() {
+	h.registers = make([]uint8, 1<<h.p)
+	for idx, r := range h.sparse {
+		h.mergeEntry(idx, r)
+	}
+	h.sparse = nil
+}
+
+func (h *hyperloglog) mergeEntry(idx uint32, r uint8) {
+	i, v := h.dense(idx, r)
+	if v > h.registers[i] {
+		h.registers[i] = v
+	}
+}
+
+// Count estimates the number of distinct items added to the sketch
+func (h *hyperloglog) Count() uint64 {
+	if h.registers == nil {
+		m := float64(uint64(1) << sparsePrecision)
+		return uint64(math.Round(m * math.Log(m/(m-float64(len(h.sparse))))))
+	}
+	m := float64(len(h.registers))
+	sum, zeros := 0.0, 0
+	for _, r := range h.registers {
+		sum += math.Ldexp(1, -int(r))
+		if r == 0 {
+			zeros++
+		}
+	}
+	estimate := alpha(len(h.registers)) * m * m / sum
+	if estimate <= 2.5*m && zeros > 0 {
+		estimate = m * math.Log(m/float64(zeros))
+	}
+	return uint64(math.Round(estimate))
+}
+
+func alpha(m int) float64 {
+	switch m {
+	case 16:
+		return 0.673
+	case 32:
+		return 0.697
+	case 64:
+		return 0.709
+	}
+	return 0.7213 / (1 + 1.079/float64(m))
+}
+
+// Merge adds every item of the given sketch into this one
+func (h *hyperloglog) Merge(t Interface) error {
+	if conv, ok := t.(*hyperloglogSync); ok {
+		conv.l.RLock()
+		defer conv.l.RUnlock()
+		t = &conv.hyperloglog
+	}
+	o, ok := t.(*hyperloglog)
+	if !ok || o.p != h.p {
+		return ErrIncompatible
+	}
+	switch {
+	case o.registers != nil:
+		if h.registers == nil {
+			h.densify()
+		}
+		for i, r := range o.registers {
+			if r > h.registers[i] {
+				h.registers[i] = r
+			}
+		}
+	case h.registers != nil:
+		for idx, r := range o.sparse {
+			h.mergeEntry(idx, r)
+		}
+	default:
+		for idx, r := range o.sparse {
+			if r > h.sparse[idx] {
+				h.sparse[idx] = r
+			}
+		}
+		if len(h.sparse) > h.threshold() {
+			h.densify()
+		}
+	}
+	return nil
+}
+
+func (h *hyperloglog) Clear() {
+	h.sparse, h.registers = make(map[uint32]uint8), nil
+}
+
+func (h *hyperloglog) IsEmpty() bool {
+	if h.registers == nil {
+		return len(h.sparse) == 0
+	}
+	for _, r := range h.registers {
+		if r != 0 {
+			return false
+		}
+	}
+	return true
+}
+
+func (h *hyperloglog) Precision() uint8 {
+	return h.p
+}
+
+func (h *hyperloglog) Copy() Interface {
+	return h.copy()
+}
+
+func (h *hyperloglog) copy() *hyperloglog {
+	c := &hyperloglog{p: h.p}
+	if h.registers != nil {
+		c.registers = make([]uint8, len(h.registers))
+		copy(c.registers, h.registers)
+		return c
+	}
+	c.sparse = make(map[uint32]uint8, len(h.sparse))
+	for idx, r := range h.sparse {
+		c.sparse[idx] = r
+	}
+	return c
+}
+
+// MarshalBinary encodes the sketch in a portable little endian format.
+// Sparse entries are written in ascending index order.
+func (h *hyperloglog) MarshalBinary() ([]byte, error) {
+	if h.registers != nil {
+		buf := make([]byte, 0, 4+len(h.registers))
+		buf = append(buf, tag, version, h.p, denseMode)
+		return append(buf, h.registers...), nil
+	}
+	idxs := make([]uint32, 0, len(h.sparse))
+	for idx := range h.sparse {
+		idxs = append(idxs, idx)
+	}
+	sort.Slice(idxs, func(i, j int) bool { return idxs[i] < idxs[j] })
+	buf := make([]byte, 0, 8+5*len(idxs))
+	buf = append(buf, tag, version, h.p, sparseMode)
+	buf = binary.LittleEndian.AppendUint32(buf, uint32(len(idxs)))
+	for _, idx := range idxs {
+		buf = binary.LittleEndian.AppendUint32(buf, idx)
+		buf = append(buf, h.sparse[idx])
+	}
+	return buf, nil
+}
+
+// UnmarshalBinary replaces the sketch with the one encoded in data
+func (h *hyperloglog) UnmarshalBinary(data []byte) error {
+	if len(data) < 4 || data[0] != tag || data[1] != version {
+		return ErrBadFormat
+	}
+	p, mode, data := data[2], data[3], data[4:]
+	if p < MinPrecision || p > MaxPrecision {
+		return ErrBadFormat
+	}
+	switch mode {
+	case denseMode:
+		if len(data) != 1<<p {
+			return ErrBadFormat
+		}
+		registers := make([]uint8, len(data))
+		for i, r := range data {
+			if r > 64-p+1 {
+				return ErrBadFormat
+			}
+			registers[i] = r
+		}
+		h.p, h.sparse, h.registers = p, nil, registers
+	case sparseMode:
+		if len(data) < 4 {
+			return ErrBadFormat
+		}
+		n := binary.LittleEndian.Uint32(data)
+		data = data[4:]
+		if uint64(len(data)) != 5*uint64(n) {
+			return ErrBadFormat
+		}
+		sparse := make(map[uint32]uint8, n)
+		for i := 0; i < len(data); i += 5 {
+			idx, r := binary.LittleEndian.Uint32(data[i:]), data[i+4]
+			if idx >= 1<<sparsePrecision || r == 0 || r > 64-sparsePrecision+1 {
+				return ErrBadFormat
+			}
+			if _, ok := sparse[idx]; ok {
+				return ErrBadFormat
+			}
+			sparse[idx] = r
+		}
+		h.p, h.sparse, h.registers = p, sparse, nil
+	default:
+		return ErrBadFormat
+	}
+	return nil
+}
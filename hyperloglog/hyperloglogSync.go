@@ -0,0 +1,86 @@
+package hyperloglog
+
+import (
+	"sync"
+
+	"github.com/khezen/struct/collection"
+)
+
+type hyperloglogSync struct {
+	hyperloglog
+	l sync.RWMutex
+}
+
+// NewSync creates a threadsafe sketch with 2^precision registers
+func NewSync(precision uint8) Interface {
+	return &hyperloglogSync{*New(precision).(*hyperloglog), sync.RWMutex{}}
+}
+
+// FromCollectionSync creates a threadsafe sketch holding the items of the given collection
+func FromCollectionSync(precision uint8, c collection.Interface) Interface {
+	return &hyperloglogSync{*FromCollection(precision, c).(*hyperloglog), sync.RWMutex{}}
+}
+
+func (h *hyperloglogSync) Add(items ...interface{}) {
+	h.l.Lock()
+	defer h.l.Unlock()
+	h.hyperloglog.Add(items...)
+}
+
+func (h *hyperloglogSync) Count() uint64 {
+	h.l.RLock()
+	defer h.l.RUnlock()
+	return h.hyperloglog.Count()
+}
+
+func (h *hyperloglogSync) Merge(t Interface) error {
+	if t == Interface(h) {
+		return nil
+	}
+	h.l.Lock()
+	defer h.l.Unlock()
+	return h.hyperloglog.Merge(t)
+}
+
+func (h *hyperloglogSync) Clear() {
+	h.l.Lock()
+	defer h.l.Unlock()
+	h.hyperloglog.Clear()
+}
+
+func (h *hyperloglogSync) IsEmpty() bool {
+	h.l.RLock()
+	defer h.l.RUnlock()
+	return h.hyperloglog.IsEmpty()
+}
+
+func (h *hyperloglogSync) Precision() uint8 {
+	h.l.RLock()
+	defer h.l.RUnlock()
+	return h.hyperloglog.Precision()
+}
+
+func (h *hyperloglogSync) Copy() Interface {
+	h.l.RLock()
+	defer h.l.RUnlock()
+	return &hyperloglogSync{*h.hyperloglog.copy(), sync.RWMutex{}}
+}
+
+func (h *hyperloglogSync) MarshalBinary() ([]byte, error) {
+	h.l.RLock()
+	defer h.l.RUnlock()
+	return h.hyperloglog.MarshalBinary()
+}
+
+func (h *hyperloglogSync) UnmarshalBinary(data []byte) error {
+	h.l.Lock()
+	defer h.l.Unlock()
+	return h.hyperloglog.UnmarshalBinary(data)
+}
+
+// Snapshot returns a non-threadsafe copy of h consistent at the time of the call.
+func (h *hyperloglogSync) Snapshot() Interface {
+	h.l.RLock()
+	defer h.l.RUnlock()
+	return h.hyperloglog.copy()
+}
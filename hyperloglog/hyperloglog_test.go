@@ -0,0 +1,243 @@
+package hyperloglog
+
+import (
+	"math"
+	"sync"
+	"testing"
+
+	"github.com/khezen/struct/set"
+)
+
+func relativeError(expected, got uint64) float64 {
+	return math.Abs(float64(got)-float64(expected)) / float64(expected)
+}
+
+func TestCount(t *testing.T) {
+	cases := []struct {
+		h        Interface
+		n        int
+		maxError float64
+	}{
+		{New(14), 10, 0},
+		{New(14), 1000, 0.01},
+		{New(14), 100000, 0.03},
+		{New(10), 100000, 0.1},
+		{New(4), 100000, 0.8},
+		{NewSync(14), 1000000, 0.03},
+		{New(18), 1000000, 0.01},
+	}
+	for _, c := range cases {
+		for i := 0; i < c.n; i++ {
+			c.h.Add(i, i)
+		}
+		if err := relativeError(uint64(c.n), c.h.Count()); err > c.maxError {
+			t.Errorf("Expected %v. Got %v.", c.n, c.h.Count())
+		}
+	}
+}
+
+func TestSparse(t *testing.T) {
+	h := New(14).(*hyperloglog)
+	for i := 0; i < h.threshold(); i++ {
+		h.Add(i)
+	}
+	if h.registers != nil {
+		t.Errorf("Expected %v. Got %v.", "sparse", "dense")
+	}
+	sparse := h.Count()
+	dense := h.copy()
+	dense.densify()
+	if err := relativeError(uint64(h.threshold()), sparse); err > 0.01 {
+		t.Errorf("Expected %v. Got %v.", h.threshold(), sparse)
+	}
+	if err := relativeError(uint64(h.threshold()), dense.Count()); err > 0.05 {
+		t.Errorf("Expected %v. Got %v.", h.threshold(), dense.Count())
+	}
+	h.Add("one more")
+	if h.registers == nil {
+		t.Errorf("Expected %v. Got %v.", "dense", "sparse")
+	}
+	if h.Count() < dense.Count() {
+		t.Errorf("Expected %v. Got %v.", dense.Count(), h.Count())
+	}
+}
+
+func TestIsEmptyClear(t *testing.T) {
+	for _, h := range []Interface{New(8), NewSync(8)} {
+		if !h.IsEmpty() || h.Count() != 0 {
+			t.Errorf("Expected %v. Got %v.", 0, h.Count())
+		}
+		for i := 0; i < 1000; i++ {
+			h.Add(i)
+		}
+		if h.IsEmpty() {
+			t.Errorf("Expected %v. Got %v.", false, true)
+		}
+		h.Clear()
+		if !h.IsEmpty() || h.Count() != 0 {
+			t.Errorf("Expected %v. Got %v.", 0, h.Count())
+		}
+	}
+}
+
+func TestMerge(t *testing.T) {
+	cases := []struct {
+		a, b     Interface
+		na, nb   int
+		maxError float64
+	}{
+		{New(14), New(14), 100, 200, 0.01},
+		{New(14), New(14), 100, 100000, 0.03},
+		{New(14), New(14), 100000, 100, 0.03},
+		{NewSync(14), NewSync(14), 100000, 100000, 0.03},
+		{New(14), NewSync(14), 3000, 3000, 0.03},
+	}
+	for _, c := range cases {
+		for i := 0; i < c.na; i++ {
+			c.a.Add(i)
+		}
+		for i := 0; i < c.nb; i++ {
+			c.b.Add(-i - 1)
+		}
+		if err := c.a.Merge(c.b); err != nil {
+			t.Errorf("Expected %v. Got %v.", nil, err)
+		}
+		if err := relativeError(uint64(c.na+c.nb), c.a.Count()); err > c.maxError {
+			t.Errorf("Expected %v. Got %v.", c.na+c.nb, c.a.Count())
+		}
+		count := c.a.Count()
+		if err := c.a.Merge(c.a); err != nil || c.a.Count() != count {
+			t.Errorf("Expected %v. Got %v.", count, c.a.Count())
+		}
+	}
+	if err := New(14).Merge(New(12)); err != ErrIncompatible {
+		t.Errorf("Expected %v. Got %v.", ErrIncompatible, err)
+	}
+}
+
+func TestFromCollection(t *testing.T) {
+	s := set.New()
+	for i := 0; i < 5000; i++ {
+		s.Add(i)
+	}
+	cases := []Interface{
+		FromCollection(14, s),
+		FromCollectionSync(14, set.NewSync(s.Slice()...)),
+	}
+	for _, h := range cases {
+		if err := relativeError(5000, h.Count()); err > 0.03 {
+			t.Errorf("Expected %v. Got %v.", 5000, h.Count())
+		}
+	}
+}
+
+func TestBadPrecision(t *testing.T) {
+	for _, p := range []uint8{0, MinPrecision - 1, MaxPrecision + 1} {
+		func() {
+			defer func() {
+				if r := recover(); r != ErrBadPrecision {
+					t.Errorf("Expected %v. Got %v.", ErrBadPrecision, r)
+				}
+			}()
+			New(p)
+		}()
+	}
+}
+
+func TestSerialization(t *testing.T) {
+	cases := []struct {
+		h Interface
+		n int
+	}{
+		{New(12), 0},
+		{New(12), 100},
+		{NewSync(12), 100000},
+	}
+	for _, c := range cases {
+		for i := 0; i < c.n; i++ {
+			c.h.Add(i)
+		}
+		data, err := c.h.MarshalBinary()
+		if err != nil {
+			t.Errorf("Expected %v. Got %v.", nil, err)
+		}
+		decoded := New(4)
+		decoded.Add("erased")
+		if err = decoded.UnmarshalBinary(data); err != nil {
+			t.Errorf("Expected %v. Got %v.", nil, err)
+		}
+		if decoded.Count() != c.h.Count() || decoded.Precision() != c.h.Precision() {
+			t.Errorf("Expected %v. Got %v.", c.h.Count(), decoded.Count())
+		}
+		if err = decoded.UnmarshalBinary(data[:len(data)-1]); err != ErrBadFormat {
+			t.Errorf("Expected %v. Got %v.", ErrBadFormat, err)
+		}
+	}
+	sparse := &hyperloglog{4, map[uint32]uint8{2: 3, 1: 7}, nil}
+	dense := &hyperloglog{4, nil, []uint8{1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15, 16}}
+	expected := [][]byte{
+		{'H', 1, 4, 0, 2, 0, 0, 0, 1, 0, 0, 0, 7, 2, 0, 0, 0, 3},
+		{'H', 1, 4, 1, 1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15, 16},
+	}
+	for i, h := range []*hyperloglog{sparse, dense} {
+		if data, _ := h.MarshalBinary(); string(data) != string(expected[i]) {
+			t.Errorf("Expected %v. Got %v.", expected[i], data)
+		}
+	}
+	bad := [][]byte{
+		nil,
+		{'H', 2, 4, 0, 0, 0, 0, 0},
+		{'H', 1, 3, 0, 0, 0, 0, 0},
+		{'H', 1, 4, 2, 0, 0, 0, 0},
+		{'H', 1, 4, 0, 1, 0, 0, 0, 1, 0, 0, 0, 0},
+		{'H', 1, 4, 0, 2, 0, 0, 0, 1, 0, 0, 0, 7, 1, 0, 0, 0, 3},
+	}
+	for _, data := range bad {
+		if err := New(4).UnmarshalBinary(data); err != ErrBadFormat {
+			t.Errorf("Expected %v. Got %v.", ErrBadFormat, err)
+		}
+	}
+}
+
+func TestCopy(t *testing.T) {
+	for _, h := range []Interface{New(10), NewSync(10)} {
+		h.Add(1, 2, 3)
+		c := h.Copy()
+		c.Add(4, 5, 6)
+		if h.Count() != 3 || c.Count() != 6 {
+			t.Errorf("Expected %v. Got %v.", 3, h.Count())
+		}
+	}
+}
+
+func TestConcurrency(t *testing.T) {
+	h, other := NewSync(14), NewSync(14)
+	var wg sync.WaitGroup
+	for g := 0; g < 4; g++ {
+		wg.Add(1)
+		go func(g int) {
+			defer wg.Done()
+			for i := 0; i < 1000; i++ {
+				h.Add(g*1000 + i)
+				other.Add(-i)
+				h.Merge(other)
+				h.Count()
+			}
+		}(g)
+	}
+	wg.Wait()
+	if err := relativeError(5000, h.Count()); err > 0.03 {
+		t.Errorf("Expected %v. Got %v.", 5000, h.Count())
+	}
+}
+
+func TestSnapshot(t *testing.T) {
+	h := NewSync(10)
+	h.Add(1)
+	snapshot := h.(interface{ Snapshot() Interface }).Snapshot()
+	h.Add(2)
+	snapshot.Add(3, 4)
+	if _, ok := snapshot.(*hyperloglog); !ok || snapshot.Count() != 3 || h.Count() != 2 {
+		t.Errorf("Expected %v. Got %v.", 3, snapshot.Count())
+	}
+}
@@ -0,0 +1,41 @@
+// Package hyperloglog provides a HyperLogLog sketch estimating the number of
+// distinct items added to it in a fixed amount of memory. Small cardinalities
+// are counted with a sparse representation which switches to dense registers
+// once it would use more memory than them.
+package hyperloglog
+
+import (
+	"encoding"
+	"errors"
+)
+
+// Interface describes functions a sketch exposes
+type Interface interface {
+	Add(items ...interface{})
+	Count() uint64
+
+	Merge(Interface) error
+	Clear()
+	IsEmpty() bool
+	Precision() uint8
+	Copy() Interface
+
+	encoding.BinaryMarshaler
+	encoding.BinaryUnmarshaler
+}
+
+const (
+	// MinPrecision is the smallest supported precision
+	MinPrecision = 4
+	// MaxPrecision is the largest supported precision
+	MaxPrecision = 18
+)
+
+var (
+	// ErrBadPrecision - precision must be in [MinPrecision, MaxPrecision]
+	ErrBadPrecision = errors.New("ErrBadPrecision - precision must be in [MinPrecision, MaxPrecision]")
+	// ErrIncompatible - sketches must have the same precision
+	ErrIncompatible = errors.New("ErrIncompatible - sketches must have the same precision")
+	// ErrBadFormat - data is not a serialized sketch
+	ErrBadFormat = errors.New("ErrBadFormat - data is not a serialized sketch")
+)
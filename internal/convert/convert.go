@@ -0,0 +1,34 @@
+// Package convert assigns items to typed slices and maps through reflection.
+package convert
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// Value returns item as a value of type t. nil converts to the zero value of
+// the types which can be nil. err, wrapped with the types involved, is
+// returned when item is not assignable to t.
+func Value(item interface{}, t reflect.Type, err error) (reflect.Value, error) {
+	if item == nil {
+		switch t.Kind() {
+		case reflect.Ptr, reflect.Map, reflect.Slice, reflect.Interface, reflect.Chan, reflect.Func:
+			return reflect.Zero(t), nil
+		}
+		return reflect.Value{}, fmt.Errorf("%w: nil is not %v", err, t)
+	}
+	v := reflect.ValueOf(item)
+	if !v.Type().AssignableTo(t) {
+		return reflect.Value{}, fmt.Errorf("%w: %T is not %v", err, item, t)
+	}
+	return v, nil
+}
+
+// Target returns the value ptr points to when it is a pointer to kind.
+func Target(ptr interface{}, kind reflect.Kind) (reflect.Value, bool) {
+	p := reflect.ValueOf(ptr)
+	if p.Kind() != reflect.Ptr || p.IsNil() || p.Elem().Kind() != kind {
+		return reflect.Value{}, false
+	}
+	return p.Elem(), true
+}
@@ -0,0 +1,55 @@
+package convert
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+var errType = errors.New("errType")
+
+func TestValue(t *testing.T) {
+	cases := []struct {
+		item     interface{}
+		t        reflect.Type
+		expected interface{}
+		err      bool
+	}{
+		{"a", reflect.TypeOf(""), "a", false},
+		{1, reflect.TypeOf((*interface{})(nil)).Elem(), 1, false},
+		{nil, reflect.TypeOf([]int(nil)), []int(nil), false},
+		{nil, reflect.TypeOf(0), nil, true},
+		{int64(1), reflect.TypeOf(0), nil, true},
+	}
+	for _, c := range cases {
+		v, err := Value(c.item, c.t, errType)
+		if c.err {
+			if !errors.Is(err, errType) {
+				t.Errorf("Expected %v. Got %v.", errType, err)
+			}
+			continue
+		}
+		if got := v.Interface(); !reflect.DeepEqual(got, c.expected) {
+			t.Errorf("Expected %v. Got %v.", c.expected, got)
+		}
+	}
+}
+
+func TestTarget(t *testing.T) {
+	var s []int
+	var nilPtr *[]int
+	cases := []struct {
+		ptr      interface{}
+		expected bool
+	}{
+		{&s, true},
+		{s, false},
+		{nilPtr, false},
+		{new(map[int]int), false},
+	}
+	for _, c := range cases {
+		if _, ok := Target(c.ptr, reflect.Slice); ok != c.expected {
+			t.Errorf("Expected %v. Got %v.", c.expected, ok)
+		}
+	}
+}
@@ -0,0 +1,90 @@
+// Package digest hashes arbitrary items for probabilistic structures.
+package digest
+
+import (
+	"encoding/binary"
+	"fmt"
+	"hash"
+	"hash/fnv"
+	"math"
+	"reflect"
+)
+
+// Item returns two independent 64 bit hashes of item. Items of different types
+// never share an encoding. Pointers, channels and functions are hashed by
+// address, as map keys compare them, so only their hashes are not stable
+// across processes; other hashes are, and serialized structures remain valid.
+func Item(item interface{}) (uint64, uint64) {
+	h := fnv.New128a()
+	write(h, item)
+	sum := h.Sum(nil)
+	return mix(binary.BigEndian.Uint64(sum[:8])), mix(binary.BigEndian.Uint64(sum[8:]))
+}
+
+// mix spreads every input bit over the whole word since low bits of FNV hashes
+// only depend on low bits of the input
+func mix(h uint64) uint64 {
+	h ^= h >> 33
+	h *= 0xff51afd7ed558ccd
+	h ^= h >> 33
+	h *= 0xc4ceb9fe1a85ec53
+	h ^= h >> 33
+	return h
+}
+
+func write(h hash.Hash, item interface{}) {
+	var buf [9]byte
+	putInt := func(tag byte, v uint64) {
+		buf[0] = tag
+		binary.LittleEndian.PutUint64(buf[1:], v)
+		h.Write(buf[:])
+	}
+	switch v := item.(type) {
+	case nil:
+		h.Write([]byte{0})
+	case string:
+		h.Write([]byte{1})
+		h.Write([]byte(v))
+	case []byte:
+		h.Write([]byte{2})
+		h.Write(v)
+	case bool:
+		if v {
+			h.Write([]byte{3, 1})
+		} else {
+			h.Write([]byte{3, 0})
+		}
+	case int:
+		putInt(4, uint64(v))
+	case int8:
+		putInt(5, uint64(v))
+	case int16:
+		putInt(6, uint64(v))
+	case int32:
+		putInt(7, uint64(v))
+	case int64:
+		putInt(8, uint64(v))
+	case uint:
+		putInt(9, uint64(v))
+	case uint8:
+		putInt(10, uint64(v))
+	case uint16:
+		putInt(11, uint64(v))
+	case uint32:
+		putInt(12, uint64(v))
+	case uint64:
+		putInt(13, v)
+	case float32:
+		putInt(14, uint64(math.Float32bits(v)))
+	case float64:
+		putInt(15, math.Float64bits(v))
+	default:
+		switch rv := reflect.ValueOf(item); rv.Kind() {
+		case reflect.Ptr, reflect.Chan, reflect.Func, reflect.UnsafePointer:
+			fmt.Fprintf(h, "\xfe%T:", item)
+			putInt(16, uint64(rv.Pointer()))
+		default:
+			fmt.Fprintf(h, "\xff%T:%#v", item, item)
+		}
+	}
+}
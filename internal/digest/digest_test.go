@@ -0,0 +1,45 @@
+package digest
+
+import "testing"
+
+type point struct {
+	x, y int
+}
+
+func TestItem(t *testing.T) {
+	items := []interface{}{
+		nil, "1", []byte("1"), true, false,
+		int(1), int8(1), int16(1), int32(1), int64(1),
+		uint(1), uint8(1), uint16(1), uint32(1), uint64(1),
+		float32(1), float64(1), point{1, 2}, point{2, 1},
+		&point{1, 2}, &point{1, 2},
+	}
+	seen := make(map[[2]uint64]interface{})
+	for _, item := range items {
+		h1, h2 := Item(item)
+		if g1, g2 := Item(item); g1 != h1 || g2 != h2 {
+			t.Errorf("Expected %v. Got %v.", [2]uint64{h1, h2}, [2]uint64{g1, g2})
+		}
+		if other, ok := seen[[2]uint64{h1, h2}]; ok {
+			t.Errorf("Expected %v. Got %v.", item, other)
+		}
+		seen[[2]uint64{h1, h2}] = item
+	}
+}
+
+func TestItemStable(t *testing.T) {
+	cases := []struct {
+		item interface{}
+		h1   uint64
+		h2   uint64
+	}{
+		{"a", 0xc2f8985cce134a2, 0xc0cf79b9a8cb0134},
+		{42, 0xf490394740b6a47, 0xa1a85d5203c4cefe},
+		{[]byte{}, 0x2b6214a5393592a0, 0x6ed7d0d3ea4803d9},
+	}
+	for _, c := range cases {
+		if h1, h2 := Item(c.item); h1 != c.h1 || h2 != c.h2 {
+			t.Errorf("Expected %v. Got %v.", [2]uint64{c.h1, c.h2}, [2]uint64{h1, h2})
+		}
+	}
+}
@@ -0,0 +1,178 @@
+// Package expiry provides the bookkeeping shared by expiring collections: a
+// map of entries indexed by a min-heap of expiration dates, and a janitor
+// goroutine purging expired entries periodically.
+package expiry
+
+import (
+	"container/heap"
+	"sync"
+	"time"
+)
+
+// Entry is a key/value pair which may expire
+type Entry struct {
+	Key, Value interface{}
+	// Expires is the expiration date. The zero value means the entry never expires.
+	Expires time.Time
+	index   int
+}
+
+// Store is a non thread safe map of entries which may expire
+type Store struct {
+	m map[interface{}]*Entry
+	h entryHeap
+}
+
+// NewStore creates an empty store
+func NewStore() *Store {
+	return &Store{
+		m: make(map[interface{}]*Entry),
+	}
+}
+
+// Deadline returns the expiration date of an entry created at now with the given ttl.
+func Deadline(now time.Time, ttl time.Duration) time.Time {
+	if ttl <= 0 {
+		return time.Time{}
+	}
+	return now.Add(ttl)
+}
+
+// Put inserts or replaces the entry for k.
+func (s *Store) Put(k, v interface{}, expires time.Time) {
+	e, ok := s.m[k]
+	if !ok {
+		e = &Entry{Key: k, index: -1}
+		s.m[k] = e
+	}
+	e.Value = v
+	e.Expires = expires
+	switch {
+	case e.index >= 0 && expires.IsZero():
+		heap.Remove(&s.h, e.index)
+	case e.index >= 0:
+		heap.Fix(&s.h, e.index)
+	case !expires.IsZero():
+		heap.Push(&s.h, e)
+	}
+}
+
+// Get returns the entry for k.
+func (s *Store) Get(k interface{}) (*Entry, bool) {
+	e, ok := s.m[k]
+	return e, ok
+}
+
+// Delete removes the entry for k and returns it.
+func (s *Store) Delete(k interface{}) (*Entry, bool) {
+	e, ok := s.m[k]
+	if !ok {
+		return nil, false
+	}
+	delete(s.m, k)
+	if e.index >= 0 {
+		heap.Remove(&s.h, e.index)
+	}
+	return e, true
+}
+
+// Expire removes and returns every entry expiring at or before now.
+func (s *Store) Expire(now time.Time) []*Entry {
+	var expired []*Entry
+	for len(s.h) > 0 && !now.Before(s.h[0].Expires) {
+		e := heap.Pop(&s.h).(*Entry)
+		delete(s.m, e.Key)
+		expired = append(expired, e)
+	}
+	return expired
+}
+
+// Each traverses the entries until all have been visited or f returns false.
+func (s *Store) Each(f func(e *Entry) bool) {
+	for _, e := range s.m {
+		if !f(e) {
+			break
+		}
+	}
+}
+
+// Len returns the number of entries.
+func (s *Store) Len() int {
+	return len(s.m)
+}
+
+// Clear removes all entries.
+func (s *Store) Clear() {
+	s.m = make(map[interface{}]*Entry)
+	s.h = nil
+}
+
+// Janitor calls a function periodically until stopped
+type Janitor struct {
+	stop chan struct{}
+	once sync.Once
+}
+
+// StartJanitor calls f every interval in a new goroutine. A non-positive
+// interval returns a janitor which never calls f.
+func StartJanitor(interval time.Duration, f func()) *Janitor {
+	j := &Janitor{
+		stop: make(chan struct{}),
+	}
+	if interval <= 0 {
+		return j
+	}
+	ticker := time.NewTicker(interval)
+	go func() {
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				f()
+			case <-j.stop:
+				return
+			}
+		}
+	}()
+	return j
+}
+
+// Stop stops the janitor. It is safe to call Stop several times.
+func (j *Janitor) Stop() {
+	j.once.Do(func() {
+		close(j.stop)
+	})
+}
+
+// entryHeap is a min-heap of entries ordered by expiration date
+type entryHeap []*Entry
+
+func (h entryHeap) Len() int {
+	return len(h)
+}
+
+func (h entryHeap) Less(i, j int) bool {
+	return h[i].Expires.Before(h[j].Expires)
+}
+
+func (h entryHeap) Swap(i, j int) {
+	h[i], h[j] = h[j], h[i]
+	h[i].index = i
+	h[j].index = j
+}
+
+func (h *entryHeap) Push(x interface{}) {
+	e := x.(*Entry)
+	e.index = len(*h)
+	*h = append(*h, e)
+}
+
+func (h *entryHeap) Pop() interface{} {
+	old := *h
+	n := len(old)
+	e := old[n-1]
+	old[n-1] = nil
+	e.index = -1
+	*h = old[:n-1]
+	return e
+}
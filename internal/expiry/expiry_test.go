@@ -0,0 +1,65 @@
+package expiry
+
+import (
+	"testing"
+	"time"
+)
+
+func TestStore(t *testing.T) {
+	now := time.Unix(0, 0)
+	s := NewStore()
+	s.Put("1", 1, Deadline(now, 3*time.Second))
+	s.Put("2", 2, Deadline(now, time.Second))
+	s.Put("3", 3, Deadline(now, 0))
+	s.Put("4", 4, Deadline(now, 2*time.Second))
+	s.Put("4", 4, Deadline(now, 0))
+	s.Put("3", 3, Deadline(now, 2*time.Second))
+	if s.Len() != 4 {
+		t.Errorf("Expected 4. Got %v.", s.Len())
+	}
+	expired := s.Expire(now.Add(2 * time.Second))
+	if len(expired) != 2 || expired[0].Key != "2" || expired[1].Key != "3" {
+		t.Errorf("Expected 2 then 3. Got %v.", expired)
+	}
+	if _, ok := s.Get("2"); ok {
+		t.Error("2 should be expired")
+	}
+	if e, ok := s.Delete("1"); !ok || e.Value != 1 {
+		t.Errorf("Expected 1. Got %v.", e)
+	}
+	if _, ok := s.Delete("1"); ok {
+		t.Error("1 should be deleted")
+	}
+	if expired := s.Expire(now.Add(time.Hour)); len(expired) != 0 {
+		t.Errorf("Expected nothing. Got %v.", expired)
+	}
+	count := 0
+	s.Each(func(e *Entry) bool {
+		count++
+		return false
+	})
+	if count != 1 {
+		t.Errorf("Expected 1. Got %v.", count)
+	}
+	s.Clear()
+	if s.Len() != 0 {
+		t.Errorf("Expected 0. Got %v.", s.Len())
+	}
+}
+
+func TestJanitor(t *testing.T) {
+	calls := make(chan struct{}, 1)
+	j := StartJanitor(time.Millisecond, func() {
+		select {
+		case calls <- struct{}{}:
+		default:
+		}
+	})
+	select {
+	case <-calls:
+	case <-time.After(time.Second):
+		t.Error("Janitor should have run")
+	}
+	j.Stop()
+	j.Stop()
+}
@@ -0,0 +1,143 @@
+// Package format implements the fmt.Formatter of the collections:
+//
+//	%v   [1 2 3] or map[a:1 b:2]
+//	%+v  set.Sync(len=3) [1 2 3], annotated with the type and the length
+//	%#v  set.NewSync(1, 2, 3), the Go syntax creating an equal collection
+//
+// Other verbs apply to each item, so that %q quotes strings. A precision
+// truncates long collections: %.2v prints [1 2 ...].
+package format
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/khezen/struct/compare"
+)
+
+// Seq formats items in their order. name is the type of the collection, such
+// as "array.Sync", and constructor the function creating an equal collection
+// from its items, such as "array.NewSync".
+func Seq(f fmt.State, verb rune, name, constructor string, items []interface{}) {
+	write(f, verb, name, constructor, nil, items, nil, false)
+}
+
+// Set formats items sorted in natural order when they are orderable.
+func Set(f fmt.State, verb rune, name, constructor string, items []interface{}) {
+	if compare.Orderable(items...) {
+		sort.SliceStable(items, func(i, j int) bool {
+			return compare.Natural(items[i], items[j]) < 0
+		})
+	}
+	write(f, verb, name, constructor, nil, items, nil, false)
+}
+
+// Map formats the pairs sorted by keys in natural order when they are
+// orderable. Constructors take the pairs as k, v, k, v... after args, the Go
+// syntax of their leading arguments.
+func Map(f fmt.State, verb rune, name, constructor string, keys, values []interface{}, args ...string) {
+	if compare.Orderable(keys...) {
+		sort.Stable(pairs{keys, values})
+	}
+	write(f, verb, name, constructor, args, keys, values, true)
+}
+
+// Pairs formats the pairs visited by each as Map does.
+func Pairs(f fmt.State, verb rune, name, constructor string, each func(func(k, v interface{}) bool), args ...string) {
+	var keys, values []interface{}
+	each(func(k, v interface{}) bool {
+		keys = append(keys, k)
+		values = append(values, v)
+		return true
+	})
+	Map(f, verb, name, constructor, keys, values, args...)
+}
+
+// Delegate formats v as f and verb require. Wrappers use it to format the
+// collection they wrap.
+func Delegate(f fmt.State, verb rune, v interface{}) {
+	fmt.Fprintf(f, fmt.FormatString(f, verb), v)
+}
+
+// String formats v with %v.
+func String(v fmt.Formatter) string {
+	return fmt.Sprintf("%v", v)
+}
+
+func write(f fmt.State, verb rune, name, constructor string, args []string, keys, values []interface{}, isMap bool) {
+	length := len(keys)
+	if precision, ok := f.Precision(); ok && precision < len(keys) {
+		keys = keys[:precision]
+		if isMap {
+			values = values[:precision]
+		}
+	}
+	truncated := len(keys) < length
+	item := "%" + string(verb)
+	switch verb {
+	case 'v', 's':
+		item = "%v"
+	}
+	if f.Flag('#') && verb == 'v' {
+		writeGo(f, constructor, args, keys, values, isMap, truncated)
+		return
+	}
+	if f.Flag('+') && verb == 'v' {
+		fmt.Fprintf(f, "%s(len=%d) ", name, length)
+	}
+	open, sep, end := "[", " ", "]"
+	if isMap {
+		open = "map["
+	}
+	f.Write([]byte(open))
+	for i, k := range keys {
+		if i > 0 {
+			f.Write([]byte(sep))
+		}
+		fmt.Fprintf(f, item, k)
+		if isMap {
+			f.Write([]byte(":"))
+			fmt.Fprintf(f, item, values[i])
+		}
+	}
+	if truncated {
+		if len(keys) > 0 {
+			f.Write([]byte(sep))
+		}
+		f.Write([]byte("..."))
+	}
+	f.Write([]byte(end))
+}
+
+// writeGo writes the call to the constructor creating the collection
+func writeGo(f fmt.State, constructor string, args []string, keys, values []interface{}, isMap, truncated bool) {
+	for i, k := range keys {
+		args = append(args, fmt.Sprintf("%#v", k))
+		if isMap {
+			args = append(args, fmt.Sprintf("%#v", values[i]))
+		}
+	}
+	if truncated {
+		args = append(args, "...")
+	}
+	fmt.Fprintf(f, "%s(%s)", constructor, strings.Join(args, ", "))
+}
+
+// pairs sorts keys and values by keys
+type pairs struct {
+	keys, values []interface{}
+}
+
+func (p pairs) Len() int {
+	return len(p.keys)
+}
+
+func (p pairs) Less(i, j int) bool {
+	return compare.Natural(p.keys[i], p.keys[j]) < 0
+}
+
+func (p pairs) Swap(i, j int) {
+	p.keys[i], p.keys[j] = p.keys[j], p.keys[i]
+	p.values[i], p.values[j] = p.values[j], p.values[i]
+}
@@ -0,0 +1,74 @@
+package format
+
+import (
+	"fmt"
+	"testing"
+)
+
+type seq []interface{}
+
+func (s seq) Format(f fmt.State, verb rune) {
+	Seq(f, verb, "test.Seq", "test.NewSeq", s)
+}
+
+type set []interface{}
+
+func (s set) Format(f fmt.State, verb rune) {
+	Set(f, verb, "test.Set", "test.NewSet", append([]interface{}(nil), s...))
+}
+
+type hashmap map[interface{}]interface{}
+
+func (p hashmap) Format(f fmt.State, verb rune) {
+	Pairs(f, verb, "test.Map", "test.NewMap", func(visit func(k, v interface{}) bool) {
+		for k, v := range p {
+			if !visit(k, v) {
+				return
+			}
+		}
+	}, "test.Option")
+}
+
+type wrapper struct {
+	v interface{}
+}
+
+func (w wrapper) Format(f fmt.State, verb rune) {
+	Delegate(f, verb, w.v)
+}
+
+func TestFormat(t *testing.T) {
+	cases := []struct {
+		format   string
+		v        interface{}
+		expected string
+	}{
+		{"%v", seq{3, 1, 2}, "[3 1 2]"},
+		{"%s", seq{"b", "a"}, "[b a]"},
+		{"%q", seq{"b", "a"}, `["b" "a"]`},
+		{"%d", seq{}, "[]"},
+		{"%+v", seq{3, 1, 2}, "test.Seq(len=3) [3 1 2]"},
+		{"%#v", seq{"a", 1}, `test.NewSeq("a", 1)`},
+		{"%.2v", seq{3, 1, 2}, "[3 1 ...]"},
+		{"%.0v", seq{3, 1, 2}, "[...]"},
+		{"%.5v", seq{3, 1, 2}, "[3 1 2]"},
+		{"%+.1v", seq{3, 1, 2}, "test.Seq(len=3) [3 ...]"},
+		{"%#.1v", seq{3, 1, 2}, "test.NewSeq(3, ...)"},
+		{"%v", set{3, 1.5, 2}, "[1.5 2 3]"},
+		{"%v", set{"b", "a", "c"}, "[a b c]"},
+		{"%.2v", set{"b", "a", "c"}, "[a b ...]"},
+		{"%v", set{"b", 1}, "[b 1]"},
+		{"%v", hashmap{"b": 2, "a": 1}, "map[a:1 b:2]"},
+		{"%+v", hashmap{"b": 2, "a": 1}, "test.Map(len=2) map[a:1 b:2]"},
+		{"%#v", hashmap{"b": 2, "a": 1}, `test.NewMap(test.Option, "a", 1, "b", 2)`},
+		{"%.1v", hashmap{"b": 2, "a": 1}, "map[a:1 ...]"},
+		{"%v", hashmap{}, "map[]"},
+		{"%+.1v", wrapper{seq{3, 1}}, "test.Seq(len=2) [3 ...]"},
+		{"%s", String(seq{1}), "[1]"},
+	}
+	for _, c := range cases {
+		if got := fmt.Sprintf(c.format, c.v); got != c.expected {
+			t.Errorf("Expected %v. Got %v.", c.expected, got)
+		}
+	}
+}
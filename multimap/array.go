@@ -0,0 +1,54 @@
+package multimap
+
+import (
+	"fmt"
+
+	"github.com/khezen/struct/array"
+	"github.com/khezen/struct/collection"
+	"github.com/khezen/struct/internal/format"
+)
+
+type arrayMultimap struct {
+	multimap
+}
+
+// NewArray creates a multimap holding an ordered list of values for each key.
+// It accepts key/value pairs to populate the initial multimap.
+func NewArray(pairs ...interface{}) Array {
+	m := &arrayMultimap{
+		newMultimap(newArrayValues),
+	}
+	length := len(pairs)
+	for i := 0; i < length-1; i += 2 {
+		m.Put(pairs[i], pairs[i+1])
+	}
+	return m
+}
+
+func newArrayValues() collection.Interface {
+	return array.New()
+}
+
+// Get returns a copy of the values associated with k in insertion order. If k
+// is missing an empty array is returned.
+func (m *arrayMultimap) Get(k interface{}) array.Interface {
+	return array.New(m.values(k)...)
+}
+
+// Inverse returns a new multimap in which every value is associated with its keys.
+func (m *arrayMultimap) Inverse() Array {
+	inv := NewArray().(*arrayMultimap)
+	m.inverse(&inv.multimap)
+	return inv
+}
+
+func (m *arrayMultimap) String() string {
+	return format.String(m)
+}
+
+// Format implements fmt.Formatter: %v prints each key with its values, %+v
+// adds the type and the number of keys and %#v the Go syntax. A precision
+// truncates the keys: %.3v prints the 3 first followed by "...".
+func (m *arrayMultimap) Format(f fmt.State, verb rune) {
+	m.format(f, verb, "multimap.Array", "multimap.NewArray")
+}
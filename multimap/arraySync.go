@@ -0,0 +1,56 @@
+package multimap
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/khezen/struct/array"
+	"github.com/khezen/struct/internal/format"
+)
+
+type arrayMultimapSync struct {
+	multimapSync
+}
+
+// NewArraySync creates a thread safe multimap holding an ordered list of values for each key.
+func NewArraySync(pairs ...interface{}) Array {
+	return &arrayMultimapSync{
+		multimapSync{
+			NewArray(pairs...).(*arrayMultimap).multimap,
+			sync.RWMutex{},
+		},
+	}
+}
+
+func (m *arrayMultimapSync) Get(k interface{}) array.Interface {
+	m.l.RLock()
+	defer m.l.RUnlock()
+	return array.NewSync(m.values(k)...)
+}
+
+func (m *arrayMultimapSync) Inverse() Array {
+	m.l.RLock()
+	defer m.l.RUnlock()
+	inv := NewArraySync().(*arrayMultimapSync)
+	m.inverse(&inv.multimap)
+	return inv
+}
+
+// Snapshot returns a non-threadsafe copy of m consistent at the time of the call.
+func (m *arrayMultimapSync) Snapshot() Array {
+	m.l.RLock()
+	defer m.l.RUnlock()
+	cpy := NewArray().(*arrayMultimap)
+	m.copyTo(&cpy.multimap)
+	return cpy
+}
+
+func (m *arrayMultimapSync) String() string {
+	return format.String(m)
+}
+
+func (m *arrayMultimapSync) Format(f fmt.State, verb rune) {
+	m.l.RLock()
+	defer m.l.RUnlock()
+	m.format(f, verb, "multimap.ArraySync", "multimap.NewArraySync")
+}
@@ -0,0 +1,44 @@
+// Package multimap provides both threadsafe and non-threadsafe implementations of
+// a generic multimap, a map in which each key is associated with one or more
+// values. A key is dropped as soon as its last value is removed, so a multimap
+// never holds a key mapped to an empty collection.
+package multimap
+
+import (
+	"github.com/khezen/struct/array"
+	"github.com/khezen/struct/set"
+)
+
+// Interface describes functions shared by every multimap
+type Interface interface {
+	Put(k, v interface{})
+	PutAll(k interface{}, values ...interface{})
+	RemoveValue(k, v interface{})
+	RemoveAll(keys ...interface{})
+	Has(keys ...interface{}) bool
+	ContainsEntry(k, v interface{}) bool
+	Each(func(k, v interface{}) bool)
+
+	Len() int
+	KeyLen() int
+	Clear()
+	IsEmpty() bool
+
+	String() string
+	KeySet() set.Interface
+}
+
+// Set is a multimap holding distinct values for each key
+type Set interface {
+	Interface
+	Get(k interface{}) set.Interface
+	Inverse() Set
+}
+
+// Array is a multimap holding an ordered list of values for each key. The same
+// value may be associated more than once with a key.
+type Array interface {
+	Interface
+	Get(k interface{}) array.Interface
+	Inverse() Array
+}
@@ -0,0 +1,167 @@
+package multimap
+
+import (
+	"fmt"
+
+	"github.com/khezen/struct/collection"
+	"github.com/khezen/struct/internal/format"
+	"github.com/khezen/struct/set"
+)
+
+// Provides a common multimap baseline for every value collection.
+type multimap struct {
+	m         map[interface{}]collection.Interface
+	newValues func() collection.Interface
+	length    int
+}
+
+func newMultimap(newValues func() collection.Interface) multimap {
+	return multimap{
+		m:         make(map[interface{}]collection.Interface),
+		newValues: newValues,
+	}
+}
+
+func (m *multimap) Put(k, v interface{}) {
+	m.PutAll(k, v)
+}
+
+// PutAll associates every given value with k. If passed no value it silently returns.
+func (m *multimap) PutAll(k interface{}, values ...interface{}) {
+	if len(values) == 0 {
+		return
+	}
+	c, ok := m.m[k]
+	if !ok {
+		c = m.newValues()
+		m.m[k] = c
+	}
+	before := c.Len()
+	c.Add(values...)
+	m.length += c.Len() - before
+}
+
+// RemoveValue removes one association between k and v. The key is removed
+// once its last value is gone.
+func (m *multimap) RemoveValue(k, v interface{}) {
+	c, ok := m.m[k]
+	if !ok {
+		return
+	}
+	before := c.Len()
+	c.Remove(v)
+	m.length -= before - c.Len()
+	if c.IsEmpty() {
+		delete(m.m, k)
+	}
+}
+
+// RemoveAll removes the given keys along with all of their values.
+func (m *multimap) RemoveAll(keys ...interface{}) {
+	for _, k := range keys {
+		if c, ok := m.m[k]; ok {
+			m.length -= c.Len()
+			delete(m.m, k)
+		}
+	}
+}
+
+// Has returns true only if every given key is associated with at least one value.
+func (m *multimap) Has(keys ...interface{}) bool {
+	for _, k := range keys {
+		if _, ok := m.m[k]; !ok {
+			return false
+		}
+	}
+	return true
+}
+
+func (m *multimap) ContainsEntry(k, v interface{}) bool {
+	c, ok := m.m[k]
+	return ok && c.Has(v)
+}
+
+// Each traverses every key/value pair. Traversal will continue until all entries
+// have been visited, or if the closure returns false.
+func (m *multimap) Each(f func(k, v interface{}) bool) {
+	for k, c := range m.m {
+		next := true
+		c.Each(func(v interface{}) bool {
+			next = f(k, v)
+			return next
+		})
+		if !next {
+			break
+		}
+	}
+}
+
+// Len returns the number of key/value pairs.
+func (m *multimap) Len() int {
+	return m.length
+}
+
+// KeyLen returns the number of distinct keys.
+func (m *multimap) KeyLen() int {
+	return len(m.m)
+}
+
+func (m *multimap) Clear() {
+	m.m = make(map[interface{}]collection.Interface)
+	m.length = 0
+}
+
+func (m *multimap) IsEmpty() bool {
+	return m.Len() == 0
+}
+
+// format prints each key with the collection of its values, sorted by keys
+// when they are orderable. With %#v, it prints the syntax of the constructor
+// taking the pairs.
+func (m *multimap) format(f fmt.State, verb rune, name, constructor string) {
+	var keys, values []interface{}
+	if verb == 'v' && f.Flag('#') {
+		m.Each(func(k, v interface{}) bool {
+			keys = append(keys, k)
+			values = append(values, v)
+			return true
+		})
+	} else {
+		for k, c := range m.m {
+			keys = append(keys, k)
+			values = append(values, c)
+		}
+	}
+	format.Map(f, verb, name, constructor, keys, values)
+}
+
+func (m *multimap) KeySet() set.Interface {
+	keys := set.New()
+	for k := range m.m {
+		keys.Add(k)
+	}
+	return keys
+}
+
+func (m *multimap) values(k interface{}) []interface{} {
+	c, ok := m.m[k]
+	if !ok {
+		return []interface{}{}
+	}
+	return c.Slice()
+}
+
+func (m *multimap) inverse(inv *multimap) {
+	m.Each(func(k, v interface{}) bool {
+		inv.Put(v, k)
+		return true
+	})
+}
+
+// copyTo fills dst with copies of the value collections of m.
+func (m *multimap) copyTo(dst *multimap) {
+	for k, c := range m.m {
+		dst.m[k] = c.CopyCollection()
+	}
+	dst.length = m.length
+}
@@ -0,0 +1,87 @@
+package multimap
+
+import (
+	"sync"
+
+	"github.com/khezen/struct/set"
+)
+
+// multimapSync guards a multimap baseline with a read/write lock.
+type multimapSync struct {
+	multimap
+	l sync.RWMutex
+}
+
+func (m *multimapSync) Put(k, v interface{}) {
+	m.l.Lock()
+	defer m.l.Unlock()
+	m.multimap.Put(k, v)
+}
+
+func (m *multimapSync) PutAll(k interface{}, values ...interface{}) {
+	if len(values) > 0 {
+		m.l.Lock()
+		defer m.l.Unlock()
+		m.multimap.PutAll(k, values...)
+	}
+}
+
+func (m *multimapSync) RemoveValue(k, v interface{}) {
+	m.l.Lock()
+	defer m.l.Unlock()
+	m.multimap.RemoveValue(k, v)
+}
+
+func (m *multimapSync) RemoveAll(keys ...interface{}) {
+	m.l.Lock()
+	defer m.l.Unlock()
+	m.multimap.RemoveAll(keys...)
+}
+
+func (m *multimapSync) Has(keys ...interface{}) bool {
+	m.l.RLock()
+	defer m.l.RUnlock()
+	return m.multimap.Has(keys...)
+}
+
+func (m *multimapSync) ContainsEntry(k, v interface{}) bool {
+	m.l.RLock()
+	defer m.l.RUnlock()
+	return m.multimap.ContainsEntry(k, v)
+}
+
+func (m *multimapSync) Each(f func(k, v interface{}) bool) {
+	m.l.RLock()
+	defer m.l.RUnlock()
+	m.multimap.Each(f)
+}
+
+func (m *multimapSync) Len() int {
+	m.l.RLock()
+	defer m.l.RUnlock()
+	return m.multimap.Len()
+}
+
+func (m *multimapSync) KeyLen() int {
+	m.l.RLock()
+	defer m.l.RUnlock()
+	return m.multimap.KeyLen()
+}
+
+func (m *multimapSync) Clear() {
+	m.l.Lock()
+	defer m.l.Unlock()
+	m.multimap.Clear()
+}
+
+func (m *multimapSync) IsEmpty() bool {
+	m.l.RLock()
+	defer m.l.RUnlock()
+	return m.multimap.IsEmpty()
+}
+
+func (m *multimapSync) KeySet() set.Interface {
+	m.l.RLock()
+	defer m.l.RUnlock()
+	return set.NewSync(m.multimap.KeySet().Slice()...)
+}
@@ -0,0 +1,287 @@
+package multimap
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/khezen/struct/array"
+	"github.com/khezen/struct/set"
+)
+
+func TestPut(t *testing.T) {
+	cases := []struct {
+		m        Interface
+		k, v     interface{}
+		len      int
+		keyLen   int
+		hasEntry bool
+	}{
+		{NewSet("a", 1, "a", 2), "a", 3, 3, 1, true},
+		{NewSet("a", 1, "a", 2), "a", 2, 2, 1, true},
+		{NewSet("a", 1), "b", 1, 2, 2, true},
+		{NewSetSync("a", 1, "a", 2), "a", 2, 2, 1, true},
+		{NewArray("a", 1, "a", 2), "a", 2, 3, 1, true},
+		{NewArray("a", 1), "b", 1, 2, 2, true},
+		{NewArraySync("a", 1, "a", 2), "a", 2, 3, 1, true},
+	}
+	for _, c := range cases {
+		c.m.Put(c.k, c.v)
+		if c.m.Len() != c.len {
+			t.Errorf("Expected %v. Got %v.", c.len, c.m.Len())
+		}
+		if c.m.KeyLen() != c.keyLen {
+			t.Errorf("Expected %v. Got %v.", c.keyLen, c.m.KeyLen())
+		}
+		if c.m.ContainsEntry(c.k, c.v) != c.hasEntry {
+			t.Errorf("Expected %v. Got %v.", c.hasEntry, !c.hasEntry)
+		}
+	}
+}
+
+func TestGetSet(t *testing.T) {
+	cases := []struct {
+		m        Set
+		k        interface{}
+		expected set.Interface
+	}{
+		{NewSet("a", 1, "a", 2, "b", 3), "a", set.New(1, 2)},
+		{NewSet("a", 1, "a", 2, "b", 3), "c", set.New()},
+		{NewSetSync("a", 1, "a", 2, "b", 3), "a", set.New(1, 2)},
+		{NewSetSync("a", 1, "a", 2, "b", 3), "c", set.New()},
+	}
+	for _, c := range cases {
+		values := c.m.Get(c.k)
+		if !values.IsEqual(c.expected) {
+			t.Errorf("Expected %v. Got %v.", c.expected, values)
+		}
+		values.Add(42)
+		if c.m.ContainsEntry(c.k, 42) {
+			t.Error("Get should return a copy")
+		}
+	}
+}
+
+func TestGetArray(t *testing.T) {
+	cases := []struct {
+		m        Array
+		k        interface{}
+		expected array.Interface
+	}{
+		{NewArray("a", 2, "a", 1, "a", 2, "b", 3), "a", array.New(2, 1, 2)},
+		{NewArray("a", 1), "c", array.New()},
+		{NewArraySync("a", 2, "a", 1, "a", 2, "b", 3), "a", array.New(2, 1, 2)},
+		{NewArraySync("a", 1), "c", array.New()},
+	}
+	for _, c := range cases {
+		values := c.m.Get(c.k)
+		if !values.IsEqual(c.expected) {
+			t.Errorf("Expected %v. Got %v.", c.expected, values)
+		}
+	}
+}
+
+func TestRemoveValue(t *testing.T) {
+	cases := []struct {
+		m      Interface
+		k, v   interface{}
+		len    int
+		hasKey bool
+	}{
+		{NewSet("a", 1, "a", 2), "a", 1, 1, true},
+		{NewSet("a", 1, "b", 2), "a", 1, 1, false},
+		{NewSet("a", 1), "a", 2, 1, true},
+		{NewSet("a", 1), "b", 1, 1, false},
+		{NewSetSync("a", 1, "b", 2), "a", 1, 1, false},
+		{NewArray("a", 1, "a", 1), "a", 1, 1, true},
+		{NewArray("a", 1, "b", 2), "a", 1, 1, false},
+		{NewArraySync("a", 1, "b", 2), "a", 1, 1, false},
+	}
+	for _, c := range cases {
+		c.m.RemoveValue(c.k, c.v)
+		if c.m.Len() != c.len {
+			t.Errorf("Expected %v. Got %v.", c.len, c.m.Len())
+		}
+		if c.m.Has(c.k) != c.hasKey {
+			t.Errorf("Expected %v. Got %v.", c.hasKey, !c.hasKey)
+		}
+	}
+}
+
+func TestRemoveAll(t *testing.T) {
+	cases := []struct {
+		m    Interface
+		keys []interface{}
+		len  int
+	}{
+		{NewSet("a", 1, "a", 2, "b", 3), []interface{}{"a"}, 1},
+		{NewSet("a", 1, "a", 2, "b", 3), []interface{}{"a", "b", "c"}, 0},
+		{NewSetSync("a", 1, "a", 2, "b", 3), []interface{}{"a"}, 1},
+		{NewArray("a", 1, "a", 1, "b", 3), []interface{}{"a"}, 1},
+		{NewArraySync("a", 1, "a", 1, "b", 3), []interface{}{"b"}, 2},
+	}
+	for _, c := range cases {
+		c.m.RemoveAll(c.keys...)
+		if c.m.Len() != c.len {
+			t.Errorf("Expected %v. Got %v.", c.len, c.m.Len())
+		}
+		for _, k := range c.keys {
+			if c.m.Has(k) {
+				t.Errorf("%v should have been removed", k)
+			}
+		}
+	}
+}
+
+func TestEach(t *testing.T) {
+	cases := []struct {
+		m                 Interface
+		counter, expected int
+	}{
+		{NewSet("a", 1, "a", 2, "b", 3), 0, 3},
+		{NewSetSync("a", 1, "a", 2, "b", 3), 0, 3},
+		{NewArray("a", 1, "a", 1, "b", 3), 0, 3},
+		{NewArraySync("a", 1, "a", 1, "b", 3), 0, 3},
+	}
+	for _, c := range cases {
+		c.m.Each(func(k, v interface{}) bool {
+			c.counter++
+			return true
+		})
+		if c.counter != c.expected {
+			t.Errorf("Expected %v. Got %v.", c.expected, c.counter)
+		}
+		c.counter = 0
+		c.m.Each(func(k, v interface{}) bool {
+			c.counter++
+			return false
+		})
+		if c.counter != 1 {
+			t.Errorf("Expected %v. Got %v.", 1, c.counter)
+		}
+	}
+}
+
+func TestClear(t *testing.T) {
+	cases := []struct {
+		m Interface
+	}{
+		{NewSet("a", 1, "a", 2)},
+		{NewSetSync("a", 1, "a", 2)},
+		{NewArray("a", 1, "a", 2)},
+		{NewArraySync("a", 1, "a", 2)},
+	}
+	for _, c := range cases {
+		c.m.Clear()
+		if !c.m.IsEmpty() || c.m.KeyLen() != 0 {
+			t.Error("Multimap should be empty")
+		}
+	}
+}
+
+func TestKeySet(t *testing.T) {
+	cases := []struct {
+		m        Interface
+		expected set.Interface
+	}{
+		{NewSet("a", 1, "a", 2, "b", 3), set.New("a", "b")},
+		{NewSetSync("a", 1, "a", 2, "b", 3), set.New("a", "b")},
+		{NewArray("a", 1, "a", 2, "b", 3), set.New("a", "b")},
+		{NewArraySync(), set.New()},
+	}
+	for _, c := range cases {
+		keys := c.m.KeySet()
+		if !keys.IsEqual(c.expected) {
+			t.Errorf("Expected %v. Got %v.", c.expected, keys)
+		}
+	}
+}
+
+func TestInverseSet(t *testing.T) {
+	cases := []struct {
+		m, expected Set
+	}{
+		{NewSet("a", 1, "a", 2, "b", 1), NewSet(1, "a", 1, "b", 2, "a")},
+		{NewSetSync("a", 1, "a", 2, "b", 1), NewSet(1, "a", 1, "b", 2, "a")},
+	}
+	for _, c := range cases {
+		inv := c.m.Inverse()
+		if inv.Len() != c.expected.Len() {
+			t.Errorf("Expected %v. Got %v.", c.expected, inv)
+		}
+		c.expected.Each(func(k, v interface{}) bool {
+			if !inv.ContainsEntry(k, v) {
+				t.Errorf("Expected %v. Got %v.", c.expected, inv)
+			}
+			return true
+		})
+	}
+}
+
+func TestInverseArray(t *testing.T) {
+	cases := []struct {
+		m        Array
+		k        interface{}
+		expected array.Interface
+	}{
+		{NewArray("a", 1, "a", 1, "b", 2), 1, array.New("a", "a")},
+		{NewArraySync("a", 1, "a", 1, "b", 2), 2, array.New("b")},
+	}
+	for _, c := range cases {
+		values := c.m.Inverse().Get(c.k)
+		if !values.IsEqual(c.expected) {
+			t.Errorf("Expected %v. Got %v.", c.expected, values)
+		}
+	}
+}
+
+func TestString(t *testing.T) {
+	cases := []struct {
+		m        Interface
+		expected string
+	}{
+		{NewSet("a", 1), "map[a:[1]]"},
+		{NewSetSync(), "map[]"},
+		{NewArray("a", 1, "a", 2), "map[a:[1 2]]"},
+		{NewArraySync("a", 1), "map[a:[1]]"},
+	}
+	for _, c := range cases {
+		if c.m.String() != c.expected {
+			t.Errorf("Expected %v. Got %v.", c.expected, c.m.String())
+		}
+	}
+}
+
+func TestFormat(t *testing.T) {
+	cases := []struct {
+		format   string
+		m        Interface
+		expected string
+	}{
+		{"%v", NewSet("b", 2, "a", 3, "a", 1), "map[a:[1 3] b:[2]]"},
+		{"%+v", NewArraySync("a", 2, "a", 1), "multimap.ArraySync(len=1) map[a:[2 1]]"},
+		{"%#v", NewArray("b", 1, "a", 2, "a", 1), `multimap.NewArray("a", 2, "a", 1, "b", 1)`},
+		{"%#v", NewSetSync("a", 1), `multimap.NewSetSync("a", 1)`},
+	}
+	for _, c := range cases {
+		if got := fmt.Sprintf(c.format, c.m); got != c.expected {
+			t.Errorf("Expected %v. Got %v.", c.expected, got)
+		}
+	}
+}
+
+func TestSnapshot(t *testing.T) {
+	s := NewSetSync("a", 1)
+	setSnapshot := s.(interface{ Snapshot() Set }).Snapshot()
+	s.Put("a", 2)
+	setSnapshot.Put("b", 3)
+	if _, ok := setSnapshot.(*setMultimap); !ok || setSnapshot.Len() != 2 || setSnapshot.ContainsEntry("a", 2) || s.Has("b") {
+		t.Errorf("Expected %v. Got %v.", 2, setSnapshot.Len())
+	}
+	a := NewArraySync("a", 1)
+	arraySnapshot := a.(interface{ Snapshot() Array }).Snapshot()
+	a.Put("a", 2)
+	arraySnapshot.Put("a", 3)
+	if _, ok := arraySnapshot.(*arrayMultimap); !ok || !arraySnapshot.Get("a").IsEqual(array.New(1, 3)) || !a.Get("a").IsEqual(array.New(1, 2)) {
+		t.Errorf("Expected %v. Got %v.", array.New(1, 3), arraySnapshot.Get("a"))
+	}
+}
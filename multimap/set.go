@@ -0,0 +1,54 @@
+package multimap
+
+import (
+	"fmt"
+
+	"github.com/khezen/struct/collection"
+	"github.com/khezen/struct/internal/format"
+	"github.com/khezen/struct/set"
+)
+
+type setMultimap struct {
+	multimap
+}
+
+// NewSet creates a multimap holding distinct values for each key. It accepts
+// key/value pairs to populate the initial multimap.
+func NewSet(pairs ...interface{}) Set {
+	m := &setMultimap{
+		newMultimap(newSetValues),
+	}
+	length := len(pairs)
+	for i := 0; i < length-1; i += 2 {
+		m.Put(pairs[i], pairs[i+1])
+	}
+	return m
+}
+
+func newSetValues() collection.Interface {
+	return set.New()
+}
+
+// Get returns a copy of the values associated with k. If k is missing an
+// empty set is returned.
+func (m *setMultimap) Get(k interface{}) set.Interface {
+	return set.New(m.values(k)...)
+}
+
+// Inverse returns a new multimap in which every value is associated with its keys.
+func (m *setMultimap) Inverse() Set {
+	inv := NewSet().(*setMultimap)
+	m.inverse(&inv.multimap)
+	return inv
+}
+
+func (m *setMultimap) String() string {
+	return format.String(m)
+}
+
+// Format implements fmt.Formatter: %v prints each key with its values, %+v
+// adds the type and the number of keys and %#v the Go syntax. A precision
+// truncates the keys: %.3v prints the 3 first followed by "...".
+func (m *setMultimap) Format(f fmt.State, verb rune) {
+	m.format(f, verb, "multimap.Set", "multimap.NewSet")
+}
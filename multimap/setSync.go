@@ -0,0 +1,56 @@
+package multimap
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/khezen/struct/internal/format"
+	"github.com/khezen/struct/set"
+)
+
+type setMultimapSync struct {
+	multimapSync
+}
+
+// NewSetSync creates a thread safe multimap holding distinct values for each key.
+func NewSetSync(pairs ...interface{}) Set {
+	return &setMultimapSync{
+		multimapSync{
+			NewSet(pairs...).(*setMultimap).multimap,
+			sync.RWMutex{},
+		},
+	}
+}
+
+func (m *setMultimapSync) Get(k interface{}) set.Interface {
+	m.l.RLock()
+	defer m.l.RUnlock()
+	return set.NewSync(m.values(k)...)
+}
+
+func (m *setMultimapSync) Inverse() Set {
+	m.l.RLock()
+	defer m.l.RUnlock()
+	inv := NewSetSync().(*setMultimapSync)
+	m.inverse(&inv.multimap)
+	return inv
+}
+
+// Snapshot returns a non-threadsafe copy of m consistent at the time of the call.
+func (m *setMultimapSync) Snapshot() Set {
+	m.l.RLock()
+	defer m.l.RUnlock()
+	cpy := NewSet().(*setMultimap)
+	m.copyTo(&cpy.multimap)
+	return cpy
+}
+
+func (m *setMultimapSync) String() string {
+	return format.String(m)
+}
+
+func (m *setMultimapSync) Format(f fmt.State, verb rune) {
+	m.l.RLock()
+	defer m.l.RUnlock()
+	m.format(f, verb, "multimap.SetSync", "multimap.NewSetSync")
+}
@@ -0,0 +1,208 @@
+// Package observer provides the events published by observable collections
+// and the subject delivering them to subscribers, either through callbacks or
+// channels.
+package observer
+
+import (
+	"sync"
+	"sync/atomic"
+)
+
+// Op is the kind of modification an event describes
+type Op int
+
+const (
+	// Add - an item was added
+	Add Op = iota + 1
+	// Remove - an item or a key was removed
+	Remove
+	// Replace - an item or the value of a key was replaced
+	Replace
+	// Clear - every item or key was removed
+	Clear
+	// Put - a new key was associated with a value
+	Put
+	// Batch - the events of a Merge, Separate or Retain
+	Batch
+)
+
+var opNames = [...]string{"", "Add", "Remove", "Replace", "Clear", "Put", "Batch"}
+
+func (op Op) String() string {
+	if op <= 0 || int(op) >= len(opNames) {
+		return "Unknown"
+	}
+	return opNames[op]
+}
+
+// Event describes a modification of an observable collection
+type Event struct {
+	Op Op
+	// Item is the item added, removed or replaced, or the key for hashmaps
+	Item interface{}
+	// Index is the position of Item in arrays and ordered sets, -1 otherwise
+	Index int
+	// Old is the item or value before the modification
+	Old interface{}
+	// New is the item or value after the modification
+	New interface{}
+	// Batch holds the events of a Merge, Separate or Retain when Op is Batch
+	Batch []Event
+}
+
+type subscriber struct {
+	f      func(Event)
+	active int32
+}
+
+// Subject delivers events to subscribers in the order they were queued.
+// Collections queue events while holding their lock and flush them after
+// releasing it, so subscribers may read or modify the collection.
+type Subject struct {
+	l        sync.Mutex
+	subs     []*subscriber
+	queue    []Event
+	draining bool
+}
+
+// Subscribe registers f to be called with every event. Calls are sequential,
+// in modification order, and happen in the goroutine of one of the writers.
+func (s *Subject) Subscribe(f func(Event)) (unsubscribe func()) {
+	sub := &subscriber{f, 1}
+	s.l.Lock()
+	s.subs = append(s.subs, sub)
+	s.l.Unlock()
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			atomic.StoreInt32(&sub.active, 0)
+			s.l.Lock()
+			defer s.l.Unlock()
+			for i, current := range s.subs {
+				if current == sub {
+					s.subs = append(s.subs[:i:i], s.subs[i+1:]...)
+					break
+				}
+			}
+		})
+	}
+}
+
+// SubscribeChan returns a channel receiving every event. Events are buffered
+// without bound so writers never wait for the reader. The channel is closed on
+// unsubscribe.
+func (s *Subject) SubscribeChan() (events <-chan Event, unsubscribe func()) {
+	c := &channel{
+		ready: make(chan struct{}, 1),
+		done:  make(chan struct{}),
+		out:   make(chan Event),
+	}
+	go c.pump()
+	unsubscribeFunc := s.Subscribe(c.push)
+	var once sync.Once
+	return c.out, func() {
+		once.Do(func() {
+			unsubscribeFunc()
+			close(c.done)
+		})
+	}
+}
+
+// HasSubscribers reports whether events would be delivered to anyone
+func (s *Subject) HasSubscribers() bool {
+	s.l.Lock()
+	defer s.l.Unlock()
+	return len(s.subs) > 0
+}
+
+// Queue appends events to be delivered by the next Flush.
+// Events are dropped when nobody subscribed.
+func (s *Subject) Queue(events ...Event) {
+	s.l.Lock()
+	defer s.l.Unlock()
+	if len(s.subs) > 0 {
+		s.queue = append(s.queue, events...)
+	}
+}
+
+// Flush delivers queued events unless another call to Flush is already
+// delivering them, in which case it delivers events queued meanwhile as well.
+func (s *Subject) Flush() {
+	s.l.Lock()
+	if s.draining {
+		s.l.Unlock()
+		return
+	}
+	s.draining = true
+	s.l.Unlock()
+	done := false
+	defer func() {
+		if !done { // a subscriber panicked, let the next Flush resume delivery
+			s.l.Lock()
+			s.draining = false
+			s.l.Unlock()
+		}
+	}()
+	for {
+		s.l.Lock()
+		if len(s.queue) == 0 {
+			s.queue = nil
+			s.draining = false
+			done = true
+			s.l.Unlock()
+			return
+		}
+		e := s.queue[0]
+		s.queue = s.queue[1:]
+		subs := s.subs
+		s.l.Unlock()
+		for _, sub := range subs {
+			if atomic.LoadInt32(&sub.active) == 1 {
+				sub.f(e)
+			}
+		}
+	}
+}
+
+// channel forwards pushed events to out from its own goroutine
+type channel struct {
+	l     sync.Mutex
+	queue []Event
+	ready chan struct{}
+	done  chan struct{}
+	out   chan Event
+}
+
+func (c *channel) push(e Event) {
+	c.l.Lock()
+	c.queue = append(c.queue, e)
+	c.l.Unlock()
+	select {
+	case c.ready <- struct{}{}:
+	default:
+	}
+}
+
+func (c *channel) pump() {
+	defer close(c.out)
+	for {
+		c.l.Lock()
+		if len(c.queue) == 0 {
+			c.l.Unlock()
+			select {
+			case <-c.ready:
+				continue
+			case <-c.done:
+				return
+			}
+		}
+		e := c.queue[0]
+		c.queue = c.queue[1:]
+		c.l.Unlock()
+		select {
+		case c.out <- e:
+		case <-c.done:
+			return
+		}
+	}
+}
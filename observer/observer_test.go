@@ -0,0 +1,170 @@
+package observer
+
+import (
+	"sync"
+	"testing"
+)
+
+func TestOpString(t *testing.T) {
+	cases := []struct {
+		op       Op
+		expected string
+	}{
+		{Add, "Add"},
+		{Remove, "Remove"},
+		{Replace, "Replace"},
+		{Clear, "Clear"},
+		{Put, "Put"},
+		{Batch, "Batch"},
+		{Op(0), "Unknown"},
+		{Op(42), "Unknown"},
+	}
+	for _, c := range cases {
+		if c.op.String() != c.expected {
+			t.Errorf("Expected %v. Got %v.", c.expected, c.op)
+		}
+	}
+}
+
+func TestSubscribe(t *testing.T) {
+	var s Subject
+	var first, second []interface{}
+	unsubscribe := s.Subscribe(func(e Event) {
+		first = append(first, e.Item)
+	})
+	s.Subscribe(func(e Event) {
+		second = append(second, e.Item)
+	})
+	if !s.HasSubscribers() {
+		t.Errorf("Expected %v. Got %v.", true, false)
+	}
+	s.Queue(Event{Op: Add, Item: 1}, Event{Op: Add, Item: 2})
+	s.Flush()
+	unsubscribe()
+	unsubscribe()
+	s.Queue(Event{Op: Add, Item: 3})
+	s.Flush()
+	if len(first) != 2 || first[0] != 1 || first[1] != 2 {
+		t.Errorf("Expected %v. Got %v.", []interface{}{1, 2}, first)
+	}
+	if len(second) != 3 || second[2] != 3 {
+		t.Errorf("Expected %v. Got %v.", []interface{}{1, 2, 3}, second)
+	}
+}
+
+func TestQueueWithoutSubscribers(t *testing.T) {
+	var s Subject
+	s.Queue(Event{Op: Add, Item: 1})
+	var received []interface{}
+	s.Subscribe(func(e Event) {
+		received = append(received, e.Item)
+	})
+	s.Flush()
+	if len(received) != 0 {
+		t.Errorf("Expected %v. Got %v.", 0, len(received))
+	}
+}
+
+func TestReentrantFlush(t *testing.T) {
+	var s Subject
+	var received []interface{}
+	s.Subscribe(func(e Event) {
+		received = append(received, e.Item)
+		if e.Item == 1 {
+			s.Queue(Event{Op: Add, Item: 3})
+			s.Flush()
+		}
+	})
+	s.Queue(Event{Op: Add, Item: 1}, Event{Op: Add, Item: 2})
+	s.Flush()
+	expected := []interface{}{1, 2, 3}
+	if len(received) != len(expected) {
+		t.Fatalf("Expected %v. Got %v.", expected, received)
+	}
+	for i := range expected {
+		if received[i] != expected[i] {
+			t.Errorf("Expected %v. Got %v.", expected, received)
+		}
+	}
+}
+
+func TestPanickingSubscriber(t *testing.T) {
+	var s Subject
+	var received []interface{}
+	s.Subscribe(func(e Event) {
+		if e.Item == 1 {
+			panic("boom")
+		}
+		received = append(received, e.Item)
+	})
+	func() {
+		defer func() {
+			if recover() == nil {
+				t.Errorf("Expected %v. Got %v.", "panic", nil)
+			}
+		}()
+		s.Queue(Event{Op: Add, Item: 1})
+		s.Flush()
+	}()
+	s.Queue(Event{Op: Add, Item: 2})
+	s.Flush()
+	if len(received) != 1 || received[0] != 2 {
+		t.Errorf("Expected %v. Got %v.", []interface{}{2}, received)
+	}
+}
+
+func TestSubscribeChan(t *testing.T) {
+	var s Subject
+	events, unsubscribe := s.SubscribeChan()
+	for i := 0; i < 100; i++ {
+		s.Queue(Event{Op: Add, Item: i})
+		s.Flush()
+	}
+	for i := 0; i < 100; i++ {
+		if e := <-events; e.Item != i {
+			t.Errorf("Expected %v. Got %v.", i, e.Item)
+		}
+	}
+	unsubscribe()
+	unsubscribe()
+	s.Queue(Event{Op: Add, Item: 100})
+	s.Flush()
+	if _, open := <-events; open {
+		t.Errorf("Expected %v. Got %v.", false, open)
+	}
+	if s.HasSubscribers() {
+		t.Errorf("Expected %v. Got %v.", false, true)
+	}
+}
+
+func TestConcurrentDelivery(t *testing.T) {
+	var s Subject
+	var l sync.Mutex
+	next := 0
+	s.Subscribe(func(e Event) {
+		if e.Item != next {
+			t.Errorf("Expected %v. Got %v.", next, e.Item)
+		}
+		next++
+	})
+	var wg sync.WaitGroup
+	counter := 0
+	for g := 0; g < 8; g++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < 100; i++ {
+				l.Lock()
+				s.Queue(Event{Op: Add, Item: counter})
+				counter++
+				l.Unlock()
+				s.Flush()
+			}
+		}()
+	}
+	wg.Wait()
+	s.Flush()
+	if next != 800 {
+		t.Errorf("Expected %v. Got %v.", 800, next)
+	}
+}
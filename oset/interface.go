@@ -5,14 +5,26 @@ import (
 	"github.com/khezen/struct/set"
 )
 
+// ReadOnly describes methods of an ordered set which do not modify it
+type ReadOnly interface {
+	array.ReadOnly
+	IsSubset(s ReadOnly) bool
+	IsSuperset(s ReadOnly) bool
+}
+
 // Interface describe an ordered set
 type Interface interface {
 	array.Interface
-	IsSubset(s Interface) bool
-	IsSuperset(s Interface) bool
+	ReadOnly
 	CopyOset() Interface
 	Subset(i, j int) Interface
 	Set() set.Interface
 	CopySet() set.Interface
 	Arr() array.Interface
 }
+
+// Snapshotter is implemented by thread safe ordered sets, which can return a
+// non-threadsafe copy of themselves consistent at the time of the call.
+type Snapshotter interface {
+	Snapshot() Interface
+}
@@ -0,0 +1,514 @@
+package oset
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/khezen/struct/array"
+	"github.com/khezen/struct/collection"
+	"github.com/khezen/struct/internal/format"
+	"github.com/khezen/struct/observer"
+	"github.com/khezen/struct/set"
+)
+
+// Observable is a thread safe ordered set publishing an event on each modification
+type Observable interface {
+	Interface
+	// Subscribe calls f with every event until unsubscribe is called
+	Subscribe(f func(observer.Event)) (unsubscribe func())
+	// SubscribeChan sends every event on a channel closed by unsubscribe
+	SubscribeChan() (events <-chan observer.Event, unsubscribe func())
+}
+
+// osetObservable forwards calls to the wrapped ordered set and publishes its changes
+type osetObservable struct {
+	s Interface
+	l sync.RWMutex
+	observer.Subject
+}
+
+// NewObservable wraps s in a thread safe ordered set publishing Add, Remove,
+// Replace and Clear events carrying the index of the item, and a single Batch
+// event for Merge, Separate, Retain, Swap and each bulk operation. Items moved
+// by Swap, Reverse and Rotate are removed then added back. Applying the events
+// in order on a copy of s reproduces it. s must not be modified but through
+// the wrapper.
+func NewObservable(s Interface) Observable {
+	return &osetObservable{s: s}
+}
+
+// write applies f under lock and delivers the events it returns once unlocked
+func (o *osetObservable) write(f func() []observer.Event) {
+	func() {
+		o.l.Lock()
+		defer o.l.Unlock()
+		o.Queue(f()...)
+	}()
+	o.Flush()
+}
+
+// operand returns the wrapped set when t is o itself, which is already locked
+func (o *osetObservable) operand(t collection.ReadOnly) collection.ReadOnly {
+	if t == collection.ReadOnly(o) {
+		return o.s
+	}
+	return t
+}
+
+func (o *osetObservable) Add(items ...interface{}) {
+	o.write(func() []observer.Event {
+		return o.add(items)
+	})
+}
+
+func (o *osetObservable) add(items []interface{}) []observer.Event {
+	events := make([]observer.Event, 0, len(items))
+	for _, item := range items {
+		if !o.s.Has(item) {
+			events = append(events, added(o.s.Len(), item))
+			o.s.Add(item)
+		}
+	}
+	return events
+}
+
+func (o *osetObservable) Insert(i int, items ...interface{}) {
+	o.write(func() []observer.Event {
+		fresh, seen := make([]interface{}, 0, len(items)), set.New()
+		for _, item := range items {
+			if !o.s.Has(item) && !seen.Has(item) {
+				fresh = append(fresh, item)
+				seen.Add(item)
+			}
+		}
+		o.s.Insert(i, fresh...)
+		events := make([]observer.Event, 0, len(fresh))
+		for j, item := range fresh {
+			events = append(events, added(i+j, item))
+		}
+		return events
+	})
+}
+
+func (o *osetObservable) Remove(items ...interface{}) {
+	o.write(func() []observer.Event {
+		return o.remove(items)
+	})
+}
+
+func (o *osetObservable) remove(items []interface{}) []observer.Event {
+	events := make([]observer.Event, 0, len(items))
+	for _, item := range items {
+		if i, err := o.s.IndexOf(item); err == nil {
+			events = append(events, removed(i, o.s.RemoveAt(i)))
+		}
+	}
+	return events
+}
+
+func (o *osetObservable) RemoveAt(i int) (item interface{}) {
+	o.write(func() []observer.Event {
+		item = o.s.RemoveAt(i)
+		return []observer.Event{removed(i, item)}
+	})
+	return item
+}
+
+func (o *osetObservable) Replace(item, substitute interface{}) {
+	o.write(func() []observer.Event {
+		i, err := o.s.IndexOf(item)
+		if err != nil {
+			return nil
+		}
+		_, e := o.replaceAt(i, substitute)
+		return []observer.Event{e}
+	})
+}
+
+func (o *osetObservable) ReplaceAt(i int, substitute interface{}) (item interface{}) {
+	o.write(func() []observer.Event {
+		var e observer.Event
+		item, e = o.replaceAt(i, substitute)
+		return []observer.Event{e}
+	})
+	return item
+}
+
+// replaceAt reports a removal when the substitute is already present, since
+// the ordered set drops it along with the item it replaces
+func (o *osetObservable) replaceAt(i int, substitute interface{}) (interface{}, observer.Event) {
+	if o.s.Has(substitute) && o.s.Get(i) != substitute {
+		item := o.s.RemoveAt(i)
+		return item, removed(i, item)
+	}
+	item := o.s.ReplaceAt(i, substitute)
+	return item, replaced(i, item, substitute)
+}
+
+func (o *osetObservable) Swap(i, j int) {
+	o.write(func() []observer.Event {
+		return o.rewrite(func() {
+			o.s.Swap(i, j)
+		})
+	})
+}
+
+func (o *osetObservable) Clear() {
+	o.write(func() []observer.Event {
+		if o.s.IsEmpty() {
+			return nil
+		}
+		o.s.Clear()
+		return []observer.Event{{Op: observer.Clear, Index: -1}}
+	})
+}
+
+func (o *osetObservable) Merge(t collection.Interface) {
+	o.write(func() []observer.Event {
+		return batch(o.add(o.operand(t).Slice()))
+	})
+}
+
+func (o *osetObservable) Separate(t collection.Interface) {
+	o.write(func() []observer.Event {
+		return batch(o.remove(o.operand(t).Slice()))
+	})
+}
+
+// Retain removes items from the last to the first so that indexes of
+// later events remain valid once earlier events are applied.
+func (o *osetObservable) Retain(t collection.Interface) {
+	o.write(func() []observer.Event {
+		t := o.operand(t)
+		var events []observer.Event
+		for i := o.s.Len() - 1; i >= 0; i-- {
+			if !t.Has(o.s.Get(i)) {
+				events = append(events, removed(i, o.s.RemoveAt(i)))
+			}
+		}
+		return batch(events)
+	})
+}
+
+func (o *osetObservable) Splice(i, deleteCount int, items ...interface{}) (removed []interface{}) {
+	o.write(func() []observer.Event {
+		return o.splice(i, func() []interface{} {
+			removed = o.s.Splice(i, deleteCount, items...)
+			return removed
+		})
+	})
+	return removed
+}
+
+func (o *osetObservable) RemoveRange(i, j int) {
+	o.Splice(i, j-i)
+}
+
+func (o *osetObservable) InsertAll(i int, t collection.ReadOnly) {
+	o.write(func() []observer.Event {
+		return o.splice(i, func() []interface{} {
+			return o.s.Splice(i, 0, o.operand(t).Slice()...)
+		})
+	})
+}
+
+func (o *osetObservable) Reverse() {
+	o.write(func() []observer.Event {
+		return o.rewrite(o.s.Reverse)
+	})
+}
+
+func (o *osetObservable) Rotate(k int) {
+	o.write(func() []observer.Event {
+		return o.rewrite(func() {
+			o.s.Rotate(k)
+		})
+	})
+}
+
+// Fill publishes the removal of the items from i to j and the addition of item.
+func (o *osetObservable) Fill(i, j int, item interface{}) {
+	o.write(func() []observer.Event {
+		return o.splice(i, func() []interface{} {
+			removed := o.s.SliceRange(i, j).Slice()
+			o.s.Fill(i, j, item)
+			return removed
+		})
+	})
+}
+
+func (o *osetObservable) RemoveIf(pred func(item interface{}) bool) (count int) {
+	o.write(func() []observer.Event {
+		return o.patch(func() {
+			count = o.s.RemoveIf(pred)
+		})
+	})
+	return count
+}
+
+func (o *osetObservable) ReplaceIf(pred func(item interface{}) bool, substitute func(item interface{}) interface{}) (count int) {
+	o.write(func() []observer.Event {
+		return o.patch(func() {
+			count = o.s.ReplaceIf(pred, substitute)
+		})
+	})
+	return count
+}
+
+// patch applies f, then returns the events turning the previous items into the new ones
+func (o *osetObservable) patch(f func()) []observer.Event {
+	before := array.New(o.s.Slice()...)
+	f()
+	var events []observer.Event
+	for _, e := range array.Diff(before, o.s) {
+		switch e.Op {
+		case array.OpInsert:
+			events = append(events, added(e.Index, e.Item))
+		case array.OpDelete:
+			events = append(events, removed(e.Index, e.Item))
+		case array.OpMove:
+			events = append(events, removed(e.From, e.Item), added(e.Index, e.Item))
+		}
+	}
+	return batch(events)
+}
+
+// splice applies f, which removes items from i, returns them and inserts
+// others at i, then returns the events reproducing it
+func (o *osetObservable) splice(i int, f func() []interface{}) []observer.Event {
+	length := o.s.Len()
+	items := f()
+	inserted := o.s.Len() - length + len(items)
+	events := make([]observer.Event, 0, len(items)+inserted)
+	for _, item := range items {
+		events = append(events, removed(i, item))
+	}
+	for k := i; k < i+inserted; k++ {
+		events = append(events, added(k, o.s.Get(k)))
+	}
+	return batch(events)
+}
+
+// rewrite applies f, which moves items in place, then returns the removal of
+// the moved items followed by their addition at their new index. Replacing an
+// item by another one still present would drop the latter from the set.
+func (o *osetObservable) rewrite(f func()) []observer.Event {
+	before := o.s.Slice()
+	f()
+	var moved []int
+	for i, item := range before {
+		if o.s.Get(i) != item {
+			moved = append(moved, i)
+		}
+	}
+	events := make([]observer.Event, 0, 2*len(moved))
+	for k := len(moved) - 1; k >= 0; k-- {
+		events = append(events, removed(moved[k], before[moved[k]]))
+	}
+	for _, i := range moved {
+		events = append(events, added(i, o.s.Get(i)))
+	}
+	return batch(events)
+}
+
+func (o *osetObservable) Get(i int) interface{} {
+	o.l.RLock()
+	defer o.l.RUnlock()
+	return o.s.Get(i)
+}
+
+func (o *osetObservable) IndexOf(item interface{}) (int, error) {
+	o.l.RLock()
+	defer o.l.RUnlock()
+	return o.s.IndexOf(item)
+}
+
+func (o *osetObservable) Find(pred func(item interface{}) bool) (interface{}, error) {
+	o.l.RLock()
+	defer o.l.RUnlock()
+	return o.s.Find(pred)
+}
+
+func (o *osetObservable) FindIndex(pred func(item interface{}) bool) (int, error) {
+	o.l.RLock()
+	defer o.l.RUnlock()
+	return o.s.FindIndex(pred)
+}
+
+func (o *osetObservable) FindLast(pred func(item interface{}) bool) (interface{}, error) {
+	o.l.RLock()
+	defer o.l.RUnlock()
+	return o.s.FindLast(pred)
+}
+
+func (o *osetObservable) LastIndexOf(item interface{}) (int, error) {
+	o.l.RLock()
+	defer o.l.RUnlock()
+	return o.s.LastIndexOf(item)
+}
+
+func (o *osetObservable) IndexOfFrom(item interface{}, start int) (int, error) {
+	o.l.RLock()
+	defer o.l.RUnlock()
+	return o.s.IndexOfFrom(item, start)
+}
+
+func (o *osetObservable) Count(pred func(item interface{}) bool) int {
+	o.l.RLock()
+	defer o.l.RUnlock()
+	return o.s.Count(pred)
+}
+
+func (o *osetObservable) Has(items ...interface{}) bool {
+	o.l.RLock()
+	defer o.l.RUnlock()
+	return o.s.Has(items...)
+}
+
+func (o *osetObservable) Each(f func(item interface{}) bool) {
+	o.l.RLock()
+	defer o.l.RUnlock()
+	o.s.Each(f)
+}
+
+func (o *osetObservable) Len() int {
+	o.l.RLock()
+	defer o.l.RUnlock()
+	return o.s.Len()
+}
+
+func (o *osetObservable) IsEmpty() bool {
+	o.l.RLock()
+	defer o.l.RUnlock()
+	return o.s.IsEmpty()
+}
+
+func (o *osetObservable) IsEqual(t collection.ReadOnly) bool {
+	o.l.RLock()
+	defer o.l.RUnlock()
+	return o.s.IsEqual(o.operand(t))
+}
+
+func (o *osetObservable) IsSubset(t ReadOnly) bool {
+	o.l.RLock()
+	defer o.l.RUnlock()
+	return o.s.IsSubset(o.operand(t).(ReadOnly))
+}
+
+func (o *osetObservable) IsSuperset(t ReadOnly) bool {
+	o.l.RLock()
+	defer o.l.RUnlock()
+	return o.s.IsSuperset(o.operand(t).(ReadOnly))
+}
+
+func (o *osetObservable) String() string {
+	o.l.RLock()
+	defer o.l.RUnlock()
+	return o.s.String()
+}
+
+func (o *osetObservable) Format(f fmt.State, verb rune) {
+	o.l.RLock()
+	defer o.l.RUnlock()
+	format.Delegate(f, verb, o.s)
+}
+
+func (o *osetObservable) Slice() []interface{} {
+	o.l.RLock()
+	defer o.l.RUnlock()
+	return o.s.Slice()
+}
+
+// SubArray returns a copy of items from i to j, which does not publish events.
+func (o *osetObservable) SubArray(i, j int) array.Interface {
+	o.l.RLock()
+	defer o.l.RUnlock()
+	return o.s.SubArray(i, j)
+}
+
+// Subset returns a copy of items from i to j, which does not publish events.
+func (o *osetObservable) Subset(i, j int) Interface {
+	o.l.RLock()
+	defer o.l.RUnlock()
+	return o.s.Subset(i, j)
+}
+
+// SliceRange returns an ordered set holding items from i included to j
+// excluded, which does not publish events.
+func (o *osetObservable) SliceRange(i, j int) array.Interface {
+	o.l.RLock()
+	defer o.l.RUnlock()
+	return o.s.SliceRange(i, j)
+}
+
+// Repeat returns a copy of the ordered set, which does not publish events.
+func (o *osetObservable) Repeat(n int) array.Interface {
+	o.l.RLock()
+	defer o.l.RUnlock()
+	return o.s.Repeat(n)
+}
+
+// Concat returns an ordered set holding the items followed by those of each
+// t, which does not publish events.
+func (o *osetObservable) Concat(t ...collection.ReadOnly) array.Interface {
+	o.l.RLock()
+	defer o.l.RUnlock()
+	operands := make([]collection.ReadOnly, 0, len(t))
+	for _, c := range t {
+		operands = append(operands, o.operand(c))
+	}
+	return o.s.Concat(operands...)
+}
+
+// Arr returns a read only copy of the underlying array.
+func (o *osetObservable) Arr() array.Interface {
+	return array.NewReadOnly(o.CopyArr())
+}
+
+// Set returns a read only copy of the underlying set.
+func (o *osetObservable) Set() set.Interface {
+	return set.NewReadOnly(o.CopySet())
+}
+
+// CopyOset returns a copy of the wrapped ordered set, which does not publish events.
+func (o *osetObservable) CopyOset() Interface {
+	o.l.RLock()
+	defer o.l.RUnlock()
+	return o.s.CopyOset()
+}
+
+func (o *osetObservable) CopyArr() array.Interface {
+	o.l.RLock()
+	defer o.l.RUnlock()
+	return o.s.CopyArr()
+}
+
+func (o *osetObservable) CopySet() set.Interface {
+	o.l.RLock()
+	defer o.l.RUnlock()
+	return o.s.CopySet()
+}
+
+func (o *osetObservable) CopyCollection() collection.Interface {
+	return o.CopyOset()
+}
+
+func added(i int, item interface{}) observer.Event {
+	return observer.Event{Op: observer.Add, Item: item, Index: i, New: item}
+}
+
+func removed(i int, item interface{}) observer.Event {
+	return observer.Event{Op: observer.Remove, Item: item, Index: i, Old: item}
+}
+
+func replaced(i int, item, substitute interface{}) observer.Event {
+	return observer.Event{Op: observer.Replace, Item: item, Index: i, Old: item, New: substitute}
+}
+
+// batch groups the events of a Merge, Separate, Retain or Swap
+func batch(events []observer.Event) []observer.Event {
+	if len(events) == 0 {
+		return nil
+	}
+	return []observer.Event{{Op: observer.Batch, Index: -1, Batch: events}}
+}
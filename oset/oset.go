@@ -1,8 +1,11 @@
 package oset
 
 import (
+	"fmt"
+
 	"github.com/khezen/struct/array"
 	"github.com/khezen/struct/collection"
+	"github.com/khezen/struct/internal/format"
 	"github.com/khezen/struct/set"
 )
 
@@ -39,15 +42,24 @@ func (s *oset) Add(items ...interface{}) {
 	}
 }
 
-func (s *oset) Insert(i int, items ...interface{}) {
-	toInsert := make([]interface{}, 0, len(items))
+// fresh returns the items which are not present, without duplicates
+func (s *oset) fresh(items []interface{}) []interface{} {
+	fresh := make([]interface{}, 0, len(items))
+	seen := set.New()
 	for _, item := range items {
-		if !s.Has(item) {
-			toInsert = append(toInsert, item)
+		if !s.s.Has(item) && !seen.Has(item) {
+			seen.Add(item)
+			fresh = append(fresh, item)
 		}
 	}
-	s.a.Insert(i, toInsert...)
-	s.s.Add(toInsert...)
+	return fresh
+}
+
+// Insert inserts at i the items which are not present.
+func (s *oset) Insert(i int, items ...interface{}) {
+	fresh := s.fresh(items)
+	s.a.Insert(i, fresh...)
+	s.s.Add(fresh...)
 }
 
 func (s *oset) Remove(items ...interface{}) {
@@ -61,12 +73,20 @@ func (s *oset) RemoveAt(i int) interface{} {
 	return item
 }
 
+// Replace replaces item by substitute. A substitute which is already present
+// is dropped along with the item it replaces, as ReplaceIf does.
 func (s *oset) Replace(item, substitute interface{}) {
-	s.a.Replace(item, substitute)
-	s.s.Replace(item, substitute)
+	if i, err := s.a.IndexOf(item); err == nil {
+		s.ReplaceAt(i, substitute)
+	}
 }
 
+// ReplaceAt replaces the item at i by substitute and returns it. A substitute
+// which is already present is dropped along with the item it replaces.
 func (s *oset) ReplaceAt(i int, substitute interface{}) interface{} {
+	if s.s.Has(substitute) && s.a.Get(i) != substitute {
+		return s.RemoveAt(i)
+	}
 	item := s.a.ReplaceAt(i, substitute)
 	s.s.Replace(item, substitute)
 	return item
@@ -76,6 +96,74 @@ func (s *oset) IndexOf(item interface{}) (int, error) {
 	return s.a.IndexOf(item)
 }
 
+func (s *oset) Find(pred func(item interface{}) bool) (interface{}, error) {
+	return s.a.Find(pred)
+}
+
+func (s *oset) FindIndex(pred func(item interface{}) bool) (int, error) {
+	return s.a.FindIndex(pred)
+}
+
+func (s *oset) FindLast(pred func(item interface{}) bool) (interface{}, error) {
+	return s.a.FindLast(pred)
+}
+
+// LastIndexOf returns the index of item, which appears only once.
+func (s *oset) LastIndexOf(item interface{}) (int, error) {
+	return s.a.IndexOf(item)
+}
+
+func (s *oset) IndexOfFrom(item interface{}, start int) (int, error) {
+	return s.a.IndexOfFrom(item, start)
+}
+
+func (s *oset) Count(pred func(item interface{}) bool) int {
+	return s.a.Count(pred)
+}
+
+func (s *oset) RemoveIf(pred func(item interface{}) bool) int {
+	return s.a.RemoveIf(func(item interface{}) bool {
+		if pred(item) {
+			s.s.Remove(item)
+			return true
+		}
+		return false
+	})
+}
+
+// ReplaceIf replaces each item satisfying pred by substitute(item) and returns
+// how many items satisfied pred. A substitute which is already present, or
+// which replaced a previous item, is dropped along with the item it replaces.
+func (s *oset) ReplaceIf(pred func(item interface{}) bool, substitute func(item interface{}) interface{}) int {
+	items := s.a.Slice()
+	matched := make([]bool, len(items))
+	count := 0
+	for i, item := range items {
+		if pred(item) {
+			matched[i] = true
+			s.s.Remove(item)
+			count++
+		}
+	}
+	if count == 0 {
+		return 0
+	}
+	result := items[:0]
+	for i, item := range items {
+		if matched[i] {
+			item = substitute(item)
+			if s.s.Has(item) {
+				continue
+			}
+			s.s.Add(item)
+		}
+		result = append(result, item)
+	}
+	s.a.Clear()
+	s.a.Add(result...)
+	return count
+}
+
 func (s *oset) Swap(i, j int) {
 	s.a.Swap(i, j)
 }
@@ -101,7 +189,7 @@ func (s *oset) IsEmpty() bool {
 	return s.a.IsEmpty()
 }
 
-func (s *oset) IsEqual(t collection.Interface) bool {
+func (s *oset) IsEqual(t collection.ReadOnly) bool {
 	if conv, ok := t.(*osetSync); ok {
 		conv.l.RLock()
 		defer conv.l.RUnlock()
@@ -113,28 +201,24 @@ func (s *oset) IsEqual(t collection.Interface) bool {
 	return s.a.IsEqual(t)
 }
 
-func (s *oset) IsSubset(t Interface) bool {
-	if conv, ok := t.(*osetSync); ok {
-		conv.l.RLock()
-		defer conv.l.RUnlock()
-	}
-	if conv, ok := t.(*osetSortSync); ok {
-		conv.l.RLock()
-		defer conv.l.RUnlock()
-	}
-	return s.s.IsSubset(t.Set())
+// IsSubset tests whether t is a subset of s.
+func (s *oset) IsSubset(t ReadOnly) bool {
+	subset := true
+	t.Each(func(item interface{}) bool {
+		subset = s.s.Has(item)
+		return subset
+	})
+	return subset
 }
 
-func (s *oset) IsSuperset(t Interface) bool {
-	if conv, ok := t.(*osetSync); ok {
-		conv.l.RLock()
-		defer conv.l.RUnlock()
-	}
-	if conv, ok := t.(*osetSortSync); ok {
-		conv.l.RLock()
-		defer conv.l.RUnlock()
-	}
-	return s.s.IsSuperset(t.Set())
+// IsSuperset tests whether t is a superset of s.
+func (s *oset) IsSuperset(t ReadOnly) bool {
+	superset := true
+	s.s.Each(func(item interface{}) bool {
+		superset = t.Has(item)
+		return superset
+	})
+	return superset
 }
 
 func (s *oset) Merge(t collection.Interface) {
@@ -186,8 +270,89 @@ func (s *oset) Subset(i, j int) Interface {
 	return sub
 }
 
+// SliceRange returns an ordered set holding items from i included to j excluded.
+func (s *oset) SliceRange(i, j int) array.Interface {
+	return New(s.a.SliceRange(i, j).Slice()...)
+}
+
+// Splice removes deleteCount items from i, inserts at i the items which are
+// not present anymore and returns the removed items.
+func (s *oset) Splice(i, deleteCount int, items ...interface{}) []interface{} {
+	removed := s.a.Splice(i, deleteCount)
+	s.s.Remove(removed...)
+	fresh := s.fresh(items)
+	s.s.Add(fresh...)
+	s.a.Splice(i, 0, fresh...)
+	return removed
+}
+
+func (s *oset) RemoveRange(i, j int) {
+	s.Splice(i, j-i)
+}
+
+// InsertAll inserts at i the items of t which are not present.
+func (s *oset) InsertAll(i int, t collection.ReadOnly) {
+	s.Splice(i, 0, t.Slice()...)
+}
+
+func (s *oset) Reverse() {
+	s.a.Reverse()
+}
+
+func (s *oset) Rotate(k int) {
+	s.a.Rotate(k)
+}
+
+// Fill replaces the items from i included to j excluded by item, which
+// appears only once.
+func (s *oset) Fill(i, j int, item interface{}) {
+	items := []interface{}{item}
+	if i == j {
+		items = nil
+	}
+	s.Splice(i, j-i, items...)
+}
+
+// Repeat returns a copy of the ordered set, or an empty one if n <= 0.
+func (s *oset) Repeat(n int) array.Interface {
+	if n <= 0 {
+		return New()
+	}
+	return s.CopyOset()
+}
+
+// Concat returns an ordered set holding the items followed by those of each
+// t which are not present.
+func (s *oset) Concat(t ...collection.ReadOnly) array.Interface {
+	return s.concat(slices(t))
+}
+
+func (s *oset) concat(slices [][]interface{}) Interface {
+	result := s.CopyOset()
+	for _, items := range slices {
+		result.Add(items...)
+	}
+	return result
+}
+
+// slices returns the items of each collection
+func slices(t []collection.ReadOnly) [][]interface{} {
+	s := make([][]interface{}, 0, len(t))
+	for _, c := range t {
+		s = append(s, c.Slice())
+	}
+	return s
+}
+
 func (s *oset) String() string {
-	return s.a.String()
+	return format.String(s)
+}
+
+// Format implements fmt.Formatter: %v prints the items, %+v adds the type and
+// the length and %#v the Go syntax. A precision truncates the items: %.3v
+// prints the 3 first followed by "...".
+func (s *oset) Format(f fmt.State, verb rune) {
+	format.Seq(f, verb, "oset", "oset.New", s.Slice())
 }
 
 func (s *oset) Slice() []interface{} {
@@ -1,26 +1,102 @@
 package oset
 
 import (
-	"sort"
+	"fmt"
+
+	"github.com/khezen/struct/array"
+	"github.com/khezen/struct/internal/format"
+	"github.com/khezen/struct/set"
 )
 
 type osetSort struct {
 	oset
 	less func(slice []interface{}, i, j int) bool
+	auto bool
 }
 
 // NewSorted creates an oordered set that expose Sort method
 func NewSorted(less func(slice []interface{}, i, j int) bool, items ...interface{}) Sorted {
 	return &osetSort{
-		*(New(items...).(*oset)),
-		less,
+		oset: *newSorted(less, items),
+		less: less,
+	}
+}
+
+// newSorted creates an ordered set backed by a sorted array, so that it
+// benefits from its binary search and auto-sort mode.
+func newSorted(less func(slice []interface{}, i, j int) bool, items []interface{}) *oset {
+	s := &oset{
+		array.NewSorted(less),
+		set.New(),
 	}
+	s.Add(items...)
+	return s
+}
+
+// sorted returns the sorted array backing s
+func (s *oset) sorted() array.Sorted {
+	return s.a.(array.Sorted)
+}
+
+// insertSorted inserts the items which are not present at their place.
+func (s *oset) insertSorted(items []interface{}) {
+	fresh := s.fresh(items)
+	s.sorted().InsertSorted(fresh...)
+	s.s.Add(fresh...)
+}
+
+func (a *osetSort) String() string {
+	return format.String(a)
+}
+
+// Format prints the items. With %#v, it prints the syntax of an equal ordered
+// set since less cannot be printed.
+func (a *osetSort) Format(f fmt.State, verb rune) {
+	format.Seq(f, verb, "oset.Sorted", "oset.New", a.Slice())
 }
 
 func (a *osetSort) Less(i, j int) bool {
-	return a.less(a.Slice(), i, j)
+	return a.less(array.UnsafeSlice(a.a), i, j)
 }
 
 func (a *osetSort) Sort() {
-	sort.Sort(a)
+	a.sorted().Sort()
+}
+
+func (a *osetSort) SortStable() {
+	a.sorted().SortStable()
+}
+
+func (a *osetSort) SetLess(less func(slice []interface{}, i, j int) bool) {
+	a.sorted().SetLess(less)
+	a.less = less
+}
+
+func (a *osetSort) IsSorted() bool {
+	return a.sorted().IsSorted()
+}
+
+func (a *osetSort) SearchSorted(item interface{}) (int, bool) {
+	return a.sorted().SearchSorted(item)
+}
+
+func (a *osetSort) InsertSorted(items ...interface{}) {
+	a.insertSorted(items)
+}
+
+func (a *osetSort) BinaryIndexOf(item interface{}) (int, error) {
+	return a.sorted().BinaryIndexOf(item)
+}
+
+func (a *osetSort) LowerBound(item interface{}) int {
+	return a.sorted().LowerBound(item)
+}
+
+func (a *osetSort) UpperBound(item interface{}) int {
+	return a.sorted().UpperBound(item)
+}
+
+func (a *osetSort) SetAutoSort(auto bool) {
+	a.sorted().SetAutoSort(auto)
+	a.auto = auto
 }
@@ -1,29 +1,115 @@
 package oset
 
 import (
-	"sort"
+	"fmt"
+	"sync"
+
+	"github.com/khezen/struct/array"
+	"github.com/khezen/struct/internal/format"
 )
 
 // Sorted is the interface for sortable osets
 type osetSortSync struct {
 	*osetSync
 	less func(slice []interface{}, i, j int) bool
+	auto bool
 }
 
 // NewSortedSync creates an ordered  thread safe set that expose Sort method
 func NewSortedSync(less func(slice []interface{}, i, j int) bool, items ...interface{}) Sorted {
 	return &osetSortSync{
-		(NewSync(items...).(*osetSync)),
-		less,
+		osetSync: &osetSync{
+			*newSorted(less, items),
+			sync.RWMutex{},
+		},
+		less: less,
 	}
 }
 
+func (a *osetSortSync) String() string {
+	return format.String(a)
+}
+
+// Format prints the items. With %#v, it prints the syntax of an equal ordered
+// set since less cannot be printed.
+func (a *osetSortSync) Format(f fmt.State, verb rune) {
+	format.Seq(f, verb, "oset.SortedSync", "oset.NewSync", a.Slice())
+}
+
 func (a *osetSortSync) Less(i, j int) bool {
 	a.osetSync.l.RLock()
 	defer a.osetSync.l.RUnlock()
-	return a.less(a.Slice(), i, j)
+	return a.less(array.UnsafeSlice(a.a), i, j)
 }
 
 func (a *osetSortSync) Sort() {
-	sort.Sort(a)
+	a.l.Lock()
+	defer a.l.Unlock()
+	a.sorted().Sort()
+}
+
+func (a *osetSortSync) SortStable() {
+	a.l.Lock()
+	defer a.l.Unlock()
+	a.sorted().SortStable()
+}
+
+func (a *osetSortSync) SetLess(less func(slice []interface{}, i, j int) bool) {
+	a.l.Lock()
+	defer a.l.Unlock()
+	a.sorted().SetLess(less)
+	a.less = less
+}
+
+// IsSorted takes the write lock since the array records the result of the check.
+func (a *osetSortSync) IsSorted() bool {
+	a.l.Lock()
+	defer a.l.Unlock()
+	return a.sorted().IsSorted()
+}
+
+func (a *osetSortSync) SearchSorted(item interface{}) (int, bool) {
+	a.l.RLock()
+	defer a.l.RUnlock()
+	return a.sorted().SearchSorted(item)
+}
+
+func (a *osetSortSync) InsertSorted(items ...interface{}) {
+	a.l.Lock()
+	defer a.l.Unlock()
+	a.insertSorted(items)
+}
+
+func (a *osetSortSync) BinaryIndexOf(item interface{}) (int, error) {
+	a.l.RLock()
+	defer a.l.RUnlock()
+	return a.sorted().BinaryIndexOf(item)
+}
+
+func (a *osetSortSync) LowerBound(item interface{}) int {
+	a.l.RLock()
+	defer a.l.RUnlock()
+	return a.sorted().LowerBound(item)
+}
+
+func (a *osetSortSync) UpperBound(item interface{}) int {
+	a.l.RLock()
+	defer a.l.RUnlock()
+	return a.sorted().UpperBound(item)
+}
+
+func (a *osetSortSync) SetAutoSort(auto bool) {
+	a.l.Lock()
+	defer a.l.Unlock()
+	a.sorted().SetAutoSort(auto)
+	a.auto = auto
+}
+
+// Snapshot returns a non-threadsafe sorted copy of a consistent at the time of the call.
+func (a *osetSortSync) Snapshot() Interface {
+	a.osetSync.l.RLock()
+	defer a.osetSync.l.RUnlock()
+	s := NewSorted(a.less, a.osetSync.oset.Slice()...)
+	s.SetAutoSort(a.auto)
+	return s
 }
@@ -1,10 +1,12 @@
 package oset
 
 import (
+	"fmt"
 	"sync"
 
 	"github.com/khezen/struct/array"
 	"github.com/khezen/struct/collection"
+	"github.com/khezen/struct/internal/format"
 	"github.com/khezen/struct/set"
 )
 
@@ -126,19 +128,19 @@ func (s *osetSync) IsEmpty() bool {
 	return s.oset.IsEmpty()
 }
 
-func (s *osetSync) IsEqual(t collection.Interface) bool {
+func (s *osetSync) IsEqual(t collection.ReadOnly) bool {
 	s.l.RLock()
 	defer s.l.RUnlock()
 	return s.oset.IsEqual(t)
 }
 
-func (s *osetSync) IsSubset(t Interface) bool {
+func (s *osetSync) IsSubset(t ReadOnly) bool {
 	s.l.RLock()
 	defer s.l.RUnlock()
 	return s.oset.IsSubset(t)
 }
 
-func (s *osetSync) IsSuperset(t Interface) bool {
+func (s *osetSync) IsSuperset(t ReadOnly) bool {
 	s.l.RLock()
 	defer s.l.RUnlock()
 	return s.oset.IsSuperset(t)
@@ -178,7 +180,119 @@ func (s *osetSync) SubArray(i, j int) array.Interface {
 func (s *osetSync) Subset(i, j int) Interface {
 	s.l.RLock()
 	defer s.l.RUnlock()
-	os := s.oset.Subset(i, j)
+	return s.wrap(s.oset.Subset(i, j))
+}
+
+func (s *osetSync) SliceRange(i, j int) array.Interface {
+	s.l.RLock()
+	defer s.l.RUnlock()
+	return s.wrap(s.oset.SliceRange(i, j))
+}
+
+func (s *osetSync) Splice(i, deleteCount int, items ...interface{}) []interface{} {
+	s.l.Lock()
+	defer s.l.Unlock()
+	return s.oset.Splice(i, deleteCount, items...)
+}
+
+func (s *osetSync) RemoveRange(i, j int) {
+	s.l.Lock()
+	defer s.l.Unlock()
+	s.oset.RemoveRange(i, j)
+}
+
+// InsertAll reads t before locking s, so that t may be s itself.
+func (s *osetSync) InsertAll(i int, t collection.ReadOnly) {
+	items := t.Slice()
+	s.l.Lock()
+	defer s.l.Unlock()
+	s.oset.Splice(i, 0, items...)
+}
+
+func (s *osetSync) Reverse() {
+	s.l.Lock()
+	defer s.l.Unlock()
+	s.oset.Reverse()
+}
+
+func (s *osetSync) Rotate(k int) {
+	s.l.Lock()
+	defer s.l.Unlock()
+	s.oset.Rotate(k)
+}
+
+func (s *osetSync) Fill(i, j int, item interface{}) {
+	s.l.Lock()
+	defer s.l.Unlock()
+	s.oset.Fill(i, j, item)
+}
+
+func (s *osetSync) Repeat(n int) array.Interface {
+	s.l.RLock()
+	defer s.l.RUnlock()
+	return s.wrap(s.oset.Repeat(n))
+}
+
+// Concat reads each t before locking s, so that t may be s itself.
+func (s *osetSync) Concat(t ...collection.ReadOnly) array.Interface {
+	slices := slices(t)
+	s.l.RLock()
+	defer s.l.RUnlock()
+	return s.wrap(s.oset.concat(slices))
+}
+
+func (s *osetSync) Find(pred func(item interface{}) bool) (interface{}, error) {
+	s.l.RLock()
+	defer s.l.RUnlock()
+	return s.oset.Find(pred)
+}
+
+func (s *osetSync) FindIndex(pred func(item interface{}) bool) (int, error) {
+	s.l.RLock()
+	defer s.l.RUnlock()
+	return s.oset.FindIndex(pred)
+}
+
+func (s *osetSync) FindLast(pred func(item interface{}) bool) (interface{}, error) {
+	s.l.RLock()
+	defer s.l.RUnlock()
+	return s.oset.FindLast(pred)
+}
+
+func (s *osetSync) LastIndexOf(item interface{}) (int, error) {
+	s.l.RLock()
+	defer s.l.RUnlock()
+	return s.oset.LastIndexOf(item)
+}
+
+func (s *osetSync) IndexOfFrom(item interface{}, start int) (int, error) {
+	s.l.RLock()
+	defer s.l.RUnlock()
+	return s.oset.IndexOfFrom(item, start)
+}
+
+func (s *osetSync) Count(pred func(item interface{}) bool) int {
+	s.l.RLock()
+	defer s.l.RUnlock()
+	return s.oset.Count(pred)
+}
+
+// RemoveIf holds the lock while calling pred, which must not use s.
+func (s *osetSync) RemoveIf(pred func(item interface{}) bool) int {
+	s.l.Lock()
+	defer s.l.Unlock()
+	return s.oset.RemoveIf(pred)
+}
+
+// ReplaceIf holds the lock while calling pred and substitute, which must not use s.
+func (s *osetSync) ReplaceIf(pred func(item interface{}) bool, substitute func(item interface{}) interface{}) int {
+	s.l.Lock()
+	defer s.l.Unlock()
+	return s.oset.ReplaceIf(pred, substitute)
+}
+
+// wrap returns a thread safe ordered set holding the items of os
+func (s *osetSync) wrap(os array.Interface) Interface {
 	return &osetSync{
 		*os.(*oset),
 		sync.RWMutex{},
@@ -186,13 +300,15 @@ func (s *osetSync) Subset(i, j int) Interface {
 }
 
 func (s *osetSync) String() string {
-	s.l.RLock()
-	defer s.l.RUnlock()
-	return s.oset.String()
+	return format.String(s)
+}
+
+func (s *osetSync) Format(f fmt.State, verb rune) {
+	format.Seq(f, verb, "oset.Sync", "oset.NewSync", s.Slice())
 }
 
-// Slice returns a slice of all items. There is also StringSlice() and
-// IntSlice() methods for returning slices of type string or int.
+// Slice returns a slice of all items. The StringSlice and IntSlice functions
+// return slices of type string or int.
 func (s *osetSync) Slice() []interface{} {
 	s.l.RLock()
 	defer s.l.RUnlock()
@@ -219,3 +335,10 @@ func (s *osetSync) CopySet() set.Interface {
 func (s *osetSync) CopyCollection() collection.Interface {
 	return s.CopyArr()
 }
+
+// Snapshot returns a non-threadsafe copy of s consistent at the time of the call.
+func (s *osetSync) Snapshot() Interface {
+	s.l.RLock()
+	defer s.l.RUnlock()
+	return New(s.oset.Slice()...)
+}
@@ -1,10 +1,15 @@
 package oset
 
 import (
+	"fmt"
+	"math/rand"
+	"reflect"
 	"testing"
 
 	"github.com/khezen/struct/array"
 	"github.com/khezen/struct/collection"
+	"github.com/khezen/struct/compare"
+	"github.com/khezen/struct/observer"
 	"github.com/khezen/struct/set"
 )
 
@@ -68,6 +73,13 @@ func TestInsert(t *testing.T) {
 			t.Errorf("Expected %v. Got %v", c.expected.Slice(), c.oset.Slice())
 		}
 	}
+	for _, s := range []Interface{New(3), NewSync(3)} {
+		s.Insert(0, 1, 1, 3)
+		s.InsertAll(0, array.New(2, 2))
+		if !s.IsEqual(New(2, 1, 3)) || !s.Set().IsEqual(set.New(2, 1, 3)) {
+			t.Errorf("Expected %v. Got %v", New(2, 1, 3), s)
+		}
+	}
 }
 
 func TestRemove(t *testing.T) {
@@ -122,10 +134,13 @@ func TestReplace(t *testing.T) {
 		{New(1, 4, -8), New(1, 4, -8), 1000, 42},
 		{NewSync(1, 4, -8), NewSync(42, 4, -8), 1, 42},
 		{NewSync(1, 4, -8), NewSync(1, 4, -8), 1000, 42},
+		{New(5, 1, 3, 2, 4), New(5, 1, 2, 4), 3, 2},
+		{NewSync(5, 1, 3, 2, 4), NewSync(5, 1, 2, 4), 3, 2},
+		{New(1, 4, -8), New(1, 4, -8), 4, 4},
 	}
 	for _, c := range cases {
 		c.oset.Replace(c.item, c.substitute)
-		if !c.oset.IsEqual(c.expected) {
+		if !c.oset.IsEqual(c.expected) || !c.oset.Set().IsEqual(c.expected.Set()) {
 			t.Errorf("Expected %v. Got %v", c.expected.Slice(), c.oset.Slice())
 		}
 	}
@@ -139,14 +154,16 @@ func TestReplaceAt(t *testing.T) {
 	}{
 		{New(1, 4, -8), New(1, 42, -8), 1, 42},
 		{NewSync(1, 4, -8), NewSync(1, 42, -8), 1, 42},
+		{New(5, 1, 3, 2, 4), New(5, 1, 2, 4), 2, 2},
+		{NewSync(5, 1, 3, 2, 4), NewSync(5, 1, 2, 4), 2, 2},
+		{New(1, 4, -8), New(1, 4, -8), 1, 4},
 	}
 	for _, c := range cases {
 		c.oset.ReplaceAt(c.i, c.substitute)
-		if !c.oset.IsEqual(c.expected) {
+		if !c.oset.IsEqual(c.expected) || !c.oset.Set().IsEqual(c.expected.Set()) {
 			t.Errorf("Expected %v. Got %v", c.expected.Slice(), c.oset.Slice())
 		}
 	}
-
 }
 
 func TestIndexOf(t *testing.T) {
@@ -189,6 +206,126 @@ func TestSubArray(t *testing.T) {
 	}
 }
 
+func TestSplice(t *testing.T) {
+	s := NewSync(1, 2)
+	cases := []struct {
+		oset, expected Interface
+		f              func(s Interface) []interface{}
+		removed        []interface{}
+	}{
+		{New(1, 2, 3, 4), New(1, 5, 3, 4), func(s Interface) []interface{} { return s.Splice(1, 1, 5, 5, 4) }, []interface{}{2}},
+		{New(1, 2, 3, 4), New(1, 2, 3, 4), func(s Interface) []interface{} { return s.Splice(0, 1, 1) }, []interface{}{1}},
+		{NewSync(1, 2, 3), NewSync(1, 2, 3), func(s Interface) []interface{} { return s.Splice(0, 1, 3, 1) }, []interface{}{1}},
+		{New(1, 2, 3, 4), New(1, 4), func(s Interface) []interface{} { s.RemoveRange(1, 3); return nil }, nil},
+		{NewSync(1, 2, 3, 4), NewSync(1), func(s Interface) []interface{} { s.RemoveRange(1, 4); return nil }, nil},
+		{New(1, 2), New(1, 3, 2), func(s Interface) []interface{} { s.InsertAll(1, New(1, 3)); return nil }, nil},
+		{s, NewSync(1, 2), func(s Interface) []interface{} { s.InsertAll(2, s); return nil }, nil},
+		{New(1, 2, 3, 4), New(1, 3, 4), func(s Interface) []interface{} { s.Fill(1, 3, 3); return nil }, nil},
+		{NewSync(1, 2, 3, 4), NewSync(1, 0, 4), func(s Interface) []interface{} { s.Fill(1, 3, 0); return nil }, nil},
+		{New(1, 2, 3), New(1, 2, 3), func(s Interface) []interface{} { s.Fill(1, 1, 0); return nil }, nil},
+		{New(1, 2, 3), New(3, 2, 1), func(s Interface) []interface{} { s.Reverse(); return nil }, nil},
+		{NewSync(1, 2, 3), NewSync(2, 3, 1), func(s Interface) []interface{} { s.Rotate(-1); return nil }, nil},
+		{NewSync(1, 2, 3), NewSync(2, 3, 1), func(s Interface) []interface{} { s.Reverse(); s.Rotate(1); s.Reverse(); return nil }, nil},
+	}
+	for _, c := range cases {
+		removed := c.f(c.oset)
+		if !c.oset.IsEqual(c.expected) || !c.oset.Set().IsEqual(c.expected.Set()) {
+			t.Errorf("Expected %v. Got %v.", c.expected, c.oset)
+		}
+		if !New(removed...).IsEqual(New(c.removed...)) {
+			t.Errorf("Expected %v. Got %v.", c.removed, removed)
+		}
+	}
+}
+
+func TestFind(t *testing.T) {
+	even := func(item interface{}) bool {
+		return item.(int)%2 == 0
+	}
+	for _, s := range []Interface{New(1, 2, 3, 4, 5), NewSync(1, 2, 3, 4, 5)} {
+		cases := []struct {
+			f         func() (interface{}, error)
+			expected  interface{}
+			expectErr bool
+		}{
+			{func() (interface{}, error) { return s.Find(even) }, 2, false},
+			{func() (interface{}, error) { return s.FindIndex(even) }, 1, false},
+			{func() (interface{}, error) { return s.FindLast(even) }, 4, false},
+			{func() (interface{}, error) { return s.LastIndexOf(4) }, 3, false},
+			{func() (interface{}, error) { return s.LastIndexOf(9) }, -1, true},
+			{func() (interface{}, error) { return s.IndexOfFrom(2, 1) }, 1, false},
+			{func() (interface{}, error) { return s.IndexOfFrom(2, 2) }, -1, true},
+			{func() (interface{}, error) { return s.Count(even), nil }, 2, false},
+		}
+		for _, c := range cases {
+			v, err := c.f()
+			testErr(err, c.expectErr, t)
+			if v != c.expected {
+				t.Errorf("Expected %v. Got %v.", c.expected, v)
+			}
+		}
+	}
+}
+
+func TestRemoveReplaceIf(t *testing.T) {
+	even := func(item interface{}) bool {
+		return item.(int)%2 == 0
+	}
+	cases := []struct {
+		oset, expected Interface
+		f              func(s Interface) int
+		count          int
+	}{
+		{New(1, 2, 3, 4), New(1, 3), func(s Interface) int { return s.RemoveIf(even) }, 2},
+		{NewSync(2, 4, 5), NewSync(5), func(s Interface) int { return s.RemoveIf(even) }, 2},
+		{New(1, 2, 3, 4), New(1, 20, 3, 40), func(s Interface) int {
+			return s.ReplaceIf(even, func(item interface{}) interface{} { return item.(int) * 10 })
+		}, 2},
+		{New(1, 2, 3, 4), New(1, 3), func(s Interface) int {
+			return s.ReplaceIf(even, func(item interface{}) interface{} { return 3 })
+		}, 2},
+		{NewSync(1, 2, 3, 4), NewSync(1, 0, 3), func(s Interface) int {
+			return s.ReplaceIf(even, func(item interface{}) interface{} { return 0 })
+		}, 2},
+		{New(2, 4), New(4, 2), func(s Interface) int {
+			return s.ReplaceIf(even, func(item interface{}) interface{} { return 6 - item.(int) })
+		}, 2},
+		{New(1, 3), New(1, 3), func(s Interface) int {
+			return s.ReplaceIf(even, func(item interface{}) interface{} { return 0 })
+		}, 0},
+	}
+	for _, c := range cases {
+		if count := c.f(c.oset); count != c.count {
+			t.Errorf("Expected %v. Got %v.", c.count, count)
+		}
+		if !c.oset.IsEqual(c.expected) || !c.oset.Set().IsEqual(c.expected.Set()) {
+			t.Errorf("Expected %v. Got %v.", c.expected, c.oset)
+		}
+	}
+}
+
+func TestRepeatConcat(t *testing.T) {
+	s := NewSync(1, 2)
+	cases := []struct {
+		result, expected array.Interface
+	}{
+		{New(1, 2, 3).SliceRange(1, 3), New(2, 3)},
+		{s.SliceRange(0, 1), NewSync(1)},
+		{New(1, 2).Repeat(3), New(1, 2)},
+		{NewSync(1, 2).Repeat(0), NewSync()},
+		{New(1, 2).Concat(New(2, 3), array.New(4, 4)), New(1, 2, 3, 4)},
+		{s.Concat(s), NewSync(1, 2)},
+	}
+	for _, c := range cases {
+		if !c.result.IsEqual(c.expected) {
+			t.Errorf("Expected %v. Got %v.", c.expected, c.result)
+		}
+		if _, ok := c.result.(Interface); !ok {
+			t.Errorf("Expected %v. Got %v.", true, ok)
+		}
+	}
+}
+
 func TestSwap(t *testing.T) {
 	cases := []struct {
 		oset, expected Interface
@@ -489,6 +626,27 @@ func TestString(t *testing.T) {
 
 }
 
+func TestFormat(t *testing.T) {
+	cases := []struct {
+		format   string
+		oset     Interface
+		expected string
+	}{
+		{"%v", New(3, 1, 2), "[3 1 2]"},
+		{"%+v", NewSync(3, 1, 2), "oset.Sync(len=3) [3 1 2]"},
+		{"%#v", New("a", 1), `oset.New("a", 1)`},
+		{"%.2v", NewSorted(nil, 3, 1, 2), "[3 1 ...]"},
+		{"%+v", NewSortedSync(nil, 3), "oset.SortedSync(len=1) [3]"},
+		{"%v", NewReadOnly(New(1)), "[1]"},
+		{"%v", NewObservable(New(1)), "[1]"},
+	}
+	for _, c := range cases {
+		if got := fmt.Sprintf(c.format, c.oset); got != c.expected {
+			t.Errorf("Expected %v. Got %v.", c.expected, got)
+		}
+	}
+}
+
 func TestSlice(t *testing.T) {
 	cases := []struct {
 		slice []interface{}
@@ -713,3 +871,382 @@ func TestSort(t *testing.T) {
 		}
 	}
 }
+
+func TestSearchSorted(t *testing.T) {
+	less := func(slice []interface{}, i, j int) bool {
+		return slice[i].(int) < slice[j].(int)
+	}
+	cases := []Sorted{NewSorted(less, 3, 1, 5), NewSortedSync(less, 3, 1, 5)}
+	for _, s := range cases {
+		if s.IsSorted() {
+			t.Errorf("Expected %v. Got %v.", false, true)
+		}
+		s.InsertSorted(4, 0, 4, 3)
+		if !s.IsEqual(New(0, 1, 3, 4, 5)) {
+			t.Errorf("Expected %v. Got %v.", New(0, 1, 3, 4, 5), s)
+		}
+		if _, err := s.IndexOf("x"); s.Has("x") || err == nil {
+			t.Errorf("Expected %v. Got %v.", array.ErrNotFound, err)
+		}
+		if !s.IsSorted() {
+			t.Errorf("Expected %v. Got %v.", true, false)
+		}
+		searches := []struct {
+			item         int
+			i, low, high int
+			found        bool
+		}{
+			{3, 2, 2, 3, true},
+			{2, 2, 2, 2, false},
+			{6, 5, 5, 5, false},
+		}
+		for _, c := range searches {
+			i, found := s.SearchSorted(c.item)
+			if i != c.i || found != c.found {
+				t.Errorf("Expected %v, %v. Got %v, %v.", c.i, c.found, i, found)
+			}
+			if low := s.LowerBound(c.item); low != c.low {
+				t.Errorf("Expected %v. Got %v.", c.low, low)
+			}
+			if high := s.UpperBound(c.item); high != c.high {
+				t.Errorf("Expected %v. Got %v.", c.high, high)
+			}
+			if _, err := s.BinaryIndexOf(c.item); (err == nil) != c.found {
+				t.Errorf("Expected %v. Got %v.", c.found, err)
+			}
+		}
+		s.SetAutoSort(true)
+		s.Add(2, 9)
+		s.Insert(0, 7)
+		s.ReplaceAt(0, 8)
+		if !s.IsEqual(New(1, 2, 3, 4, 5, 7, 8, 9)) {
+			t.Errorf("Expected %v. Got %v.", New(1, 2, 3, 4, 5, 7, 8, 9), s)
+		}
+		i, err := s.IndexOf(7)
+		if err != nil || i != 5 {
+			t.Errorf("Expected %v. Got %v, %v.", 5, i, err)
+		}
+	}
+	snapshot := cases[1].(*osetSortSync).Snapshot().(Sorted)
+	snapshot.Add(6)
+	if !snapshot.IsEqual(New(1, 2, 3, 4, 5, 6, 7, 8, 9)) {
+		t.Errorf("Expected %v. Got %v.", New(1, 2, 3, 4, 5, 6, 7, 8, 9), snapshot)
+	}
+}
+
+func TestSortStable(t *testing.T) {
+	cases := []Sorted{NewSorted(nil, 3, 1, 2), NewSortedSync(nil, 3, 1, 2)}
+	for _, s := range cases {
+		array.SortFunc(s, compare.Natural)
+		if !s.IsEqual(New(1, 2, 3)) {
+			t.Errorf("Expected %v. Got %v.", New(1, 2, 3), s)
+		}
+		s.SetLess(compare.Less(compare.Reversed(compare.Natural)))
+		if s.IsSorted() {
+			t.Errorf("Expected %v. Got %v.", false, true)
+		}
+		s.SortStable()
+		if !s.IsEqual(New(3, 2, 1)) || !s.IsSorted() {
+			t.Errorf("Expected %v. Got %v.", New(3, 2, 1), s)
+		}
+		if !s.Less(0, 1) {
+			t.Errorf("Expected %v. Got %v.", true, false)
+		}
+	}
+}
+
+func TestReadOnly(t *testing.T) {
+	cases := []Interface{New(1, 2, 3), NewSync(1, 2, 3)}
+	for _, s := range cases {
+		r := NewReadOnly(s)
+		if NewReadOnly(r) != r {
+			t.Errorf("Expected %v. Got %v.", r, NewReadOnly(r))
+		}
+		mutators := []func(){
+			func() { r.Add(4) },
+			func() { r.Insert(0, 4) },
+			func() { r.Remove(1) },
+			func() { r.RemoveAt(0) },
+			func() { r.Replace(1, 4) },
+			func() { r.ReplaceAt(0, 4) },
+			func() { r.Swap(0, 1) },
+			func() { r.Clear() },
+			func() { r.Merge(New(4)) },
+			func() { r.Separate(New(1)) },
+			func() { r.Retain(New(1)) },
+			func() { r.Splice(0, 1) },
+			func() { r.RemoveRange(0, 1) },
+			func() { r.InsertAll(0, New(4)) },
+			func() { r.Reverse() },
+			func() { r.Rotate(1) },
+			func() { r.Fill(0, 1, 4) },
+			func() { r.RemoveIf(func(item interface{}) bool { return true }) },
+			func() {
+				r.ReplaceIf(func(item interface{}) bool { return true }, func(item interface{}) interface{} { return 0 })
+			},
+			func() { r.Arr().Add(4) },
+			func() { r.Set().Add(4) },
+		}
+		for _, mutate := range mutators {
+			func() {
+				defer func() {
+					if r := recover(); r != collection.ErrReadOnly {
+						t.Errorf("Expected %v. Got %v.", collection.ErrReadOnly, r)
+					}
+				}()
+				mutate()
+			}()
+		}
+		if !r.IsEqual(New(1, 2, 3)) || r.Len() != 3 || r.IsEmpty() || !r.Has(1, 2) || r.Get(1) != 2 {
+			t.Errorf("Expected %v. Got %v.", s, r)
+		}
+		if !r.IsSubset(New(1)) || r.IsSuperset(New(1)) || !r.IsSuperset(NewSync(1, 2, 3, 4)) {
+			t.Errorf("Expected %v. Got %v.", true, false)
+		}
+		if i, err := r.IndexOf(3); err != nil || i != 2 || r.String() != "[1 2 3]" {
+			t.Errorf("Expected %v. Got %v.", 2, i)
+		}
+		s.Add(4)
+		if !r.Has(4) || len(r.Slice()) != 4 || !r.Subset(0, 1).IsEqual(New(1, 2)) || r.SubArray(0, 1).Len() != 2 {
+			t.Errorf("Expected %v. Got %v.", s, r)
+		}
+		three := func(item interface{}) bool { return item == 3 }
+		if v, _ := r.Find(three); v != 3 || r.Count(three) != 1 {
+			t.Errorf("Expected %v. Got %v.", 3, v)
+		}
+		i, _ := r.FindIndex(three)
+		j, _ := r.LastIndexOf(3)
+		k, _ := r.IndexOfFrom(3, 1)
+		if v, _ := r.FindLast(three); v != 3 || i != 2 || j != 2 || k != 2 {
+			t.Errorf("Expected %v. Got %v.", 3, v)
+		}
+		if !r.SliceRange(1, 2).IsEqual(New(2)) || !r.Repeat(2).IsEqual(r) || !r.Concat(New(5)).IsEqual(New(1, 2, 3, 4, 5)) {
+			t.Errorf("Expected %v. Got %v.", s, r)
+		}
+		count := 0
+		r.Each(func(item interface{}) bool {
+			count++
+			return true
+		})
+		r.CopyOset().Add(5)
+		r.CopyArr().Add(5)
+		r.CopySet().Add(5)
+		r.CopyCollection().Add(5)
+		if count != 4 || r.Has(5) {
+			t.Errorf("Expected %v. Got %v.", 4, count)
+		}
+	}
+}
+
+func TestSnapshot(t *testing.T) {
+	less := func(slice []interface{}, i, j int) bool {
+		return slice[i].(int) < slice[j].(int)
+	}
+	cases := []struct {
+		s      Interface
+		sorted bool
+	}{
+		{NewSync(3, 1, 2), false},
+		{NewSortedSync(less, 3, 1, 2), true},
+	}
+	for _, c := range cases {
+		snapshot := c.s.(Snapshotter).Snapshot()
+		c.s.Add(4)
+		if !snapshot.IsEqual(New(3, 1, 2)) {
+			t.Errorf("Expected %v. Got %v.", New(3, 1, 2), snapshot)
+		}
+		if sorted, ok := snapshot.(Sorted); ok != c.sorted {
+			t.Errorf("Expected %v. Got %v.", c.sorted, ok)
+		} else if ok {
+			sorted.Sort()
+			if !snapshot.IsEqual(New(1, 2, 3)) {
+				t.Errorf("Expected %v. Got %v.", New(1, 2, 3), snapshot)
+			}
+		}
+	}
+}
+
+// replay applies events on s to reproduce the modifications of an observable ordered set
+func replay(s Interface, events ...observer.Event) {
+	for _, e := range events {
+		switch e.Op {
+		case observer.Add:
+			if e.Index == s.Len() {
+				s.Add(e.New)
+			} else {
+				s.Insert(e.Index, e.New)
+			}
+		case observer.Remove:
+			s.RemoveAt(e.Index)
+		case observer.Replace:
+			s.ReplaceAt(e.Index, e.New)
+		case observer.Clear:
+			s.Clear()
+		case observer.Batch:
+			replay(s, e.Batch...)
+		}
+	}
+}
+
+func TestObservable(t *testing.T) {
+	s := NewObservable(New(1, 2, 3))
+	mirror := New(1, 2, 3)
+	added := 0
+	unsubscribe := s.Subscribe(func(e observer.Event) {
+		if e.Op == observer.Add {
+			added++
+		}
+		replay(mirror, e)
+	})
+	s.Add(4, 4, 1)
+	s.Insert(0, 0, 2, 5, 5)
+	s.Remove(4, 9)
+	s.RemoveAt(1)
+	s.Replace(2, 20)
+	s.ReplaceAt(0, 10)
+	s.Replace(3, 10)
+	s.Add(3)
+	s.Swap(0, 1)
+	s.Merge(New(6, 20))
+	s.Separate(New(3))
+	s.Retain(New(20, 10, 1, 6))
+	s.Merge(s)
+	if added != 4 {
+		t.Errorf("Expected %v. Got %v.", 4, added)
+	}
+	if !mirror.IsEqual(s) || !s.IsEqual(New(1, 10, 20, 6)) {
+		t.Errorf("Expected %v. Got %v.", s, mirror)
+	}
+	s.Splice(1, 1, 30, 1, 31)
+	s.InsertAll(0, New(40, 6))
+	s.RemoveRange(0, 1)
+	s.Reverse()
+	s.Rotate(2)
+	s.Fill(1, 3, 6)
+	s.RemoveIf(func(item interface{}) bool { return item == 20 })
+	s.ReplaceIf(func(item interface{}) bool { return item != 31 }, func(item interface{}) interface{} { return item.(int) + 1 })
+	s.ReplaceIf(func(item interface{}) bool { return item == 31 }, func(item interface{}) interface{} { return 7 })
+	if !mirror.IsEqual(s) || !mirror.Set().IsEqual(s.Set()) || !s.IsEqual(New(7)) {
+		t.Errorf("Expected %v. Got %v.", s, mirror)
+	}
+	s.Clear()
+	s.Add(1, 10, 20, 6)
+	if !s.IsSubset(New(1, 6)) || !s.IsSuperset(s) || !s.Has(20) || s.Get(1) != 10 {
+		t.Errorf("Expected %v. Got %v.", true, false)
+	}
+	if i, err := s.IndexOf(6); err != nil || i != 3 {
+		t.Errorf("Expected %v. Got %v.", 3, i)
+	}
+	if s.SliceRange(0, 1).Len() != 1 || s.Repeat(2).Len() != 4 || s.Concat(s, New(7)).Len() != 5 {
+		t.Errorf("Expected %v. Got %v.", 4, s.Len())
+	}
+	small := func(item interface{}) bool { return item.(int) < 10 }
+	if v, _ := s.Find(small); v != 1 || s.Count(small) != 2 {
+		t.Errorf("Expected %v. Got %v.", 1, v)
+	}
+	i, _ := s.FindIndex(small)
+	j, _ := s.LastIndexOf(6)
+	k, _ := s.IndexOfFrom(6, 1)
+	if v, _ := s.FindLast(small); v != 6 || i != 0 || j != 3 || k != 3 {
+		t.Errorf("Expected %v. Got %v.", 6, v)
+	}
+	if s.SubArray(0, 1).Len() != 2 || s.Subset(1, 3).Len() != 3 || s.CopyCollection().Len() != 4 {
+		t.Errorf("Expected %v. Got %v.", 4, s.Len())
+	}
+	if s.CopySet().Len() != 4 || s.String() != "[1 10 20 6]" || len(s.Slice()) != 4 {
+		t.Errorf("Expected %v. Got %v.", "[1 10 20 6]", s)
+	}
+	visited := 0
+	s.Each(func(item interface{}) bool {
+		visited++
+		return true
+	})
+	if visited != 4 {
+		t.Errorf("Expected %v. Got %v.", 4, visited)
+	}
+	for _, mutate := range []func(){
+		func() { s.Arr().Add(7) },
+		func() { s.Set().Add(7) },
+	} {
+		func() {
+			defer func() {
+				if r := recover(); r != collection.ErrReadOnly {
+					t.Errorf("Expected %v. Got %v.", collection.ErrReadOnly, r)
+				}
+			}()
+			mutate()
+		}()
+	}
+	s.Clear()
+	unsubscribe()
+	s.Add(1)
+	if !mirror.IsEmpty() || s.IsEmpty() || s.Len() != 1 {
+		t.Errorf("Expected %v. Got %v.", "[]", mirror)
+	}
+}
+
+func TestObservableChan(t *testing.T) {
+	s := NewObservable(NewSync())
+	events, unsubscribe := s.SubscribeChan()
+	s.Add(1, 2)
+	s.Add(2)
+	s.Remove(1)
+	expected := []observer.Event{
+		{Op: observer.Add, Item: 1, Index: 0, New: 1},
+		{Op: observer.Add, Item: 2, Index: 1, New: 2},
+		{Op: observer.Remove, Item: 1, Index: 0, Old: 1},
+	}
+	for _, exp := range expected {
+		e := <-events
+		if e.Op != exp.Op || e.Item != exp.Item || e.Index != exp.Index || e.Old != exp.Old || e.New != exp.New {
+			t.Errorf("Expected %v. Got %v.", exp, e)
+		}
+	}
+	unsubscribe()
+	if _, open := <-events; open {
+		t.Errorf("Expected %v. Got %v.", false, open)
+	}
+}
+
+func TestDiff(t *testing.T) {
+	r := rand.New(rand.NewSource(42))
+	randomOset := func() Interface {
+		s := New()
+		for _, i := range r.Perm(20)[:r.Intn(20)] {
+			s.Add(i)
+		}
+		return s
+	}
+	for i := 0; i < 1000; i++ {
+		a, b := randomOset(), randomOset()
+		patch := array.Diff(a, b)
+		counts := make(map[array.EditOp]int)
+		for _, e := range patch {
+			counts[e.Op]++
+		}
+		removed := collection.Difference(a.CopySet(), b.CopySet()).Len()
+		added := collection.Difference(b.CopySet(), a.CopySet()).Len()
+		if counts[array.OpDelete] != removed || counts[array.OpInsert] != added {
+			t.Errorf("Expected %v %v. Got %v %v.", removed, added, counts[array.OpDelete], counts[array.OpInsert])
+		}
+		array.Apply(a, patch)
+		if !a.IsEqual(b) || !a.CopySet().IsEqual(b.CopySet()) {
+			t.Errorf("Expected %v. Got %v.", b, a)
+		}
+	}
+}
+
+func TestFromSlice(t *testing.T) {
+	for _, from := range []func(interface{}) (Interface, error){FromSlice, FromSliceSync} {
+		c, err := from([]string{"b", "a", "b"})
+		testErr(err, false, t)
+		if !c.IsEqual(New("b", "a")) {
+			t.Errorf("Expected %v. Got %v.", New("b", "a"), c)
+		}
+		var strs []string
+		testErr(ToSlice(c, &strs), false, t)
+		if !reflect.DeepEqual(strs, []string{"b", "a"}) {
+			t.Errorf("Expected %v. Got %v.", []string{"b", "a"}, strs)
+		}
+	}
+}
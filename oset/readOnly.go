@@ -0,0 +1,224 @@
+package oset
+
+import (
+	"fmt"
+
+	"github.com/khezen/struct/array"
+	"github.com/khezen/struct/collection"
+	"github.com/khezen/struct/internal/format"
+	"github.com/khezen/struct/set"
+)
+
+// osetReadOnly forwards reads to the wrapped oset and panics on mutation
+type osetReadOnly struct {
+	s Interface
+}
+
+// NewReadOnly returns a view of s which panics with collection.ErrReadOnly on mutation.
+// The view reflects later modifications of s.
+func NewReadOnly(s Interface) Interface {
+	if _, ok := s.(*osetReadOnly); ok {
+		return s
+	}
+	return &osetReadOnly{s}
+}
+
+func (r *osetReadOnly) Add(items ...interface{}) {
+	panic(collection.ErrReadOnly)
+}
+
+func (r *osetReadOnly) Insert(i int, items ...interface{}) {
+	panic(collection.ErrReadOnly)
+}
+
+func (r *osetReadOnly) Remove(items ...interface{}) {
+	panic(collection.ErrReadOnly)
+}
+
+func (r *osetReadOnly) RemoveAt(i int) interface{} {
+	panic(collection.ErrReadOnly)
+}
+
+func (r *osetReadOnly) Replace(toBeReplaced, substitute interface{}) {
+	panic(collection.ErrReadOnly)
+}
+
+func (r *osetReadOnly) ReplaceAt(i int, substitute interface{}) interface{} {
+	panic(collection.ErrReadOnly)
+}
+
+func (r *osetReadOnly) Swap(i, j int) {
+	panic(collection.ErrReadOnly)
+}
+
+func (r *osetReadOnly) Clear() {
+	panic(collection.ErrReadOnly)
+}
+
+func (r *osetReadOnly) Merge(t collection.Interface) {
+	panic(collection.ErrReadOnly)
+}
+
+func (r *osetReadOnly) Separate(t collection.Interface) {
+	panic(collection.ErrReadOnly)
+}
+
+func (r *osetReadOnly) Retain(t collection.Interface) {
+	panic(collection.ErrReadOnly)
+}
+
+func (r *osetReadOnly) Splice(i, deleteCount int, items ...interface{}) []interface{} {
+	panic(collection.ErrReadOnly)
+}
+
+func (r *osetReadOnly) RemoveRange(i, j int) {
+	panic(collection.ErrReadOnly)
+}
+
+func (r *osetReadOnly) InsertAll(i int, t collection.ReadOnly) {
+	panic(collection.ErrReadOnly)
+}
+
+func (r *osetReadOnly) Reverse() {
+	panic(collection.ErrReadOnly)
+}
+
+func (r *osetReadOnly) Rotate(k int) {
+	panic(collection.ErrReadOnly)
+}
+
+func (r *osetReadOnly) Fill(i, j int, item interface{}) {
+	panic(collection.ErrReadOnly)
+}
+
+func (r *osetReadOnly) RemoveIf(pred func(item interface{}) bool) int {
+	panic(collection.ErrReadOnly)
+}
+
+func (r *osetReadOnly) ReplaceIf(pred func(item interface{}) bool, substitute func(item interface{}) interface{}) int {
+	panic(collection.ErrReadOnly)
+}
+
+func (r *osetReadOnly) Get(i int) interface{} {
+	return r.s.Get(i)
+}
+
+func (r *osetReadOnly) IndexOf(item interface{}) (int, error) {
+	return r.s.IndexOf(item)
+}
+
+func (r *osetReadOnly) Find(pred func(item interface{}) bool) (interface{}, error) {
+	return r.s.Find(pred)
+}
+
+func (r *osetReadOnly) FindIndex(pred func(item interface{}) bool) (int, error) {
+	return r.s.FindIndex(pred)
+}
+
+func (r *osetReadOnly) FindLast(pred func(item interface{}) bool) (interface{}, error) {
+	return r.s.FindLast(pred)
+}
+
+func (r *osetReadOnly) LastIndexOf(item interface{}) (int, error) {
+	return r.s.LastIndexOf(item)
+}
+
+func (r *osetReadOnly) IndexOfFrom(item interface{}, start int) (int, error) {
+	return r.s.IndexOfFrom(item, start)
+}
+
+func (r *osetReadOnly) Count(pred func(item interface{}) bool) int {
+	return r.s.Count(pred)
+}
+
+func (r *osetReadOnly) Has(items ...interface{}) bool {
+	return r.s.Has(items...)
+}
+
+func (r *osetReadOnly) Each(f func(item interface{}) bool) {
+	r.s.Each(f)
+}
+
+func (r *osetReadOnly) Len() int {
+	return r.s.Len()
+}
+
+func (r *osetReadOnly) IsEmpty() bool {
+	return r.s.IsEmpty()
+}
+
+func (r *osetReadOnly) IsEqual(t collection.ReadOnly) bool {
+	return r.s.IsEqual(t)
+}
+
+func (r *osetReadOnly) IsSubset(t ReadOnly) bool {
+	return r.s.IsSubset(t)
+}
+
+func (r *osetReadOnly) IsSuperset(t ReadOnly) bool {
+	return r.s.IsSuperset(t)
+}
+
+func (r *osetReadOnly) String() string {
+	return r.s.String()
+}
+
+func (r *osetReadOnly) Format(f fmt.State, verb rune) {
+	format.Delegate(f, verb, r.s)
+}
+
+func (r *osetReadOnly) Slice() []interface{} {
+	return r.s.Slice()
+}
+
+// SubArray returns a mutable array holding items from i to j.
+func (r *osetReadOnly) SubArray(i, j int) array.Interface {
+	return r.s.SubArray(i, j)
+}
+
+// Subset returns a mutable oset holding items from i to j.
+func (r *osetReadOnly) Subset(i, j int) Interface {
+	return r.s.Subset(i, j)
+}
+
+// SliceRange returns a mutable oset holding items from i included to j excluded.
+func (r *osetReadOnly) SliceRange(i, j int) array.Interface {
+	return r.s.SliceRange(i, j)
+}
+
+// Repeat returns a mutable copy of the oset, or an empty one if n <= 0.
+func (r *osetReadOnly) Repeat(n int) array.Interface {
+	return r.s.Repeat(n)
+}
+
+// Concat returns a mutable oset holding the items followed by those of each t.
+func (r *osetReadOnly) Concat(t ...collection.ReadOnly) array.Interface {
+	return r.s.Concat(t...)
+}
+
+// Arr returns a read only view of the underlying array.
+func (r *osetReadOnly) Arr() array.Interface {
+	return array.NewReadOnly(r.s.Arr())
+}
+
+// Set returns a read only view of the underlying set.
+func (r *osetReadOnly) Set() set.Interface {
+	return set.NewReadOnly(r.s.Set())
+}
+
+// CopyOset returns a mutable copy of the oset.
+func (r *osetReadOnly) CopyOset() Interface {
+	return r.s.CopyOset()
+}
+
+func (r *osetReadOnly) CopyArr() array.Interface {
+	return r.s.CopyArr()
+}
+
+func (r *osetReadOnly) CopySet() set.Interface {
+	return r.s.CopySet()
+}
+
+func (r *osetReadOnly) CopyCollection() collection.Interface {
+	return r.s.CopyCollection()
+}
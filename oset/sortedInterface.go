@@ -1,8 +1,30 @@
 package oset
 
-// Sorted is the interface for sortable ordered sets
+// Sorted is the interface for sortable ordered sets. Once the items are known
+// to be sorted, IndexOf uses a binary search.
 type Sorted interface {
 	Interface
 	Sort()
 	Less(i, j int) bool
+	// SortStable sorts the set, keeping equivalent items in their order
+	SortStable()
+	// SetLess changes the comparison used to sort the set
+	SetLess(less func(slice []interface{}, i, j int) bool)
+	// IsSorted reports whether the items are sorted
+	IsSorted() bool
+	// SearchSorted returns the index of item and true if it is found, or the
+	// index where it would be inserted and false otherwise
+	SearchSorted(item interface{}) (int, bool)
+	// InsertSorted inserts the items which are not present at their place,
+	// sorting the set first if needed
+	InsertSorted(items ...interface{})
+	// BinaryIndexOf returns the index of item in the sorted set
+	BinaryIndexOf(item interface{}) (int, error)
+	// LowerBound returns the index of the first item not sorting before item
+	LowerBound(item interface{}) int
+	// UpperBound returns the index of the first item sorting after item
+	UpperBound(item interface{}) int
+	// SetAutoSort sorts the set and makes Add, Insert, ReplaceAt and the
+	// other modifications keep it sorted, ignoring the given indexes
+	SetAutoSort(auto bool)
 }
@@ -0,0 +1,100 @@
+package persist
+
+import (
+	"sync"
+	"time"
+
+	"github.com/khezen/struct/internal/expiry"
+)
+
+// durable holds the log shared by persisted collections and implements Durable
+type durable struct {
+	l        sync.RWMutex
+	w        *wal
+	err      error
+	closed   bool
+	janitors []*expiry.Janitor
+	// dump emits the records rebuilding the collection, l being held
+	dump func(emit func(record) error) error
+}
+
+func (d *durable) start(opts Options) {
+	if opts.Sync == SyncInterval {
+		interval := opts.SyncInterval
+		if interval <= 0 {
+			interval = time.Second
+		}
+		d.janitors = append(d.janitors, expiry.StartJanitor(interval, func() {
+			d.Sync()
+		}))
+	}
+	if opts.CompactInterval > 0 {
+		d.janitors = append(d.janitors, expiry.StartJanitor(opts.CompactInterval, func() {
+			d.Compact()
+		}))
+	}
+}
+
+// log appends r then calls apply, l being held. The mutation is dropped when
+// r cannot be logged.
+func (d *durable) log(r record, apply func()) {
+	if d.closed {
+		d.err = ErrClosed
+		return
+	}
+	if err := d.w.append(r); err != nil {
+		d.err = err
+		return
+	}
+	apply()
+	if d.w.opts.CompactAfter > 0 && d.w.records >= d.w.opts.CompactAfter {
+		if err := d.w.compact(d.dump); err != nil {
+			d.err = err
+		}
+	}
+}
+
+func (d *durable) Compact() error {
+	d.l.Lock()
+	defer d.l.Unlock()
+	if d.closed {
+		return ErrClosed
+	}
+	err := d.w.compact(d.dump)
+	if err != nil {
+		d.err = err
+	}
+	return err
+}
+
+func (d *durable) Sync() error {
+	d.l.Lock()
+	defer d.l.Unlock()
+	if d.closed {
+		return ErrClosed
+	}
+	err := d.w.sync()
+	if err != nil {
+		d.err = err
+	}
+	return err
+}
+
+func (d *durable) Close() error {
+	for _, j := range d.janitors {
+		j.Stop()
+	}
+	d.l.Lock()
+	defer d.l.Unlock()
+	if d.closed {
+		return ErrClosed
+	}
+	d.closed = true
+	return d.w.close()
+}
+
+func (d *durable) Err() error {
+	d.l.RLock()
+	defer d.l.RUnlock()
+	return d.err
+}
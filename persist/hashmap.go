@@ -0,0 +1,172 @@
+package persist
+
+import (
+	"fmt"
+
+	"github.com/khezen/struct/hashmap"
+	"github.com/khezen/struct/internal/format"
+)
+
+// Hashmap is a hashmap whose mutations are logged to disk. Mutations which
+// cannot be logged are not applied, and Err reports why.
+type Hashmap interface {
+	hashmap.Interface
+	Durable
+}
+
+type persistedHashmap struct {
+	durable
+	h hashmap.Interface
+}
+
+// OpenHashmap recovers the hashmap persisted in dir into h, which is cleared
+// first, and logs its later mutations. h must not be modified but through the
+// returned hashmap.
+func OpenHashmap(dir string, h hashmap.Interface, opts Options) (Hashmap, error) {
+	h.Clear()
+	w, err := openWAL(dir, opts, func(r record) {
+		switch r.Op {
+		case opPut:
+			for i := 0; i+1 < len(r.Items); i += 2 {
+				h.Put(r.Items[i], r.Items[i+1])
+			}
+		case opRemove:
+			h.Remove(r.Items...)
+		case opClear:
+			h.Clear()
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+	p := &persistedHashmap{h: h}
+	p.w = w
+	p.dump = func(emit func(record) error) error {
+		pairs := make([]interface{}, 0, 2*h.Len())
+		h.Each(func(k, v interface{}) bool {
+			pairs = append(pairs, k, v)
+			return true
+		})
+		return chunks(opPut, pairs, emit)
+	}
+	p.start(opts)
+	return p, nil
+}
+
+func (p *persistedHashmap) Put(k, v interface{}) {
+	p.l.Lock()
+	defer p.l.Unlock()
+	p.log(record{opPut, []interface{}{k, v}}, func() {
+		p.h.Put(k, v)
+	})
+}
+
+func (p *persistedHashmap) Remove(keys ...interface{}) {
+	p.l.Lock()
+	defer p.l.Unlock()
+	var present []interface{}
+	for _, k := range keys {
+		if p.h.Has(k) {
+			present = append(present, k)
+		}
+	}
+	if len(present) > 0 {
+		p.log(record{opRemove, present}, func() {
+			p.h.Remove(present...)
+		})
+	}
+}
+
+func (p *persistedHashmap) Clear() {
+	p.l.Lock()
+	defer p.l.Unlock()
+	p.log(record{Op: opClear}, p.h.Clear)
+}
+
+func (p *persistedHashmap) Get(k interface{}) (interface{}, error) {
+	p.l.RLock()
+	defer p.l.RUnlock()
+	return p.h.Get(k)
+}
+
+func (p *persistedHashmap) Has(keys ...interface{}) bool {
+	p.l.RLock()
+	defer p.l.RUnlock()
+	return p.h.Has(keys...)
+}
+
+func (p *persistedHashmap) HasValue(values ...interface{}) bool {
+	p.l.RLock()
+	defer p.l.RUnlock()
+	return p.h.HasValue(values...)
+}
+
+func (p *persistedHashmap) KeyOf(value interface{}) (interface{}, error) {
+	p.l.RLock()
+	defer p.l.RUnlock()
+	return p.h.KeyOf(value)
+}
+
+func (p *persistedHashmap) Each(f func(k, v interface{}) bool) {
+	p.l.RLock()
+	defer p.l.RUnlock()
+	p.h.Each(f)
+}
+
+func (p *persistedHashmap) Len() int {
+	p.l.RLock()
+	defer p.l.RUnlock()
+	return p.h.Len()
+}
+
+func (p *persistedHashmap) IsEmpty() bool {
+	p.l.RLock()
+	defer p.l.RUnlock()
+	return p.h.IsEmpty()
+}
+
+func (p *persistedHashmap) IsEqual(t hashmap.ReadOnly) bool {
+	p.l.RLock()
+	defer p.l.RUnlock()
+	if t == hashmap.ReadOnly(p) {
+		return true
+	}
+	return p.h.IsEqual(t)
+}
+
+func (p *persistedHashmap) String() string {
+	p.l.RLock()
+	defer p.l.RUnlock()
+	return p.h.String()
+}
+
+func (p *persistedHashmap) Format(f fmt.State, verb rune) {
+	p.l.RLock()
+	defer p.l.RUnlock()
+	format.Delegate(f, verb, p.h)
+}
+
+func (p *persistedHashmap) Keys() []interface{} {
+	p.l.RLock()
+	defer p.l.RUnlock()
+	return p.h.Keys()
+}
+
+func (p *persistedHashmap) Values() []interface{} {
+	p.l.RLock()
+	defer p.l.RUnlock()
+	return p.h.Values()
+}
+
+func (p *persistedHashmap) Map() map[interface{}]interface{} {
+	p.l.RLock()
+	defer p.l.RUnlock()
+	return p.h.Map()
+}
+
+// Copy returns an in-memory copy of the hashmap.
+func (p *persistedHashmap) Copy() hashmap.Interface {
+	p.l.RLock()
+	defer p.l.RUnlock()
+	return p.h.Copy()
+}
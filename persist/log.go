@@ -0,0 +1,372 @@
+package persist
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"encoding/gob"
+	"hash/crc32"
+	"io"
+	"os"
+	"path/filepath"
+)
+
+const (
+	walName      = "wal"
+	snapshotName = "snapshot"
+	tmpSuffix    = ".tmp"
+	headerSize   = 12 // magic and generation
+	frameSize    = 8  // payload length and checksum
+	chunkSize    = 1024
+)
+
+var (
+	walMagic      = [4]byte{'W', 'A', 'L', '1'}
+	snapshotMagic = [4]byte{'S', 'N', 'P', '1'}
+	crcTable      = crc32.MakeTable(crc32.Castagnoli)
+)
+
+type op uint8
+
+const (
+	opAdd op = iota + 1
+	opRemove
+	opClear
+	opReplace
+	opPut
+)
+
+// record is one logged mutation
+type record struct {
+	Op    op
+	Items []interface{}
+}
+
+// wal is the append-only log of a collection. Its generation is incremented by
+// each compaction so that a log already merged into the snapshot is discarded.
+type wal struct {
+	dir     string
+	opts    Options
+	f       *os.File
+	gen     uint64
+	size    int64 // offset following the last complete record
+	records int   // records appended since the last compaction
+	dirty   bool  // records appended since the last flush
+	broken  error // set when the log cannot be appended anymore
+}
+
+// openWAL replays the snapshot and the log of dir, truncating a torn record at the end of the log.
+func openWAL(dir string, opts Options, apply func(record)) (*wal, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, err
+	}
+	// leftover of an interrupted compaction
+	os.Remove(filepath.Join(dir, snapshotName+tmpSuffix))
+	os.Remove(filepath.Join(dir, walName+tmpSuffix))
+	w := &wal{dir: dir, opts: opts}
+	if f, err := os.Open(filepath.Join(dir, snapshotName)); err == nil {
+		w.gen, err = readSnapshot(f, apply)
+		f.Close()
+		if err != nil {
+			return nil, err
+		}
+	} else if !os.IsNotExist(err) {
+		return nil, err
+	}
+	f, err := os.OpenFile(filepath.Join(dir, walName), os.O_RDWR|os.O_CREATE, 0644)
+	if err != nil {
+		return nil, err
+	}
+	w.f = f
+	if err = w.replay(apply); err != nil {
+		f.Close()
+		return nil, err
+	}
+	return w, nil
+}
+
+func (w *wal) replay(apply func(record)) error {
+	info, err := w.f.Stat()
+	if err != nil {
+		return err
+	}
+	r := bufio.NewReader(io.NewSectionReader(w.f, 0, info.Size()))
+	magic, gen, err := readHeader(r)
+	switch {
+	case err == io.ErrUnexpectedEOF || err == io.EOF || (err == nil && magic == walMagic && gen < w.gen):
+		// empty, torn while created or already compacted
+		return w.reset()
+	case err != nil:
+		return err
+	case magic != walMagic || gen > w.gen:
+		return ErrBadFormat
+	}
+	w.size = headerSize
+	for {
+		rec, n, err := readRecord(r, info.Size()-w.size)
+		if err != nil {
+			break
+		}
+		apply(rec)
+		w.size += n
+		w.records++
+	}
+	if w.size < info.Size() {
+		if err = w.f.Truncate(w.size); err != nil {
+			return err
+		}
+		return w.f.Sync()
+	}
+	return nil
+}
+
+// reset empties the log and writes its header
+func (w *wal) reset() error {
+	if err := w.f.Truncate(0); err != nil {
+		return err
+	}
+	if _, err := w.f.WriteAt(header(walMagic, w.gen), 0); err != nil {
+		return err
+	}
+	w.size = headerSize
+	w.records = 0
+	return w.f.Sync()
+}
+
+// append logs r, which must not be applied when an error is returned.
+func (w *wal) append(r record) error {
+	if w.broken != nil {
+		return w.broken
+	}
+	frame, err := encode(r)
+	if err != nil {
+		return err
+	}
+	if _, err = w.f.WriteAt(frame, w.size); err != nil {
+		// drop the partial record so that later ones are not lost on replay
+		if terr := w.f.Truncate(w.size); terr != nil {
+			w.broken = terr
+		}
+		return err
+	}
+	w.size += int64(len(frame))
+	w.records++
+	w.dirty = true
+	if w.opts.Sync == SyncAlways {
+		if err = w.sync(); err != nil {
+			// the mutation is not applied, so it must not reappear on replay.
+			// The log is broken from now on, even if truncating fails.
+			w.f.Truncate(w.size - int64(len(frame)))
+			w.size -= int64(len(frame))
+			w.records--
+		}
+		return err
+	}
+	return nil
+}
+
+// sync flushes the log. A failed flush leaves the durability of previous
+// records unknown, so the log refuses further records.
+func (w *wal) sync() error {
+	if w.broken != nil {
+		return w.broken
+	}
+	if !w.dirty {
+		return nil
+	}
+	if err := w.f.Sync(); err != nil {
+		w.broken = err
+		return err
+	}
+	w.dirty = false
+	return nil
+}
+
+// compact writes the records emitted by dump into a new snapshot, then
+// replaces the log by an empty one of the next generation.
+func (w *wal) compact(dump func(emit func(record) error) error) error {
+	if w.broken != nil {
+		return w.broken
+	}
+	gen := w.gen + 1
+	snapshot := filepath.Join(w.dir, snapshotName)
+	err := writeFile(snapshot+tmpSuffix, header(snapshotMagic, gen), func(b *bufio.Writer) error {
+		return dump(func(r record) error {
+			frame, err := encode(r)
+			if err == nil {
+				_, err = b.Write(frame)
+			}
+			return err
+		})
+	})
+	if err == nil {
+		err = os.Rename(snapshot+tmpSuffix, snapshot)
+	}
+	if err == nil {
+		err = syncDir(w.dir)
+	}
+	if err != nil {
+		os.Remove(snapshot + tmpSuffix)
+		return err
+	}
+	// from now on the current log is obsolete, even if replacing it fails
+	log := filepath.Join(w.dir, walName)
+	err = writeFile(log+tmpSuffix, header(walMagic, gen), nil)
+	if err == nil {
+		err = os.Rename(log+tmpSuffix, log)
+	}
+	if err == nil {
+		err = syncDir(w.dir)
+	}
+	var f *os.File
+	if err == nil {
+		f, err = os.OpenFile(log, os.O_RDWR, 0644)
+	}
+	if err != nil {
+		w.broken = err
+		return err
+	}
+	w.f.Close()
+	w.f = f
+	w.gen = gen
+	w.size = headerSize
+	w.records = 0
+	w.dirty = false
+	return nil
+}
+
+func (w *wal) close() error {
+	err := w.sync()
+	if cerr := w.f.Close(); err == nil {
+		err = cerr
+	}
+	w.broken = ErrClosed
+	return err
+}
+
+func header(magic [4]byte, gen uint64) []byte {
+	b := make([]byte, headerSize)
+	copy(b, magic[:])
+	binary.LittleEndian.PutUint64(b[4:], gen)
+	return b
+}
+
+func readHeader(r io.Reader) (magic [4]byte, gen uint64, err error) {
+	b := make([]byte, headerSize)
+	if _, err = io.ReadFull(r, b); err != nil {
+		return
+	}
+	copy(magic[:], b)
+	return magic, binary.LittleEndian.Uint64(b[4:]), nil
+}
+
+// encode frames r with its length and checksum
+func encode(r record) ([]byte, error) {
+	buf := bytes.NewBuffer(make([]byte, frameSize))
+	if err := gob.NewEncoder(buf).Encode(r); err != nil {
+		return nil, err
+	}
+	frame := buf.Bytes()
+	payload := frame[frameSize:]
+	binary.LittleEndian.PutUint32(frame, uint32(len(payload)))
+	binary.LittleEndian.PutUint32(frame[4:], crc32.Checksum(payload, crcTable))
+	return frame, nil
+}
+
+// readRecord returns the next record and its size, or an error when the
+// record is missing, incomplete or corrupted.
+// At most max bytes are read.
+func readRecord(r io.Reader, max int64) (rec record, n int64, err error) {
+	frame := make([]byte, frameSize)
+	if _, err = io.ReadFull(r, frame); err != nil {
+		return
+	}
+	length := int64(binary.LittleEndian.Uint32(frame))
+	if length > max-frameSize {
+		return rec, 0, io.ErrUnexpectedEOF
+	}
+	payload := make([]byte, length)
+	if _, err = io.ReadFull(r, payload); err != nil {
+		return
+	}
+	if crc32.Checksum(payload, crcTable) != binary.LittleEndian.Uint32(frame[4:]) {
+		return rec, 0, ErrBadFormat
+	}
+	if err = gob.NewDecoder(bytes.NewReader(payload)).Decode(&rec); err != nil {
+		return
+	}
+	return rec, int64(frameSize + len(payload)), nil
+}
+
+// readSnapshot applies the records of a snapshot and returns its generation.
+// Snapshots are written atomically, so any damage is reported.
+func readSnapshot(f *os.File, apply func(record)) (uint64, error) {
+	info, err := f.Stat()
+	if err != nil {
+		return 0, err
+	}
+	r := bufio.NewReader(f)
+	magic, gen, err := readHeader(r)
+	if err != nil || magic != snapshotMagic {
+		return 0, ErrBadFormat
+	}
+	for remaining := info.Size() - headerSize; ; {
+		rec, n, err := readRecord(r, remaining)
+		if err == io.EOF {
+			return gen, nil
+		}
+		if err != nil {
+			return 0, ErrBadFormat
+		}
+		apply(rec)
+		remaining -= n
+	}
+}
+
+// writeFile creates name with the given header and content, and flushes it.
+func writeFile(name string, head []byte, content func(*bufio.Writer) error) error {
+	f, err := os.Create(name)
+	if err != nil {
+		return err
+	}
+	b := bufio.NewWriter(f)
+	_, err = b.Write(head)
+	if err == nil && content != nil {
+		err = content(b)
+	}
+	if err == nil {
+		err = b.Flush()
+	}
+	if err == nil {
+		err = f.Sync()
+	}
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	return err
+}
+
+// syncDir flushes the entries of dir so that renames survive a crash
+func syncDir(dir string) error {
+	d, err := os.Open(dir)
+	if err != nil {
+		return err
+	}
+	defer d.Close()
+	return d.Sync()
+}
+
+// chunks emits items in records of at most chunkSize items
+func chunks(o op, items []interface{}, emit func(record) error) error {
+	for len(items) > 0 {
+		n := len(items)
+		if n > chunkSize {
+			n = chunkSize
+		}
+		if err := emit(record{o, items[:n]}); err != nil {
+			return err
+		}
+		items = items[n:]
+	}
+	return nil
+}
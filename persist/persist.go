@@ -0,0 +1,57 @@
+// Package persist makes sets and hashmaps survive process restarts. Every
+// mutation is appended to a write-ahead log before being applied in memory,
+// and the log is periodically compacted into a snapshot. On open, the snapshot
+// and the log are replayed, and a record torn by a crash is truncated.
+//
+// Items, keys and values are encoded with encoding/gob: types other than the
+// predeclared ones must be registered with gob.Register.
+package persist
+
+import (
+	"errors"
+	"time"
+)
+
+// SyncPolicy tells when the log is flushed to stable storage
+type SyncPolicy int
+
+const (
+	// SyncAlways flushes the log after every mutation. A mutation whose flush
+	// fails is dropped from the log and not applied.
+	SyncAlways SyncPolicy = iota
+	// SyncInterval flushes the log every Options.SyncInterval
+	SyncInterval
+	// SyncNever leaves flushing to the operating system
+	SyncNever
+)
+
+// Options configures a persisted collection. The zero value flushes after every
+// mutation and never compacts automatically.
+type Options struct {
+	Sync SyncPolicy
+	// SyncInterval defaults to one second with the SyncInterval policy
+	SyncInterval time.Duration
+	// CompactAfter compacts the log once it holds that many records, 0 disables it
+	CompactAfter int
+	// CompactInterval compacts the log periodically, 0 disables it
+	CompactInterval time.Duration
+}
+
+// Durable describes the methods shared by persisted collections
+type Durable interface {
+	// Compact writes the current state into a snapshot and empties the log
+	Compact() error
+	// Sync flushes the log to stable storage
+	Sync() error
+	// Close flushes and closes the log. The collection remains readable.
+	Close() error
+	// Err returns the last error met by a mutation, a periodic sync or a compaction
+	Err() error
+}
+
+var (
+	// ErrClosed - the collection is closed
+	ErrClosed = errors.New("ErrClosed - the collection is closed")
+	// ErrBadFormat - file is not a log or a snapshot, or is corrupted
+	ErrBadFormat = errors.New("ErrBadFormat - file is not a log or a snapshot, or is corrupted")
+)
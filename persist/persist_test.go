@@ -0,0 +1,321 @@
+package persist
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/khezen/struct/hashmap"
+	"github.com/khezen/struct/set"
+)
+
+func testErr(err error, expectErr bool, t *testing.T) {
+	if (expectErr && err == nil) || (!expectErr && err != nil) {
+		t.Errorf(" Error expected? %v. Got: %v.", expectErr, err)
+	}
+}
+
+func openSet(dir string, t *testing.T) Set {
+	s, err := OpenSet(dir, set.NewSync(), Options{})
+	if err != nil {
+		t.Fatalf("Expected %v. Got %v.", nil, err)
+	}
+	return s
+}
+
+func TestSetRecovery(t *testing.T) {
+	dir := t.TempDir()
+	s := openSet(dir, t)
+	s.Add(1, 2, 3, 4)
+	s.Remove(2)
+	s.Replace(3, 30)
+	s.Merge(set.New(5, 6))
+	s.Separate(set.New(6))
+	s.Retain(set.New(1, 30, 4, 5))
+	s.Merge(s)
+	item := s.Pop()
+	if s.Has(item) || s.Len() != 3 {
+		t.Errorf("Expected %v. Got %v.", 3, s.Len())
+	}
+	expected := s.CopySet()
+	testErr(s.Close(), false, t)
+	testErr(s.Close(), true, t)
+	s.Add(7)
+	testErr(s.Err(), true, t)
+	if s.Has(7) {
+		t.Errorf("Expected %v. Got %v.", false, true)
+	}
+	s = openSet(dir, t)
+	if !s.IsEqual(expected) {
+		t.Errorf("Expected %v. Got %v.", expected, s)
+	}
+	s.Clear()
+	testErr(s.Close(), false, t)
+	s = openSet(dir, t)
+	if !s.IsEmpty() {
+		t.Errorf("Expected %v. Got %v.", "[]", s)
+	}
+	testErr(s.Err(), false, t)
+	s.Close()
+}
+
+func TestSetCompaction(t *testing.T) {
+	dir := t.TempDir()
+	s, err := OpenSet(dir, set.New(), Options{Sync: SyncNever, CompactAfter: 100})
+	testErr(err, false, t)
+	for i := 0; i < 2500; i++ {
+		s.Add(i)
+	}
+	s.Remove(0)
+	info, err := os.Stat(filepath.Join(dir, walName))
+	testErr(err, false, t)
+	if info.Size() > 16<<10 {
+		t.Errorf("Expected %v. Got %v.", "compacted log", info.Size())
+	}
+	testErr(s.Compact(), false, t)
+	s.Add(-1)
+	testErr(s.Close(), false, t)
+	testErr(s.Compact(), true, t)
+	testErr(s.Sync(), true, t)
+	s = openSet(dir, t)
+	if s.Len() != 2500 || s.Has(0) || !s.Has(-1, 2499) {
+		t.Errorf("Expected %v. Got %v.", 2500, s.Len())
+	}
+	s.Close()
+}
+
+func TestHashmapRecovery(t *testing.T) {
+	dir := t.TempDir()
+	open := func() Hashmap {
+		h, err := OpenHashmap(dir, hashmap.NewSync(), Options{Sync: SyncNever})
+		testErr(err, false, t)
+		return h
+	}
+	h := open()
+	h.Put("a", 1)
+	h.Put("b", 2)
+	h.Put("a", 10)
+	h.Remove("b", "z")
+	h.Put("c", nil)
+	testErr(h.Compact(), false, t)
+	h.Put("d", "x")
+	testErr(h.Sync(), false, t)
+	testErr(h.Close(), false, t)
+	h = open()
+	expected := hashmap.New("a", 10, "c", nil, "d", "x")
+	if !h.IsEqual(expected) || !h.IsEqual(h) {
+		t.Errorf("Expected %v. Got %v.", expected, h)
+	}
+	if v, err := h.Get("a"); err != nil || v != 10 || !h.Has("c") || !h.HasValue("x") || h.IsEmpty() {
+		t.Errorf("Expected %v. Got %v.", 10, v)
+	}
+	if k, err := h.KeyOf("x"); err != nil || k != "d" {
+		t.Errorf("Expected %v. Got %v.", "d", k)
+	}
+	if len(h.Keys()) != 3 || len(h.Values()) != 3 || len(h.Map()) != 3 || h.Copy().Len() != 3 || h.String() == "" {
+		t.Errorf("Expected %v. Got %v.", 3, h.Len())
+	}
+	h.Clear()
+	h.Close()
+	h = open()
+	if h.Len() != 0 {
+		t.Errorf("Expected %v. Got %v.", 0, h.Len())
+	}
+	h.Close()
+}
+
+// TestTornWrite cuts the log at every offset of its last record, as a crash
+// during the write would, and checks that only complete records are recovered.
+func TestTornWrite(t *testing.T) {
+	dir := t.TempDir()
+	s := openSet(dir, t)
+	s.Add(1)
+	s.Add(2)
+	s.Close()
+	log := filepath.Join(dir, walName)
+	full, err := os.ReadFile(log)
+	testErr(err, false, t)
+	s = openSet(dir, t)
+	s.Add(3)
+	s.Close()
+	withLast, err := os.ReadFile(log)
+	testErr(err, false, t)
+	for cut := len(full); cut < len(withLast); cut++ {
+		testErr(os.WriteFile(log, withLast[:cut], 0644), false, t)
+		s = openSet(dir, t)
+		if !s.IsEqual(set.New(1, 2)) {
+			t.Errorf("Expected %v. Got %v.", "[1 2]", s)
+		}
+		s.Add(4)
+		s.Close()
+		s = openSet(dir, t)
+		if !s.IsEqual(set.New(1, 2, 4)) {
+			t.Errorf("Expected %v. Got %v.", "[1 2 4]", s)
+		}
+		s.Close()
+	}
+}
+
+func TestCorruptedRecord(t *testing.T) {
+	dir := t.TempDir()
+	s := openSet(dir, t)
+	s.Add(1)
+	s.Add(2)
+	s.Close()
+	log := filepath.Join(dir, walName)
+	b, err := os.ReadFile(log)
+	testErr(err, false, t)
+	b[len(b)-1] ^= 0xff
+	testErr(os.WriteFile(log, b, 0644), false, t)
+	s = openSet(dir, t)
+	if !s.IsEqual(set.New(1)) {
+		t.Errorf("Expected %v. Got %v.", "[1]", s)
+	}
+	s.Close()
+	// a torn header is reset
+	testErr(os.WriteFile(log, walMagic[:2], 0644), false, t)
+	s = openSet(dir, t)
+	if !s.IsEmpty() {
+		t.Errorf("Expected %v. Got %v.", "[]", s)
+	}
+	s.Close()
+	testErr(os.WriteFile(log, []byte("not a log file"), 0644), false, t)
+	_, err = OpenSet(dir, set.New(), Options{})
+	if err != ErrBadFormat {
+		t.Errorf("Expected %v. Got %v.", ErrBadFormat, err)
+	}
+}
+
+// TestInterruptedCompaction simulates crashes between the steps of a compaction.
+func TestInterruptedCompaction(t *testing.T) {
+	dir := t.TempDir()
+	s := openSet(dir, t)
+	s.Add(1, 2)
+	s.Close()
+	log := filepath.Join(dir, walName)
+	oldLog, err := os.ReadFile(log)
+	testErr(err, false, t)
+	s = openSet(dir, t)
+	testErr(s.Compact(), false, t)
+	s.Close()
+	// crash after the snapshot was renamed but before the log was replaced:
+	// the old log must not be replayed over the snapshot
+	testErr(os.WriteFile(log, oldLog, 0644), false, t)
+	testErr(os.WriteFile(filepath.Join(dir, snapshotName+tmpSuffix), []byte("partial"), 0644), false, t)
+	s = openSet(dir, t)
+	if !s.IsEqual(set.New(1, 2)) {
+		t.Errorf("Expected %v. Got %v.", "[1 2]", s)
+	}
+	s.Remove(1)
+	s.Close()
+	s = openSet(dir, t)
+	if !s.IsEqual(set.New(2)) {
+		t.Errorf("Expected %v. Got %v.", "[2]", s)
+	}
+	s.Close()
+	if _, err := os.Stat(filepath.Join(dir, snapshotName+tmpSuffix)); !os.IsNotExist(err) {
+		t.Errorf("Expected %v. Got %v.", "no temporary snapshot", err)
+	}
+	// a damaged snapshot is reported
+	testErr(os.WriteFile(filepath.Join(dir, snapshotName), []byte("SNP1 damaged snapshot"), 0644), false, t)
+	_, err = OpenSet(dir, set.New(), Options{})
+	if err != ErrBadFormat {
+		t.Errorf("Expected %v. Got %v.", ErrBadFormat, err)
+	}
+}
+
+type unregistered struct{ v int }
+
+func TestUnencodableItem(t *testing.T) {
+	dir := t.TempDir()
+	s := openSet(dir, t)
+	s.Add(unregistered{1})
+	testErr(s.Err(), true, t)
+	if s.Len() != 0 {
+		t.Errorf("Expected %v. Got %v.", 0, s.Len())
+	}
+	s.Add(1)
+	s.Close()
+	s = openSet(dir, t)
+	if !s.IsEqual(set.New(1)) {
+		t.Errorf("Expected %v. Got %v.", "[1]", s)
+	}
+	s.Close()
+}
+
+func TestBackgroundTasks(t *testing.T) {
+	dir := t.TempDir()
+	s, err := OpenSet(dir, set.New(), Options{Sync: SyncInterval, SyncInterval: time.Millisecond, CompactInterval: time.Millisecond})
+	testErr(err, false, t)
+	for i := 0; i < 100; i++ {
+		s.Add(i)
+	}
+	// wait for a compaction
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		info, err := os.Stat(filepath.Join(dir, snapshotName))
+		if err == nil && info.Size() > headerSize {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("Expected %v. Got %v.", "snapshot", err)
+		}
+		time.Sleep(time.Millisecond)
+	}
+	testErr(s.Close(), false, t)
+	s = openSet(dir, t)
+	if s.Len() != 100 {
+		t.Errorf("Expected %v. Got %v.", 100, s.Len())
+	}
+	s.Close()
+}
+
+func TestReadOnlyDir(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "file")
+	testErr(os.WriteFile(dir, nil, 0644), false, t)
+	_, err := OpenHashmap(dir, hashmap.New(), Options{})
+	testErr(err, true, t)
+	if errors.Is(err, ErrBadFormat) {
+		t.Errorf("Expected %v. Got %v.", "filesystem error", err)
+	}
+}
+
+func TestPopNil(t *testing.T) {
+	dir := t.TempDir()
+	s := openSet(dir, t)
+	s.Add(nil)
+	if item := s.Pop(); item != nil || !s.IsEmpty() {
+		t.Errorf("Expected %v. Got %v.", "[]", s)
+	}
+	testErr(s.Err(), false, t)
+	testErr(s.Close(), false, t)
+	s = openSet(dir, t)
+	defer s.Close()
+	if !s.IsEmpty() {
+		t.Errorf("Expected %v. Got %v.", "[]", s)
+	}
+}
+
+func TestSetReads(t *testing.T) {
+	s := openSet(t.TempDir(), t)
+	defer s.Close()
+	s.Add(1, 2, 3)
+	if !s.IsSubset(set.New(1)) || !s.IsSuperset(s) || s.IsEmpty() || len(s.Slice()) != 3 || s.CopyCollection().Len() != 3 || s.String() == "" {
+		t.Errorf("Expected %v. Got %v.", true, false)
+	}
+	visited := 0
+	s.Each(func(item interface{}) bool {
+		visited++
+		return true
+	})
+	if visited != 3 {
+		t.Errorf("Expected %v. Got %v.", 3, visited)
+	}
+	for s.Pop() != nil {
+	}
+	if !s.IsEmpty() {
+		t.Errorf("Expected %v. Got %v.", "[]", s)
+	}
+}
@@ -0,0 +1,234 @@
+package persist
+
+import (
+	"fmt"
+
+	"github.com/khezen/struct/collection"
+	"github.com/khezen/struct/internal/format"
+	"github.com/khezen/struct/set"
+)
+
+// Set is a set whose mutations are logged to disk. Mutations which cannot be
+// logged are not applied, and Err reports why.
+type Set interface {
+	set.Interface
+	Durable
+}
+
+type persistedSet struct {
+	durable
+	s set.Interface
+}
+
+// OpenSet recovers the set persisted in dir into s, which is cleared first, and
+// logs its later mutations. s must not be modified but through the returned set.
+func OpenSet(dir string, s set.Interface, opts Options) (Set, error) {
+	s.Clear()
+	w, err := openWAL(dir, opts, func(r record) {
+		switch r.Op {
+		case opAdd:
+			s.Add(r.Items...)
+		case opRemove:
+			s.Remove(r.Items...)
+		case opClear:
+			s.Clear()
+		case opReplace:
+			s.Replace(r.Items[0], r.Items[1])
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+	p := &persistedSet{s: s}
+	p.w = w
+	p.dump = func(emit func(record) error) error {
+		return chunks(opAdd, s.Slice(), emit)
+	}
+	p.start(opts)
+	return p, nil
+}
+
+// operand returns the wrapped set when t is p itself, which is already locked
+func (p *persistedSet) operand(t collection.ReadOnly) collection.ReadOnly {
+	if t == collection.ReadOnly(p) {
+		return p.s
+	}
+	return t
+}
+
+func (p *persistedSet) Add(items ...interface{}) {
+	if len(items) > 0 {
+		p.l.Lock()
+		defer p.l.Unlock()
+		p.log(record{opAdd, items}, func() {
+			p.s.Add(items...)
+		})
+	}
+}
+
+func (p *persistedSet) Remove(items ...interface{}) {
+	if len(items) > 0 {
+		p.l.Lock()
+		defer p.l.Unlock()
+		p.remove(items)
+	}
+}
+
+func (p *persistedSet) remove(items []interface{}) {
+	if len(items) > 0 {
+		p.log(record{opRemove, items}, func() {
+			p.s.Remove(items...)
+		})
+	}
+}
+
+// Pop removes and returns an item, or nil when the set is empty or the
+// removal could not be logged. A nil item is popped like any other.
+func (p *persistedSet) Pop() (item interface{}) {
+	p.l.Lock()
+	defer p.l.Unlock()
+	found := false
+	p.s.Each(func(i interface{}) bool {
+		item, found = i, true
+		return false
+	})
+	if !found {
+		return nil
+	}
+	p.remove([]interface{}{item})
+	if p.s.Has(item) {
+		return nil
+	}
+	return item
+}
+
+func (p *persistedSet) Replace(item, substitute interface{}) {
+	p.l.Lock()
+	defer p.l.Unlock()
+	if p.s.Has(item) {
+		p.log(record{opReplace, []interface{}{item, substitute}}, func() {
+			p.s.Replace(item, substitute)
+		})
+	}
+}
+
+func (p *persistedSet) Clear() {
+	p.l.Lock()
+	defer p.l.Unlock()
+	p.log(record{Op: opClear}, p.s.Clear)
+}
+
+func (p *persistedSet) Merge(t collection.Interface) {
+	p.l.Lock()
+	defer p.l.Unlock()
+	var items []interface{}
+	p.operand(t).Each(func(item interface{}) bool {
+		if !p.s.Has(item) {
+			items = append(items, item)
+		}
+		return true
+	})
+	if len(items) > 0 {
+		p.log(record{opAdd, items}, func() {
+			p.s.Add(items...)
+		})
+	}
+}
+
+func (p *persistedSet) Separate(t collection.Interface) {
+	p.l.Lock()
+	defer p.l.Unlock()
+	var items []interface{}
+	p.operand(t).Each(func(item interface{}) bool {
+		if p.s.Has(item) {
+			items = append(items, item)
+		}
+		return true
+	})
+	p.remove(items)
+}
+
+func (p *persistedSet) Retain(t collection.Interface) {
+	p.l.Lock()
+	defer p.l.Unlock()
+	t = p.operand(t).(collection.Interface)
+	var items []interface{}
+	p.s.Each(func(item interface{}) bool {
+		if !t.Has(item) {
+			items = append(items, item)
+		}
+		return true
+	})
+	p.remove(items)
+}
+
+func (p *persistedSet) Has(items ...interface{}) bool {
+	p.l.RLock()
+	defer p.l.RUnlock()
+	return p.s.Has(items...)
+}
+
+func (p *persistedSet) Each(f func(item interface{}) bool) {
+	p.l.RLock()
+	defer p.l.RUnlock()
+	p.s.Each(f)
+}
+
+func (p *persistedSet) Len() int {
+	p.l.RLock()
+	defer p.l.RUnlock()
+	return p.s.Len()
+}
+
+func (p *persistedSet) IsEmpty() bool {
+	p.l.RLock()
+	defer p.l.RUnlock()
+	return p.s.IsEmpty()
+}
+
+func (p *persistedSet) IsEqual(t collection.ReadOnly) bool {
+	p.l.RLock()
+	defer p.l.RUnlock()
+	return p.s.IsEqual(p.operand(t))
+}
+
+func (p *persistedSet) IsSubset(t set.ReadOnly) bool {
+	p.l.RLock()
+	defer p.l.RUnlock()
+	return p.s.IsSubset(p.operand(t).(set.ReadOnly))
+}
+
+func (p *persistedSet) IsSuperset(t set.ReadOnly) bool {
+	p.l.RLock()
+	defer p.l.RUnlock()
+	return p.s.IsSuperset(p.operand(t).(set.ReadOnly))
+}
+
+func (p *persistedSet) String() string {
+	p.l.RLock()
+	defer p.l.RUnlock()
+	return p.s.String()
+}
+
+func (p *persistedSet) Format(f fmt.State, verb rune) {
+	p.l.RLock()
+	defer p.l.RUnlock()
+	format.Delegate(f, verb, p.s)
+}
+
+func (p *persistedSet) Slice() []interface{} {
+	p.l.RLock()
+	defer p.l.RUnlock()
+	return p.s.Slice()
+}
+
+// CopySet returns an in-memory copy of the set.
+func (p *persistedSet) CopySet() set.Interface {
+	p.l.RLock()
+	defer p.l.RUnlock()
+	return p.s.CopySet()
+}
+
+func (p *persistedSet) CopyCollection() collection.Interface {
+	return p.CopySet()
+}
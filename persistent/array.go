@@ -0,0 +1,268 @@
+package persistent
+
+import (
+	"fmt"
+
+	"github.com/khezen/struct/array"
+	"github.com/khezen/struct/collection"
+	"github.com/khezen/struct/internal/format"
+)
+
+const (
+	width    = 1 << bitsPerLevel
+	pathMask = width - 1
+)
+
+// vnode is a node of a vector trie. Leaves hold items, other nodes hold *vnode.
+type vnode struct {
+	a [width]interface{}
+}
+
+// persistentArray keeps its last items in tail so Add rarely walks the trie
+type persistentArray struct {
+	size  int
+	shift uint
+	root  *vnode
+	tail  []interface{}
+}
+
+var emptyArray = &persistentArray{0, bitsPerLevel, &vnode{}, nil}
+
+// NewArray creates an immutable array holding the given items
+func NewArray(items ...interface{}) Array {
+	return emptyArray.Add(items...)
+}
+
+func (a *persistentArray) tailOffset() int {
+	if a.size < width {
+		return 0
+	}
+	return (a.size - 1) >> bitsPerLevel << bitsPerLevel
+}
+
+// leafOf returns the items of the leaf holding index i
+func (a *persistentArray) leafOf(i int) []interface{} {
+	if i >= a.tailOffset() {
+		return a.tail
+	}
+	n := a.root
+	for level := a.shift; level > 0; level -= bitsPerLevel {
+		n = n.a[(i>>level)&pathMask].(*vnode)
+	}
+	return n.a[:]
+}
+
+func (a *persistentArray) checkIndex(i int) {
+	if i < 0 || i >= a.size {
+		panic(array.ErrIndexOutOfBounds)
+	}
+}
+
+func (a *persistentArray) Get(i int) interface{} {
+	a.checkIndex(i)
+	return a.leafOf(i)[i&pathMask]
+}
+
+// Add returns an array holding items of a followed by the given items
+func (a *persistentArray) Add(items ...interface{}) Array {
+	for _, item := range items {
+		a = a.push(item)
+	}
+	return a
+}
+
+func (a *persistentArray) push(item interface{}) *persistentArray {
+	if a.size-a.tailOffset() < width {
+		tail := make([]interface{}, len(a.tail), len(a.tail)+1)
+		copy(tail, a.tail)
+		return &persistentArray{a.size + 1, a.shift, a.root, append(tail, item)}
+	}
+	leaf := &vnode{}
+	copy(leaf.a[:], a.tail)
+	root, shift := a.root, a.shift
+	if a.size>>bitsPerLevel > 1<<a.shift {
+		root = &vnode{}
+		root.a[0] = a.root
+		root.a[1] = newPath(a.shift, leaf)
+		shift += bitsPerLevel
+	} else {
+		root = a.pushTail(a.shift, a.root, leaf)
+	}
+	return &persistentArray{a.size + 1, shift, root, []interface{}{item}}
+}
+
+func newPath(level uint, n *vnode) *vnode {
+	for ; level > 0; level -= bitsPerLevel {
+		parent := &vnode{}
+		parent.a[0] = n
+		n = parent
+	}
+	return n
+}
+
+func (a *persistentArray) pushTail(level uint, parent, leaf *vnode) *vnode {
+	i := ((a.size - 1) >> level) & pathMask
+	n := &vnode{parent.a}
+	if level == bitsPerLevel {
+		n.a[i] = leaf
+	} else if child, ok := parent.a[i].(*vnode); ok {
+		n.a[i] = a.pushTail(level-bitsPerLevel, child, leaf)
+	} else {
+		n.a[i] = newPath(level-bitsPerLevel, leaf)
+	}
+	return n
+}
+
+// ReplaceAt returns an array where the item at index i is substitute
+func (a *persistentArray) ReplaceAt(i int, substitute interface{}) Array {
+	a.checkIndex(i)
+	if i >= a.tailOffset() {
+		tail := make([]interface{}, len(a.tail))
+		copy(tail, a.tail)
+		tail[i&pathMask] = substitute
+		return &persistentArray{a.size, a.shift, a.root, tail}
+	}
+	return &persistentArray{a.size, a.shift, replaceAt(a.shift, a.root, i, substitute), a.tail}
+}
+
+func replaceAt(level uint, parent *vnode, i int, substitute interface{}) *vnode {
+	n := &vnode{parent.a}
+	if level == 0 {
+		n.a[i&pathMask] = substitute
+		return n
+	}
+	j := (i >> level) & pathMask
+	n.a[j] = replaceAt(level-bitsPerLevel, parent.a[j].(*vnode), i, substitute)
+	return n
+}
+
+// RemoveLast returns an array holding items of a except the last one
+func (a *persistentArray) RemoveLast() Array {
+	switch {
+	case a.size == 0:
+		panic(array.ErrIndexOutOfBounds)
+	case a.size == 1:
+		return emptyArray
+	case a.size-a.tailOffset() > 1:
+		return &persistentArray{a.size - 1, a.shift, a.root, a.tail[: len(a.tail)-1 : len(a.tail)-1]}
+	}
+	tail := a.leafOf(a.size - 2)
+	root, shift := a.popTail(a.shift, a.root), a.shift
+	if root == nil {
+		root = &vnode{}
+	}
+	if shift > bitsPerLevel && root.a[1] == nil {
+		root, shift = root.a[0].(*vnode), shift-bitsPerLevel
+	}
+	return &persistentArray{a.size - 1, shift, root, tail[:width:width]}
+}
+
+func (a *persistentArray) popTail(level uint, parent *vnode) *vnode {
+	i := ((a.size - 2) >> level) & pathMask
+	if level > bitsPerLevel {
+		child := a.popTail(level-bitsPerLevel, parent.a[i].(*vnode))
+		if child == nil && i == 0 {
+			return nil
+		}
+		n := &vnode{parent.a}
+		if child == nil {
+			n.a[i] = nil
+		} else {
+			n.a[i] = child
+		}
+		return n
+	}
+	if i == 0 {
+		return nil
+	}
+	n := &vnode{parent.a}
+	n.a[i] = nil
+	return n
+}
+
+func (a *persistentArray) IndexOf(item interface{}) (int, error) {
+	index := -1
+	a.each(func(i int, current interface{}) bool {
+		if current == item {
+			index = i
+		}
+		return index < 0
+	})
+	if index < 0 {
+		return -1, array.ErrNotFound
+	}
+	return index, nil
+}
+
+func (a *persistentArray) Has(items ...interface{}) bool {
+	for _, item := range items {
+		if _, err := a.IndexOf(item); err != nil {
+			return false
+		}
+	}
+	return true
+}
+
+// each traverses items leaf by leaf rather than walking the trie for every index
+func (a *persistentArray) each(f func(i int, item interface{}) bool) {
+	for offset := 0; offset < a.size; offset += width {
+		leaf := a.leafOf(offset)
+		for j := 0; j < width && offset+j < a.size; j++ {
+			if !f(offset+j, leaf[j]) {
+				return
+			}
+		}
+	}
+}
+
+func (a *persistentArray) Each(f func(item interface{}) bool) {
+	a.each(func(i int, item interface{}) bool {
+		return f(item)
+	})
+}
+
+func (a *persistentArray) Len() int {
+	return a.size
+}
+
+func (a *persistentArray) IsEmpty() bool {
+	return a.size == 0
+}
+
+func (a *persistentArray) IsEqual(t collection.ReadOnly) bool {
+	if a.size != t.Len() {
+		return false
+	}
+	items := t.Slice()
+	equal := true
+	a.each(func(i int, item interface{}) bool {
+		equal = item == items[i]
+		return equal
+	})
+	return equal
+}
+
+func (a *persistentArray) String() string {
+	return format.String(a)
+}
+
+// Format implements fmt.Formatter: %v prints the items, %+v adds the type and
+// the length and %#v the Go syntax. A precision truncates the items: %.3v
+// prints the 3 first followed by "...".
+func (a *persistentArray) Format(f fmt.State, verb rune) {
+	format.Seq(f, verb, "persistent.Array", "persistent.NewArray", a.Slice())
+}
+
+func (a *persistentArray) Slice() []interface{} {
+	items := make([]interface{}, 0, a.size)
+	a.Each(func(item interface{}) bool {
+		items = append(items, item)
+		return true
+	})
+	return items
+}
+
+// CopyArr returns a mutable copy of a
+func (a *persistentArray) CopyArr() array.Interface {
+	return array.New(a.Slice()...)
+}
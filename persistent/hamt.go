@@ -0,0 +1,258 @@
+package persistent
+
+import (
+	"math/bits"
+	"reflect"
+
+	"github.com/khezen/struct/internal/digest"
+)
+
+const (
+	bitsPerLevel = 5
+	levelMask    = 1<<bitsPerLevel - 1
+)
+
+// node is a node of a hash array mapped trie. Nodes are never modified once
+// built: put and remove return a new node and the receiver when nothing changed.
+type node interface {
+	get(hash uint64, shift uint, k interface{}) (interface{}, bool)
+	put(hash uint64, shift uint, k, v interface{}) (n node, added bool)
+	remove(hash uint64, shift uint, k interface{}) (n node, removed bool)
+	each(f func(k, v interface{}) bool) bool
+}
+
+type leaf struct {
+	hash uint64
+	k, v interface{}
+}
+
+// collision holds entries whose keys share the same hash
+type collision struct {
+	hash    uint64
+	entries []*leaf
+}
+
+// branch holds up to 32 children indexed by 5 bits of hash
+type branch struct {
+	bitmap   uint32
+	children []node
+}
+
+func hash(k interface{}) uint64 {
+	h, _ := digest.Item(k)
+	return h
+}
+
+// same reports whether x and y are equal, and false when they cannot be
+// compared, such as slices
+func same(x, y interface{}) bool {
+	if x == nil || y == nil {
+		return x == y
+	}
+	return reflect.ValueOf(x).Comparable() && reflect.ValueOf(y).Comparable() && x == y
+}
+
+func (l *leaf) get(hash uint64, shift uint, k interface{}) (interface{}, bool) {
+	if l.k == k {
+		return l.v, true
+	}
+	return nil, false
+}
+
+func (l *leaf) put(hash uint64, shift uint, k, v interface{}) (node, bool) {
+	if l.k == k {
+		if same(l.v, v) {
+			return l, false
+		}
+		return &leaf{hash, k, v}, false
+	}
+	if l.hash == hash {
+		return &collision{hash, []*leaf{l, {hash, k, v}}}, true
+	}
+	return split(l, l.hash, shift).put(hash, shift, k, v)
+}
+
+func (l *leaf) remove(hash uint64, shift uint, k interface{}) (node, bool) {
+	if l.k == k {
+		return nil, true
+	}
+	return l, false
+}
+
+func (l *leaf) each(f func(k, v interface{}) bool) bool {
+	return f(l.k, l.v)
+}
+
+func (c *collision) get(hash uint64, shift uint, k interface{}) (interface{}, bool) {
+	for _, e := range c.entries {
+		if e.k == k {
+			return e.v, true
+		}
+	}
+	return nil, false
+}
+
+func (c *collision) put(hash uint64, shift uint, k, v interface{}) (node, bool) {
+	if hash != c.hash {
+		return split(c, c.hash, shift).put(hash, shift, k, v)
+	}
+	entries := make([]*leaf, len(c.entries), len(c.entries)+1)
+	copy(entries, c.entries)
+	for i, e := range entries {
+		if e.k == k {
+			if same(e.v, v) {
+				return c, false
+			}
+			entries[i] = &leaf{hash, k, v}
+			return &collision{hash, entries}, false
+		}
+	}
+	return &collision{hash, append(entries, &leaf{hash, k, v})}, true
+}
+
+func (c *collision) remove(hash uint64, shift uint, k interface{}) (node, bool) {
+	for i, e := range c.entries {
+		if e.k == k {
+			if len(c.entries) == 2 {
+				return c.entries[1-i], true
+			}
+			entries := make([]*leaf, 0, len(c.entries)-1)
+			entries = append(entries, c.entries[:i]...)
+			return &collision{hash, append(entries, c.entries[i+1:]...)}, true
+		}
+	}
+	return c, false
+}
+
+func (c *collision) each(f func(k, v interface{}) bool) bool {
+	for _, e := range c.entries {
+		if !f(e.k, e.v) {
+			return false
+		}
+	}
+	return true
+}
+
+// split returns a branch holding n, so another hash can be put next to it
+func split(n node, hash uint64, shift uint) *branch {
+	return &branch{uint32(1) << ((hash >> shift) & levelMask), []node{n}}
+}
+
+// position returns the bit of the child of hash and its index in children
+func (b *branch) position(hash uint64, shift uint) (uint32, int) {
+	bit := uint32(1) << ((hash >> shift) & levelMask)
+	return bit, bits.OnesCount32(b.bitmap & (bit - 1))
+}
+
+// replace returns a copy of b where the child of hash is n
+func (b *branch) replace(hash uint64, shift uint, n node) *branch {
+	_, i := b.position(hash, shift)
+	children := make([]node, len(b.children))
+	copy(children, b.children)
+	children[i] = n
+	return &branch{b.bitmap, children}
+}
+
+func (b *branch) get(hash uint64, shift uint, k interface{}) (interface{}, bool) {
+	bit, i := b.position(hash, shift)
+	if b.bitmap&bit == 0 {
+		return nil, false
+	}
+	return b.children[i].get(hash, shift+bitsPerLevel, k)
+}
+
+func (b *branch) put(hash uint64, shift uint, k, v interface{}) (node, bool) {
+	bit, i := b.position(hash, shift)
+	if b.bitmap&bit == 0 {
+		children := make([]node, 0, len(b.children)+1)
+		children = append(children, b.children[:i]...)
+		children = append(children, &leaf{hash, k, v})
+		children = append(children, b.children[i:]...)
+		return &branch{b.bitmap | bit, children}, true
+	}
+	child, added := b.children[i].put(hash, shift+bitsPerLevel, k, v)
+	if child == b.children[i] {
+		return b, false
+	}
+	return b.replace(hash, shift, child), added
+}
+
+func (b *branch) remove(hash uint64, shift uint, k interface{}) (node, bool) {
+	bit, i := b.position(hash, shift)
+	if b.bitmap&bit == 0 {
+		return b, false
+	}
+	child, removed := b.children[i].remove(hash, shift+bitsPerLevel, k)
+	if !removed {
+		return b, false
+	}
+	if child != nil {
+		if _, isBranch := child.(*branch); !isBranch && len(b.children) == 1 {
+			return child, true
+		}
+		return b.replace(hash, shift, child), true
+	}
+	if len(b.children) == 1 {
+		return nil, true
+	}
+	if len(b.children) == 2 {
+		if _, isBranch := b.children[1-i].(*branch); !isBranch {
+			return b.children[1-i], true
+		}
+	}
+	children := make([]node, 0, len(b.children)-1)
+	children = append(children, b.children[:i]...)
+	children = append(children, b.children[i+1:]...)
+	return &branch{b.bitmap &^ bit, children}, true
+}
+
+func (b *branch) each(f func(k, v interface{}) bool) bool {
+	for _, child := range b.children {
+		if !child.each(f) {
+			return false
+		}
+	}
+	return true
+}
+
+// trie is a persistent map from keys to values
+type trie struct {
+	root node
+	size int
+}
+
+func (t trie) get(k interface{}) (interface{}, bool) {
+	if t.root == nil {
+		return nil, false
+	}
+	return t.root.get(hash(k), 0, k)
+}
+
+func (t trie) put(k, v interface{}) trie {
+	h := hash(k)
+	if t.root == nil {
+		return trie{&leaf{h, k, v}, 1}
+	}
+	root, added := t.root.put(h, 0, k, v)
+	if added {
+		return trie{root, t.size + 1}
+	}
+	return trie{root, t.size}
+}
+
+func (t trie) remove(k interface{}) trie {
+	if t.root == nil {
+		return t
+	}
+	root, removed := t.root.remove(hash(k), 0, k)
+	if removed {
+		return trie{root, t.size - 1}
+	}
+	return t
+}
+
+func (t trie) each(f func(k, v interface{}) bool) {
+	if t.root != nil {
+		t.root.each(f)
+	}
+}
@@ -0,0 +1,145 @@
+package persistent
+
+import (
+	"fmt"
+
+	"github.com/khezen/struct/hashmap"
+	"github.com/khezen/struct/internal/format"
+)
+
+type persistentHashmap struct {
+	t trie
+}
+
+// NewHashmap creates an immutable hashmap holding the given key value pairs
+func NewHashmap(pairs ...interface{}) Hashmap {
+	h := &persistentHashmap{}
+	for i := 0; i < len(pairs)-1; i += 2 {
+		h.t = h.t.put(pairs[i], pairs[i+1])
+	}
+	return h
+}
+
+func (h *persistentHashmap) Get(k interface{}) (interface{}, error) {
+	v, ok := h.t.get(k)
+	if !ok {
+		return nil, fmt.Errorf("%v not found", k)
+	}
+	return v, nil
+}
+
+// Put returns a hashmap where k is associated to v in addition to pairs of h
+func (h *persistentHashmap) Put(k, v interface{}) Hashmap {
+	t := h.t.put(k, v)
+	if t.root == h.t.root {
+		return h
+	}
+	return &persistentHashmap{t}
+}
+
+// Remove returns a hashmap holding pairs of h except those of the given keys
+func (h *persistentHashmap) Remove(keys ...interface{}) Hashmap {
+	t := h.t
+	for _, k := range keys {
+		t = t.remove(k)
+	}
+	if t.root == h.t.root {
+		return h
+	}
+	return &persistentHashmap{t}
+}
+
+func (h *persistentHashmap) Has(keys ...interface{}) bool {
+	for _, k := range keys {
+		if _, ok := h.t.get(k); !ok {
+			return false
+		}
+	}
+	return true
+}
+
+func (h *persistentHashmap) HasValue(values ...interface{}) bool {
+	for _, value := range values {
+		if _, err := h.KeyOf(value); err != nil {
+			return false
+		}
+	}
+	return true
+}
+
+func (h *persistentHashmap) KeyOf(value interface{}) (interface{}, error) {
+	var key interface{}
+	found := false
+	h.t.each(func(k, v interface{}) bool {
+		key, found = k, v == value
+		return !found
+	})
+	if !found {
+		return nil, fmt.Errorf("%v not found", value)
+	}
+	return key, nil
+}
+
+func (h *persistentHashmap) Each(f func(k, v interface{}) bool) {
+	h.t.each(f)
+}
+
+func (h *persistentHashmap) Len() int {
+	return h.t.size
+}
+
+func (h *persistentHashmap) IsEmpty() bool {
+	return h.t.size == 0
+}
+
+func (h *persistentHashmap) IsEqual(t hashmap.ReadOnly) bool {
+	if h.Len() != t.Len() {
+		return false
+	}
+	equal := true
+	t.Each(func(k, v interface{}) bool {
+		value, ok := h.t.get(k)
+		equal = ok && value == v
+		return equal
+	})
+	return equal
+}
+
+func (h *persistentHashmap) String() string {
+	return format.String(h)
+}
+
+// Format implements fmt.Formatter: %v prints the pairs, sorted by keys when
+// they are orderable, %+v adds the type and the length and %#v the Go syntax.
+// A precision truncates the pairs: %.3v prints the 3 first followed by "...".
+func (h *persistentHashmap) Format(f fmt.State, verb rune) {
+	format.Pairs(f, verb, "persistent.Hashmap", "persistent.NewHashmap", h.Each)
+}
+
+func (h *persistentHashmap) Keys() []interface{} {
+	keys := make([]interface{}, 0, h.Len())
+	h.t.each(func(k, v interface{}) bool {
+		keys = append(keys, k)
+		return true
+	})
+	return keys
+}
+
+func (h *persistentHashmap) Values() []interface{} {
+	values := make([]interface{}, 0, h.Len())
+	h.t.each(func(k, v interface{}) bool {
+		values = append(values, v)
+		return true
+	})
+	return values
+}
+
+// Copy returns a mutable copy of h
+func (h *persistentHashmap) Copy() hashmap.Interface {
+	cpy := hashmap.New()
+	h.t.each(func(k, v interface{}) bool {
+		cpy.Put(k, v)
+		return true
+	})
+	return cpy
+}
@@ -0,0 +1,39 @@
+// Package persistent provides immutable set, hashmap and array. Operations
+// which would modify them return a new version instead, sharing most of its
+// structure with the previous one, so versions can be handed to other
+// goroutines without copying nor locking.
+package persistent
+
+import (
+	"github.com/khezen/struct/array"
+	"github.com/khezen/struct/hashmap"
+	"github.com/khezen/struct/set"
+)
+
+// Set is an immutable set based on a hash array mapped trie.
+// Add and Remove run in O(log n) and leave the receiver untouched.
+type Set interface {
+	set.ReadOnly
+	Add(items ...interface{}) Set
+	Remove(items ...interface{}) Set
+	CopySet() set.Interface
+}
+
+// Hashmap is an immutable map based on a hash array mapped trie.
+// Put and Remove run in O(log n) and leave the receiver untouched.
+type Hashmap interface {
+	hashmap.ReadOnly
+	Put(k, v interface{}) Hashmap
+	Remove(keys ...interface{}) Hashmap
+	Copy() hashmap.Interface
+}
+
+// Array is an immutable array based on a vector trie.
+// Add, ReplaceAt and RemoveLast run in O(log n) and leave the receiver untouched.
+type Array interface {
+	array.ReadOnly
+	Add(items ...interface{}) Array
+	ReplaceAt(i int, substitute interface{}) Array
+	RemoveLast() Array
+	CopyArr() array.Interface
+}
@@ -0,0 +1,334 @@
+package persistent
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/khezen/struct/array"
+	"github.com/khezen/struct/hashmap"
+	"github.com/khezen/struct/set"
+)
+
+func TestSetAddRemove(t *testing.T) {
+	cases := []struct {
+		s           Set
+		toBeAdded   []interface{}
+		toBeRemoved []interface{}
+		expected    set.Interface
+	}{
+		{NewSet(), []interface{}{1, 2, 3}, []interface{}{2}, set.New(1, 3)},
+		{NewSet(1, "1"), []interface{}{1, int64(1)}, []interface{}{"1", 42}, set.New(1, int64(1))},
+		{NewSet(1, 2), nil, []interface{}{1, 2}, set.New()},
+	}
+	for _, c := range cases {
+		before := c.s.Slice()
+		s := c.s.Add(c.toBeAdded...).Remove(c.toBeRemoved...)
+		if !s.IsEqual(c.expected) || !c.expected.IsEqual(s) {
+			t.Errorf("Expected %v. Got %v.", c.expected, s)
+		}
+		if !c.s.IsEqual(set.New(before...)) {
+			t.Errorf("Expected %v. Got %v.", before, c.s)
+		}
+	}
+}
+
+func TestSetLarge(t *testing.T) {
+	versions := []Set{NewSet()}
+	for i := 0; i < 10000; i++ {
+		versions = append(versions, versions[i].Add(i))
+	}
+	for n, s := range versions {
+		if s.Len() != n {
+			t.Errorf("Expected %v. Got %v.", n, s.Len())
+		}
+	}
+	s := versions[10000]
+	for i := 0; i < 10000; i++ {
+		if !s.Has(i) || versions[i].Has(i) {
+			t.Errorf("Expected %v. Got %v.", true, false)
+		}
+	}
+	for i := 0; i < 10000; i += 2 {
+		s = s.Remove(i)
+	}
+	if s.Len() != 5000 || s.Has(0) || !s.Has(1) || !versions[10000].Has(0) {
+		t.Errorf("Expected %v. Got %v.", 5000, s.Len())
+	}
+	for i := 1; i < 10000; i += 2 {
+		s = s.Remove(i)
+	}
+	if !s.IsEmpty() {
+		t.Errorf("Expected %v. Got %v.", true, false)
+	}
+}
+
+func TestSetReadOnly(t *testing.T) {
+	s := NewSet(1, 2, 3)
+	cases := []struct {
+		t          set.ReadOnly
+		isSubset   bool
+		isSuperset bool
+	}{
+		{set.New(1, 2), true, false},
+		{NewSet(1, 2, 3, 4), false, true},
+		{set.NewSync(1, 2, 3), true, true},
+	}
+	for _, c := range cases {
+		if s.IsSubset(c.t) != c.isSubset {
+			t.Errorf("Expected %v. Got %v.", c.isSubset, !c.isSubset)
+		}
+		if s.IsSuperset(c.t) != c.isSuperset {
+			t.Errorf("Expected %v. Got %v.", c.isSuperset, !c.isSuperset)
+		}
+	}
+	if s.Add(3) != s || s.Remove(4) != s {
+		t.Errorf("Expected %v. Got %v.", s, s.Add(3))
+	}
+	if NewSet(1).String() != "[1]" {
+		t.Errorf("Expected %v. Got %v.", "[1]", NewSet(1).String())
+	}
+	mutable := s.CopySet()
+	mutable.Add(4)
+	if s.Has(4) || !set.New(1, 2, 3).IsEqual(s) {
+		t.Errorf("Expected %v. Got %v.", false, true)
+	}
+}
+
+func TestCollisions(t *testing.T) {
+	var n node = &leaf{7, "a", 1}
+	n, _ = n.put(7, 0, "b", 2)
+	n, _ = n.put(7, 0, "c", 3)
+	if _, ok := n.(*collision); !ok {
+		t.Errorf("Expected %v. Got %T.", "*collision", n)
+	}
+	n, _ = n.put(7, 0, "b", 20)
+	n, _ = n.put(7, 0, "c", []int{3})
+	n, _ = n.put(7, 0, "c", []int{3})
+	n, _ = n.put(7, 0, "c", 3)
+	n, added := n.put(7<<40, 0, "d", 4)
+	if !added {
+		t.Errorf("Expected %v. Got %v.", true, added)
+	}
+	cases := []struct {
+		hash uint64
+		k    interface{}
+		v    interface{}
+	}{
+		{7, "a", 1},
+		{7, "b", 20},
+		{7, "c", 3},
+		{7 << 40, "d", 4},
+	}
+	for _, c := range cases {
+		if v, ok := n.get(c.hash, 0, c.k); !ok || v != c.v {
+			t.Errorf("Expected %v. Got %v.", c.v, v)
+		}
+	}
+	for _, c := range cases {
+		var removed bool
+		if n, removed = n.remove(c.hash, 0, c.k); !removed {
+			t.Errorf("Expected %v. Got %v.", true, removed)
+		}
+		if n != nil {
+			if _, ok := n.get(c.hash, 0, c.k); ok {
+				t.Errorf("Expected %v. Got %v.", false, ok)
+			}
+		}
+	}
+	if n != nil {
+		t.Errorf("Expected %v. Got %v.", nil, n)
+	}
+}
+
+func TestHashmap(t *testing.T) {
+	h := NewHashmap("a", 1, "b", 2)
+	h2 := h.Put("c", 3).Put("a", 10).Remove("b")
+	cases := []struct {
+		h        Hashmap
+		expected hashmap.Interface
+	}{
+		{h, hashmap.New("a", 1, "b", 2)},
+		{h2, hashmap.New("a", 10, "c", 3)},
+		{h2.Remove("a", "c"), hashmap.New()},
+	}
+	for _, c := range cases {
+		if !c.h.IsEqual(c.expected) || !c.expected.IsEqual(c.h) {
+			t.Errorf("Expected %v. Got %v.", c.expected, c.h)
+		}
+	}
+	if v, err := h2.Get("a"); err != nil || v != 10 {
+		t.Errorf("Expected %v. Got %v.", 10, v)
+	}
+	if _, err := h2.Get("b"); err == nil {
+		t.Errorf("Expected %v. Got %v.", "error", err)
+	}
+	if k, err := h2.KeyOf(3); err != nil || k != "c" {
+		t.Errorf("Expected %v. Got %v.", "c", k)
+	}
+	if _, err := h2.KeyOf(2); err == nil {
+		t.Errorf("Expected %v. Got %v.", "error", err)
+	}
+	if !h.Has("a", "b") || h.Has("c") || !h.HasValue(1, 2) || h.HasValue(3) {
+		t.Errorf("Expected %v. Got %v.", true, false)
+	}
+	if h.Put("a", 1) != h || h.Remove("z") != h {
+		t.Errorf("Expected %v. Got %v.", h, h.Put("a", 1))
+	}
+	if len(h.Keys()) != 2 || len(h.Values()) != 2 || h.Len() != 2 || h.IsEmpty() {
+		t.Errorf("Expected %v. Got %v.", 2, h.Len())
+	}
+	if h.String() != "map[a:1 b:2]" {
+		t.Errorf("Expected %v. Got %v.", "map[a:1 b:2]", h.String())
+	}
+	slices := NewHashmap("a", []int{1}).Put("a", []int{2}).Put("a", []int{2})
+	if v, _ := slices.Get("a"); len(v.([]int)) != 1 || v.([]int)[0] != 2 {
+		t.Errorf("Expected %v. Got %v.", []int{2}, v)
+	}
+	mutable := h.Copy()
+	mutable.Put("z", 26)
+	if h.Has("z") || h.IsEqual(mutable) {
+		t.Errorf("Expected %v. Got %v.", false, true)
+	}
+}
+
+func TestPointerKeys(t *testing.T) {
+	type box struct{ n int }
+	k := &box{1}
+	h := NewHashmap(k, "k")
+	for i := 0; i < 100; i++ {
+		h = h.Put(i, i)
+	}
+	k.n = 2
+	if v, err := h.Get(k); err != nil || v != "k" || !h.Has(k) {
+		t.Errorf("Expected %v. Got %v.", "k", v)
+	}
+	if h = h.Put(k, "k"); h.Len() != 101 {
+		t.Errorf("Expected %v. Got %v.", 101, h.Len())
+	}
+	if h.Has(&box{2}) {
+		t.Errorf("Expected %v. Got %v.", false, true)
+	}
+}
+
+func TestArray(t *testing.T) {
+	a := NewArray(1, 2, 3)
+	b := a.Add(4).ReplaceAt(0, 0)
+	cases := []struct {
+		a        Array
+		expected array.Interface
+	}{
+		{a, array.New(1, 2, 3)},
+		{b, array.New(0, 2, 3, 4)},
+		{b.RemoveLast().RemoveLast(), array.New(0, 2)},
+		{NewArray(1).RemoveLast(), array.New()},
+	}
+	for _, c := range cases {
+		if !c.a.IsEqual(c.expected) || !c.expected.IsEqual(c.a) {
+			t.Errorf("Expected %v. Got %v.", c.expected, c.a)
+		}
+	}
+	if i, err := b.IndexOf(3); err != nil || i != 2 {
+		t.Errorf("Expected %v. Got %v.", 2, i)
+	}
+	if _, err := b.IndexOf(42); err != array.ErrNotFound {
+		t.Errorf("Expected %v. Got %v.", array.ErrNotFound, err)
+	}
+	if !b.Has(0, 4) || b.Has(1) || b.String() != "[0 2 3 4]" {
+		t.Errorf("Expected %v. Got %v.", "[0 2 3 4]", b)
+	}
+	mutable := b.CopyArr()
+	mutable.Add(5)
+	if b.Len() != 4 {
+		t.Errorf("Expected %v. Got %v.", 4, b.Len())
+	}
+	for _, f := range []func(){
+		func() { a.Get(3) },
+		func() { a.Get(-1) },
+		func() { a.ReplaceAt(3, 0) },
+		func() { NewArray().RemoveLast() },
+	} {
+		func() {
+			defer func() {
+				if r := recover(); r != array.ErrIndexOutOfBounds {
+					t.Errorf("Expected %v. Got %v.", array.ErrIndexOutOfBounds, r)
+				}
+			}()
+			f()
+		}()
+	}
+}
+
+func TestArrayLarge(t *testing.T) {
+	const n = 40000
+	versions := make([]Array, 0, n+1)
+	versions = append(versions, NewArray())
+	for i := 0; i < n; i++ {
+		versions = append(versions, versions[i].Add(i))
+	}
+	for _, size := range []int{0, 1, 32, 33, 1024, 1056, 1057, n} {
+		a := versions[size]
+		if a.Len() != size {
+			t.Errorf("Expected %v. Got %v.", size, a.Len())
+		}
+		for i := 0; i < size; i++ {
+			if a.Get(i) != i {
+				t.Errorf("Expected %v. Got %v.", i, a.Get(i))
+			}
+		}
+	}
+	a := versions[n]
+	for i := 0; i < n; i += 7 {
+		a = a.ReplaceAt(i, -i)
+	}
+	for i := 0; i < n; i++ {
+		expected := i
+		if i%7 == 0 {
+			expected = -i
+		}
+		if a.Get(i) != expected || versions[n].Get(i) != i {
+			t.Errorf("Expected %v. Got %v.", expected, a.Get(i))
+		}
+	}
+	for size := n; size > 0; size-- {
+		if a.Len() != size || a.Get(size-1) != versions[n].Get(size-1) && a.Get(size-1) != -(size-1) {
+			t.Errorf("Expected %v. Got %v.", size, a.Len())
+		}
+		a = a.RemoveLast()
+	}
+	if !a.IsEmpty() {
+		t.Errorf("Expected %v. Got %v.", true, false)
+	}
+	for i := 0; i < n; i++ {
+		if versions[n].Get(i) != i {
+			t.Errorf("Expected %v. Got %v.", i, versions[n].Get(i))
+		}
+	}
+	if len(versions[n].Slice()) != n {
+		t.Errorf("Expected %v. Got %v.", n, len(versions[n].Slice()))
+	}
+}
+
+func TestConcurrency(t *testing.T) {
+	s, h, a := NewSet(), NewHashmap(), NewArray()
+	for i := 0; i < 1000; i++ {
+		s, h, a = s.Add(i), h.Put(i, i), a.Add(i)
+	}
+	var wg sync.WaitGroup
+	for g := 0; g < 4; g++ {
+		wg.Add(1)
+		go func(g int) {
+			defer wg.Done()
+			s, h, a := s, h, a
+			for i := 0; i < 1000; i++ {
+				s, h, a = s.Remove(i).Add(-g), h.Put(i, g), a.ReplaceAt(i, g)
+			}
+			if s.Len() != 1 || h.Len() != 1000 || a.Get(999) != g {
+				t.Errorf("Expected %v. Got %v.", 1, s.Len())
+			}
+		}(g)
+	}
+	wg.Wait()
+	if s.Len() != 1000 || !h.HasValue(999) || a.Get(999) != 999 {
+		t.Errorf("Expected %v. Got %v.", 1000, s.Len())
+	}
+}
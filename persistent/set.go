@@ -0,0 +1,117 @@
+package persistent
+
+import (
+	"fmt"
+
+	"github.com/khezen/struct/collection"
+	"github.com/khezen/struct/internal/format"
+	"github.com/khezen/struct/set"
+)
+
+type persistentSet struct {
+	t trie
+}
+
+// NewSet creates an immutable set holding the given items
+func NewSet(items ...interface{}) Set {
+	return (&persistentSet{}).Add(items...)
+}
+
+// Add returns a set holding items in addition to those of s
+func (s *persistentSet) Add(items ...interface{}) Set {
+	t := s.t
+	for _, item := range items {
+		t = t.put(item, struct{}{})
+	}
+	if t.root == s.t.root {
+		return s
+	}
+	return &persistentSet{t}
+}
+
+// Remove returns a set holding items of s except the given ones
+func (s *persistentSet) Remove(items ...interface{}) Set {
+	t := s.t
+	for _, item := range items {
+		t = t.remove(item)
+	}
+	if t.root == s.t.root {
+		return s
+	}
+	return &persistentSet{t}
+}
+
+func (s *persistentSet) Has(items ...interface{}) bool {
+	for _, item := range items {
+		if _, ok := s.t.get(item); !ok {
+			return false
+		}
+	}
+	return true
+}
+
+func (s *persistentSet) Each(f func(item interface{}) bool) {
+	s.t.each(func(k, v interface{}) bool {
+		return f(k)
+	})
+}
+
+func (s *persistentSet) Len() int {
+	return s.t.size
+}
+
+func (s *persistentSet) IsEmpty() bool {
+	return s.t.size == 0
+}
+
+func (s *persistentSet) IsEqual(t collection.ReadOnly) bool {
+	if s.Len() != t.Len() {
+		return false
+	}
+	equal := true
+	t.Each(func(item interface{}) bool {
+		equal = s.Has(item)
+		return equal
+	})
+	return equal
+}
+
+// IsSubset tests whether t is a subset of s.
+func (s *persistentSet) IsSubset(t set.ReadOnly) bool {
+	subset := true
+	t.Each(func(item interface{}) bool {
+		subset = s.Has(item)
+		return subset
+	})
+	return subset
+}
+
+// IsSuperset tests whether t is a superset of s.
+func (s *persistentSet) IsSuperset(t set.ReadOnly) bool {
+	return t.IsSubset(s)
+}
+
+func (s *persistentSet) String() string {
+	return format.String(s)
+}
+
+// Format implements fmt.Formatter: %v prints the items, sorted when they are
+// orderable, %+v adds the type and the length and %#v the Go syntax. A
+// precision truncates the items: %.3v prints the 3 first followed by "...".
+func (s *persistentSet) Format(f fmt.State, verb rune) {
+	format.Set(f, verb, "persistent.Set", "persistent.NewSet", s.Slice())
+}
+
+func (s *persistentSet) Slice() []interface{} {
+	items := make([]interface{}, 0, s.Len())
+	s.Each(func(item interface{}) bool {
+		items = append(items, item)
+		return true
+	})
+	return items
+}
+
+// CopySet returns a mutable copy of s
+func (s *persistentSet) CopySet() set.Interface {
+	return set.New(s.Slice()...)
+}
@@ -0,0 +1,369 @@
+package roaring
+
+import (
+	"math/bits"
+	"sort"
+)
+
+const (
+	// arrayMaxSize is the largest cardinality stored as an array container.
+	arrayMaxSize = 4096
+	bitmapWords  = 1024
+)
+
+// container holds the 16 least significant bits of the items sharing the same
+// 16 most significant bits. Mutations return the container to use from then
+// on, since they may change its representation. A container is never empty.
+type container interface {
+	add(x uint16) container
+	remove(x uint16) container
+	has(x uint16) bool
+	card() int
+	each(f func(x uint16) bool) bool
+	clone() container
+	toBitmap() *bitmapContainer
+}
+
+type arrayContainer []uint16
+
+type bitmapContainer struct {
+	w [bitmapWords]uint64
+	n int
+}
+
+// interval covers [start, start+length] inclusive, as in the Roaring format.
+type interval struct {
+	start, length uint16
+}
+
+type runContainer []interval
+
+// normalize returns the array or bitmap representation expected for a non-run
+// container of that cardinality, or nil if b is empty.
+func normalize(b *bitmapContainer) container {
+	switch {
+	case b.n == 0:
+		return nil
+	case b.n <= arrayMaxSize:
+		a := make(arrayContainer, 0, b.n)
+		b.each(func(x uint16) bool {
+			a = append(a, x)
+			return true
+		})
+		return a
+	default:
+		return b
+	}
+}
+
+// optimize returns the most compact representation of c, runs included.
+func optimize(c container) container {
+	runs := toRuns(c)
+	runSize := 2 + 4*len(runs)
+	size := 8192
+	if c.card() <= arrayMaxSize {
+		size = 2 * c.card()
+	}
+	if runSize < size {
+		return runs
+	}
+	if _, ok := c.(runContainer); ok {
+		return normalize(c.toBitmap())
+	}
+	return c
+}
+
+func toRuns(c container) runContainer {
+	if r, ok := c.(runContainer); ok {
+		return r
+	}
+	runs := make(runContainer, 0)
+	c.each(func(x uint16) bool {
+		last := len(runs) - 1
+		if last >= 0 && uint32(runs[last].start)+uint32(runs[last].length)+1 == uint32(x) {
+			runs[last].length++
+		} else {
+			runs = append(runs, interval{x, 0})
+		}
+		return true
+	})
+	return runs
+}
+
+func (a arrayContainer) search(x uint16) int {
+	return sort.Search(len(a), func(i int) bool { return a[i] >= x })
+}
+
+func (a arrayContainer) add(x uint16) container {
+	i := a.search(x)
+	if i < len(a) && a[i] == x {
+		return a
+	}
+	if len(a) >= arrayMaxSize {
+		b := a.toBitmap()
+		return b.add(x)
+	}
+	a = append(a, 0)
+	copy(a[i+1:], a[i:])
+	a[i] = x
+	return a
+}
+
+func (a arrayContainer) remove(x uint16) container {
+	i := a.search(x)
+	if i == len(a) || a[i] != x {
+		return a
+	}
+	if len(a) == 1 {
+		return nil
+	}
+	return append(a[:i], a[i+1:]...)
+}
+
+func (a arrayContainer) has(x uint16) bool {
+	i := a.search(x)
+	return i < len(a) && a[i] == x
+}
+
+func (a arrayContainer) card() int {
+	return len(a)
+}
+
+func (a arrayContainer) each(f func(x uint16) bool) bool {
+	for _, x := range a {
+		if !f(x) {
+			return false
+		}
+	}
+	return true
+}
+
+func (a arrayContainer) clone() container {
+	cpy := make(arrayContainer, len(a))
+	copy(cpy, a)
+	return cpy
+}
+
+func (a arrayContainer) toBitmap() *bitmapContainer {
+	b := &bitmapContainer{}
+	for _, x := range a {
+		b.w[x/64] |= 1 << (x % 64)
+	}
+	b.n = len(a)
+	return b
+}
+
+func (b *bitmapContainer) add(x uint16) container {
+	if !b.has(x) {
+		b.w[x/64] |= 1 << (x % 64)
+		b.n++
+	}
+	return b
+}
+
+func (b *bitmapContainer) remove(x uint16) container {
+	if !b.has(x) {
+		return b
+	}
+	b.w[x/64] &^= 1 << (x % 64)
+	b.n--
+	if b.n <= arrayMaxSize {
+		return normalize(b)
+	}
+	return b
+}
+
+func (b *bitmapContainer) has(x uint16) bool {
+	return b.w[x/64]&(1<<(x%64)) != 0
+}
+
+func (b *bitmapContainer) card() int {
+	return b.n
+}
+
+func (b *bitmapContainer) each(f func(x uint16) bool) bool {
+	for i, w := range b.w {
+		for w != 0 {
+			tz := bits.TrailingZeros64(w)
+			if !f(uint16(i*64 + tz)) {
+				return false
+			}
+			w &^= 1 << uint(tz)
+		}
+	}
+	return true
+}
+
+func (b *bitmapContainer) clone() container {
+	cpy := *b
+	return &cpy
+}
+
+func (b *bitmapContainer) toBitmap() *bitmapContainer {
+	return b.clone().(*bitmapContainer)
+}
+
+// count recomputes the cardinality after word operations.
+func (b *bitmapContainer) count() {
+	b.n = 0
+	for _, w := range b.w {
+		b.n += bits.OnesCount64(w)
+	}
+}
+
+// Run containers are read only. Mutations convert them back to array or
+// bitmap containers, and RunOptimize converts them again.
+func (r runContainer) add(x uint16) container {
+	if r.has(x) {
+		return r
+	}
+	return normalize(r.toBitmap()).add(x)
+}
+
+func (r runContainer) remove(x uint16) container {
+	if !r.has(x) {
+		return r
+	}
+	c := normalize(r.toBitmap())
+	return c.remove(x)
+}
+
+func (r runContainer) has(x uint16) bool {
+	i := sort.Search(len(r), func(i int) bool { return uint32(r[i].start)+uint32(r[i].length) >= uint32(x) })
+	return i < len(r) && r[i].start <= x
+}
+
+func (r runContainer) card() int {
+	n := 0
+	for _, run := range r {
+		n += int(run.length) + 1
+	}
+	return n
+}
+
+func (r runContainer) each(f func(x uint16) bool) bool {
+	for _, run := range r {
+		for x := uint32(run.start); x <= uint32(run.start)+uint32(run.length); x++ {
+			if !f(uint16(x)) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+func (r runContainer) clone() container {
+	cpy := make(runContainer, len(r))
+	copy(cpy, r)
+	return cpy
+}
+
+func (r runContainer) toBitmap() *bitmapContainer {
+	b := &bitmapContainer{}
+	r.each(func(x uint16) bool {
+		b.w[x/64] |= 1 << (x % 64)
+		return true
+	})
+	b.n = r.card()
+	return b
+}
+
+// and returns the intersection of a and b, or nil if it is empty.
+func and(a, b container) container {
+	if x, ok := a.(arrayContainer); ok {
+		return filter(x, b.has, true)
+	}
+	if y, ok := b.(arrayContainer); ok {
+		return filter(y, a.has, true)
+	}
+	x, y := a.toBitmap(), b.toBitmap()
+	for i := range x.w {
+		x.w[i] &= y.w[i]
+	}
+	x.count()
+	return normalize(x)
+}
+
+// andNot returns the items of a which are not in b, or nil if there is none.
+func andNot(a, b container) container {
+	if x, ok := a.(arrayContainer); ok {
+		return filter(x, b.has, false)
+	}
+	x, y := a.toBitmap(), b.toBitmap()
+	for i := range x.w {
+		x.w[i] &^= y.w[i]
+	}
+	x.count()
+	return normalize(x)
+}
+
+func or(a, b container) container {
+	x, okx := a.(arrayContainer)
+	y, oky := b.(arrayContainer)
+	if okx && oky && len(x)+len(y) <= arrayMaxSize {
+		return mergeArrays(x, y, false)
+	}
+	bx, by := a.toBitmap(), b.toBitmap()
+	for i := range bx.w {
+		bx.w[i] |= by.w[i]
+	}
+	bx.count()
+	return normalize(bx)
+}
+
+func xor(a, b container) container {
+	x, okx := a.(arrayContainer)
+	y, oky := b.(arrayContainer)
+	if okx && oky && len(x)+len(y) <= arrayMaxSize {
+		return mergeArrays(x, y, true)
+	}
+	bx, by := a.toBitmap(), b.toBitmap()
+	for i := range bx.w {
+		bx.w[i] ^= by.w[i]
+	}
+	bx.count()
+	return normalize(bx)
+}
+
+// filter returns the items of a for which pred returns keep.
+func filter(a arrayContainer, pred func(x uint16) bool, keep bool) container {
+	result := make(arrayContainer, 0, len(a))
+	for _, x := range a {
+		if pred(x) == keep {
+			result = append(result, x)
+		}
+	}
+	if len(result) == 0 {
+		return nil
+	}
+	return result
+}
+
+// mergeArrays returns the union of two sorted arrays, or their symmetric
+// difference if exclusive is true.
+func mergeArrays(x, y arrayContainer, exclusive bool) container {
+	result := make(arrayContainer, 0, len(x)+len(y))
+	i, j := 0, 0
+	for i < len(x) && j < len(y) {
+		switch {
+		case x[i] < y[j]:
+			result = append(result, x[i])
+			i++
+		case x[i] > y[j]:
+			result = append(result, y[j])
+			j++
+		default:
+			if !exclusive {
+				result = append(result, x[i])
+			}
+			i++
+			j++
+		}
+	}
+	result = append(result, x[i:]...)
+	result = append(result, y[j:]...)
+	if len(result) == 0 {
+		return nil
+	}
+	return result
+}
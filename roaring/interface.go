@@ -0,0 +1,46 @@
+// Package roaring provides both threadsafe and non-threadsafe implementations of
+// a compressed set of uint32 based on Roaring bitmaps. Items are partitioned by
+// their 16 most significant bits into containers stored as sorted arrays,
+// bitmaps or runs, whichever is the most compact. Bitmaps implement
+// set.Interface and serialize to the portable Roaring format.
+package roaring
+
+import (
+	"encoding"
+	"errors"
+	"io"
+
+	"github.com/khezen/struct/set"
+)
+
+// Interface describes a Roaring bitmap
+type Interface interface {
+	set.Interface
+	And(Interface) Interface
+	Or(Interface) Interface
+	Xor(Interface) Interface
+	AndNot(Interface) Interface
+	RunOptimize()
+	Iterator() Iterator
+	CopyBitmap() Interface
+
+	io.WriterTo
+	io.ReaderFrom
+	encoding.BinaryMarshaler
+	encoding.BinaryUnmarshaler
+}
+
+// Iterator walks through the items of a bitmap in ascending order
+type Iterator interface {
+	HasNext() bool
+	Next() uint32
+}
+
+var (
+	// ErrBadItem - Roaring bitmaps only hold uint32
+	ErrBadItem = errors.New("ErrBadItem - Roaring bitmaps only hold uint32")
+	// ErrNoMoreItems - the iterator went past the last item
+	ErrNoMoreItems = errors.New("ErrNoMoreItems - the iterator went past the last item")
+	// ErrBadFormat - data does not follow the Roaring serialization format
+	ErrBadFormat = errors.New("ErrBadFormat - data does not follow the Roaring serialization format")
+)
@@ -0,0 +1,498 @@
+package roaring
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/khezen/struct/collection"
+	"github.com/khezen/struct/internal/format"
+	"github.com/khezen/struct/set"
+)
+
+// bitmap defines a non-thread safe Roaring bitmap. keys are sorted and
+// containers[i] holds the items whose 16 most significant bits are keys[i].
+type bitmap struct {
+	keys       []uint16
+	containers []container
+}
+
+// New creates a non thread safe Roaring bitmap
+func New(items ...uint32) Interface {
+	b := &bitmap{}
+	for _, item := range items {
+		b.add(item)
+	}
+	return b
+}
+
+// FromSet creates a Roaring bitmap holding the items of c. It returns
+// ErrBadItem if any item is not a uint32.
+func FromSet(c collection.Interface) (Interface, error) {
+	if conv, ok := c.(Interface); ok {
+		return conv.CopyBitmap(), nil
+	}
+	b := &bitmap{}
+	var err error
+	c.Each(func(item interface{}) bool {
+		x, ok := item.(uint32)
+		if !ok {
+			err = ErrBadItem
+			return false
+		}
+		b.add(x)
+		return true
+	})
+	if err != nil {
+		return nil, err
+	}
+	return b, nil
+}
+
+func split(x uint32) (uint16, uint16) {
+	return uint16(x >> 16), uint16(x)
+}
+
+func (b *bitmap) index(key uint16) (int, bool) {
+	i := sort.Search(len(b.keys), func(i int) bool { return b.keys[i] >= key })
+	return i, i < len(b.keys) && b.keys[i] == key
+}
+
+func (b *bitmap) add(x uint32) {
+	key, low := split(x)
+	i, ok := b.index(key)
+	if ok {
+		b.containers[i] = b.containers[i].add(low)
+		return
+	}
+	b.keys = append(b.keys, 0)
+	copy(b.keys[i+1:], b.keys[i:])
+	b.keys[i] = key
+	b.containers = append(b.containers, nil)
+	copy(b.containers[i+1:], b.containers[i:])
+	b.containers[i] = arrayContainer{low}
+}
+
+func (b *bitmap) has(x uint32) bool {
+	key, low := split(x)
+	i, ok := b.index(key)
+	return ok && b.containers[i].has(low)
+}
+
+func (b *bitmap) remove(x uint32) {
+	key, low := split(x)
+	i, ok := b.index(key)
+	if !ok {
+		return
+	}
+	if c := b.containers[i].remove(low); c != nil {
+		b.containers[i] = c
+		return
+	}
+	b.keys = append(b.keys[:i], b.keys[i+1:]...)
+	b.containers = append(b.containers[:i], b.containers[i+1:]...)
+}
+
+// Add includes the specified items. It panics with ErrBadItem if any item is
+// not a uint32.
+func (b *bitmap) Add(items ...interface{}) {
+	for _, item := range items {
+		x, ok := item.(uint32)
+		if !ok {
+			panic(ErrBadItem)
+		}
+		b.add(x)
+	}
+}
+
+func (b *bitmap) Remove(items ...interface{}) {
+	for _, item := range items {
+		if x, ok := item.(uint32); ok {
+			b.remove(x)
+		}
+	}
+}
+
+// Pop deletes and returns the lowest item. If the bitmap is empty, nil is returned.
+func (b *bitmap) Pop() interface{} {
+	if len(b.keys) == 0 {
+		return nil
+	}
+	var low uint16
+	b.containers[0].each(func(x uint16) bool {
+		low = x
+		return false
+	})
+	x := uint32(b.keys[0])<<16 | uint32(low)
+	b.remove(x)
+	return x
+}
+
+func (b *bitmap) Replace(item, substitute interface{}) {
+	if b.Has(item) {
+		b.Remove(item)
+		b.Add(substitute)
+	}
+}
+
+func (b *bitmap) Has(items ...interface{}) bool {
+	for _, item := range items {
+		x, ok := item.(uint32)
+		if !ok || !b.has(x) {
+			return false
+		}
+	}
+	return true
+}
+
+// Each traverses the items in ascending order until all have been visited or
+// the closure returns false.
+func (b *bitmap) Each(f func(item interface{}) bool) {
+	for i, c := range b.containers {
+		high := uint32(b.keys[i]) << 16
+		if !c.each(func(x uint16) bool { return f(high | uint32(x)) }) {
+			return
+		}
+	}
+}
+
+func (b *bitmap) Len() int {
+	n := 0
+	for _, c := range b.containers {
+		n += c.card()
+	}
+	return n
+}
+
+func (b *bitmap) Clear() {
+	b.keys = nil
+	b.containers = nil
+}
+
+func (b *bitmap) IsEmpty() bool {
+	return len(b.keys) == 0
+}
+
+// bitmapOf returns the bitmap behind t, if any. Callers must hold the lock of t.
+func bitmapOf(t collection.ReadOnly) (*bitmap, bool) {
+	switch conv := t.(type) {
+	case *bitmap:
+		return conv, true
+	case *bitmapSync:
+		return &conv.bitmap, true
+	}
+	return nil, false
+}
+
+func (b *bitmap) IsEqual(t collection.ReadOnly) bool {
+	// Force locking only if given bitmap is threadsafe.
+	if conv, ok := t.(*bitmapSync); ok {
+		conv.l.RLock()
+		defer conv.l.RUnlock()
+	}
+	if other, ok := bitmapOf(t); ok {
+		if len(b.keys) != len(other.keys) {
+			return false
+		}
+		for i, key := range b.keys {
+			if key != other.keys[i] || b.containers[i].card() != other.containers[i].card() {
+				return false
+			}
+			if andNot(b.containers[i], other.containers[i]) != nil {
+				return false
+			}
+		}
+		return true
+	}
+	if b.Len() != t.Len() {
+		return false
+	}
+	equal := true
+	t.Each(func(item interface{}) bool {
+		equal = b.Has(item)
+		return equal
+	})
+	return equal
+}
+
+// IsSubset tests whether t is a subset of b.
+func (b *bitmap) IsSubset(t set.ReadOnly) bool {
+	if conv, ok := t.(*bitmapSync); ok {
+		conv.l.RLock()
+		defer conv.l.RUnlock()
+	}
+	if other, ok := bitmapOf(t); ok {
+		return other.andNot(b).IsEmpty()
+	}
+	subset := true
+	t.Each(func(item interface{}) bool {
+		subset = b.Has(item)
+		return subset
+	})
+	return subset
+}
+
+// IsSuperset tests whether t is a superset of b.
+func (b *bitmap) IsSuperset(t set.ReadOnly) bool {
+	if conv, ok := t.(*bitmapSync); ok {
+		conv.l.RLock()
+		defer conv.l.RUnlock()
+		return b.andNot(&conv.bitmap).IsEmpty()
+	}
+	return t.IsSubset(b)
+}
+
+// Merge adds the items of t to b. It panics with ErrBadItem if t holds
+// anything but uint32.
+func (b *bitmap) Merge(t collection.Interface) {
+	if conv, ok := t.(*bitmapSync); ok {
+		conv.l.RLock()
+		defer conv.l.RUnlock()
+	}
+	if other, ok := bitmapOf(t); ok {
+		*b = *b.or(other)
+		return
+	}
+	t.Each(func(item interface{}) bool {
+		b.Add(item)
+		return true
+	})
+}
+
+// Separate removes the items of t from b.
+func (b *bitmap) Separate(t collection.Interface) {
+	if conv, ok := t.(*bitmapSync); ok {
+		conv.l.RLock()
+		defer conv.l.RUnlock()
+	}
+	if other, ok := bitmapOf(t); ok {
+		*b = *b.andNot(other)
+		return
+	}
+	b.Remove(t.Slice()...)
+}
+
+// Retain removes the items of b which are not in t.
+func (b *bitmap) Retain(t collection.Interface) {
+	if conv, ok := t.(*bitmapSync); ok {
+		conv.l.RLock()
+		defer conv.l.RUnlock()
+	}
+	if other, ok := bitmapOf(t); ok {
+		*b = *b.and(other)
+		return
+	}
+	removed := make([]interface{}, 0)
+	b.Each(func(item interface{}) bool {
+		if !t.Has(item) {
+			removed = append(removed, item)
+		}
+		return true
+	})
+	b.Remove(removed...)
+}
+
+// And returns a new bitmap holding the items in both b and t.
+func (b *bitmap) And(t Interface) Interface {
+	return b.operate(t, (*bitmap).and)
+}
+
+// Or returns a new bitmap holding the items of both b and t.
+func (b *bitmap) Or(t Interface) Interface {
+	return b.operate(t, (*bitmap).or)
+}
+
+// Xor returns a new bitmap holding the items in either b or t but not both.
+func (b *bitmap) Xor(t Interface) Interface {
+	return b.operate(t, (*bitmap).xor)
+}
+
+// AndNot returns a new bitmap holding the items of b which are not in t.
+func (b *bitmap) AndNot(t Interface) Interface {
+	return b.operate(t, (*bitmap).andNot)
+}
+
+// operate applies op to b and t, converting t to a bitmap first if needed.
+func (b *bitmap) operate(t Interface, op func(b, other *bitmap) *bitmap) Interface {
+	if conv, ok := t.(*bitmapSync); ok {
+		conv.l.RLock()
+		defer conv.l.RUnlock()
+	}
+	other, ok := bitmapOf(t)
+	if !ok {
+		conv, err := FromSet(t)
+		if err != nil {
+			panic(err)
+		}
+		other, _ = bitmapOf(conv)
+	}
+	return op(b, other)
+}
+
+func (b *bitmap) and(other *bitmap) *bitmap {
+	result := &bitmap{}
+	i, j := 0, 0
+	for i < len(b.keys) && j < len(other.keys) {
+		switch {
+		case b.keys[i] < other.keys[j]:
+			i++
+		case b.keys[i] > other.keys[j]:
+			j++
+		default:
+			result.push(b.keys[i], and(b.containers[i], other.containers[j]))
+			i++
+			j++
+		}
+	}
+	return result
+}
+
+func (b *bitmap) andNot(other *bitmap) *bitmap {
+	result := &bitmap{}
+	i, j := 0, 0
+	for i < len(b.keys) {
+		switch {
+		case j == len(other.keys) || b.keys[i] < other.keys[j]:
+			result.push(b.keys[i], b.containers[i].clone())
+			i++
+		case b.keys[i] > other.keys[j]:
+			j++
+		default:
+			result.push(b.keys[i], andNot(b.containers[i], other.containers[j]))
+			i++
+			j++
+		}
+	}
+	return result
+}
+
+func (b *bitmap) or(other *bitmap) *bitmap {
+	return b.merge(other, or)
+}
+
+func (b *bitmap) xor(other *bitmap) *bitmap {
+	return b.merge(other, xor)
+}
+
+// merge walks the keys of both bitmaps, applying op to shared containers and
+// copying the others.
+func (b *bitmap) merge(other *bitmap, op func(a, b container) container) *bitmap {
+	result := &bitmap{}
+	i, j := 0, 0
+	for i < len(b.keys) || j < len(other.keys) {
+		switch {
+		case j == len(other.keys) || (i < len(b.keys) && b.keys[i] < other.keys[j]):
+			result.push(b.keys[i], b.containers[i].clone())
+			i++
+		case i == len(b.keys) || b.keys[i] > other.keys[j]:
+			result.push(other.keys[j], other.containers[j].clone())
+			j++
+		default:
+			result.push(b.keys[i], op(b.containers[i], other.containers[j]))
+			i++
+			j++
+		}
+	}
+	return result
+}
+
+// push appends a container with a key greater than any other, skipping empty ones.
+func (b *bitmap) push(key uint16, c container) {
+	if c == nil {
+		return
+	}
+	b.keys = append(b.keys, key)
+	b.containers = append(b.containers, c)
+}
+
+// RunOptimize converts containers to run containers wherever that is more compact.
+func (b *bitmap) RunOptimize() {
+	for i, c := range b.containers {
+		b.containers[i] = optimize(c)
+	}
+}
+
+// Iterator returns an iterator over the items in ascending order. The
+// bitmap must not be modified while iterating.
+func (b *bitmap) Iterator() Iterator {
+	return &iterator{b: b}
+}
+
+// String returns a string representation of b in ascending order
+func (b *bitmap) String() string {
+	return format.String(b)
+}
+
+// Format implements fmt.Formatter: %v prints the items in ascending order,
+// %+v adds the type and the length and %#v the Go syntax. A precision
+// truncates the items: %.3v prints the 3 first followed by "...".
+func (b *bitmap) Format(f fmt.State, verb rune) {
+	format.Seq(f, verb, "roaring", "roaring.New", b.Slice())
+}
+
+// Slice returns the items in ascending order.
+func (b *bitmap) Slice() []interface{} {
+	items := make([]interface{}, 0, b.Len())
+	b.Each(func(item interface{}) bool {
+		items = append(items, item)
+		return true
+	})
+	return items
+}
+
+func (b *bitmap) copy() *bitmap {
+	cpy := &bitmap{
+		keys:       make([]uint16, len(b.keys)),
+		containers: make([]container, len(b.containers)),
+	}
+	copy(cpy.keys, b.keys)
+	for i, c := range b.containers {
+		cpy.containers[i] = c.clone()
+	}
+	return cpy
+}
+
+func (b *bitmap) CopyBitmap() Interface {
+	return b.copy()
+}
+
+func (b *bitmap) CopySet() set.Interface {
+	return b.copy()
+}
+
+func (b *bitmap) CopyCollection() collection.Interface {
+	return b.copy()
+}
+
+type iterator struct {
+	b      *bitmap
+	i      int // index of the current container
+	buffer []uint16
+	j      int // index in buffer
+}
+
+func (it *iterator) HasNext() bool {
+	for it.j >= len(it.buffer) {
+		if it.i >= len(it.b.containers) {
+			return false
+		}
+		c := it.b.containers[it.i]
+		it.buffer = it.buffer[:0]
+		c.each(func(x uint16) bool {
+			it.buffer = append(it.buffer, x)
+			return true
+		})
+		it.i++
+		it.j = 0
+	}
+	return true
+}
+
+// Next returns the next item. It panics with ErrNoMoreItems if there is none.
+func (it *iterator) Next() uint32 {
+	if !it.HasNext() {
+		panic(ErrNoMoreItems)
+	}
+	x := uint32(it.b.keys[it.i-1])<<16 | uint32(it.buffer[it.j])
+	it.j++
+	return x
+}
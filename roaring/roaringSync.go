@@ -0,0 +1,235 @@
+package roaring
+
+import (
+	"fmt"
+	"io"
+	"sync"
+
+	"github.com/khezen/struct/collection"
+	"github.com/khezen/struct/internal/format"
+	"github.com/khezen/struct/set"
+)
+
+// bitmapSync defines a thread safe Roaring bitmap.
+type bitmapSync struct {
+	bitmap
+	l sync.RWMutex // we name it because we don't want to expose it
+}
+
+// NewSync creates a thread safe Roaring bitmap
+func NewSync(items ...uint32) Interface {
+	return &bitmapSync{
+		*New(items...).(*bitmap),
+		sync.RWMutex{},
+	}
+}
+
+// self returns the unsynchronized bitmap if t is b itself, whose lock is
+// already held by the caller.
+func (b *bitmapSync) self(t collection.ReadOnly) collection.ReadOnly {
+	if conv, ok := t.(*bitmapSync); ok && conv == b {
+		return &b.bitmap
+	}
+	return t
+}
+
+func (b *bitmapSync) Add(items ...interface{}) {
+	if len(items) > 0 {
+		b.l.Lock()
+		defer b.l.Unlock()
+		b.bitmap.Add(items...)
+	}
+}
+
+func (b *bitmapSync) Remove(items ...interface{}) {
+	if len(items) > 0 {
+		b.l.Lock()
+		defer b.l.Unlock()
+		b.bitmap.Remove(items...)
+	}
+}
+
+func (b *bitmapSync) Pop() interface{} {
+	b.l.Lock()
+	defer b.l.Unlock()
+	return b.bitmap.Pop()
+}
+
+func (b *bitmapSync) Replace(item, substitute interface{}) {
+	b.l.Lock()
+	defer b.l.Unlock()
+	b.bitmap.Replace(item, substitute)
+}
+
+func (b *bitmapSync) Has(items ...interface{}) bool {
+	if len(items) > 0 {
+		b.l.RLock()
+		defer b.l.RUnlock()
+		return b.bitmap.Has(items...)
+	}
+	return true
+}
+
+func (b *bitmapSync) Each(f func(item interface{}) bool) {
+	b.l.RLock()
+	defer b.l.RUnlock()
+	b.bitmap.Each(f)
+}
+
+func (b *bitmapSync) Len() int {
+	b.l.RLock()
+	defer b.l.RUnlock()
+	return b.bitmap.Len()
+}
+
+func (b *bitmapSync) Clear() {
+	b.l.Lock()
+	defer b.l.Unlock()
+	b.bitmap.Clear()
+}
+
+func (b *bitmapSync) IsEmpty() bool {
+	b.l.RLock()
+	defer b.l.RUnlock()
+	return b.bitmap.IsEmpty()
+}
+
+func (b *bitmapSync) IsEqual(t collection.ReadOnly) bool {
+	b.l.RLock()
+	defer b.l.RUnlock()
+	return b.bitmap.IsEqual(b.self(t))
+}
+
+func (b *bitmapSync) IsSubset(t set.ReadOnly) bool {
+	b.l.RLock()
+	defer b.l.RUnlock()
+	return b.bitmap.IsSubset(b.self(t).(set.ReadOnly))
+}
+
+func (b *bitmapSync) IsSuperset(t set.ReadOnly) bool {
+	b.l.RLock()
+	defer b.l.RUnlock()
+	return b.bitmap.IsSuperset(b.self(t).(set.ReadOnly))
+}
+
+func (b *bitmapSync) Merge(t collection.Interface) {
+	b.l.Lock()
+	defer b.l.Unlock()
+	b.bitmap.Merge(b.self(t).(collection.Interface))
+}
+
+func (b *bitmapSync) Separate(t collection.Interface) {
+	b.l.Lock()
+	defer b.l.Unlock()
+	b.bitmap.Separate(b.self(t).(collection.Interface))
+}
+
+func (b *bitmapSync) Retain(t collection.Interface) {
+	b.l.Lock()
+	defer b.l.Unlock()
+	b.bitmap.Retain(b.self(t).(collection.Interface))
+}
+
+func (b *bitmapSync) And(t Interface) Interface {
+	b.l.RLock()
+	defer b.l.RUnlock()
+	return b.sync(b.bitmap.And(b.self(t).(Interface)))
+}
+
+func (b *bitmapSync) Or(t Interface) Interface {
+	b.l.RLock()
+	defer b.l.RUnlock()
+	return b.sync(b.bitmap.Or(b.self(t).(Interface)))
+}
+
+func (b *bitmapSync) Xor(t Interface) Interface {
+	b.l.RLock()
+	defer b.l.RUnlock()
+	return b.sync(b.bitmap.Xor(b.self(t).(Interface)))
+}
+
+func (b *bitmapSync) AndNot(t Interface) Interface {
+	b.l.RLock()
+	defer b.l.RUnlock()
+	return b.sync(b.bitmap.AndNot(b.self(t).(Interface)))
+}
+
+// sync wraps the result of an operation into a thread safe bitmap.
+func (b *bitmapSync) sync(result Interface) Interface {
+	return &bitmapSync{
+		*result.(*bitmap),
+		sync.RWMutex{},
+	}
+}
+
+func (b *bitmapSync) RunOptimize() {
+	b.l.Lock()
+	defer b.l.Unlock()
+	b.bitmap.RunOptimize()
+}
+
+// Iterator returns an iterator over a snapshot of the items.
+func (b *bitmapSync) Iterator() Iterator {
+	b.l.RLock()
+	defer b.l.RUnlock()
+	return b.bitmap.copy().Iterator()
+}
+
+func (b *bitmapSync) String() string {
+	return format.String(b)
+}
+
+func (b *bitmapSync) Format(f fmt.State, verb rune) {
+	format.Seq(f, verb, "roaring.Sync", "roaring.NewSync", b.Slice())
+}
+
+func (b *bitmapSync) Slice() []interface{} {
+	b.l.RLock()
+	defer b.l.RUnlock()
+	return b.bitmap.Slice()
+}
+
+func (b *bitmapSync) CopyBitmap() Interface {
+	b.l.RLock()
+	defer b.l.RUnlock()
+	return b.sync(b.bitmap.copy())
+}
+
+func (b *bitmapSync) CopySet() set.Interface {
+	return b.CopyBitmap()
+}
+
+func (b *bitmapSync) CopyCollection() collection.Interface {
+	return b.CopyBitmap()
+}
+
+func (b *bitmapSync) WriteTo(w io.Writer) (int64, error) {
+	b.l.RLock()
+	defer b.l.RUnlock()
+	return b.bitmap.WriteTo(w)
+}
+
+func (b *bitmapSync) MarshalBinary() ([]byte, error) {
+	b.l.RLock()
+	defer b.l.RUnlock()
+	return b.bitmap.MarshalBinary()
+}
+
+func (b *bitmapSync) ReadFrom(r io.Reader) (int64, error) {
+	b.l.Lock()
+	defer b.l.Unlock()
+	return b.bitmap.ReadFrom(r)
+}
+
+func (b *bitmapSync) UnmarshalBinary(data []byte) error {
+	b.l.Lock()
+	defer b.l.Unlock()
+	return b.bitmap.UnmarshalBinary(data)
+}
+
+// Snapshot returns a non-threadsafe copy of b consistent at the time of the call.
+func (b *bitmapSync) Snapshot() Interface {
+	b.l.RLock()
+	defer b.l.RUnlock()
+	return b.bitmap.copy()
+}
@@ -0,0 +1,457 @@
+package roaring
+
+import (
+	"bytes"
+	"math/rand"
+	"testing"
+
+	"github.com/khezen/struct/collection"
+	"github.com/khezen/struct/set"
+)
+
+// items returns count pseudo random uint32 spread over a few containers so
+// that array, bitmap and run containers all appear.
+func items(seed int64, count int) []uint32 {
+	r := rand.New(rand.NewSource(seed))
+	items := make([]uint32, 0, count)
+	for i := 0; i < count; i++ {
+		switch i % 3 {
+		case 0:
+			items = append(items, uint32(r.Intn(1<<16)))
+		case 1:
+			items = append(items, 3<<16|uint32(r.Intn(1<<10)))
+		default:
+			items = append(items, uint32(r.Int31()))
+		}
+	}
+	return items
+}
+
+func reference(items []uint32) set.Interface {
+	s := set.New()
+	for _, item := range items {
+		s.Add(item)
+	}
+	return s
+}
+
+func TestAddRemove(t *testing.T) {
+	cases := []struct {
+		b Interface
+	}{
+		{New()},
+		{NewSync()},
+	}
+	for _, c := range cases {
+		in := items(1, 20000)
+		for _, item := range in {
+			c.b.Add(item)
+		}
+		ref := reference(in)
+		if !c.b.IsEqual(ref) || !ref.IsEqual(c.b) {
+			t.Fatalf("Expected %v items. Got %v.", ref.Len(), c.b.Len())
+		}
+		for _, item := range in[:15000] {
+			c.b.Remove(item)
+			ref.Remove(item)
+		}
+		if !c.b.IsEqual(ref) {
+			t.Fatalf("Expected %v items. Got %v.", ref.Len(), c.b.Len())
+		}
+	}
+}
+
+func TestAddPanics(t *testing.T) {
+	defer func() {
+		if r := recover(); r != ErrBadItem {
+			t.Errorf("Expected %v. Got %v.", ErrBadItem, r)
+		}
+	}()
+	New().Add(1)
+}
+
+func TestHas(t *testing.T) {
+	cases := []struct {
+		b        Interface
+		items    []interface{}
+		expected bool
+	}{
+		{New(1, 1<<20, 1<<31), []interface{}{uint32(1), uint32(1 << 20), uint32(1 << 31)}, true},
+		{New(1, 1<<20), []interface{}{uint32(2)}, false},
+		{New(1, 1<<20), []interface{}{1}, false},
+		{New(1), []interface{}{}, true},
+		{NewSync(1, 1<<20), []interface{}{uint32(1 << 20)}, true},
+		{NewSync(), []interface{}{}, true},
+	}
+	for _, c := range cases {
+		if has := c.b.Has(c.items...); has != c.expected {
+			t.Errorf("Expected %v. Got %v.", c.expected, has)
+		}
+	}
+}
+
+func TestEachIterator(t *testing.T) {
+	cases := []struct {
+		b Interface
+	}{
+		{New(items(2, 10000)...)},
+		{NewSync(items(2, 10000)...)},
+	}
+	for _, c := range cases {
+		c.b.RunOptimize()
+		prev, count := int64(-1), 0
+		c.b.Each(func(item interface{}) bool {
+			if int64(item.(uint32)) <= prev {
+				t.Fatalf("Each should be ascending. Got %v after %v.", item, prev)
+			}
+			prev = int64(item.(uint32))
+			count++
+			return true
+		})
+		if count != c.b.Len() {
+			t.Errorf("Expected %v. Got %v.", c.b.Len(), count)
+		}
+		slice := c.b.Slice()
+		it := c.b.Iterator()
+		for i := 0; it.HasNext(); i++ {
+			if x := it.Next(); x != slice[i] {
+				t.Fatalf("Expected %v. Got %v.", slice[i], x)
+			}
+		}
+		func() {
+			defer func() {
+				if r := recover(); r != ErrNoMoreItems {
+					t.Errorf("Expected %v. Got %v.", ErrNoMoreItems, r)
+				}
+			}()
+			it.Next()
+		}()
+	}
+}
+
+func TestPopReplace(t *testing.T) {
+	cases := []struct {
+		b Interface
+	}{
+		{New(1<<20, 5, 3)},
+		{NewSync(1<<20, 5, 3)},
+	}
+	for _, c := range cases {
+		c.b.Replace(uint32(5), uint32(4))
+		c.b.Replace(uint32(6), uint32(7))
+		for _, expected := range []interface{}{uint32(3), uint32(4), uint32(1 << 20), nil} {
+			if item := c.b.Pop(); item != expected {
+				t.Errorf("Expected %v. Got %v.", expected, item)
+			}
+		}
+		if !c.b.IsEmpty() {
+			t.Errorf("Expected empty. Got %v.", c.b)
+		}
+	}
+}
+
+func TestOperations(t *testing.T) {
+	a, b := items(3, 30000), items(4, 30000)
+	b = append(b, a[:10000]...)
+	ra, rb := reference(a), reference(b)
+	union := collection.Union(ra, rb)
+	intersection := collection.Intersection(ra, rb)
+	difference := collection.Difference(ra, rb)
+	exclusion := collection.Union(collection.Difference(ra, rb), collection.Difference(rb, ra))
+	cases := []struct {
+		a, b Interface
+	}{
+		{New(a...), New(b...)},
+		{NewSync(a...), NewSync(b...)},
+		{New(a...), NewSync(b...)},
+	}
+	for _, c := range cases {
+		for _, optimize := range []bool{false, true} {
+			if optimize {
+				c.a.RunOptimize()
+				c.b.RunOptimize()
+			}
+			if r := c.a.Or(c.b); !r.IsEqual(union) {
+				t.Errorf("Or: expected %v items. Got %v.", union.Len(), r.Len())
+			}
+			if r := c.a.And(c.b); !r.IsEqual(intersection) {
+				t.Errorf("And: expected %v items. Got %v.", intersection.Len(), r.Len())
+			}
+			if r := c.a.AndNot(c.b); !r.IsEqual(difference) {
+				t.Errorf("AndNot: expected %v items. Got %v.", difference.Len(), r.Len())
+			}
+			if r := c.a.Xor(c.b); !r.IsEqual(exclusion) {
+				t.Errorf("Xor: expected %v items. Got %v.", exclusion.Len(), r.Len())
+			}
+			if !c.a.IsEqual(ra) {
+				t.Error("Operands should not be modified")
+			}
+		}
+	}
+}
+
+func TestMergeSeparateRetain(t *testing.T) {
+	a, b := items(5, 20000), items(6, 20000)
+	b = append(b, a[:5000]...)
+	ra, rb := reference(a), reference(b)
+	cases := []struct {
+		b     func() Interface
+		other func() collection.Interface
+	}{
+		{func() Interface { return New(a...) }, func() collection.Interface { return New(b...) }},
+		{func() Interface { return NewSync(a...) }, func() collection.Interface { return NewSync(b...) }},
+		{func() Interface { return New(a...) }, func() collection.Interface { return reference(b) }},
+		{func() Interface { return NewSync(a...) }, func() collection.Interface { return set.NewSync(rb.Slice()...) }},
+	}
+	for _, c := range cases {
+		x := c.b()
+		x.Merge(c.other())
+		if expected := collection.Union(ra, rb); !x.IsEqual(expected) {
+			t.Errorf("Merge: expected %v items. Got %v.", expected.Len(), x.Len())
+		}
+		x = c.b()
+		x.Separate(c.other())
+		if expected := collection.Difference(ra, rb); !x.IsEqual(expected) {
+			t.Errorf("Separate: expected %v items. Got %v.", expected.Len(), x.Len())
+		}
+		x = c.b()
+		x.Retain(c.other())
+		if expected := collection.Intersection(ra, rb); !x.IsEqual(expected) {
+			t.Errorf("Retain: expected %v items. Got %v.", expected.Len(), x.Len())
+		}
+	}
+	x := NewSync(1, 2)
+	x.Merge(x)
+	x.Retain(x)
+	if !x.IsEqual(x) {
+		t.Error("A bitmap should be equal to itself")
+	}
+	x.Separate(x)
+	if !x.IsEmpty() {
+		t.Errorf("Expected empty. Got %v.", x)
+	}
+}
+
+func TestCollectionFunctions(t *testing.T) {
+	cases := []struct {
+		collections         []collection.Interface
+		union, intersection collection.Interface
+	}{
+		{[]collection.Interface{New(1, 42, 1<<20), New(5, 42, 1<<20)}, New(1, 5, 42, 1<<20), New(42, 1<<20)},
+		{[]collection.Interface{NewSync(1, 42), set.New(uint32(5), uint32(42))}, New(1, 5, 42), New(42)},
+		{[]collection.Interface{set.New(uint32(1), uint32(42)), New(5, 42)}, New(1, 5, 42), New(42)},
+	}
+	for _, c := range cases {
+		if u := collection.Union(c.collections...); !c.union.IsEqual(u) {
+			t.Errorf("Expected %v. Got %v.", c.union, u)
+		}
+		if i := collection.Intersection(c.collections...); !c.intersection.IsEqual(i) {
+			t.Errorf("Expected %v. Got %v.", c.intersection, i)
+		}
+	}
+}
+
+func TestIsSubset(t *testing.T) {
+	cases := []struct {
+		b, sub            set.Interface
+		isSub, isSuperset bool
+	}{
+		{New(1, 2, 1<<20), New(1, 1<<20), true, false},
+		{New(1, 2), New(1, 1<<20), false, false},
+		{New(1, 2), NewSync(1, 2, 1<<20), false, true},
+		{NewSync(1, 2, 1<<20), set.New(uint32(1), uint32(2)), true, false},
+		{NewSync(1, 2), set.New(uint32(1), uint32(2), uint32(3)), false, true},
+	}
+	for _, c := range cases {
+		if ok := c.b.IsSubset(c.sub); ok != c.isSub {
+			t.Errorf("Expected %v. Got %v.", c.isSub, ok)
+		}
+		if ok := c.b.IsSuperset(c.sub); ok != c.isSuperset {
+			t.Errorf("Expected %v. Got %v.", c.isSuperset, ok)
+		}
+	}
+}
+
+func TestRunOptimize(t *testing.T) {
+	b := New()
+	for x := uint32(0); x < 100000; x++ {
+		b.Add(x)
+	}
+	before, _ := b.MarshalBinary()
+	b.RunOptimize()
+	after, _ := b.MarshalBinary()
+	if len(after) >= len(before) {
+		t.Errorf("Run optimization should shrink the bitmap. Got %v bytes from %v.", len(after), len(before))
+	}
+	if b.Len() != 100000 || !b.Has(uint32(99999)) || b.Has(uint32(100000)) {
+		t.Errorf("Expected 100000 items. Got %v.", b.Len())
+	}
+	b.Remove(uint32(500))
+	b.Add(uint32(1 << 30))
+	if b.Len() != 100000 || b.Has(uint32(500)) {
+		t.Errorf("Expected 100000 items. Got %v.", b.Len())
+	}
+}
+
+func TestSerializationFormat(t *testing.T) {
+	runs := New()
+	for x := uint32(1); x <= 100; x++ {
+		runs.Add(x)
+	}
+	runs.RunOptimize()
+	cases := []struct {
+		b        Interface
+		expected []byte
+	}{
+		{New(), []byte{0x3A, 0x30, 0, 0, 0, 0, 0, 0}},
+		{
+			New(1, 2, 3),
+			[]byte{
+				0x3A, 0x30, 0, 0, 1, 0, 0, 0, // cookie, size
+				0, 0, 2, 0, // key 0, cardinality 3
+				16, 0, 0, 0, // offset
+				1, 0, 2, 0, 3, 0, // array container
+			},
+		},
+		{
+			runs,
+			[]byte{
+				0x3B, 0x30, 0, 0, // cookie, size 1
+				1,           // run flags
+				0, 0, 99, 0, // key 0, cardinality 100
+				1, 0, 1, 0, 99, 0, // one run starting at 1 of length 100
+			},
+		},
+	}
+	for _, c := range cases {
+		data, err := c.b.MarshalBinary()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(data, c.expected) {
+			t.Errorf("Expected %v. Got %v.", c.expected, data)
+		}
+		decoded := New()
+		if err := decoded.UnmarshalBinary(c.expected); err != nil {
+			t.Fatal(err)
+		}
+		if !decoded.IsEqual(c.b) {
+			t.Errorf("Expected %v. Got %v.", c.b, decoded)
+		}
+	}
+}
+
+func TestSerializationRoundTrip(t *testing.T) {
+	cases := []struct {
+		b        Interface
+		optimize bool
+	}{
+		{New(items(7, 30000)...), false},
+		{New(items(7, 30000)...), true},
+		{NewSync(items(8, 100)...), true},
+	}
+	for _, c := range cases {
+		for x := uint32(5 << 16); x < 5<<16+5000; x++ {
+			c.b.Add(x)
+		}
+		if c.optimize {
+			c.b.RunOptimize()
+		}
+		var buf bytes.Buffer
+		written, err := c.b.WriteTo(&buf)
+		if err != nil {
+			t.Fatal(err)
+		}
+		buf.WriteString("trailing data")
+		decoded := NewSync()
+		read, err := decoded.ReadFrom(&buf)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if read != written {
+			t.Errorf("Expected %v bytes read. Got %v.", written, read)
+		}
+		if !decoded.IsEqual(c.b) {
+			t.Errorf("Expected %v items. Got %v.", c.b.Len(), decoded.Len())
+		}
+		if buf.String() != "trailing data" {
+			t.Errorf("ReadFrom should not read past the bitmap. Left %q.", buf.String())
+		}
+	}
+}
+
+func TestSerializationErrors(t *testing.T) {
+	valid, _ := New(1, 2, 3, 1<<20).MarshalBinary()
+	cases := []struct {
+		data []byte
+	}{
+		{[]byte{}},
+		{[]byte{1, 2, 3, 4, 0, 0, 0, 0}},
+		{valid[:len(valid)-1]},
+		{append(append([]byte{}, valid[:24]...), 3, 0, 2, 0, 1, 0)},
+	}
+	for _, c := range cases {
+		if err := New().UnmarshalBinary(c.data); err == nil {
+			t.Errorf("Expected an error for %v.", c.data)
+		}
+	}
+}
+
+func TestFromSet(t *testing.T) {
+	cases := []struct {
+		s         collection.Interface
+		expectErr bool
+	}{
+		{set.New(uint32(1), uint32(1<<20)), false},
+		{New(1, 1<<20), false},
+		{set.New(1), true},
+	}
+	for _, c := range cases {
+		b, err := FromSet(c.s)
+		if (err != nil) != c.expectErr {
+			t.Errorf("Error expected? %v. Got %v.", c.expectErr, err)
+		}
+		if err == nil && !b.IsEqual(c.s) {
+			t.Errorf("Expected %v. Got %v.", c.s, b)
+		}
+	}
+}
+
+func TestStringCopy(t *testing.T) {
+	cases := []struct {
+		b        Interface
+		expected string
+	}{
+		{New(), "[]"},
+		{New(1<<16, 3, 1), "[1 3 65536]"},
+		{NewSync(1<<16, 3, 1), "[1 3 65536]"},
+	}
+	for _, c := range cases {
+		if str := c.b.String(); str != c.expected {
+			t.Errorf("Expected %v. Got %v.", c.expected, str)
+		}
+		for _, cpy := range []collection.Interface{c.b.CopyBitmap(), c.b.CopySet(), c.b.CopyCollection()} {
+			if !cpy.IsEqual(c.b) {
+				t.Errorf("Expected %v. Got %v.", c.b, cpy)
+			}
+			cpy.Add(uint32(7))
+			if c.b.Has(uint32(7)) {
+				t.Error("Copy should not alter the original")
+			}
+		}
+		c.b.Clear()
+		if !c.b.IsEmpty() || c.b.Len() != 0 {
+			t.Errorf("Expected empty. Got %v.", c.b)
+		}
+	}
+}
+
+func TestSnapshot(t *testing.T) {
+	b := NewSync(1, 2)
+	snapshot := b.(interface{ Snapshot() Interface }).Snapshot()
+	b.Add(uint32(3))
+	snapshot.Add(uint32(4))
+	if _, ok := snapshot.(*bitmap); !ok || !snapshot.IsEqual(New(1, 2, 4)) || !b.IsEqual(New(1, 2, 3)) {
+		t.Errorf("Expected %v. Got %v.", New(1, 2, 4), snapshot)
+	}
+}
@@ -0,0 +1,235 @@
+package roaring
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+)
+
+// Constants of the portable Roaring serialization format, see
+// https://github.com/RoaringBitmap/RoaringFormatSpec
+const (
+	serialCookieNoRunContainer = 12346
+	serialCookie               = 12347
+	noOffsetThreshold          = 4
+)
+
+var le = binary.LittleEndian
+
+// WriteTo writes b to w in the portable Roaring format.
+func (b *bitmap) WriteTo(w io.Writer) (int64, error) {
+	n := len(b.keys)
+	hasRun := false
+	for _, c := range b.containers {
+		if _, ok := c.(runContainer); ok {
+			hasRun = true
+		}
+	}
+	buf := make([]byte, 0, b.serializedSize(hasRun))
+	if hasRun {
+		buf = le.AppendUint32(buf, serialCookie|uint32(n-1)<<16)
+		flags := make([]byte, (n+7)/8)
+		for i, c := range b.containers {
+			if _, ok := c.(runContainer); ok {
+				flags[i/8] |= 1 << uint(i%8)
+			}
+		}
+		buf = append(buf, flags...)
+	} else {
+		buf = le.AppendUint32(buf, serialCookieNoRunContainer)
+		buf = le.AppendUint32(buf, uint32(n))
+	}
+	for i, key := range b.keys {
+		buf = le.AppendUint16(buf, key)
+		buf = le.AppendUint16(buf, uint16(b.containers[i].card()-1))
+	}
+	if !hasRun || n >= noOffsetThreshold {
+		offset := len(buf) + 4*n
+		for _, c := range b.containers {
+			buf = le.AppendUint32(buf, uint32(offset))
+			offset += containerSize(c)
+		}
+	}
+	for _, c := range b.containers {
+		switch c := c.(type) {
+		case arrayContainer:
+			for _, x := range c {
+				buf = le.AppendUint16(buf, x)
+			}
+		case *bitmapContainer:
+			for _, w := range c.w {
+				buf = le.AppendUint64(buf, w)
+			}
+		case runContainer:
+			buf = le.AppendUint16(buf, uint16(len(c)))
+			for _, run := range c {
+				buf = le.AppendUint16(buf, run.start)
+				buf = le.AppendUint16(buf, run.length)
+			}
+		}
+	}
+	written, err := w.Write(buf)
+	return int64(written), err
+}
+
+func (b *bitmap) serializedSize(hasRun bool) int {
+	n := len(b.keys)
+	size := 8 + 4*n
+	if hasRun {
+		size = 4 + (n+7)/8 + 4*n
+	}
+	if !hasRun || n >= noOffsetThreshold {
+		size += 4 * n
+	}
+	for _, c := range b.containers {
+		size += containerSize(c)
+	}
+	return size
+}
+
+func containerSize(c container) int {
+	switch c := c.(type) {
+	case arrayContainer:
+		return 2 * len(c)
+	case runContainer:
+		return 2 + 4*len(c)
+	default:
+		return 8 * bitmapWords
+	}
+}
+
+// MarshalBinary encodes b in the portable Roaring format.
+func (b *bitmap) MarshalBinary() ([]byte, error) {
+	var buf bytes.Buffer
+	_, err := b.WriteTo(&buf)
+	return buf.Bytes(), err
+}
+
+// ReadFrom replaces the content of b with a bitmap read from r in the
+// portable Roaring format. It reads no further than the end of the bitmap.
+func (b *bitmap) ReadFrom(r io.Reader) (int64, error) {
+	cr := &countingReader{r: r}
+	decoded, err := decode(cr)
+	if err != nil {
+		return cr.n, err
+	}
+	*b = *decoded
+	return cr.n, nil
+}
+
+// UnmarshalBinary replaces the content of b with a bitmap decoded from data in
+// the portable Roaring format.
+func (b *bitmap) UnmarshalBinary(data []byte) error {
+	_, err := b.ReadFrom(bytes.NewReader(data))
+	return err
+}
+
+func decode(r io.Reader) (*bitmap, error) {
+	var cookie uint32
+	if err := binary.Read(r, le, &cookie); err != nil {
+		return nil, err
+	}
+	var n int
+	var flags []byte
+	switch {
+	case cookie&0xFFFF == serialCookie:
+		n = int(cookie>>16) + 1
+		flags = make([]byte, (n+7)/8)
+		if _, err := io.ReadFull(r, flags); err != nil {
+			return nil, err
+		}
+	case cookie == serialCookieNoRunContainer:
+		var size uint32
+		if err := binary.Read(r, le, &size); err != nil {
+			return nil, err
+		}
+		if size > 1<<16 {
+			return nil, ErrBadFormat
+		}
+		n = int(size)
+	default:
+		return nil, ErrBadFormat
+	}
+	header := make([]uint16, 2*n)
+	if err := binary.Read(r, le, header); err != nil {
+		return nil, err
+	}
+	if flags == nil || n >= noOffsetThreshold {
+		offsets := make([]byte, 4*n)
+		if _, err := io.ReadFull(r, offsets); err != nil {
+			return nil, err
+		}
+	}
+	b := &bitmap{
+		keys:       make([]uint16, n),
+		containers: make([]container, n),
+	}
+	for i := 0; i < n; i++ {
+		key, card := header[2*i], int(header[2*i+1])+1
+		if i > 0 && key <= b.keys[i-1] {
+			return nil, ErrBadFormat
+		}
+		c, err := decodeContainer(r, card, flags != nil && flags[i/8]&(1<<uint(i%8)) != 0)
+		if err != nil {
+			return nil, err
+		}
+		b.keys[i], b.containers[i] = key, c
+	}
+	return b, nil
+}
+
+func decodeContainer(r io.Reader, card int, isRun bool) (container, error) {
+	switch {
+	case isRun:
+		var length uint16
+		if err := binary.Read(r, le, &length); err != nil {
+			return nil, err
+		}
+		values := make([]uint16, 2*int(length))
+		if err := binary.Read(r, le, values); err != nil {
+			return nil, err
+		}
+		runs := make(runContainer, length)
+		for i := range runs {
+			runs[i] = interval{values[2*i], values[2*i+1]}
+			if i > 0 && uint32(runs[i-1].start)+uint32(runs[i-1].length) >= uint32(runs[i].start) {
+				return nil, ErrBadFormat
+			}
+		}
+		if len(runs) == 0 || runs.card() != card {
+			return nil, ErrBadFormat
+		}
+		return runs, nil
+	case card > arrayMaxSize:
+		c := &bitmapContainer{}
+		if err := binary.Read(r, le, c.w[:]); err != nil {
+			return nil, err
+		}
+		if c.count(); c.n != card {
+			return nil, ErrBadFormat
+		}
+		return c, nil
+	default:
+		a := make(arrayContainer, card)
+		if err := binary.Read(r, le, []uint16(a)); err != nil {
+			return nil, err
+		}
+		for i := 1; i < len(a); i++ {
+			if a[i] <= a[i-1] {
+				return nil, ErrBadFormat
+			}
+		}
+		return a, nil
+	}
+}
+
+type countingReader struct {
+	r io.Reader
+	n int64
+}
+
+func (cr *countingReader) Read(p []byte) (int, error) {
+	n, err := cr.r.Read(p)
+	cr.n += int64(n)
+	return n, err
+}
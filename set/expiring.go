@@ -0,0 +1,298 @@
+package set
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/khezen/struct/collection"
+	"github.com/khezen/struct/internal/expiry"
+	"github.com/khezen/struct/internal/format"
+)
+
+// Expiring is a thread safe set whose items expire after a time to live
+type Expiring interface {
+	Interface
+	AddWithTTL(ttl time.Duration, items ...interface{})
+	OnExpire(func(item interface{}))
+	SetClock(now func() time.Time)
+	StartJanitor(interval time.Duration)
+	Close() error
+}
+
+type setExpiring struct {
+	s        *expiry.Store
+	ttl      time.Duration
+	now      func() time.Time
+	onExpire func(item interface{})
+	janitor  *expiry.Janitor
+	pending  []*expiry.Entry
+	l        sync.Mutex
+}
+
+// NewExpiring creates a thread safe set in which items added with Add expire
+// after defaultTTL. A defaultTTL lower than or equal to zero means those
+// items never expire. Expired items are removed lazily, or periodically once
+// StartJanitor is called.
+func NewExpiring(defaultTTL time.Duration, items ...interface{}) Expiring {
+	return NewExpiringWithClock(defaultTTL, time.Now, items...)
+}
+
+// NewExpiringWithClock is like NewExpiring, but tells the current time with
+// now, including for the deadlines of the given items.
+func NewExpiringWithClock(defaultTTL time.Duration, now func() time.Time, items ...interface{}) Expiring {
+	s := &setExpiring{
+		s:   expiry.NewStore(),
+		ttl: defaultTTL,
+		now: now,
+	}
+	for _, item := range items {
+		s.s.Put(item, keyExists, expiry.Deadline(s.now(), defaultTTL))
+	}
+	return s
+}
+
+// lock acquires the lock and drops expired items.
+func (s *setExpiring) lock() {
+	s.l.Lock()
+	s.pending = append(s.pending, s.s.Expire(s.now())...)
+}
+
+// unlock releases the lock, then calls the expiry callback.
+func (s *setExpiring) unlock() {
+	pending, f := s.pending, s.onExpire
+	s.pending = nil
+	s.l.Unlock()
+	if f != nil {
+		for _, e := range pending {
+			f(e.Key)
+		}
+	}
+}
+
+func (s *setExpiring) Add(items ...interface{}) {
+	s.AddWithTTL(s.ttl, items...)
+}
+
+// AddWithTTL includes the specified items, which expire once ttl has elapsed.
+// A ttl lower than or equal to zero means the items never expire.
+func (s *setExpiring) AddWithTTL(ttl time.Duration, items ...interface{}) {
+	if len(items) == 0 {
+		return
+	}
+	s.lock()
+	defer s.unlock()
+	expires := expiry.Deadline(s.now(), ttl)
+	for _, item := range items {
+		s.s.Put(item, keyExists, expires)
+	}
+}
+
+func (s *setExpiring) Remove(items ...interface{}) {
+	if len(items) == 0 {
+		return
+	}
+	s.lock()
+	defer s.unlock()
+	for _, item := range items {
+		s.s.Delete(item)
+	}
+}
+
+// Pop deletes and return an item from the set. If set is empty, nil is returned.
+func (s *setExpiring) Pop() interface{} {
+	s.lock()
+	defer s.unlock()
+	var popped interface{}
+	s.s.Each(func(e *expiry.Entry) bool {
+		popped = e.Key
+		return false
+	})
+	s.s.Delete(popped)
+	return popped
+}
+
+// Replace swaps item for substitute, which inherits the expiration date of item.
+func (s *setExpiring) Replace(item, substitute interface{}) {
+	s.lock()
+	defer s.unlock()
+	if e, ok := s.s.Delete(item); ok {
+		s.s.Put(substitute, keyExists, e.Expires)
+	}
+}
+
+func (s *setExpiring) Has(items ...interface{}) bool {
+	s.lock()
+	defer s.unlock()
+	return s.has(items...)
+}
+
+func (s *setExpiring) has(items ...interface{}) bool {
+	for _, item := range items {
+		if _, ok := s.s.Get(item); !ok {
+			return false
+		}
+	}
+	return true
+}
+
+// Each calls f on a snapshot of the live items, without holding the lock, so
+// f may call the methods of s.
+func (s *setExpiring) Each(f func(item interface{}) bool) {
+	s.lock()
+	items := make([]interface{}, 0, s.s.Len())
+	s.s.Each(func(e *expiry.Entry) bool {
+		items = append(items, e.Key)
+		return true
+	})
+	s.unlock()
+	for _, item := range items {
+		if !f(item) {
+			return
+		}
+	}
+}
+
+func (s *setExpiring) Len() int {
+	s.lock()
+	defer s.unlock()
+	return s.s.Len()
+}
+
+func (s *setExpiring) Clear() {
+	s.lock()
+	defer s.unlock()
+	s.s.Clear()
+}
+
+func (s *setExpiring) IsEmpty() bool {
+	return s.Len() == 0
+}
+
+func (s *setExpiring) IsEqual(t collection.ReadOnly) bool {
+	if t == collection.Interface(s) {
+		return true
+	}
+	items := t.Slice()
+	s.lock()
+	defer s.unlock()
+	return s.s.Len() == len(items) && s.has(items...)
+}
+
+// IsSubset tests whether t is a subset of s.
+func (s *setExpiring) IsSubset(t ReadOnly) bool {
+	items := t.Slice()
+	s.lock()
+	defer s.unlock()
+	return s.has(items...)
+}
+
+// IsSuperset tests whether t is a superset of s.
+func (s *setExpiring) IsSuperset(t ReadOnly) bool {
+	return t.IsSubset(s)
+}
+
+// Merge adds the items of t with the default TTL.
+func (s *setExpiring) Merge(t collection.Interface) {
+	s.Add(t.Slice()...)
+}
+
+func (s *setExpiring) Separate(t collection.Interface) {
+	s.Remove(t.Slice()...)
+}
+
+func (s *setExpiring) Retain(t collection.Interface) {
+	if t == collection.Interface(s) {
+		return
+	}
+	retained := New(t.Slice()...)
+	s.lock()
+	defer s.unlock()
+	removed := make([]interface{}, 0)
+	s.s.Each(func(e *expiry.Entry) bool {
+		if !retained.Has(e.Key) {
+			removed = append(removed, e.Key)
+		}
+		return true
+	})
+	for _, item := range removed {
+		s.s.Delete(item)
+	}
+}
+
+func (s *setExpiring) String() string {
+	return format.String(s)
+}
+
+// Format prints the live items. With %#v, it prints the syntax of an equal
+// set which does not expire.
+func (s *setExpiring) Format(f fmt.State, verb rune) {
+	format.Set(f, verb, "set.Expiring", "set.NewSync", s.Slice())
+}
+
+func (s *setExpiring) Slice() []interface{} {
+	items := make([]interface{}, 0)
+	s.Each(func(item interface{}) bool {
+		items = append(items, item)
+		return true
+	})
+	return items
+}
+
+// CopySet returns a new expiring set with the same items, deadlines, default
+// TTL and clock. The expiry callback and the janitor are not copied.
+func (s *setExpiring) CopySet() Interface {
+	s.lock()
+	defer s.unlock()
+	cpy := NewExpiringWithClock(s.ttl, s.now).(*setExpiring)
+	s.s.Each(func(e *expiry.Entry) bool {
+		cpy.s.Put(e.Key, keyExists, e.Expires)
+		return true
+	})
+	return cpy
+}
+
+func (s *setExpiring) CopyCollection() collection.Interface {
+	return s.CopySet()
+}
+
+// OnExpire registers a callback called each time an item expires.
+func (s *setExpiring) OnExpire(f func(item interface{})) {
+	s.l.Lock()
+	defer s.l.Unlock()
+	s.onExpire = f
+}
+
+// SetClock replaces the function used to tell the current time. The items
+// already present keep their deadlines.
+func (s *setExpiring) SetClock(now func() time.Time) {
+	s.l.Lock()
+	defer s.l.Unlock()
+	s.now = now
+}
+
+// StartJanitor purges expired items every interval in the background,
+// replacing any janitor already running. Call Close to stop it. A
+// non-positive interval only stops the running janitor.
+func (s *setExpiring) StartJanitor(interval time.Duration) {
+	s.l.Lock()
+	defer s.l.Unlock()
+	if s.janitor != nil {
+		s.janitor.Stop()
+	}
+	s.janitor = expiry.StartJanitor(interval, func() {
+		s.lock()
+		s.unlock()
+	})
+}
+
+// Close stops the janitor, if any.
+func (s *setExpiring) Close() error {
+	s.l.Lock()
+	defer s.l.Unlock()
+	if s.janitor != nil {
+		s.janitor.Stop()
+		s.janitor = nil
+	}
+	return nil
+}
@@ -9,14 +9,26 @@ import (
 	"github.com/khezen/struct/collection"
 )
 
+// ReadOnly describes methods of a set which do not modify it
+type ReadOnly interface {
+	collection.ReadOnly
+	IsSubset(s ReadOnly) bool
+	IsSuperset(s ReadOnly) bool
+}
+
 // Interface is describing a set. sets are an unordered, unique Slice of values.
 type Interface interface {
 	collection.Interface
+	ReadOnly
 	Pop() interface{}
-	IsSubset(s Interface) bool
-	IsSuperset(s Interface) bool
 	CopySet() Interface
 }
 
+// Snapshotter is implemented by thread safe sets, which can return a
+// non-threadsafe copy of themselves consistent at the time of the call.
+type Snapshotter interface {
+	Snapshot() Interface
+}
+
 // helpful to not write everywhere struct{}{}
 var keyExists = struct{}{}
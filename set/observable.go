@@ -0,0 +1,233 @@
+package set
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/khezen/struct/collection"
+	"github.com/khezen/struct/internal/format"
+	"github.com/khezen/struct/observer"
+)
+
+// Observable is a thread safe set publishing an event on each modification
+type Observable interface {
+	Interface
+	// Subscribe calls f with every event until unsubscribe is called
+	Subscribe(f func(observer.Event)) (unsubscribe func())
+	// SubscribeChan sends every event on a channel closed by unsubscribe
+	SubscribeChan() (events <-chan observer.Event, unsubscribe func())
+}
+
+// setObservable forwards calls to the wrapped set and publishes its changes
+type setObservable struct {
+	s Interface
+	l sync.RWMutex
+	observer.Subject
+}
+
+// NewObservable wraps s in a thread safe set publishing Add, Remove, Replace
+// and Clear events, and a single Batch event for Merge, Separate and Retain.
+// Events carry Index -1. s must not be modified but through the wrapper.
+func NewObservable(s Interface) Observable {
+	return &setObservable{s: s}
+}
+
+// write applies f under lock and delivers the events it returns once unlocked
+func (o *setObservable) write(f func() []observer.Event) {
+	func() {
+		o.l.Lock()
+		defer o.l.Unlock()
+		o.Queue(f()...)
+	}()
+	o.Flush()
+}
+
+// operand returns the wrapped set when t is o itself, which is already locked
+func (o *setObservable) operand(t collection.ReadOnly) collection.ReadOnly {
+	if t == collection.ReadOnly(o) {
+		return o.s
+	}
+	return t
+}
+
+func (o *setObservable) Add(items ...interface{}) {
+	o.write(func() []observer.Event {
+		events := make([]observer.Event, 0, len(items))
+		for _, item := range items {
+			if !o.s.Has(item) {
+				o.s.Add(item)
+				events = append(events, added(item))
+			}
+		}
+		return events
+	})
+}
+
+func (o *setObservable) Remove(items ...interface{}) {
+	o.write(func() []observer.Event {
+		return o.remove(items)
+	})
+}
+
+func (o *setObservable) remove(items []interface{}) []observer.Event {
+	events := make([]observer.Event, 0, len(items))
+	for _, item := range items {
+		if o.s.Has(item) {
+			o.s.Remove(item)
+			events = append(events, removed(item))
+		}
+	}
+	return events
+}
+
+func (o *setObservable) Pop() (item interface{}) {
+	o.write(func() []observer.Event {
+		if o.s.IsEmpty() {
+			return nil
+		}
+		item = o.s.Pop()
+		return []observer.Event{removed(item)}
+	})
+	return item
+}
+
+func (o *setObservable) Replace(item, substitute interface{}) {
+	o.write(func() []observer.Event {
+		if !o.s.Has(item) || item == substitute {
+			return nil
+		}
+		o.s.Replace(item, substitute)
+		return []observer.Event{{Op: observer.Replace, Item: item, Index: -1, Old: item, New: substitute}}
+	})
+}
+
+func (o *setObservable) Clear() {
+	o.write(func() []observer.Event {
+		if o.s.IsEmpty() {
+			return nil
+		}
+		o.s.Clear()
+		return []observer.Event{{Op: observer.Clear, Index: -1}}
+	})
+}
+
+func (o *setObservable) Merge(t collection.Interface) {
+	o.write(func() []observer.Event {
+		var events []observer.Event
+		o.operand(t).Each(func(item interface{}) bool {
+			if !o.s.Has(item) {
+				o.s.Add(item)
+				events = append(events, added(item))
+			}
+			return true
+		})
+		return batch(events)
+	})
+}
+
+func (o *setObservable) Separate(t collection.Interface) {
+	o.write(func() []observer.Event {
+		return batch(o.remove(o.operand(t).Slice()))
+	})
+}
+
+func (o *setObservable) Retain(t collection.Interface) {
+	o.write(func() []observer.Event {
+		t := o.operand(t)
+		var toRemove []interface{}
+		o.s.Each(func(item interface{}) bool {
+			if !t.Has(item) {
+				toRemove = append(toRemove, item)
+			}
+			return true
+		})
+		return batch(o.remove(toRemove))
+	})
+}
+
+func (o *setObservable) Has(items ...interface{}) bool {
+	o.l.RLock()
+	defer o.l.RUnlock()
+	return o.s.Has(items...)
+}
+
+func (o *setObservable) Each(f func(item interface{}) bool) {
+	o.l.RLock()
+	defer o.l.RUnlock()
+	o.s.Each(f)
+}
+
+func (o *setObservable) Len() int {
+	o.l.RLock()
+	defer o.l.RUnlock()
+	return o.s.Len()
+}
+
+func (o *setObservable) IsEmpty() bool {
+	o.l.RLock()
+	defer o.l.RUnlock()
+	return o.s.IsEmpty()
+}
+
+func (o *setObservable) IsEqual(t collection.ReadOnly) bool {
+	o.l.RLock()
+	defer o.l.RUnlock()
+	return o.s.IsEqual(o.operand(t))
+}
+
+func (o *setObservable) IsSubset(t ReadOnly) bool {
+	o.l.RLock()
+	defer o.l.RUnlock()
+	return o.s.IsSubset(o.operand(t).(ReadOnly))
+}
+
+func (o *setObservable) IsSuperset(t ReadOnly) bool {
+	o.l.RLock()
+	defer o.l.RUnlock()
+	return o.s.IsSuperset(o.operand(t).(ReadOnly))
+}
+
+func (o *setObservable) String() string {
+	o.l.RLock()
+	defer o.l.RUnlock()
+	return o.s.String()
+}
+
+func (o *setObservable) Format(f fmt.State, verb rune) {
+	o.l.RLock()
+	defer o.l.RUnlock()
+	format.Delegate(f, verb, o.s)
+}
+
+func (o *setObservable) Slice() []interface{} {
+	o.l.RLock()
+	defer o.l.RUnlock()
+	return o.s.Slice()
+}
+
+// CopySet returns a copy of the wrapped set, which does not publish events.
+func (o *setObservable) CopySet() Interface {
+	o.l.RLock()
+	defer o.l.RUnlock()
+	return o.s.CopySet()
+}
+
+func (o *setObservable) CopyCollection() collection.Interface {
+	return o.CopySet()
+}
+
+func added(item interface{}) observer.Event {
+	return observer.Event{Op: observer.Add, Item: item, Index: -1, New: item}
+}
+
+func removed(item interface{}) observer.Event {
+	return observer.Event{Op: observer.Remove, Item: item, Index: -1, Old: item}
+}
+
+// batch groups the events of a Merge, Separate or Retain
+func batch(events []observer.Event) []observer.Event {
+	if len(events) == 0 {
+		return nil
+	}
+	return []observer.Event{{Op: observer.Batch, Index: -1, Batch: events}}
+}
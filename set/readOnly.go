@@ -0,0 +1,103 @@
+package set
+
+import (
+	"fmt"
+
+	"github.com/khezen/struct/collection"
+	"github.com/khezen/struct/internal/format"
+)
+
+// setReadOnly forwards reads to the wrapped set and panics on mutation
+type setReadOnly struct {
+	s Interface
+}
+
+// NewReadOnly returns a view of s which panics with collection.ErrReadOnly on mutation.
+// The view reflects later modifications of s.
+func NewReadOnly(s Interface) Interface {
+	if _, ok := s.(*setReadOnly); ok {
+		return s
+	}
+	return &setReadOnly{s}
+}
+
+func (r *setReadOnly) Add(items ...interface{}) {
+	panic(collection.ErrReadOnly)
+}
+
+func (r *setReadOnly) Remove(items ...interface{}) {
+	panic(collection.ErrReadOnly)
+}
+
+func (r *setReadOnly) Pop() interface{} {
+	panic(collection.ErrReadOnly)
+}
+
+func (r *setReadOnly) Replace(item, substitute interface{}) {
+	panic(collection.ErrReadOnly)
+}
+
+func (r *setReadOnly) Clear() {
+	panic(collection.ErrReadOnly)
+}
+
+func (r *setReadOnly) Merge(t collection.Interface) {
+	panic(collection.ErrReadOnly)
+}
+
+func (r *setReadOnly) Separate(t collection.Interface) {
+	panic(collection.ErrReadOnly)
+}
+
+func (r *setReadOnly) Retain(t collection.Interface) {
+	panic(collection.ErrReadOnly)
+}
+
+func (r *setReadOnly) Has(items ...interface{}) bool {
+	return r.s.Has(items...)
+}
+
+func (r *setReadOnly) Each(f func(item interface{}) bool) {
+	r.s.Each(f)
+}
+
+func (r *setReadOnly) Len() int {
+	return r.s.Len()
+}
+
+func (r *setReadOnly) IsEmpty() bool {
+	return r.s.IsEmpty()
+}
+
+func (r *setReadOnly) IsEqual(t collection.ReadOnly) bool {
+	return r.s.IsEqual(t)
+}
+
+func (r *setReadOnly) IsSubset(t ReadOnly) bool {
+	return r.s.IsSubset(t)
+}
+
+func (r *setReadOnly) IsSuperset(t ReadOnly) bool {
+	return r.s.IsSuperset(t)
+}
+
+func (r *setReadOnly) String() string {
+	return r.s.String()
+}
+
+func (r *setReadOnly) Format(f fmt.State, verb rune) {
+	format.Delegate(f, verb, r.s)
+}
+
+func (r *setReadOnly) Slice() []interface{} {
+	return r.s.Slice()
+}
+
+// CopySet returns a mutable copy of the set.
+func (r *setReadOnly) CopySet() Interface {
+	return r.s.CopySet()
+}
+
+func (r *setReadOnly) CopyCollection() collection.Interface {
+	return r.s.CopyCollection()
+}
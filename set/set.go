@@ -2,9 +2,9 @@ package set
 
 import (
 	"fmt"
-	"strings"
 
 	"github.com/khezen/struct/collection"
+	"github.com/khezen/struct/internal/format"
 )
 
 // set defines a non-thread safe set data structure.
@@ -81,7 +81,7 @@ func (s *set) IsEmpty() bool {
 }
 
 // IsEqual test whether s and t are the same in size and have the same items.
-func (s *set) IsEqual(t collection.Interface) bool {
+func (s *set) IsEqual(t collection.ReadOnly) bool {
 	// Force locking only if given set is threadsafe.
 	if conv, ok := t.(*setSync); ok {
 		conv.l.RLock()
@@ -104,7 +104,7 @@ func (s *set) IsEqual(t collection.Interface) bool {
 }
 
 // IsSubset tests whether t is a subset of s.
-func (s *set) IsSubset(t Interface) (subset bool) {
+func (s *set) IsSubset(t ReadOnly) (subset bool) {
 	if conv, ok := t.(*setSync); ok {
 		conv.l.RLock()
 		defer conv.l.RUnlock()
@@ -120,7 +120,7 @@ func (s *set) IsSubset(t Interface) (subset bool) {
 }
 
 // IsSuperset tests whether t is a superset of s.
-func (s *set) IsSuperset(t Interface) bool {
+func (s *set) IsSuperset(t ReadOnly) bool {
 	if conv, ok := t.(*setSync); ok {
 		conv.l.RLock()
 		defer conv.l.RUnlock()
@@ -148,18 +148,20 @@ func (s *set) CopySet() Interface {
 	return u
 }
 
-// String returns a string representation of s
+// String returns a string representation of s, sorted when the items are orderable
 func (s *set) String() string {
-	t := make([]string, 0, len(s.Slice()))
-	for _, item := range s.Slice() {
-		t = append(t, fmt.Sprintf("%v", item))
-	}
+	return format.String(s)
+}
 
-	return fmt.Sprintf("[%s]", strings.Join(t, " "))
+// Format implements fmt.Formatter: %v prints the items, sorted when they are
+// orderable, %+v adds the type and the length and %#v the Go syntax. A
+// precision truncates the items: %.3v prints the 3 first followed by "...".
+func (s *set) Format(f fmt.State, verb rune) {
+	format.Set(f, verb, "set", "set.New", s.Slice())
 }
 
-// Slice returns a slice of all items. There is also StringSlice() and
-// IntSlice() methods for returning slices of type string or int.
+// Slice returns a slice of all items. The StringSlice and IntSlice functions
+// return slices of type string or int.
 func (s *set) Slice() []interface{} {
 	Slice := make([]interface{}, 0, len(s.m))
 
@@ -0,0 +1,150 @@
+package set
+
+import (
+	"fmt"
+	"sync"
+	"sync/atomic"
+
+	"github.com/khezen/struct/collection"
+	"github.com/khezen/struct/internal/format"
+)
+
+// setCOW defines a thread safe copy-on-write set. Readers work on an immutable
+// snapshot without locking while writers clone it, modify the clone and publish it.
+type setCOW struct {
+	snapshot atomic.Value // *set, never modified once published
+	l        sync.Mutex   // serializes writers
+}
+
+// NewCOW creates a thread safe copy-on-write set. Reads, including long Each
+// traversals, never block nor are blocked by writes, which cost O(n) each.
+// It suits sets read far more often than they are modified.
+func NewCOW(items ...interface{}) Interface {
+	s := &setCOW{}
+	s.snapshot.Store(New(items...).(*set))
+	return s
+}
+
+func (s *setCOW) load() *set {
+	return s.snapshot.Load().(*set)
+}
+
+// write applies f on a clone of the current snapshot and publishes the clone
+func (s *setCOW) write(f func(clone *set)) {
+	s.l.Lock()
+	defer s.l.Unlock()
+	clone := s.load().CopySet().(*set)
+	f(clone)
+	s.snapshot.Store(clone)
+}
+
+func (s *setCOW) Add(items ...interface{}) {
+	if len(items) > 0 {
+		s.write(func(clone *set) {
+			clone.Add(items...)
+		})
+	}
+}
+
+func (s *setCOW) Remove(items ...interface{}) {
+	if len(items) > 0 {
+		s.write(func(clone *set) {
+			clone.Remove(items...)
+		})
+	}
+}
+
+func (s *setCOW) Pop() (item interface{}) {
+	s.write(func(clone *set) {
+		item = clone.Pop()
+	})
+	return item
+}
+
+func (s *setCOW) Replace(item, substitute interface{}) {
+	s.write(func(clone *set) {
+		clone.Replace(item, substitute)
+	})
+}
+
+func (s *setCOW) Clear() {
+	s.l.Lock()
+	defer s.l.Unlock()
+	s.snapshot.Store(New().(*set))
+}
+
+func (s *setCOW) Merge(t collection.Interface) {
+	s.write(func(clone *set) {
+		clone.Merge(t)
+	})
+}
+
+func (s *setCOW) Separate(t collection.Interface) {
+	s.write(func(clone *set) {
+		clone.Separate(t)
+	})
+}
+
+func (s *setCOW) Retain(t collection.Interface) {
+	s.write(func(clone *set) {
+		clone.Retain(t)
+	})
+}
+
+func (s *setCOW) Has(items ...interface{}) bool {
+	return s.load().Has(items...)
+}
+
+// Each traverses the snapshot taken when it is called. Writes performed
+// meanwhile, including by f, are not visited.
+func (s *setCOW) Each(f func(item interface{}) bool) {
+	s.load().Each(f)
+}
+
+func (s *setCOW) Len() int {
+	return s.load().Len()
+}
+
+func (s *setCOW) IsEmpty() bool {
+	return s.load().IsEmpty()
+}
+
+func (s *setCOW) IsEqual(t collection.ReadOnly) bool {
+	return s.load().IsEqual(t)
+}
+
+func (s *setCOW) IsSubset(t ReadOnly) bool {
+	return s.load().IsSubset(t)
+}
+
+func (s *setCOW) IsSuperset(t ReadOnly) bool {
+	return s.load().IsSuperset(t)
+}
+
+func (s *setCOW) String() string {
+	return format.String(s)
+}
+
+func (s *setCOW) Format(f fmt.State, verb rune) {
+	format.Set(f, verb, "set.COW", "set.NewCOW", s.Slice())
+}
+
+func (s *setCOW) Slice() []interface{} {
+	return s.load().Slice()
+}
+
+// CopySet returns a copy-on-write set sharing the current snapshot, in O(1).
+func (s *setCOW) CopySet() Interface {
+	cpy := &setCOW{}
+	cpy.snapshot.Store(s.load())
+	return cpy
+}
+
+func (s *setCOW) CopyCollection() collection.Interface {
+	return s.CopySet()
+}
+
+// Snapshot returns a non-threadsafe copy of s consistent at the time of the call.
+func (s *setCOW) Snapshot() Interface {
+	return s.load().CopySet()
+}
@@ -1,9 +1,11 @@
 package set
 
 import (
+	"fmt"
 	"sync"
 
 	"github.com/khezen/struct/collection"
+	"github.com/khezen/struct/internal/format"
 )
 
 // setSync defines a thread safe set data structure.
@@ -82,14 +84,14 @@ func (s *setSync) Clear() {
 }
 
 // IsEqual test whether s and t are the same in size and have the same items.
-func (s *setSync) IsEqual(t collection.Interface) bool {
+func (s *setSync) IsEqual(t collection.ReadOnly) bool {
 	s.l.RLock()
 	defer s.l.RUnlock()
 	return s.set.IsEqual(t)
 }
 
 // IsSubset tests whether t is a subset of s.
-func (s *setSync) IsSubset(t Interface) (subset bool) {
+func (s *setSync) IsSubset(t ReadOnly) (subset bool) {
 	s.l.RLock()
 	defer s.l.RUnlock()
 	return s.set.IsSubset(t)
@@ -104,14 +106,25 @@ func (s *setSync) Each(f func(item interface{}) bool) {
 	s.set.Each(f)
 }
 
-// Slice returns a slice of all items. There is also StringSlice() and
-// IntSlice() methods for returning slices of type string or int.
+// Slice returns a slice of all items. The StringSlice and IntSlice functions
+// return slices of type string or int.
 func (s *setSync) Slice() []interface{} {
 	s.l.RLock()
 	defer s.l.RUnlock()
 	return s.set.Slice()
 }
 
+func (s *setSync) String() string {
+	return format.String(s)
+}
+
+// Format implements fmt.Formatter: %v prints the items, sorted when they are
+// orderable, %+v adds the type and the length and %#v the Go syntax. A
+// precision truncates the items: %.3v prints the 3 first followed by "...".
+func (s *setSync) Format(f fmt.State, verb rune) {
+	format.Set(f, verb, "set.Sync", "set.NewSync", s.Slice())
+}
+
 // Copy returns a new set with a copy of s.
 func (s *setSync) CopySet() Interface {
 	s.l.RLock()
@@ -141,3 +154,10 @@ func (s *setSync) Retain(t collection.Interface) {
 func (s *setSync) CopyCollection() collection.Interface {
 	return s.CopySet()
 }
+
+// Snapshot returns a non-threadsafe copy of s consistent at the time of the call.
+func (s *setSync) Snapshot() Interface {
+	s.l.RLock()
+	defer s.l.RUnlock()
+	return s.set.CopySet()
+}
@@ -1,9 +1,14 @@
 package set
 
 import (
+	"fmt"
+	"reflect"
+	"sync"
 	"testing"
+	"time"
 
 	"github.com/khezen/struct/collection"
+	"github.com/khezen/struct/observer"
 )
 
 func testErr(err error, expectErr bool, t *testing.T) {
@@ -344,6 +349,38 @@ func TestString(t *testing.T) {
 
 }
 
+func TestFormat(t *testing.T) {
+	cases := []struct {
+		format   string
+		set      Interface
+		expected string
+	}{
+		{"%v", New(3, 1, 2), "[1 2 3]"},
+		{"%v", NewSync("b", "c", "a"), "[a b c]"},
+		{"%v", NewCOW(2, 1), "[1 2]"},
+		{"%v", NewReadOnly(New(2, 1)), "[1 2]"},
+		{"%v", NewObservable(New(2, 1)), "[1 2]"},
+		{"%+v", NewSync(3, 1, 2), "set.Sync(len=3) [1 2 3]"},
+		{"%#v", New(3, 1, 2), "set.New(1, 2, 3)"},
+		{"%#v", NewCOW("a"), `set.NewCOW("a")`},
+		{"%.2v", New(3, 1, 2), "[1 2 ...]"},
+		{"%q", New("b", "a"), `["a" "b"]`},
+	}
+	for _, c := range cases {
+		if got := fmt.Sprintf(c.format, c.set); got != c.expected {
+			t.Errorf("Expected %v. Got %v.", c.expected, got)
+		}
+	}
+	if str := New(3, 1, 2).String(); str != "[1 2 3]" {
+		t.Errorf("Expected %v. Got %v.", "[1 2 3]", str)
+	}
+	e := NewExpiring(0, 2, 1)
+	defer e.Close()
+	if got := fmt.Sprintf("%+v", e); got != "set.Expiring(len=2) [1 2]" {
+		t.Errorf("Expected %v. Got %v.", "set.Expiring(len=2) [1 2]", got)
+	}
+}
+
 func TestSlice(t *testing.T) {
 	cases := []struct {
 		slice []interface{}
@@ -498,3 +535,386 @@ func TestExclusion(t *testing.T) {
 		}
 	}
 }
+
+// clock is a manual clock for deterministic expiry tests
+type clock struct {
+	t time.Time
+}
+
+func (c *clock) now() time.Time {
+	return c.t
+}
+
+func TestExpiringClock(t *testing.T) {
+	clk := &clock{time.Unix(0, 0)}
+	s := NewExpiringWithClock(time.Minute, clk.now, 1)
+	clk.t = clk.t.Add(59 * time.Second)
+	if !s.Has(1) {
+		t.Errorf("Expected %v. Got %v.", true, false)
+	}
+	clk.t = clk.t.Add(2 * time.Second)
+	if s.Has(1) {
+		t.Errorf("Expected %v. Got %v.", false, true)
+	}
+	s.AddWithTTL(time.Second, 2)
+	cpy := s.CopySet().(Expiring)
+	cpy.SetClock(clk.now)
+	clk.t = clk.t.Add(10 * time.Second)
+	if s.Has(2) || cpy.Has(2) {
+		t.Errorf("Expected %v. Got %v.", false, true)
+	}
+	s.Add(3)
+	s.Each(func(item interface{}) bool {
+		if !s.Has(item) || s.Len() != 1 {
+			t.Errorf("Expected %v. Got %v.", item, s)
+		}
+		return true
+	})
+	s.StartJanitor(0)
+	s.StartJanitor(-time.Second)
+	testErr(s.Close(), false, t)
+}
+
+func TestExpiring(t *testing.T) {
+	clk := &clock{time.Unix(0, 0)}
+	s := NewExpiringWithClock(time.Minute, clk.now, 1)
+	expired := New()
+	s.OnExpire(func(item interface{}) {
+		expired.Add(item)
+	})
+	s.Add(1)
+	s.AddWithTTL(time.Second, 42, 43)
+	s.AddWithTTL(0, -8)
+	if s.Len() != 4 {
+		t.Errorf("Expected 4. Got %v.", s.Len())
+	}
+	clk.t = clk.t.Add(time.Second)
+	if s.Len() != 2 || s.Has(42) {
+		t.Errorf("Expected 42 to be expired. Got %v.", s)
+	}
+	count := 0
+	s.Each(func(item interface{}) bool {
+		count++
+		return true
+	})
+	if count != 2 {
+		t.Errorf("Expected 2. Got %v.", count)
+	}
+	if !expired.IsEqual(New(42, 43)) {
+		t.Errorf("Expected %v. Got %v.", New(42, 43), expired)
+	}
+	clk.t = clk.t.Add(time.Hour)
+	if !s.IsEqual(New(-8)) || !New(-8).IsEqual(s) {
+		t.Errorf("Expected %v. Got %v.", New(-8), s)
+	}
+}
+
+func TestExpiringMethods(t *testing.T) {
+	clk := &clock{time.Unix(0, 0)}
+	s := NewExpiring(time.Second)
+	s.SetClock(clk.now)
+	s.Add(1, 2, 3)
+	s.Replace(3, 4)
+	if !s.IsEqual(New(1, 2, 4)) {
+		t.Errorf("Expected %v. Got %v.", New(1, 2, 4), s)
+	}
+	s.Merge(New(5))
+	s.Separate(New(1))
+	s.Retain(New(2, 4, 5, 6))
+	if !s.IsEqual(New(2, 4, 5)) {
+		t.Errorf("Expected %v. Got %v.", New(2, 4, 5), s)
+	}
+	if !s.IsSubset(New(2, 4)) || !s.IsSuperset(New(2, 4, 5, 6)) {
+		t.Errorf("Unexpected subset relation for %v.", s)
+	}
+	s.AddWithTTL(time.Hour, 42)
+	cpy := s.CopySet()
+	clk.t = clk.t.Add(time.Second)
+	if !cpy.IsEqual(New(42)) || cpy.String() != "[42]" {
+		t.Errorf("Copy should keep deadlines. Got %v.", cpy)
+	}
+	if item := cpy.Pop(); item != 42 || !cpy.IsEmpty() {
+		t.Errorf("Expected 42. Got %v.", item)
+	}
+	s.Clear()
+	if !s.IsEmpty() || s.CopyCollection().Len() != 0 {
+		t.Errorf("Expected empty. Got %v.", s)
+	}
+}
+
+func TestExpiringJanitor(t *testing.T) {
+	s := NewExpiring(time.Millisecond)
+	expired := make(chan interface{}, 1)
+	s.OnExpire(func(item interface{}) {
+		expired <- item
+	})
+	s.Add(1)
+	s.StartJanitor(time.Millisecond)
+	defer s.Close()
+	select {
+	case item := <-expired:
+		if item != 1 {
+			t.Errorf("Expected 1. Got %v.", item)
+		}
+	case <-time.After(time.Second):
+		t.Error("Janitor should have expired 1")
+	}
+}
+
+func TestReadOnly(t *testing.T) {
+	cases := []Interface{New(1, 2, 3), NewSync(1, 2, 3)}
+	for _, s := range cases {
+		r := NewReadOnly(s)
+		if NewReadOnly(r) != r {
+			t.Errorf("Expected %v. Got %v.", r, NewReadOnly(r))
+		}
+		mutators := []func(){
+			func() { r.Add(4) },
+			func() { r.Remove(1) },
+			func() { r.Pop() },
+			func() { r.Replace(1, 4) },
+			func() { r.Clear() },
+			func() { r.Merge(New(4)) },
+			func() { r.Separate(New(1)) },
+			func() { r.Retain(New(1)) },
+		}
+		for _, mutate := range mutators {
+			func() {
+				defer func() {
+					if r := recover(); r != collection.ErrReadOnly {
+						t.Errorf("Expected %v. Got %v.", collection.ErrReadOnly, r)
+					}
+				}()
+				mutate()
+			}()
+		}
+		if !r.IsEqual(New(1, 2, 3)) || r.Len() != 3 || r.IsEmpty() || !r.Has(1, 2) {
+			t.Errorf("Expected %v. Got %v.", s, r)
+		}
+		if !r.IsSubset(New(1)) || r.IsSuperset(New(1)) || len(r.String()) != len(s.String()) {
+			t.Errorf("Expected %v. Got %v.", true, false)
+		}
+		s.Add(4)
+		if !r.Has(4) || len(r.Slice()) != 4 {
+			t.Errorf("Expected %v. Got %v.", s, r)
+		}
+		count := 0
+		r.Each(func(item interface{}) bool {
+			count++
+			return true
+		})
+		cpy := r.CopySet()
+		cpy.Add(5)
+		r.CopyCollection().Add(5)
+		if count != 4 || r.Has(5) {
+			t.Errorf("Expected %v. Got %v.", 4, count)
+		}
+	}
+}
+
+func TestCOW(t *testing.T) {
+	s := NewCOW(1, 2, 3)
+	s.Add(4)
+	s.Remove(1)
+	s.Replace(2, 5)
+	if !s.IsEqual(New(3, 4, 5)) || s.Len() != 3 || s.IsEmpty() || !s.Has(3, 4) {
+		t.Errorf("Expected %v. Got %v.", New(3, 4, 5), s)
+	}
+	s.Merge(New(6))
+	s.Separate(New(3))
+	s.Retain(New(4, 5, 6, 7))
+	if !s.IsEqual(New(4, 5, 6)) || !s.IsSubset(New(4)) || s.IsSuperset(New(4)) || len(s.Slice()) != 3 {
+		t.Errorf("Expected %v. Got %v.", New(4, 5, 6), s)
+	}
+	cpy := s.CopySet()
+	cpy.Add(7)
+	if s.Has(7) || !cpy.Has(4, 7) || cpy.CopyCollection().Len() != 4 {
+		t.Errorf("Expected %v. Got %v.", s, cpy)
+	}
+	if item := s.Pop(); item == nil || s.Has(item) || s.Len() != 2 {
+		t.Errorf("Expected %v. Got %v.", 2, s.Len())
+	}
+	s.Clear()
+	if !s.IsEmpty() || s.String() != "[]" {
+		t.Errorf("Expected %v. Got %v.", "[]", s)
+	}
+}
+
+func TestCOWEach(t *testing.T) {
+	s := NewCOW(1, 2, 3)
+	visited := 0
+	s.Each(func(item interface{}) bool {
+		s.Add(item.(int) + 10)
+		visited++
+		return true
+	})
+	if visited != 3 || s.Len() != 6 {
+		t.Errorf("Expected %v. Got %v.", 3, visited)
+	}
+	var wg sync.WaitGroup
+	for g := 0; g < 4; g++ {
+		wg.Add(1)
+		go func(g int) {
+			defer wg.Done()
+			for i := 0; i < 100; i++ {
+				s.Add(g*1000 + i + 100)
+				s.Each(func(item interface{}) bool {
+					return true
+				})
+				s.(Snapshotter).Snapshot().Add(-1)
+			}
+		}(g)
+	}
+	wg.Wait()
+	if s.Len() != 406 || s.Has(-1) {
+		t.Errorf("Expected %v. Got %v.", 406, s.Len())
+	}
+}
+
+func TestSnapshot(t *testing.T) {
+	cases := []Interface{NewSync(1, 2, 3), NewCOW(1, 2, 3)}
+	for _, s := range cases {
+		snapshot := s.(Snapshotter).Snapshot()
+		if _, ok := snapshot.(*set); !ok {
+			t.Errorf("Expected %v. Got %T.", "*set", snapshot)
+		}
+		s.Add(4)
+		snapshot.Add(5)
+		if !snapshot.IsEqual(New(1, 2, 3, 5)) || !s.IsEqual(New(1, 2, 3, 4)) {
+			t.Errorf("Expected %v. Got %v.", New(1, 2, 3, 5), snapshot)
+		}
+	}
+}
+
+func TestObservable(t *testing.T) {
+	s := NewObservable(New(1, 2, 3))
+	mirror := New(1, 2, 3)
+	var events []observer.Event
+	var replay func(e observer.Event)
+	replay = func(e observer.Event) {
+		switch e.Op {
+		case observer.Add:
+			mirror.Add(e.New)
+		case observer.Remove:
+			mirror.Remove(e.Old)
+		case observer.Replace:
+			mirror.Replace(e.Old, e.New)
+		case observer.Clear:
+			mirror.Clear()
+		case observer.Batch:
+			for _, e := range e.Batch {
+				replay(e)
+			}
+		}
+	}
+	unsubscribe := s.Subscribe(func(e observer.Event) {
+		if e.Index != -1 {
+			t.Errorf("Expected %v. Got %v.", -1, e.Index)
+		}
+		events = append(events, e)
+		replay(e)
+	})
+	s.Add(3, 4, 4)
+	s.Remove(4, 9)
+	s.Replace(3, 30)
+	s.Replace(9, 90)
+	s.Merge(New(5, 6, 30))
+	s.Separate(New(5, 9))
+	s.Retain(New(1, 2, 6, 30, 7))
+	s.Merge(s)
+	if item := s.Pop(); s.Has(item) {
+		t.Errorf("Expected %v. Got %v.", false, true)
+	}
+	if !mirror.IsEqual(s) || s.Len() != 3 {
+		t.Errorf("Expected %v. Got %v.", s, mirror)
+	}
+	if len(events) != 6 {
+		t.Errorf("Expected %v. Got %v.", 6, len(events))
+	}
+	if !s.IsSubset(New()) || !s.IsSuperset(s) || s.IsEmpty() || len(s.Slice()) != 3 || s.CopyCollection().Len() != 3 {
+		t.Errorf("Expected %v. Got %v.", true, false)
+	}
+	s.Clear()
+	s.Clear()
+	if s.Pop() != nil || !mirror.IsEmpty() || len(events) != 7 || s.String() != "[]" {
+		t.Errorf("Expected %v. Got %v.", 7, len(events))
+	}
+	unsubscribe()
+	s.Add(1)
+	visited := 0
+	s.Each(func(item interface{}) bool {
+		visited++
+		return true
+	})
+	if visited != 1 || !mirror.IsEmpty() {
+		t.Errorf("Expected %v. Got %v.", 1, visited)
+	}
+}
+
+func TestObservableChan(t *testing.T) {
+	s := NewObservable(NewSync())
+	events, unsubscribe := s.SubscribeChan()
+	var wg sync.WaitGroup
+	for g := 0; g < 4; g++ {
+		wg.Add(1)
+		go func(g int) {
+			defer wg.Done()
+			for i := 0; i < 50; i++ {
+				s.Add(g*100 + i)
+			}
+		}(g)
+	}
+	wg.Wait()
+	received := New()
+	for i := 0; i < 200; i++ {
+		e := <-events
+		received.Add(e.Item)
+	}
+	if !received.IsEqual(s) {
+		t.Errorf("Expected %v. Got %v.", s.Len(), received.Len())
+	}
+	unsubscribe()
+	if _, open := <-events; open {
+		t.Errorf("Expected %v. Got %v.", false, open)
+	}
+}
+
+func TestDiff(t *testing.T) {
+	cases := []struct {
+		a, b           Interface
+		added, removed Interface
+	}{
+		{New(1, 2, 3), New(2, 3, 4, 5), New(4, 5), New(1)},
+		{New(1, 2), New(1, 2), New(), New()},
+		{New(), NewSync(1), New(1), New()},
+		{NewSync(1, 2), New(), New(), New(1, 2)},
+	}
+	for _, c := range cases {
+		d := collection.Diff(c.a, c.b)
+		if !New(d.Added...).IsEqual(c.added) || !New(d.Removed...).IsEqual(c.removed) {
+			t.Errorf("Expected %v %v. Got %v %v.", c.added, c.removed, d.Added, d.Removed)
+		}
+		if d.IsEmpty() != (c.added.IsEmpty() && c.removed.IsEmpty()) {
+			t.Errorf("Expected %v. Got %v.", !d.IsEmpty(), d.IsEmpty())
+		}
+		collection.Apply(c.a, d)
+		if !c.a.IsEqual(c.b) {
+			t.Errorf("Expected %v. Got %v.", c.b, c.a)
+		}
+	}
+}
+
+func TestFromSlice(t *testing.T) {
+	for _, from := range []func(interface{}) (Interface, error){FromSlice, FromSliceSync} {
+		c, err := from([]string{"a"})
+		testErr(err, false, t)
+		if !c.IsEqual(New("a")) {
+			t.Errorf("Expected %v. Got %v.", New("a"), c)
+		}
+		var strs []string
+		testErr(ToSlice(c, &strs), false, t)
+		if !reflect.DeepEqual(strs, []string{"a"}) {
+			t.Errorf("Expected %v. Got %v.", []string{"a"}, strs)
+		}
+	}
+}